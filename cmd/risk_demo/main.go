@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"gopkg.in/yaml.v3"
@@ -17,8 +18,14 @@ type customRiskRule string
 func main() {
 	getInfo := flag.Bool("get-info", false, "get rule info")
 	generateRisks := flag.Bool("generate-risks", false, "generate risks")
+	serve := flag.Bool("serve", false, "keep running and answer line-delimited JSON-RPC requests on stdin")
 	flag.Parse()
 
+	if *serve {
+		serveRequests()
+		os.Exit(0)
+	}
+
 	if *getInfo {
 		rule := new(customRiskRule)
 		riskData, marshalError := yaml.Marshal(new(model.CustomRiskCategory).Init(rule.Category(), rule.SupportedTags()))
@@ -67,6 +74,90 @@ func main() {
 	os.Exit(-2)
 }
 
+type rpcRequest struct {
+	ID     *int64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string    `json:"jsonrpc"`
+	ID      *int64    `json:"id"`
+	Result  any       `json:"result,omitempty"`
+	Error   *rpcError `json:"error,omitempty"`
+}
+
+// serveRequests answers requests until stdin is closed; the model sent by set_model is kept for the following
+// generate_risks requests
+func serveRequests() {
+	var parsedModel *types.Model
+	rule := new(customRiskRule)
+
+	reader := bufio.NewReader(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		line, readError := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var request rpcRequest
+			response := rpcResponse{JSONRPC: "2.0"}
+
+			if unmarshalError := json.Unmarshal(line, &request); unmarshalError != nil {
+				response.Error = &rpcError{Code: -32700, Message: unmarshalError.Error()}
+			} else {
+				response.ID = request.ID
+				switch request.Method {
+				case "get_info":
+					response.Result = []*model.CustomRiskCategory{new(model.CustomRiskCategory).Init(rule.Category(), rule.SupportedTags())}
+
+				case "set_model":
+					var params struct {
+						Model *types.Model `json:"model"`
+					}
+
+					if paramsError := json.Unmarshal(request.Params, &params); paramsError != nil {
+						response.Error = &rpcError{Code: -32602, Message: paramsError.Error()}
+						break
+					}
+
+					parsedModel = params.Model
+					response.Result = true
+
+				case "generate_risks":
+					if parsedModel == nil {
+						response.Error = &rpcError{Code: -32000, Message: "no model"}
+						break
+					}
+
+					generatedRisks, riskError := rule.GenerateRisks(parsedModel)
+					if riskError != nil {
+						response.Error = &rpcError{Code: -32000, Message: riskError.Error()}
+						break
+					}
+
+					response.Result = generatedRisks
+
+				default:
+					response.Error = &rpcError{Code: -32601, Message: "unknown method " + request.Method}
+				}
+			}
+
+			if encodeError := encoder.Encode(&response); encodeError != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "failed to write response: %v\n", encodeError)
+				return
+			}
+		}
+
+		if readError != nil {
+			return
+		}
+	}
+}
+
 func (r customRiskRule) Category() *types.RiskCategory {
 	return &types.RiskCategory{
 		ID:                         "demo",
@@ -0,0 +1,280 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxErrorOutput limits the captured stderr of a process, only the latest output is kept
+	maxErrorOutput = 64 * 1024
+
+	// waitDelay is how long to wait for the output of a process after it exited, which matters if the process left
+	// children behind that still hold its stdout or stderr
+	waitDelay = time.Second
+
+	// closeGracePeriod is how long Close waits for a plugin to exit after closing its stdin, regardless of the timeout
+	// of requests, which may be zero
+	closeGracePeriod = 5 * time.Second
+)
+
+// Process is a plugin started once and kept running for several requests, exchanging line-delimited JSON-RPC 2.0
+// messages via stdin/stdout: each request and each response is a single line of json. The plugin's stderr is captured
+// and added to the errors of failed requests.
+type Process struct {
+	Filename   string
+	Parameters []string
+	Timeout    time.Duration
+
+	mutex     sync.Mutex
+	command   *exec.Cmd
+	stdin     io.WriteCloser
+	stderr    *tailBuffer
+	responses chan []byte
+	stopped   chan struct{}
+	stopOnce  sync.Once
+	waited    chan struct{}
+	exited    chan struct{}
+	exitError error
+	lastID    int64
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is the error object of a JSON-RPC response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (what *RPCError) Error() string {
+	return fmt.Sprintf("%v (code %d)", what.Message, what.Code)
+}
+
+// Start runs the plugin with the given parameters; a timeout of zero lets requests wait forever
+func (p *Process) Start(filename string, timeout time.Duration, parameters ...string) (*Process, error) {
+	p.Filename = filename
+	p.Parameters = parameters
+	p.Timeout = timeout
+	p.stderr = new(tailBuffer)
+	p.responses = make(chan []byte)
+	p.stopped = make(chan struct{})
+	p.waited = make(chan struct{})
+	p.exited = make(chan struct{})
+
+	stdout, stdoutWriter := io.Pipe()
+	p.command = exec.Command(p.Filename, p.Parameters...) // #nosec G204
+	p.command.Stdout = stdoutWriter
+	p.command.Stderr = p.stderr
+	p.command.WaitDelay = waitDelay
+
+	stdin, stdinError := p.command.StdinPipe()
+	if stdinError != nil {
+		return p, stdinError
+	}
+	p.stdin = stdin
+
+	startError := p.command.Start()
+	if startError != nil {
+		return p, startError
+	}
+
+	go p.read(stdout)
+	go p.wait(stdoutWriter)
+
+	return p, nil
+}
+
+// Call sends a request and waits for its response, whose result is unmarshalled into result (if not nil).
+// If the plugin doesn't answer in time, it is killed and all further calls fail.
+func (p *Process) Call(method string, params any, result any) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	select {
+	case <-p.exited:
+		return p.exitedError()
+	default:
+	}
+
+	p.lastID++
+	request, marshalError := json.Marshal(&rpcRequest{JSONRPC: "2.0", ID: p.lastID, Method: method, Params: params})
+	if marshalError != nil {
+		return fmt.Errorf("error encoding %q request: %w", method, marshalError)
+	}
+
+	_, writeError := p.stdin.Write(append(request, '\n'))
+	if writeError != nil {
+		// most likely the plugin exited, which tells more than the failed write
+		select {
+		case <-p.exited:
+			return p.exitedError()
+		case <-time.After(waitDelay):
+		}
+
+		return fmt.Errorf("error sending %q request to plugin %q: %w%v", method, p.Filename, writeError, p.errorOutputSuffix())
+	}
+
+	var timeout <-chan time.Time
+	if p.Timeout > 0 {
+		timer := time.NewTimer(p.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		select {
+		case line := <-p.responses:
+			var response rpcResponse
+			unmarshalError := json.Unmarshal(line, &response)
+			if unmarshalError != nil {
+				return fmt.Errorf("invalid response of plugin %q to %q: %w", p.Filename, method, unmarshalError)
+			}
+
+			// not the answer to this request, e.g. a notification
+			if response.ID == nil || *response.ID != p.lastID {
+				continue
+			}
+
+			if response.Error != nil {
+				return fmt.Errorf("plugin %q failed to answer %q: %w%v", p.Filename, method, response.Error, p.errorOutputSuffix())
+			}
+
+			if result == nil || len(response.Result) == 0 {
+				return nil
+			}
+
+			resultError := json.Unmarshal(response.Result, result)
+			if resultError != nil {
+				return fmt.Errorf("invalid result of plugin %q for %q: %w", p.Filename, method, resultError)
+			}
+
+			return nil
+
+		case <-p.exited:
+			return p.exitedError()
+
+		case <-timeout:
+			p.stop()
+			_ = p.command.Process.Kill()
+			return fmt.Errorf("plugin %q did not answer %q within %v%v", p.Filename, method, p.Timeout, p.errorOutputSuffix())
+		}
+	}
+}
+
+// Close closes the plugin's stdin, which asks it to exit, and kills it if it doesn't exit within closeGracePeriod
+func (p *Process) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.stop()
+	_ = p.stdin.Close()
+
+	timer := time.NewTimer(closeGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-p.exited:
+	case <-timer.C:
+		_ = p.command.Process.Kill()
+		<-p.exited
+	}
+
+	return nil
+}
+
+// ErrorOutput is the latest output of the plugin to stderr
+func (p *Process) ErrorOutput() string {
+	return p.stderr.String()
+}
+
+func (p *Process) read(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	for {
+		line, readError := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			select {
+			case p.responses <- line:
+			case <-p.stopped:
+			}
+		}
+
+		if readError != nil {
+			break
+		}
+	}
+
+	<-p.waited
+	close(p.exited)
+}
+
+func (p *Process) wait(stdout *io.PipeWriter) {
+	p.exitError = p.command.Wait()
+	close(p.waited)
+	_ = stdout.Close()
+}
+
+// stop drops all further output of the plugin
+func (p *Process) stop() {
+	p.stopOnce.Do(func() { close(p.stopped) })
+}
+
+func (p *Process) exitedError() error {
+	if p.exitError != nil {
+		return fmt.Errorf("plugin %q exited: %w%v", p.Filename, p.exitError, p.errorOutputSuffix())
+	}
+
+	return fmt.Errorf("plugin %q exited%v", p.Filename, p.errorOutputSuffix())
+}
+
+func (p *Process) errorOutputSuffix() string {
+	errorOutput := strings.TrimSpace(p.ErrorOutput())
+	if len(errorOutput) == 0 {
+		return ""
+	}
+
+	return ": " + errorOutput
+}
+
+// tailBuffer keeps the latest maxErrorOutput bytes written to it
+type tailBuffer struct {
+	mutex sync.Mutex
+	data  []byte
+}
+
+func (what *tailBuffer) Write(data []byte) (int, error) {
+	what.mutex.Lock()
+	defer what.mutex.Unlock()
+
+	what.data = append(what.data, data...)
+	if len(what.data) > maxErrorOutput {
+		what.data = what.data[len(what.data)-maxErrorOutput:]
+	}
+
+	return len(data), nil
+}
+
+func (what *tailBuffer) String() string {
+	what.mutex.Lock()
+	defer what.mutex.Unlock()
+
+	return string(what.data)
+}
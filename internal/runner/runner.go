@@ -1,6 +1,5 @@
-// TODO: consider moving to internal
-
-package model
+// Package runner executes plugins (custom risk rules, custom model macros) as external programs exchanging yaml via stdin/stdout
+package runner
 
 import (
 	"bytes"
@@ -10,7 +9,7 @@ import (
 	"os/exec"
 )
 
-type runner struct {
+type Runner struct {
 	Filename    string
 	Parameters  []string
 	In          any
@@ -18,8 +17,8 @@ type runner struct {
 	ErrorOutput string
 }
 
-func (p *runner) Load(filename string) (*runner, error) {
-	*p = runner{
+func (p *Runner) Load(filename string) (*Runner, error) {
+	*p = Runner{
 		Filename: filename,
 	}
 
@@ -35,8 +34,8 @@ func (p *runner) Load(filename string) (*runner, error) {
 	return p, nil
 }
 
-func (p *runner) Run(in any, out any, parameters ...string) error {
-	*p = runner{
+func (p *Runner) Run(in any, out any, parameters ...string) error {
+	*p = Runner{
 		Filename:   p.Filename,
 		Parameters: parameters,
 		In:         in,
@@ -88,7 +87,5 @@ func (p *runner) Run(in any, out any, parameters ...string) error {
 		return unmarshalError
 	}
 
-	// _ = os.WriteFile(fmt.Sprintf("%v.yaml", rand.Int31()), stdout, 0644)
-
 	return nil
 }
@@ -19,16 +19,21 @@ func (what *Threagile) initAnalyze() *Threagile {
 			commands := what.readCommands()
 			progressReporter := DefaultProgressReporter{Verbose: what.config.GetVerbose()}
 
-			r, err := model.ReadAndAnalyzeModel(what.config, risks.GetBuiltInRiskRules(), progressReporter)
+			riskRules := risks.GetRiskRules(what.config.GetScriptRulesDir(), progressReporter)
+			customRiskRules := model.LoadCustomRiskRules(what.config, progressReporter)
+			defer model.CloseCustomRiskRules(customRiskRules)
+
+			r, err := model.ReadAndAnalyzeModel(what.config, riskRules, customRiskRules, progressReporter)
 			if err != nil {
 				return fmt.Errorf("failed to read and analyze model: %w", err)
 			}
 
-			err = report.Generate(what.config, r, commands, risks.GetBuiltInRiskRules(), progressReporter)
+			err = report.Generate(what.config, r, commands, riskRules, progressReporter)
 			if err != nil {
 				return fmt.Errorf("failed to generate reports: %w", err)
 			}
-			return nil
+
+			return what.checkRiskPolicy(cmd, r)
 		},
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: true,
@@ -39,3 +44,30 @@ func (what *Threagile) initAnalyze() *Threagile {
 
 	return what
 }
+
+func (what *Threagile) checkRiskPolicy(cmd *cobra.Command, r *model.ReadResult) error {
+	policy, policyError := model.ParseRiskPolicy(what.config.GetFailOn(), what.config.GetFailOnAllowedRiskCategories())
+	if policyError != nil {
+		return policyError
+	}
+
+	if len(policy.Rules) == 0 {
+		return nil
+	}
+
+	violations := policy.Evaluate(r.ParsedModel)
+	if len(violations) == 0 {
+		cmd.Printf("Risk policy passed (%d rule(s) checked)\n", len(policy.Rules))
+		return nil
+	}
+
+	cmd.Println("Risk policy failed:")
+	for _, violation := range violations {
+		cmd.Printf("  %q: found %d, allowed %d\n", violation.Rule, violation.Found, violation.Allowed)
+		for _, riskID := range violation.RiskIDs {
+			cmd.Printf("    - %v\n", riskID)
+		}
+	}
+
+	return fmt.Errorf("risk policy violated by %d of %d rule(s)", len(violations), len(policy.Rules))
+}
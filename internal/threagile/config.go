@@ -39,27 +39,47 @@ type Config struct {
 	DataFlowDiagramFilenameDOTValue  string `json:"DataFlowDiagramFilenameDOT,omitempty" yaml:"DataFlowDiagramFilenameDOT"`
 	DataAssetDiagramFilenameDOTValue string `json:"DataAssetDiagramFilenameDOT,omitempty" yaml:"DataAssetDiagramFilenameDOT"`
 	ReportFilenameValue              string `json:"ReportFilename,omitempty" yaml:"ReportFilename"`
+	HtmlReportFilenameValue          string `json:"HtmlReportFilename,omitempty" yaml:"HtmlReportFilename"`
 	ExcelRisksFilenameValue          string `json:"ExcelRisksFilename,omitempty" yaml:"ExcelRisksFilename"`
 	ExcelTagsFilenameValue           string `json:"ExcelTagsFilename,omitempty" yaml:"ExcelTagsFilename"`
 	JsonRisksFilenameValue           string `json:"JsonRisksFilename,omitempty" yaml:"JsonRisksFilename"`
 	JsonTechnicalAssetsFilenameValue string `json:"JsonTechnicalAssetsFilename,omitempty" yaml:"JsonTechnicalAssetsFilename"`
 	JsonStatsFilenameValue           string `json:"JsonStatsFilename,omitempty" yaml:"JsonStatsFilename"`
+	SarifFilenameValue               string `json:"SarifFilename,omitempty" yaml:"SarifFilename"`
 	TemplateFilenameValue            string `json:"TemplateFilename,omitempty" yaml:"TemplateFilename"`
 	ReportLogoImagePathValue         string `json:"ReportLogoImagePath,omitempty" yaml:"ReportLogoImagePath"`
 	TechnologyFilenameValue          string `json:"TechnologyFilename,omitempty" yaml:"TechnologyFilename"`
 	HideEmptyChaptersValue           bool   `json:"HideEmptyChapters,omitempty" yaml:"HideEmptyChapters"`
 
-	RiskRulePluginsValue   []string        `json:"RiskRulePlugins,omitempty" yaml:"RiskRulePlugins"`
-	SkipRiskRulesValue     []string        `json:"SkipRiskRules,omitempty" yaml:"SkipRiskRules"`
-	ExecuteModelMacroValue string          `json:"ExecuteModelMacro,omitempty" yaml:"ExecuteModelMacro"`
-	RiskExcelValue         RiskExcelConfig `json:"RiskExcel" yaml:"RiskExcel"`
-
-	ServerModeValue               bool `json:"ServerMode,omitempty" yaml:"ServerMode"`
-	ServerPortValue               int  `json:"ServerPort,omitempty" yaml:"ServerPort"`
-	DiagramDPIValue               int  `json:"DiagramDPI,omitempty" yaml:"DiagramDPI"`
-	GraphvizDPIValue              int  `json:"GraphvizDPI,omitempty" yaml:"GraphvizDPI"`
-	MaxGraphvizDPIValue           int  `json:"MaxGraphvizDPI,omitempty" yaml:"MaxGraphvizDPI"`
-	BackupHistoryFilesToKeepValue int  `json:"BackupHistoryFilesToKeep,omitempty" yaml:"BackupHistoryFilesToKeep"`
+	RiskRulePluginsValue           []string        `json:"RiskRulePlugins,omitempty" yaml:"RiskRulePlugins"`
+	PersistentRiskRulePluginsValue []string        `json:"PersistentRiskRulePlugins,omitempty" yaml:"PersistentRiskRulePlugins"`
+	PluginTimeoutValue             int             `json:"PluginTimeout,omitempty" yaml:"PluginTimeout"`
+	CustomRisksFileValue           string          `json:"-" yaml:"-"` // set by the server for its sub-processes only
+	SkipRiskRulesValue             []string        `json:"SkipRiskRules,omitempty" yaml:"SkipRiskRules"`
+	ScriptRulesDirValue            string          `json:"ScriptRulesDir,omitempty" yaml:"ScriptRulesDir"`
+	ExecuteModelMacroValue         string          `json:"ExecuteModelMacro,omitempty" yaml:"ExecuteModelMacro"`
+	MacroAnswersValue              string          `json:"MacroAnswers,omitempty" yaml:"MacroAnswers"`
+	RiskExcelValue                 RiskExcelConfig `json:"RiskExcel" yaml:"RiskExcel"`
+
+	FailOnValue                      []string `json:"FailOn,omitempty" yaml:"FailOn"`
+	FailOnAllowedRiskCategoriesValue []string `json:"FailOnAllowedRiskCategories,omitempty" yaml:"FailOnAllowedRiskCategories"`
+
+	ServerModeValue               bool   `json:"ServerMode,omitempty" yaml:"ServerMode"`
+	ServerPortValue               int    `json:"ServerPort,omitempty" yaml:"ServerPort"`
+	ServerStorageValue            string `json:"ServerStorage,omitempty" yaml:"ServerStorage"`
+	ServerDatabaseValue           string `json:"ServerDatabase,omitempty" yaml:"ServerDatabase"`
+	ServerAuthValue               string `json:"ServerAuth,omitempty" yaml:"ServerAuth"`
+	OIDCIssuerValue               string `json:"OIDCIssuer,omitempty" yaml:"OIDCIssuer"`
+	OIDCAudienceValue             string `json:"OIDCAudience,omitempty" yaml:"OIDCAudience"`
+	OIDCJWKSValue                 string `json:"OIDCJWKS,omitempty" yaml:"OIDCJWKS"`
+	OIDCGroupsClaimValue          string `json:"OIDCGroupsClaim,omitempty" yaml:"OIDCGroupsClaim"`
+	OIDCRolesClaimValue           string `json:"OIDCRolesClaim,omitempty" yaml:"OIDCRolesClaim"`
+	OIDCSecretFileValue           string `json:"OIDCSecretFile,omitempty" yaml:"OIDCSecretFile"`
+	DiagramDPIValue               int    `json:"DiagramDPI,omitempty" yaml:"DiagramDPI"`
+	DiagramRendererValue          string `json:"DiagramRenderer,omitempty" yaml:"DiagramRenderer"`
+	GraphvizDPIValue              int    `json:"GraphvizDPI,omitempty" yaml:"GraphvizDPI"`
+	MaxGraphvizDPIValue           int    `json:"MaxGraphvizDPI,omitempty" yaml:"MaxGraphvizDPI"`
+	BackupHistoryFilesToKeepValue int    `json:"BackupHistoryFilesToKeep,omitempty" yaml:"BackupHistoryFilesToKeep"`
 
 	AddModelTitleValue              bool `json:"AddModelTitle,omitempty" yaml:"AddModelTitle"`
 	AddLegendValue                  bool `json:"AddLegend,omitempty" yaml:"AddLegend"`
@@ -71,9 +91,11 @@ type Config struct {
 	SkipRisksJSONValue           bool `json:"SkipRisksJSON,omitempty" yaml:"SkipRisksJSON"`
 	SkipTechnicalAssetsJSONValue bool `json:"SkipTechnicalAssetsJSON,omitempty" yaml:"SkipTechnicalAssetsJSON"`
 	SkipStatsJSONValue           bool `json:"SkipStatsJSON,omitempty" yaml:"SkipStatsJSON"`
+	SkipSarifValue               bool `json:"SkipSarif,omitempty" yaml:"SkipSarif"`
 	SkipRisksExcelValue          bool `json:"SkipRisksExcel,omitempty" yaml:"SkipRisksExcel"`
 	SkipTagsExcelValue           bool `json:"SkipTagsExcel,omitempty" yaml:"SkipTagsExcel"`
 	SkipReportPDFValue           bool `json:"SkipReportPDF,omitempty" yaml:"SkipReportPDF"`
+	SkipReportHTMLValue          bool `json:"SkipReportHTML,omitempty" yaml:"SkipReportHTML"`
 	SkipReportADOCValue          bool `json:"SkipReportADOC,omitempty" yaml:"SkipReportADOC"`
 
 	AttractivenessValue Attractiveness `json:"Attractiveness" yaml:"Attractiveness"`
@@ -99,25 +121,44 @@ type ConfigGetter interface {
 	GetDataFlowDiagramFilenameDOT() string
 	GetDataAssetDiagramFilenameDOT() string
 	GetReportFilename() string
+	GetHtmlReportFilename() string
 	GetExcelRisksFilename() string
 	GetExcelTagsFilename() string
 	GetJsonRisksFilename() string
 	GetJsonTechnicalAssetsFilename() string
 	GetJsonStatsFilename() string
+	GetSarifFilename() string
 	GetReportLogoImagePath() string
 	GetTemplateFilename() string
 	GetRiskRulePlugins() []string
+	GetPersistentRiskRulePlugins() []string
+	GetPluginTimeout() int
+	GetCustomRisksFile() string
+	GetScriptRulesDir() string
 	GetSkipRiskRules() []string
 	GetExecuteModelMacro() string
+	GetMacroAnswers() string
 	GetRiskExcelConfigHideColumns() []string
 	GetRiskExcelConfigSortByColumns() []string
 	GetRiskExcelConfigWidthOfColumns() map[string]float64
 	GetRiskExcelWrapText() bool
 	GetRiskExcelShrinkColumnsToFit() bool
 	GetRiskExcelColorText() bool
+	GetFailOn() []string
+	GetFailOnAllowedRiskCategories() []string
 	GetServerMode() bool
 	GetServerPort() int
+	GetServerStorage() string
+	GetServerDatabase() string
+	GetServerAuth() string
+	GetOIDCIssuer() string
+	GetOIDCAudience() string
+	GetOIDCJWKS() string
+	GetOIDCGroupsClaim() string
+	GetOIDCRolesClaim() string
+	GetOIDCSecretFile() string
 	GetDiagramDPI() int
+	GetDiagramRenderer() string
 	GetGraphvizDPI() int
 	GetMinGraphvizDPI() int
 	GetMaxGraphvizDPI() int
@@ -131,9 +172,11 @@ type ConfigGetter interface {
 	GetSkipRisksJSON() bool
 	GetSkipTechnicalAssetsJSON() bool
 	GetSkipStatsJSON() bool
+	GetSkipSarif() bool
 	GetSkipRisksExcel() bool
 	GetSkipTagsExcel() bool
 	GetSkipReportPDF() bool
+	GetSkipReportHTML() bool
 	GetSkipReportADOC() bool
 	GetAttractiveness() Attractiveness
 	GetReportConfiguration() report.ReportConfiguation
@@ -179,19 +222,25 @@ func (c *Config) Defaults(buildTimestamp string) *Config {
 		DataFlowDiagramFilenameDOTValue:  DataFlowDiagramFilenameDOT,
 		DataAssetDiagramFilenameDOTValue: DataAssetDiagramFilenameDOT,
 		ReportFilenameValue:              ReportFilename,
+		HtmlReportFilenameValue:          HtmlReportFilename,
 		ExcelRisksFilenameValue:          ExcelRisksFilename,
 		ExcelTagsFilenameValue:           ExcelTagsFilename,
 		JsonRisksFilenameValue:           JsonRisksFilename,
 		JsonTechnicalAssetsFilenameValue: JsonTechnicalAssetsFilename,
 		JsonStatsFilenameValue:           JsonStatsFilename,
+		SarifFilenameValue:               SarifFilename,
 		TemplateFilenameValue:            TemplateFilename,
 		ReportLogoImagePathValue:         ReportLogoImagePath,
 		TechnologyFilenameValue:          "",
 		HideEmptyChaptersValue:           false,
 
-		RiskRulePluginsValue:   make([]string, 0),
-		SkipRiskRulesValue:     make([]string, 0),
-		ExecuteModelMacroValue: "",
+		RiskRulePluginsValue:           make([]string, 0),
+		PersistentRiskRulePluginsValue: make([]string, 0),
+		PluginTimeoutValue:             DefaultPluginTimeout,
+		ScriptRulesDirValue:            "",
+		SkipRiskRulesValue:             make([]string, 0),
+		ExecuteModelMacroValue:         "",
+		MacroAnswersValue:              "",
 		RiskExcelValue: RiskExcelConfig{
 			HideColumns:        make([]string, 0),
 			SortByColumns:      make([]string, 0),
@@ -201,9 +250,22 @@ func (c *Config) Defaults(buildTimestamp string) *Config {
 			ColorText:          true,
 		},
 
+		FailOnValue:                      make([]string, 0),
+		FailOnAllowedRiskCategoriesValue: make([]string, 0),
+
 		ServerModeValue:               false,
 		DiagramDPIValue:               DefaultDiagramDPI,
+		DiagramRendererValue:          DefaultDiagramRenderer,
 		ServerPortValue:               DefaultServerPort,
+		ServerStorageValue:            DefaultServerStorage,
+		ServerDatabaseValue:           ServerDatabase,
+		ServerAuthValue:               DefaultServerAuth,
+		OIDCIssuerValue:               "",
+		OIDCAudienceValue:             "",
+		OIDCJWKSValue:                 "",
+		OIDCGroupsClaimValue:          DefaultOIDCGroupsClaim,
+		OIDCRolesClaimValue:           DefaultOIDCRolesClaim,
+		OIDCSecretFileValue:           OIDCSecretFile,
 		GraphvizDPIValue:              DefaultGraphvizDPI,
 		MaxGraphvizDPIValue:           MaxGraphvizDPI,
 		BackupHistoryFilesToKeepValue: DefaultBackupHistoryFilesToKeep,
@@ -395,6 +457,9 @@ func (c *Config) Merge(config Config, values map[string]any) {
 		case strings.ToLower("ReportFilename"):
 			c.ReportFilenameValue = config.ReportFilenameValue
 
+		case strings.ToLower("HtmlReportFilename"):
+			c.HtmlReportFilenameValue = config.HtmlReportFilenameValue
+
 		case strings.ToLower("ExcelRisksFilename"):
 			c.ExcelRisksFilenameValue = config.ExcelRisksFilenameValue
 
@@ -410,6 +475,9 @@ func (c *Config) Merge(config Config, values map[string]any) {
 		case strings.ToLower("JsonStatsFilename"):
 			c.JsonStatsFilenameValue = config.JsonStatsFilenameValue
 
+		case strings.ToLower("SarifFilename"):
+			c.SarifFilenameValue = config.SarifFilenameValue
+
 		case strings.ToLower("TemplateFilename"):
 			c.TemplateFilenameValue = config.TemplateFilenameValue
 
@@ -425,12 +493,24 @@ func (c *Config) Merge(config Config, values map[string]any) {
 		case strings.ToLower("RiskRulePlugins"):
 			c.RiskRulePluginsValue = config.RiskRulePluginsValue
 
+		case strings.ToLower("PersistentRiskRulePlugins"):
+			c.PersistentRiskRulePluginsValue = config.PersistentRiskRulePluginsValue
+
+		case strings.ToLower("PluginTimeout"):
+			c.PluginTimeoutValue = config.PluginTimeoutValue
+
+		case strings.ToLower("ScriptRulesDir"):
+			c.ScriptRulesDirValue = config.ScriptRulesDirValue
+
 		case strings.ToLower("SkipRiskRules"):
 			c.SkipRiskRulesValue = config.SkipRiskRulesValue
 
 		case strings.ToLower("ExecuteModelMacro"):
 			c.ExecuteModelMacroValue = config.ExecuteModelMacroValue
 
+		case strings.ToLower("MacroAnswers"):
+			c.MacroAnswersValue = config.MacroAnswersValue
+
 		case strings.ToLower("RiskExcel"):
 			configMap, mapOk := values[key].(map[string]any)
 			if !mapOk {
@@ -465,15 +545,51 @@ func (c *Config) Merge(config Config, values map[string]any) {
 				}
 			}
 
+		case strings.ToLower("FailOn"):
+			c.FailOnValue = config.FailOnValue
+
+		case strings.ToLower("FailOnAllowedRiskCategories"):
+			c.FailOnAllowedRiskCategoriesValue = config.FailOnAllowedRiskCategoriesValue
+
 		case strings.ToLower("ServerMode"):
 			c.ServerModeValue = config.ServerModeValue
 
 		case strings.ToLower("DiagramDPI"):
 			c.DiagramDPIValue = config.DiagramDPIValue
 
+		case strings.ToLower("DiagramRenderer"):
+			c.DiagramRendererValue = config.DiagramRendererValue
+
 		case strings.ToLower("ServerPort"):
 			c.ServerPortValue = config.ServerPortValue
 
+		case strings.ToLower("ServerStorage"):
+			c.ServerStorageValue = config.ServerStorageValue
+
+		case strings.ToLower("ServerDatabase"):
+			c.ServerDatabaseValue = config.ServerDatabaseValue
+
+		case strings.ToLower("ServerAuth"):
+			c.ServerAuthValue = config.ServerAuthValue
+
+		case strings.ToLower("OIDCIssuer"):
+			c.OIDCIssuerValue = config.OIDCIssuerValue
+
+		case strings.ToLower("OIDCAudience"):
+			c.OIDCAudienceValue = config.OIDCAudienceValue
+
+		case strings.ToLower("OIDCJWKS"):
+			c.OIDCJWKSValue = config.OIDCJWKSValue
+
+		case strings.ToLower("OIDCGroupsClaim"):
+			c.OIDCGroupsClaimValue = config.OIDCGroupsClaimValue
+
+		case strings.ToLower("OIDCRolesClaim"):
+			c.OIDCRolesClaimValue = config.OIDCRolesClaimValue
+
+		case strings.ToLower("OIDCSecretFile"):
+			c.OIDCSecretFileValue = config.OIDCSecretFileValue
+
 		case strings.ToLower("GraphvizDPI"):
 			c.GraphvizDPIValue = config.GraphvizDPIValue
 
@@ -679,6 +795,10 @@ func (c *Config) GetReportFilename() string {
 	return c.ReportFilenameValue
 }
 
+func (c *Config) GetHtmlReportFilename() string {
+	return c.HtmlReportFilenameValue
+}
+
 func (c *Config) GetExcelRisksFilename() string {
 	return c.ExcelRisksFilenameValue
 }
@@ -699,6 +819,10 @@ func (c *Config) GetJsonStatsFilename() string {
 	return c.JsonStatsFilenameValue
 }
 
+func (c *Config) GetSarifFilename() string {
+	return c.SarifFilenameValue
+}
+
 func (c *Config) GetReportLogoImagePath() string {
 	return c.ReportLogoImagePathValue
 }
@@ -719,6 +843,22 @@ func (c *Config) SetRiskRulePlugins(riskRulePlugins []string) {
 	c.RiskRulePluginsValue = riskRulePlugins
 }
 
+func (c *Config) GetPersistentRiskRulePlugins() []string {
+	return c.PersistentRiskRulePluginsValue
+}
+
+func (c *Config) GetPluginTimeout() int {
+	return c.PluginTimeoutValue
+}
+
+func (c *Config) GetCustomRisksFile() string {
+	return c.CustomRisksFileValue
+}
+
+func (c *Config) GetScriptRulesDir() string {
+	return c.ScriptRulesDirValue
+}
+
 func (c *Config) GetSkipRiskRules() []string {
 	return c.SkipRiskRulesValue
 }
@@ -731,6 +871,10 @@ func (c *Config) GetExecuteModelMacro() string {
 	return c.ExecuteModelMacroValue
 }
 
+func (c *Config) GetMacroAnswers() string {
+	return c.MacroAnswersValue
+}
+
 func (c *Config) GetRiskExcelConfigHideColumns() []string {
 	return c.RiskExcelValue.HideColumns
 }
@@ -755,6 +899,14 @@ func (c *Config) GetRiskExcelColorText() bool {
 	return c.RiskExcelValue.ColorText
 }
 
+func (c *Config) GetFailOn() []string {
+	return c.FailOnValue
+}
+
+func (c *Config) GetFailOnAllowedRiskCategories() []string {
+	return c.FailOnAllowedRiskCategoriesValue
+}
+
 func (c *Config) GetServerMode() bool {
 	return c.ServerModeValue
 }
@@ -771,6 +923,42 @@ func (c *Config) SetServerPort(serverPort int) {
 	c.ServerPortValue = serverPort
 }
 
+func (c *Config) GetServerStorage() string {
+	return c.ServerStorageValue
+}
+
+func (c *Config) GetServerDatabase() string {
+	return c.ServerDatabaseValue
+}
+
+func (c *Config) GetServerAuth() string {
+	return c.ServerAuthValue
+}
+
+func (c *Config) GetOIDCIssuer() string {
+	return c.OIDCIssuerValue
+}
+
+func (c *Config) GetOIDCAudience() string {
+	return c.OIDCAudienceValue
+}
+
+func (c *Config) GetOIDCJWKS() string {
+	return c.OIDCJWKSValue
+}
+
+func (c *Config) GetOIDCGroupsClaim() string {
+	return c.OIDCGroupsClaimValue
+}
+
+func (c *Config) GetOIDCRolesClaim() string {
+	return c.OIDCRolesClaimValue
+}
+
+func (c *Config) GetOIDCSecretFile() string {
+	return c.OIDCSecretFileValue
+}
+
 func (c *Config) GetDiagramDPI() int {
 	return c.DiagramDPIValue
 }
@@ -779,6 +967,10 @@ func (c *Config) SetDiagramDPI(diagramDPI int) {
 	c.DiagramDPIValue = diagramDPI
 }
 
+func (c *Config) GetDiagramRenderer() string {
+	return c.DiagramRendererValue
+}
+
 func (c *Config) GetGraphvizDPI() int {
 	return c.GraphvizDPIValue
 }
@@ -835,6 +1027,10 @@ func (c *Config) GetSkipStatsJSON() bool {
 	return c.SkipStatsJSONValue
 }
 
+func (c *Config) GetSkipSarif() bool {
+	return c.SkipSarifValue
+}
+
 func (c *Config) GetSkipRisksExcel() bool {
 	return c.SkipRisksExcelValue
 }
@@ -847,6 +1043,10 @@ func (c *Config) GetSkipReportPDF() bool {
 	return c.SkipReportPDFValue
 }
 
+func (c *Config) GetSkipReportHTML() bool {
+	return c.SkipReportHTMLValue
+}
+
 func (c *Config) GetSkipReportADOC() bool {
 	return c.SkipReportADOCValue
 }
@@ -9,15 +9,25 @@ const (
 	ServerDir = "/app/server"
 	KeyDir    = "keys"
 
-	DefaultServerPort = 8080
+	DefaultServerPort    = 8080
+	DefaultServerStorage = "filesystem"
+	ServerDatabase       = "threagile.db"
+
+	DefaultServerAuth      = "key"
+	DefaultOIDCGroupsClaim = "groups"
+	DefaultOIDCRolesClaim  = "roles"
+	OIDCSecretFile         = "oidc-secret.key"
 
 	InputFile                   = "threagile.yaml"
+	ImportedModelFilename       = "threagile-imported-model.yaml"
 	ReportFilename              = "report.pdf"
+	HtmlReportFilename          = "report.html"
 	ExcelRisksFilename          = "risks.xlsx"
 	ExcelTagsFilename           = "tags.xlsx"
 	JsonRisksFilename           = "risks.json"
 	JsonTechnicalAssetsFilename = "technical-assets.json"
 	JsonStatsFilename           = "stats.json"
+	SarifFilename               = "risks.sarif"
 	TemplateFilename            = "background.pdf"
 	ReportLogoImagePath         = "report/threagile-logo.png"
 	DataFlowDiagramFilenameDOT  = "data-flow-diagram.gv"
@@ -26,10 +36,12 @@ const (
 	DataAssetDiagramFilenamePNG = "data-asset-diagram.png"
 
 	DefaultDiagramDPI               = 100
+	DefaultDiagramRenderer          = "graphviz"
 	DefaultGraphvizDPI              = 120
 	MinGraphvizDPI                  = 20
 	MaxGraphvizDPI                  = 300
 	DefaultBackupHistoryFilesToKeep = 50
+	DefaultPluginTimeout            = 60
 )
 
 const (
@@ -37,12 +49,19 @@ const (
 	CreateExampleModelCommand   = "create-example-model"
 	CreateStubModelCommand      = "create-stub-model"
 	CreateEditingSupportCommand = "create-editing-support"
+	DiffCommand                 = "diff"
+	MigrateServerStorageCommand = "migrate-server-storage"
+	ServerCommand               = "server"
+	TestRulesCommand            = "test-rules"
 	ImportModelCommand         	= "import-model"
 	ListTypesCommand            = "list-types"
 	ListRiskRulesCommand        = "list-risk-rules"
 	ListModelMacrosCommand      = "list-model-macros"
 	Print3rdPartyCommand        = "print-3rd-party-licenses"
 	PrintLicenseCommand         = "print-license"
+	ValidateCommand             = "validate"
+	ImportThreatDragonCommand   = "threat-dragon"
+	ImportTMTCommand            = "tmt"
 
 	CreateCommand       = "create"
 	ExplainCommand      = "explain"
@@ -0,0 +1,59 @@
+package threagile
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/report"
+	"github.com/threagile/threagile/pkg/risks"
+)
+
+func (what *Threagile) initDiff() *Threagile {
+	var baselineFile string
+	var inputFile string
+	var format string
+
+	diffCmd := &cobra.Command{
+		Use:   DiffCommand,
+		Short: "Compare two models and report new, resolved and changed risks",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			what.processArgs(cmd, args)
+
+			if len(baselineFile) == 0 {
+				return fmt.Errorf("no baseline model given, use --%v", baselineFlagName)
+			}
+
+			if len(inputFile) == 0 {
+				inputFile = what.config.GetInputFile()
+			}
+
+			progressReporter := DefaultProgressReporter{Verbose: what.config.GetVerbose()}
+
+			riskRules := risks.GetRiskRules(what.config.GetScriptRulesDir(), progressReporter)
+			customRiskRules := model.LoadCustomRiskRules(what.config, progressReporter)
+			defer model.CloseCustomRiskRules(customRiskRules)
+
+			baseline, baselineError := model.ReadAndAnalyzeModelFile(what.config.CleanPath(baselineFile), what.config, riskRules, customRiskRules, progressReporter)
+			if baselineError != nil {
+				return fmt.Errorf("failed to read and analyze baseline model: %w", baselineError)
+			}
+
+			current, currentError := model.ReadAndAnalyzeModelFile(what.config.CleanPath(inputFile), what.config, riskRules, customRiskRules, progressReporter)
+			if currentError != nil {
+				return fmt.Errorf("failed to read and analyze model: %w", currentError)
+			}
+
+			diff := model.DiffModels(baseline.ParsedModel, current.ParsedModel)
+			return report.WriteDiff(cmd.OutOrStdout(), diff, format)
+		},
+	}
+
+	diffCmd.Flags().StringVar(&baselineFile, baselineFlagName, "", "baseline model yaml file to compare against")
+	diffCmd.Flags().StringVar(&inputFile, diffInputFlagName, "", "model yaml file to compare (default: the file given by --"+inputFileFlagName+")")
+	diffCmd.Flags().StringVar(&format, formatFlagName, report.DiffFormatText, "output format: "+report.DiffFormatText+", "+report.DiffFormatJSON+" or "+report.DiffFormatMarkdown)
+
+	what.rootCmd.AddCommand(diffCmd)
+
+	return what
+}
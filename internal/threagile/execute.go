@@ -24,13 +24,25 @@ func (what *Threagile) initExecute() *Threagile {
 
 			progressReporter := DefaultProgressReporter{Verbose: what.config.GetVerbose()}
 
-			r, err := model.ReadAndAnalyzeModel(what.config, risks.GetBuiltInRiskRules(), progressReporter)
+			customRiskRules := model.LoadCustomRiskRules(what.config, progressReporter)
+			defer model.CloseCustomRiskRules(customRiskRules)
+
+			r, err := model.ReadAndAnalyzeModel(what.config, risks.GetRiskRules(what.config.GetScriptRulesDir(), progressReporter), customRiskRules, progressReporter)
 			if err != nil {
 				return fmt.Errorf("unable to read and analyze model: %w", err)
 			}
 
 			macrosId := args[0]
-			err = macros.ExecuteModelMacro(r.ModelInput, what.config.GetInputFile(), r.ParsedModel, macrosId)
+			customMacros := macros.ListCustomMacros(what.config.GetPluginFolder(), progressReporter)
+			if len(what.config.GetMacroAnswers()) > 0 {
+				answers, answersError := macros.LoadMacroAnswers(what.config.GetMacroAnswers())
+				if answersError != nil {
+					return answersError
+				}
+				err = macros.ExecuteModelMacroWithAnswers(r.ModelInput, what.config.GetInputFile(), r.ParsedModel, macrosId, customMacros, answers)
+			} else {
+				err = macros.ExecuteModelMacro(r.ModelInput, what.config.GetInputFile(), r.ParsedModel, macrosId, customMacros)
+			}
 			if err != nil {
 				return fmt.Errorf("unable to execute model macro: %w", err)
 			}
@@ -55,16 +55,29 @@ func (what *Threagile) explainRisk(cmd *cobra.Command, args []string) error {
 
 	// todo: reuse model if already loaded
 
-	result, runError := model.ReadAndAnalyzeModel(what.config, risks.GetBuiltInRiskRules(), progressReporter)
+	customRiskRules := model.LoadCustomRiskRules(what.config, progressReporter)
+	defer model.CloseCustomRiskRules(customRiskRules)
+
+	result, runError := model.ReadAndAnalyzeModel(what.config, risks.GetRiskRules(what.config.GetScriptRulesDir(), progressReporter), customRiskRules, progressReporter)
 	if runError != nil {
 		cmd.Printf("Failed to read and analyze model: %v", runError)
 		return runError
 	}
 
-	// todo: implement this
+	for n, riskID := range args {
+		if n > 0 {
+			cmd.Println()
+			cmd.Println("----------------------")
+			cmd.Println()
+		}
 
-	_ = result
-	return fmt.Errorf("not implemented yet")
+		explainError := result.ExplainRisk(riskID, cmd)
+		if explainError != nil {
+			return fmt.Errorf("failed to explain risk %q: %w", riskID, explainError)
+		}
+	}
+
+	return nil
 }
 
 func (what *Threagile) explainRules(cmd *cobra.Command, args []string) error {
@@ -76,16 +89,32 @@ func (what *Threagile) explainRules(cmd *cobra.Command, args []string) error {
 	cmd.Println("----------------------")
 	cmd.Println("Custom risk rules:")
 	cmd.Println("----------------------")
-	customRiskRules := model.LoadCustomRiskRules(what.config.GetPluginFolder(), what.config.GetRiskRulePlugins(), DefaultProgressReporter{Verbose: what.config.GetVerbose()})
+	customRiskRules := model.LoadCustomRiskRules(what.config, DefaultProgressReporter{Verbose: what.config.GetVerbose()})
+	defer model.CloseCustomRiskRules(customRiskRules)
 	for _, rule := range customRiskRules {
 		cmd.Printf("%v: %v\n", rule.Category().ID, rule.Category().Description)
 	}
 	cmd.Println()
+	scriptRiskRules := risks.GetScriptRulesDirRiskRules(what.config.GetScriptRulesDir(), DefaultProgressReporter{Verbose: what.config.GetVerbose()})
+	if len(what.config.GetScriptRulesDir()) > 0 {
+		cmd.Println("------------------")
+		cmd.Println("Script risk rules:")
+		cmd.Println("------------------")
+		cmd.Println("(from", what.config.GetScriptRulesDir()+")")
+		cmd.Println()
+		for _, rule := range scriptRiskRules {
+			cmd.Printf("%v: %v\n", rule.Category().ID, rule.Category().Description)
+		}
+		cmd.Println()
+	}
 	cmd.Println("--------------------")
 	cmd.Println("Built-in risk rules:")
 	cmd.Println("--------------------")
 	cmd.Println()
-	for _, rule := range risks.GetBuiltInRiskRules() {
+	for id, rule := range risks.GetBuiltInRiskRules() {
+		if _, shadowed := scriptRiskRules[id]; shadowed {
+			continue
+		}
 		cmd.Printf("%v: %v\n", rule.Category().ID, rule.Category().Description)
 	}
 	cmd.Println()
@@ -99,14 +128,14 @@ func (what *Threagile) explainMacros(cmd *cobra.Command, args []string) {
 	cmd.Println(Logo + "\n\n" + fmt.Sprintf(VersionText, what.buildTimestamp))
 	cmd.Println("Explanation for the model macros:")
 	cmd.Println()
-	/* TODO finish plugin stuff
+	cmd.Println("----------------------")
 	cmd.Println("Custom model macros:")
-	for _, macros := range macros.ListCustomMacros() {
-		details := macros.GetMacroDetails()
-		cmd.Println(details.ID, "-->", details.Title)
+	cmd.Println("----------------------")
+	for _, macroList := range macros.ListCustomMacros(what.config.GetPluginFolder(), DefaultProgressReporter{Verbose: what.config.GetVerbose()}) {
+		details := macroList.GetMacroDetails()
+		cmd.Printf("%v: %v\n", details.ID, details.Title)
 	}
 	cmd.Println()
-	*/
 	cmd.Println("----------------------")
 	cmd.Println("Built-in model macros:")
 	cmd.Println("----------------------")
@@ -28,22 +28,46 @@ const (
 	dataFlowDiagramDOTFileFlagName  = "data-flow-diagram-dot"
 	dataAssetDiagramDOTFileFlagName = "data-asset-diagram-dot"
 	reportFileFlagName              = "report"
+	htmlReportFileFlagName          = "report-html"
 	risksExcelFileFlagName          = "risks-excel"
 	tagsExcelFileFlagName           = "tags-excel"
 	risksJsonFileFlagName           = "risks-json"
 	technicalAssetsJsonFileFlagName = "technical-assets-json"
 	statsJsonFileFlagName           = "stats-json"
+	sarifFileFlagName               = "sarif"
 	templateFileNameFlagName        = "background"
 	reportLogoImagePathFlagName     = "reportLogoImagePath"
 	technologyFileFlagName          = "technology"
 
-	customRiskRulesPluginFlagName = "custom-risk-rules-plugin"
-	skipRiskRulesFlagName         = "skip-risk-rules"
-	executeModelMacroFlagName     = "execute-model-macro"
+	baselineFlagName  = "baseline"
+	diffInputFlagName = "input"
+	formatFlagName    = "format"
+
+	migrateFromFlagName = "from"
+	migrateToFlagName   = "to"
+
+	customRiskRulesPluginFlagName     = "custom-risk-rules-plugin"
+	persistentRiskRulesPluginFlagName = "persistent-risk-rules-plugin"
+	pluginTimeoutFlagName             = "plugin-timeout"
+	customRisksFileFlagName           = "custom-risks-file"
+	scriptRulesDirFlagName            = "script-rules-dir"
+	skipRiskRulesFlagName             = "skip-risk-rules"
+	executeModelMacroFlagName         = "execute-model-macro"
+	macroAnswersFlagName              = "macro-answers"
+
+	failOnFlagName                      = "fail-on"
+	failOnAllowedRiskCategoriesFlagName = "fail-on-allowed-risk-categories"
 
 	serverModeFlagName               = "server-mode"
 	serverPortFlagName               = "server-port"
+	serverStorageFlagName            = "server-storage"
+	serverDatabaseFlagName           = "server-database"
+	serverAuthFlagName               = "server-auth"
+	oidcIssuerFlagName               = "oidc-issuer"
+	oidcAudienceFlagName             = "oidc-audience"
+	oidcJWKSFlagName                 = "oidc-jwks"
 	diagramDpiFlagName               = "diagram-dpi"
+	diagramRendererFlagName          = "diagram-renderer"
 	graphvizDpiFlagName              = "graphviz-dpi"
 	backupHistoryFilesToKeepFlagName = "backup-history-files-to-keep"
 
@@ -56,9 +80,11 @@ const (
 	skipRisksJSONFlagName           = "skip-risks-json"
 	skipTechnicalAssetsJSONFlagName = "skip-technical-assets-json"
 	skipStatsJSONFlagName           = "skip-stats-json"
+	skipSarifFlagName               = "skip-sarif"
 	skipRisksExcelFlagName          = "skip-risks-excel"
 	skipTagsExcelFlagName           = "skip-tags-excel"
 	skipReportPDFFlagName           = "skip-report-pdf"
+	skipReportHTMLFlagName          = "skip-report-html"
 	skipReportADOCFlagName          = "skip-report-adoc"
 
 	generateDataFlowDiagramFlagName     = "generate-data-flow-diagram"
@@ -75,9 +101,13 @@ const (
 type Flags struct {
 	Config
 
-	configFlag           string
-	riskRulePluginsValue string
-	skipRiskRulesValue   string
+	configFlag                     string
+	riskRulePluginsValue           string
+	persistentRiskRulePluginsValue string
+	skipRiskRulesValue             string
+
+	failOnValue                      string
+	failOnAllowedRiskCategoriesValue string
 
 	generateDataFlowDiagramFlag     bool // deprecated
 	generateDataAssetDiagramFlag    bool // deprecated
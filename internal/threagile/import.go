@@ -2,10 +2,15 @@ package threagile
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/spf13/cobra"
+	"github.com/threagile/threagile/pkg/importer"
 	"github.com/threagile/threagile/pkg/model"
 	"github.com/threagile/threagile/pkg/report"
 	"github.com/threagile/threagile/pkg/risks"
+	"gopkg.in/yaml.v3"
 )
 
 func (what *Threagile) initImport() *Threagile {
@@ -18,12 +23,16 @@ func (what *Threagile) initImport() *Threagile {
 			commands := what.readCommands()
 			progressReporter := DefaultProgressReporter{Verbose: what.config.GetVerbose()}
 
-			r, err := model.ReadAndAnalyzeModel(what.config, risks.GetBuiltInRiskRules(), progressReporter)
+			riskRules := risks.GetRiskRules(what.config.GetScriptRulesDir(), progressReporter)
+			customRiskRules := model.LoadCustomRiskRules(what.config, progressReporter)
+			defer model.CloseCustomRiskRules(customRiskRules)
+
+			r, err := model.ReadAndAnalyzeModel(what.config, riskRules, customRiskRules, progressReporter)
 			if err != nil {
 				return fmt.Errorf("failed to read and analyze model: %w", err)
 			}
 
-			err = report.Generate(what.config, r, commands, risks.GetBuiltInRiskRules(), progressReporter)
+			err = report.Generate(what.config, r, commands, riskRules, progressReporter)
 			if err != nil {
 				return fmt.Errorf("failed to generate reports: %w", err)
 			}
@@ -35,7 +44,52 @@ func (what *Threagile) initImport() *Threagile {
 		},
 	}
 
+	analyze.AddCommand(what.importFromCommand(ImportThreatDragonCommand, importer.FormatThreatDragon, "an OWASP Threat Dragon model (.json)", "td"))
+	analyze.AddCommand(what.importFromCommand(ImportTMTCommand, importer.FormatTMT, "a Microsoft Threat Modeling Tool model (.tm7)", "tm7"))
+
 	what.rootCmd.AddCommand(analyze)
 
 	return what
 }
+
+func (what *Threagile) importFromCommand(use string, format string, description string, alias string) *cobra.Command {
+	return &cobra.Command{
+		Use:     use + " <file>",
+		Short:   "Convert " + description + " into a Threagile model yaml file",
+		Long:    "Convert " + description + " into a Threagile model yaml file written to --" + importedFileFlagName + " (default: " + ImportedModelFilename + " in the output directory) and list everything that could not be mapped",
+		Aliases: []string{alias},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			what.processArgs(cmd, args)
+
+			result, err := importer.ImportFile(what.config.CleanPath(args[0]), format)
+			if err != nil {
+				return fmt.Errorf("failed to import model: %w", err)
+			}
+			result.Model.ThreagileVersion = ThreagileVersion
+
+			modelYaml, err := yaml.Marshal(result.Model)
+			if err != nil {
+				return fmt.Errorf("failed to marshal imported model: %w", err)
+			}
+
+			filename := what.config.GetImportedInputFile()
+			if len(filename) == 0 {
+				filename = filepath.Join(what.config.GetOutputFolder(), ImportedModelFilename)
+			}
+
+			err = os.WriteFile(filename, modelYaml, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to write imported model: %w", err)
+			}
+
+			for _, unmapped := range result.Unmapped {
+				cmd.Printf("not mapped: %v\n", unmapped)
+			}
+			cmd.Printf("Imported %d technical asset(s) and %d trust boundary(s) into %q; please review the defaults (CIA ratings, technologies, data assets).\n",
+				len(result.Model.TechnicalAssets), len(result.Model.TrustBoundaries), filename)
+
+			return nil
+		},
+	}
+}
@@ -22,16 +22,32 @@ func (what *Threagile) initList() *Threagile {
 			cmd.Println("----------------------")
 			cmd.Println("Custom risk rules:")
 			cmd.Println("----------------------")
-			customRiskRules := model.LoadCustomRiskRules(what.config.GetPluginFolder(), what.config.GetRiskRulePlugins(), DefaultProgressReporter{Verbose: what.config.GetVerbose()})
+			customRiskRules := model.LoadCustomRiskRules(what.config, DefaultProgressReporter{Verbose: what.config.GetVerbose()})
+			defer model.CloseCustomRiskRules(customRiskRules)
 			for id, customRule := range customRiskRules {
 				cmd.Println(id, "-->", customRule.Category().Title, "--> with tags:", customRule.SupportedTags())
 			}
 			cmd.Println()
+			scriptRiskRules := risks.GetScriptRulesDirRiskRules(what.config.GetScriptRulesDir(), DefaultProgressReporter{Verbose: what.config.GetVerbose()})
+			if len(what.config.GetScriptRulesDir()) > 0 {
+				cmd.Println("------------------")
+				cmd.Println("Script risk rules:")
+				cmd.Println("------------------")
+				cmd.Println("(from", what.config.GetScriptRulesDir()+")")
+				cmd.Println()
+				for id, scriptRule := range scriptRiskRules {
+					cmd.Println(id, "-->", scriptRule.Category().Title, "--> with tags:", scriptRule.SupportedTags())
+				}
+				cmd.Println()
+			}
 			cmd.Println("--------------------")
 			cmd.Println("Built-in risk rules:")
 			cmd.Println("--------------------")
 			cmd.Println()
-			for _, rule := range risks.GetBuiltInRiskRules() {
+			for id, rule := range risks.GetBuiltInRiskRules() {
+				if _, shadowed := scriptRiskRules[id]; shadowed {
+					continue
+				}
 				cmd.Println(rule.Category().ID, "-->", rule.Category().Title, "--> with tags:", rule.SupportedTags())
 			}
 
@@ -48,14 +64,14 @@ func (what *Threagile) initList() *Threagile {
 			cmd.Println(Logo + "\n\n" + fmt.Sprintf(VersionText, what.buildTimestamp))
 			cmd.Println("The following model macros are available (can be extended via custom model macros):")
 			cmd.Println()
-			/* TODO finish plugin stuff
+			cmd.Println("----------------------")
 			cmd.Println("Custom model macros:")
-			for _, macros := range macros.ListCustomMacros() {
-				details := macros.GetMacroDetails()
+			cmd.Println("----------------------")
+			for _, macroList := range macros.ListCustomMacros(what.config.GetPluginFolder(), DefaultProgressReporter{Verbose: what.config.GetVerbose()}) {
+				details := macroList.GetMacroDetails()
 				cmd.Println(details.ID, "-->", details.Title)
 			}
 			cmd.Println()
-			*/
 			cmd.Println("----------------------")
 			cmd.Println("Built-in model macros:")
 			cmd.Println("----------------------")
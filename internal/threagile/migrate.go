@@ -0,0 +1,52 @@
+package threagile
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/threagile/threagile/pkg/server"
+)
+
+func (what *Threagile) initMigrate() *Threagile {
+	var from string
+	var to string
+
+	migrateCmd := &cobra.Command{
+		Use:   MigrateServerStorageCommand,
+		Short: "Copy keys, models and history of server mode from one storage to another",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			what.processArgs(cmd, args)
+
+			if from == to {
+				return fmt.Errorf("source and target storage are the same: %q", from)
+			}
+
+			source, sourceError := server.OpenStorage(from, what.config)
+			if sourceError != nil {
+				return fmt.Errorf("failed to open source storage: %w", sourceError)
+			}
+			defer func() { _ = source.Close() }()
+
+			target, targetError := server.OpenStorage(to, what.config)
+			if targetError != nil {
+				return fmt.Errorf("failed to open target storage: %w", targetError)
+			}
+			defer func() { _ = target.Close() }()
+
+			keyCount, modelCount, migrateError := server.MigrateStorage(source, target)
+			if migrateError != nil {
+				return fmt.Errorf("failed to migrate server storage: %w", migrateError)
+			}
+
+			cmd.Printf("migrated %d keys with %d models from %v to %v storage\n", keyCount, modelCount, from, to)
+			return nil
+		},
+	}
+
+	migrateCmd.Flags().StringVar(&from, migrateFromFlagName, server.FilesystemStorage, "storage to copy from: "+server.FilesystemStorage+" or "+server.SQLiteStorage)
+	migrateCmd.Flags().StringVar(&to, migrateToFlagName, server.SQLiteStorage, "storage to copy to: "+server.FilesystemStorage+" or "+server.SQLiteStorage)
+
+	what.rootCmd.AddCommand(migrateCmd)
+
+	return what
+}
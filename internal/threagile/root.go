@@ -77,24 +77,42 @@ func (what *Threagile) initFlags() *Threagile {
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.DataFlowDiagramFilenameDOTValue, dataFlowDiagramDOTFileFlagName, what.config.GetDataFlowDiagramFilenameDOT(), "data flow diagram DOT file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.DataAssetDiagramFilenameDOTValue, dataAssetDiagramDOTFileFlagName, what.config.GetDataAssetDiagramFilenameDOT(), "data asset diagram DOT file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.ReportFilenameValue, reportFileFlagName, what.config.GetReportFilename(), "report file")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.HtmlReportFilenameValue, htmlReportFileFlagName, what.config.GetHtmlReportFilename(), "interactive html report file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.ExcelRisksFilenameValue, risksExcelFileFlagName, what.config.GetExcelRisksFilename(), "risks Excel file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.ExcelTagsFilenameValue, tagsExcelFileFlagName, what.config.GetExcelTagsFilename(), "tags Excel file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.JsonRisksFilenameValue, risksJsonFileFlagName, what.config.GetJsonRisksFilename(), "risks JSON file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.JsonTechnicalAssetsFilenameValue, technicalAssetsJsonFileFlagName, what.config.GetJsonTechnicalAssetsFilename(), "technical assets JSON file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.JsonStatsFilenameValue, statsJsonFileFlagName, what.config.GetJsonStatsFilename(), "stats JSON file")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.SarifFilenameValue, sarifFileFlagName, what.config.GetSarifFilename(), "risks SARIF file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.TemplateFilenameValue, templateFileNameFlagName, what.config.GetTemplateFilename(), "template pdf file")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.ReportLogoImagePathValue, reportLogoImagePathFlagName, what.config.GetReportLogoImagePath(), "report logo image")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.TechnologyFilenameValue, technologyFileFlagName, what.config.GetTechnologyFilename(), "file name of additional technologies")
 
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.riskRulePluginsValue, customRiskRulesPluginFlagName, strings.Join(what.config.GetRiskRulePlugins(), ","), "comma-separated list of plugins file names with custom risk rules to load")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.persistentRiskRulePluginsValue, persistentRiskRulesPluginFlagName, strings.Join(what.config.GetPersistentRiskRulePlugins(), ","), "comma-separated list of plugins file names with custom risk rules to start once and keep running (line-delimited JSON-RPC via stdin/stdout)")
+	what.rootCmd.PersistentFlags().IntVar(&what.flags.PluginTimeoutValue, pluginTimeoutFlagName, what.config.GetPluginTimeout(), "seconds a persistent risk rules plugin may take to answer a request")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.CustomRisksFileValue, customRisksFileFlagName, what.config.GetCustomRisksFile(), "yaml file with the risks of custom risk rules generated by the server")
+	_ = what.rootCmd.PersistentFlags().MarkHidden(customRisksFileFlagName)
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.ScriptRulesDirValue, scriptRulesDirFlagName, what.config.GetScriptRulesDir(), "directory with script risk rules (yaml files) to load in addition to the built-in ones")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.skipRiskRulesValue, skipRiskRulesFlagName, strings.Join(what.config.GetSkipRiskRules(), ","), "comma-separated list of risk rules (by their ID) to skip")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.ExecuteModelMacroValue, executeModelMacroFlagName, what.config.GetExecuteModelMacro(), "macro to execute")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.MacroAnswersValue, macroAnswersFlagName, what.config.GetMacroAnswers(), "yaml file with answers (question id: answer or list of answers) to run a model macro without interaction")
+
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.failOnValue, failOnFlagName, strings.Join(what.config.GetFailOn(), ","), "comma-separated list of risk policies failing the analysis, e.g. \"any unchecked critical,more than 3 high not mitigated\"")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.failOnAllowedRiskCategoriesValue, failOnAllowedRiskCategoriesFlagName, strings.Join(what.config.GetFailOnAllowedRiskCategories(), ","), "comma-separated list of risk categories (by their ID) ignored by the risk policies")
 
 	// RiskExcelValue not available as flags
 
 	what.rootCmd.PersistentFlags().IntVar(&what.flags.ServerPortValue, serverPortFlagName, what.config.GetServerPort(), "server port")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.ServerStorageValue, serverStorageFlagName, what.config.GetServerStorage(), "storage of the models in server mode: filesystem or sqlite")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.ServerDatabaseValue, serverDatabaseFlagName, what.config.GetServerDatabase(), "sqlite database file of the server mode (relative to the server folder)")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.ServerAuthValue, serverAuthFlagName, what.config.GetServerAuth(), "authentication of the server mode: key or oidc (bearer tokens)")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.OIDCIssuerValue, oidcIssuerFlagName, what.config.GetOIDCIssuer(), "issuer of the bearer tokens accepted with oidc authentication")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.OIDCAudienceValue, oidcAudienceFlagName, what.config.GetOIDCAudience(), "audience the bearer tokens have to be issued for with oidc authentication (required)")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.OIDCJWKSValue, oidcJWKSFlagName, what.config.GetOIDCJWKS(), "url or file (relative to the server folder) of the jwks with the keys of the issuer for oidc authentication")
 	what.rootCmd.PersistentFlags().StringVar(&what.flags.ServerFolderValue, serverDirFlagName, what.config.GetDataFolder(), "base folder for server mode (default: "+DataDir+")")
 	what.rootCmd.PersistentFlags().IntVar(&what.flags.DiagramDPIValue, diagramDpiFlagName, what.config.GetDiagramDPI(), "DPI used to render: maximum is "+fmt.Sprintf("%d", what.config.GetMaxGraphvizDPI())+"")
+	what.rootCmd.PersistentFlags().StringVar(&what.flags.DiagramRendererValue, diagramRendererFlagName, what.config.GetDiagramRenderer(), "diagram renderer: "+report.GraphvizDiagramRenderer+" (requires the dot binary) or "+report.NativeDiagramRenderer+" (pure go)")
 	// MaxGraphvizDPIValue not available as flags
 	what.rootCmd.PersistentFlags().IntVar(&what.flags.BackupHistoryFilesToKeepValue, backupHistoryFilesToKeepFlagName, what.config.GetBackupHistoryFilesToKeep(), "number of backup history files to keep")
 
@@ -107,9 +125,11 @@ func (what *Threagile) initFlags() *Threagile {
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipRisksJSONValue, skipRisksJSONFlagName, what.config.GetSkipRisksJSON(), "skip generating risks json")
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipTechnicalAssetsJSONValue, skipTechnicalAssetsJSONFlagName, what.config.GetSkipTechnicalAssetsJSON(), "skip generating technical assets json")
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipStatsJSONValue, skipStatsJSONFlagName, what.config.GetSkipStatsJSON(), "skip generating stats json")
+	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipSarifValue, skipSarifFlagName, what.config.GetSkipSarif(), "skip generating risks sarif")
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipRisksExcelValue, skipRisksExcelFlagName, what.config.GetSkipRisksExcel(), "skip generating risks excel")
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipTagsExcelValue, skipTagsExcelFlagName, what.config.GetSkipTagsExcel(), "skip generating tags excel")
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipReportPDFValue, skipReportPDFFlagName, what.config.GetSkipReportPDF(), "skip generating report pdf, including diagrams")
+	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipReportHTMLValue, skipReportHTMLFlagName, what.config.GetSkipReportHTML(), "skip generating interactive report html")
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.SkipReportADOCValue, skipReportADOCFlagName, what.config.GetSkipReportADOC(), "skip generating report adoc, including diagrams")
 
 	what.rootCmd.PersistentFlags().BoolVar(&what.flags.generateDataFlowDiagramFlag, generateDataFlowDiagramFlagName, !what.config.GetSkipDataFlowDiagram(), "(deprecated) generate generating data flow diagram")
@@ -257,10 +277,12 @@ func (what *Threagile) readCommands() *report.GenerateCommands {
 	commands.DataAssetDiagram = !what.flags.SkipDataAssetDiagramValue
 	commands.RisksJSON = !what.flags.SkipRisksJSONValue
 	commands.StatsJSON = !what.flags.SkipStatsJSONValue
+	commands.SARIF = !what.flags.SkipSarifValue
 	commands.TechnicalAssetsJSON = !what.flags.SkipTechnicalAssetsJSONValue
 	commands.RisksExcel = !what.flags.SkipRisksExcelValue
 	commands.TagsExcel = !what.flags.SkipTagsExcelValue
 	commands.ReportPDF = !what.flags.SkipReportPDFValue
+	commands.ReportHTML = !what.flags.SkipReportHTMLValue
 	commands.ReportADOC = !what.flags.SkipReportADOCValue
 	return commands
 }
@@ -345,6 +367,10 @@ func (what *Threagile) processArgs(cmd *cobra.Command, args []string) bool {
 		what.config.ReportFilenameValue = what.config.CleanPath(what.flags.ReportFilenameValue)
 	}
 
+	if what.isFlagOverridden(cmd, htmlReportFileFlagName) {
+		what.config.HtmlReportFilenameValue = what.config.CleanPath(what.flags.HtmlReportFilenameValue)
+	}
+
 	if what.isFlagOverridden(cmd, risksExcelFileFlagName) {
 		what.config.ExcelRisksFilenameValue = what.config.CleanPath(what.flags.ExcelRisksFilenameValue)
 	}
@@ -365,6 +391,10 @@ func (what *Threagile) processArgs(cmd *cobra.Command, args []string) bool {
 		what.config.JsonStatsFilenameValue = what.config.CleanPath(what.flags.JsonStatsFilenameValue)
 	}
 
+	if what.isFlagOverridden(cmd, sarifFileFlagName) {
+		what.config.SarifFilenameValue = what.config.CleanPath(what.flags.SarifFilenameValue)
+	}
+
 	if what.isFlagOverridden(cmd, templateFileNameFlagName) {
 		what.config.TemplateFilenameValue = what.flags.TemplateFilenameValue
 	}
@@ -381,6 +411,22 @@ func (what *Threagile) processArgs(cmd *cobra.Command, args []string) bool {
 		what.config.RiskRulePluginsValue = strings.Split(what.flags.riskRulePluginsValue, ",")
 	}
 
+	if what.isFlagOverridden(cmd, persistentRiskRulesPluginFlagName) {
+		what.config.PersistentRiskRulePluginsValue = strings.Split(what.flags.persistentRiskRulePluginsValue, ",")
+	}
+
+	if what.isFlagOverridden(cmd, pluginTimeoutFlagName) {
+		what.config.PluginTimeoutValue = what.flags.PluginTimeoutValue
+	}
+
+	if what.isFlagOverridden(cmd, customRisksFileFlagName) {
+		what.config.CustomRisksFileValue = what.config.CleanPath(what.flags.CustomRisksFileValue)
+	}
+
+	if what.isFlagOverridden(cmd, scriptRulesDirFlagName) {
+		what.config.ScriptRulesDirValue = what.config.CleanPath(what.flags.ScriptRulesDirValue)
+	}
+
 	if what.isFlagOverridden(cmd, skipRiskRulesFlagName) {
 		what.config.SkipRiskRulesValue = strings.Split(what.flags.skipRiskRulesValue, ",")
 	}
@@ -389,6 +435,18 @@ func (what *Threagile) processArgs(cmd *cobra.Command, args []string) bool {
 		what.config.ExecuteModelMacroValue = what.flags.ExecuteModelMacroValue
 	}
 
+	if what.isFlagOverridden(cmd, macroAnswersFlagName) {
+		what.config.MacroAnswersValue = what.config.CleanPath(what.flags.MacroAnswersValue)
+	}
+
+	if what.isFlagOverridden(cmd, failOnFlagName) {
+		what.config.FailOnValue = strings.Split(what.flags.failOnValue, ",")
+	}
+
+	if what.isFlagOverridden(cmd, failOnAllowedRiskCategoriesFlagName) {
+		what.config.FailOnAllowedRiskCategoriesValue = strings.Split(what.flags.failOnAllowedRiskCategoriesValue, ",")
+	}
+
 	// RiskExcelValue not available as flags
 
 	if what.isFlagOverridden(cmd, serverModeFlagName) {
@@ -399,10 +457,38 @@ func (what *Threagile) processArgs(cmd *cobra.Command, args []string) bool {
 		what.config.ServerPortValue = what.flags.ServerPortValue
 	}
 
+	if what.isFlagOverridden(cmd, serverStorageFlagName) {
+		what.config.ServerStorageValue = what.flags.ServerStorageValue
+	}
+
+	if what.isFlagOverridden(cmd, serverDatabaseFlagName) {
+		what.config.ServerDatabaseValue = what.flags.ServerDatabaseValue
+	}
+
+	if what.isFlagOverridden(cmd, serverAuthFlagName) {
+		what.config.ServerAuthValue = what.flags.ServerAuthValue
+	}
+
+	if what.isFlagOverridden(cmd, oidcIssuerFlagName) {
+		what.config.OIDCIssuerValue = what.flags.OIDCIssuerValue
+	}
+
+	if what.isFlagOverridden(cmd, oidcAudienceFlagName) {
+		what.config.OIDCAudienceValue = what.flags.OIDCAudienceValue
+	}
+
+	if what.isFlagOverridden(cmd, oidcJWKSFlagName) {
+		what.config.OIDCJWKSValue = what.flags.OIDCJWKSValue
+	}
+
 	if what.isFlagOverridden(cmd, diagramDpiFlagName) {
 		what.config.DiagramDPIValue = what.flags.DiagramDPIValue
 	}
 
+	if what.isFlagOverridden(cmd, diagramRendererFlagName) {
+		what.config.DiagramRendererValue = what.flags.DiagramRendererValue
+	}
+
 	if what.isFlagOverridden(cmd, graphvizDpiFlagName) {
 		what.config.GraphvizDPIValue = what.flags.GraphvizDPIValue
 	}
@@ -445,6 +531,10 @@ func (what *Threagile) processArgs(cmd *cobra.Command, args []string) bool {
 		what.config.SkipStatsJSONValue = what.flags.SkipStatsJSONValue
 	}
 
+	if what.isFlagOverridden(cmd, skipSarifFlagName) {
+		what.config.SkipSarifValue = what.flags.SkipSarifValue
+	}
+
 	if what.isFlagOverridden(cmd, skipRisksExcelFlagName) {
 		what.config.SkipRisksExcelValue = what.flags.SkipRisksExcelValue
 	}
@@ -457,6 +547,10 @@ func (what *Threagile) processArgs(cmd *cobra.Command, args []string) bool {
 		what.config.SkipReportPDFValue = what.flags.SkipReportPDFValue
 	}
 
+	if what.isFlagOverridden(cmd, skipReportHTMLFlagName) {
+		what.config.SkipReportHTMLValue = what.flags.SkipReportHTMLValue
+	}
+
 	if what.isFlagOverridden(cmd, skipReportADOCFlagName) {
 		what.config.SkipReportADOCValue = what.flags.SkipReportADOCValue
 	}
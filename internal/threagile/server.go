@@ -8,7 +8,7 @@ import (
 
 func (what *Threagile) initServer() *Threagile {
 	serverCmd := &cobra.Command{
-		Use:   "server",
+		Use:   ServerCommand,
 		Short: "Run server",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			what.processArgs(cmd, args)
@@ -31,6 +31,5 @@ func (what *Threagile) runServer() error {
 		return serverError
 	}
 
-	server.RunServer(what.config, risks.GetBuiltInRiskRules())
-	return nil
+	return server.RunServer(what.config, risks.GetRiskRules(what.config.GetScriptRulesDir(), DefaultProgressReporter{Verbose: what.config.GetVerbose()}))
 }
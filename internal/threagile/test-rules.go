@@ -0,0 +1,73 @@
+package threagile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/risks"
+	"github.com/threagile/threagile/pkg/risks/script"
+)
+
+func (what *Threagile) initTestRules() *Threagile {
+	what.rootCmd.AddCommand(&cobra.Command{
+		Use:   TestRulesCommand + " [files or folders]",
+		Short: "Run the risk rule tests (*.test.yaml) of the given files or folders, defaults to the script rules dir",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			what.processArgs(cmd, args)
+
+			paths := args
+			if len(paths) == 0 {
+				if len(what.config.GetScriptRulesDir()) == 0 {
+					return fmt.Errorf("no rule tests given, pass files or folders or set --%v", scriptRulesDirFlagName)
+				}
+
+				paths = []string{what.config.GetScriptRulesDir()}
+			}
+
+			tests, findError := model.FindRuleTests(paths...)
+			if findError != nil {
+				return findError
+			}
+
+			if len(tests) == 0 {
+				return fmt.Errorf("no rule tests (*%v) found in %v", strings.Join(script.RuleTestFileSuffixes, ", *"), strings.Join(paths, ", "))
+			}
+
+			progressReporter := DefaultProgressReporter{Verbose: what.config.GetVerbose()}
+			builtinRiskRules := risks.GetRiskRules(what.config.GetScriptRulesDir(), progressReporter)
+			customRiskRules := model.LoadCustomRiskRules(what.config, progressReporter)
+			defer model.CloseCustomRiskRules(customRiskRules)
+
+			failed := 0
+			for _, test := range tests {
+				result := test.Run(what.config, builtinRiskRules, customRiskRules, progressReporter)
+				if result.Passed() {
+					cmd.Printf("PASS %v (%v)\n", test.Name, test.File)
+					continue
+				}
+
+				failed++
+				cmd.Printf("FAIL %v (%v)\n", test.Name, test.File)
+				if result.Error != nil {
+					cmd.Printf("    %v\n", result.Error)
+				}
+
+				for _, difference := range result.Differences {
+					cmd.Printf("    %v\n", difference)
+				}
+			}
+
+			cmd.Printf("%d passed, %d failed\n", len(tests)-failed, failed)
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d rule tests failed", failed, len(tests))
+			}
+
+			return nil
+		},
+	})
+
+	return what
+}
@@ -14,13 +14,14 @@ type Threagile struct {
 }
 
 func (what *Threagile) Execute() {
-	err := what.rootCmd.Execute()
+	cmd, err := what.rootCmd.ExecuteC()
 	if err != nil {
 		what.rootCmd.Println(err)
 		os.Exit(1)
 	}
 
-	if what.config.GetServerMode() {
+	// the server command already ran the server until it was stopped
+	if what.config.GetServerMode() && cmd.Name() != ServerCommand {
 		serverError := what.runServer()
 		what.rootCmd.Println(serverError)
 	} else if what.config.GetInteractive() {
@@ -30,5 +31,5 @@ func (what *Threagile) Execute() {
 
 func (what *Threagile) Init(buildTimestamp string) *Threagile {
 	what.buildTimestamp = buildTimestamp
-	return what.initRoot().initImport().initAnalyze().initCreate().initExecute().initExplain().initList().initPrint().initQuit().initServer().initVersion().processSystemArgs(what.rootCmd)
+	return what.initRoot().initImport().initAnalyze().initDiff().initMigrate().initTestRules().initValidate().initCreate().initExecute().initExplain().initList().initPrint().initQuit().initServer().initVersion().processSystemArgs(what.rootCmd)
 }
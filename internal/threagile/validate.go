@@ -0,0 +1,60 @@
+package threagile
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/threagile/threagile/pkg/model"
+)
+
+const (
+	validateFormatText = "text"
+	validateFormatJSON = "json"
+)
+
+func (what *Threagile) initValidate() *Threagile {
+	var format string
+
+	validateCmd := &cobra.Command{
+		Use:   ValidateCommand,
+		Short: "Check the model for problems without generating risks or reports",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			what.processArgs(cmd, args)
+
+			result := model.ValidateModelFile(what.config.GetInputFile(), what.config)
+
+			switch strings.ToLower(format) {
+			case validateFormatText, "":
+				for _, problem := range result.Problems {
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), problem.String())
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d error(s), %d warning(s), %d info(s)\n", result.Count(model.ValidationError), result.Count(model.ValidationWarning), result.Count(model.ValidationInfo))
+
+			case validateFormatJSON:
+				jsonBytes, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal validation result to JSON: %w", err)
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), string(jsonBytes))
+
+			default:
+				return fmt.Errorf("unknown validation format %q (expected one of %v, %v)", format, validateFormatText, validateFormatJSON)
+			}
+
+			if result.HasErrors() {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("model %q has %d error(s)", what.config.GetInputFile(), result.Count(model.ValidationError))
+			}
+
+			return nil
+		},
+	}
+
+	validateCmd.Flags().StringVar(&format, formatFlagName, validateFormatText, "output format: "+validateFormatText+" or "+validateFormatJSON)
+
+	what.rootCmd.AddCommand(validateCmd)
+
+	return what
+}
@@ -0,0 +1,324 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+const (
+	FormatThreatDragon = "threat-dragon"
+	FormatTMT          = "tmt"
+)
+
+// Result is a model converted from a foreign threat modeling tool together with notes about everything that could not be
+// mapped onto the Threagile model and was therefore dropped or replaced by a default.
+type Result struct {
+	Model    *input.Model
+	Unmapped []string
+}
+
+// ImportFile converts the given file into a Threagile model. An empty format is derived from the file extension.
+func ImportFile(filename string, format string) (*Result, error) {
+	data, readError := os.ReadFile(filepath.Clean(filename))
+	if readError != nil {
+		return nil, fmt.Errorf("unable to read %q: %w", filename, readError)
+	}
+
+	if len(format) == 0 {
+		format = FormatThreatDragon
+		if strings.EqualFold(filepath.Ext(filename), ".tm7") {
+			format = FormatTMT
+		}
+	}
+
+	switch strings.ToLower(format) {
+	case FormatThreatDragon:
+		return ImportThreatDragon(data)
+
+	case FormatTMT:
+		return ImportTMT(data)
+
+	default:
+		return nil, fmt.Errorf("unknown import format %q (expected one of %v, %v)", format, FormatThreatDragon, FormatTMT)
+	}
+}
+
+var idCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// builder collects the elements of the imported diagrams into a model, keyed by the element IDs of the source tool
+type builder struct {
+	model        *input.Model
+	result       *Result
+	source       string
+	assetTitles  map[string]string
+	assetIds     map[string]string
+	usedIds      map[string]bool
+	unnamedCount map[string]int
+	bounded      map[string]string
+}
+
+func newBuilder(source string, title string) *builder {
+	model := new(input.Model).Defaults()
+	model.Title = title
+	model.Date = time.Now().Format("2006-01-02")
+	model.BusinessCriticality = types.Important.String()
+
+	return &builder{
+		model:        model,
+		result:       &Result{Model: model, Unmapped: make([]string, 0)},
+		source:       source,
+		assetTitles:  make(map[string]string),
+		assetIds:     make(map[string]string),
+		usedIds:      make(map[string]bool),
+		unnamedCount: make(map[string]int),
+		bounded:      make(map[string]string),
+	}
+}
+
+func (what *builder) unmapped(format string, a ...any) {
+	what.result.Unmapped = append(what.result.Unmapped, fmt.Sprintf(format, a...))
+}
+
+// title returns the trimmed name or, for unnamed elements, a numbered name derived from the kind of element
+func (what *builder) title(name string, kind string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	if len(name) > 0 {
+		return name
+	}
+
+	what.unnamedCount[kind]++
+	return fmt.Sprintf("Unnamed %v %d", kind, what.unnamedCount[kind])
+}
+
+func (what *builder) newId(title string) string {
+	base := strings.Trim(idCharacters.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(base) == 0 {
+		base = "element"
+	}
+
+	id := base
+	for count := 2; what.usedIds[id]; count++ {
+		id = fmt.Sprintf("%v-%d", base, count)
+	}
+
+	what.usedIds[id] = true
+	return id
+}
+
+// addTechnicalAsset adds an asset with conservative defaults for everything the source tool does not know about and
+// returns its title. Elements with the same title (e.g. the same actor drawn on several diagrams) are mapped to the same asset.
+func (what *builder) addTechnicalAsset(elementId string, name string, assetType types.TechnicalAssetType, technology string) string {
+	title := what.title(name, assetType.String())
+	if existing, ok := what.model.TechnicalAssets[title]; ok {
+		if existing.Type != assetType.String() {
+			what.unmapped("%v %q has the same name as a %v, both were merged into one technical asset of type %v", assetType, title, existing.Type, existing.Type)
+		}
+
+		what.assetTitles[elementId] = title
+		what.assetIds[elementId] = existing.ID
+		return title
+	}
+
+	size := types.Component
+	if assetType == types.ExternalEntity {
+		size = types.System
+	}
+
+	asset := input.TechnicalAsset{
+		ID:                     what.newId(title),
+		Description:            title,
+		Type:                   assetType.String(),
+		Usage:                  types.Business.String(),
+		Size:                   size.String(),
+		Technology:             technology,
+		Machine:                types.Virtual.String(),
+		Encryption:             types.NoneEncryption.String(),
+		Confidentiality:        types.Internal.String(),
+		Integrity:              types.Operational.String(),
+		Availability:           types.Operational.String(),
+		JustificationCiaRating: "imported from " + what.source + ", please review the CIA rating",
+		CommunicationLinks:     make(map[string]input.CommunicationLink),
+	}
+
+	what.model.TechnicalAssets[title] = asset
+	what.assetTitles[elementId] = title
+	what.assetIds[elementId] = asset.ID
+	return title
+}
+
+// addCommunicationLink adds a link between two previously added elements; it reports false if either end is unknown
+func (what *builder) addCommunicationLink(sourceElementId string, targetElementId string, name string, link input.CommunicationLink) bool {
+	sourceTitle, sourceOk := what.assetTitles[sourceElementId]
+	targetId, targetOk := what.assetIds[targetElementId]
+	if !sourceOk || !targetOk {
+		return false
+	}
+
+	source := what.model.TechnicalAssets[sourceTitle]
+	title := what.title(name, "data flow")
+	uniqueTitle := title
+	for count := 2; ; count++ {
+		if _, exists := source.CommunicationLinks[uniqueTitle]; !exists {
+			break
+		}
+		uniqueTitle = fmt.Sprintf("%v (%d)", title, count)
+	}
+
+	link.Target = targetId
+	if len(link.Description) == 0 {
+		link.Description = title
+	}
+	if len(link.Authentication) == 0 {
+		link.Authentication = types.NoneAuthentication.String()
+	}
+	if len(link.Authorization) == 0 {
+		link.Authorization = types.NoneAuthorization.String()
+	}
+	if len(link.Usage) == 0 {
+		link.Usage = types.Business.String()
+	}
+
+	source.CommunicationLinks[uniqueTitle] = link
+	what.model.TechnicalAssets[sourceTitle] = source
+	return true
+}
+
+// box is the rectangle of a diagram element used to derive trust boundary membership from the drawing
+type box struct {
+	elementId string
+	left      float64
+	top       float64
+	width     float64
+	height    float64
+}
+
+func (what box) contains(x float64, y float64) bool {
+	return x >= what.left && x <= what.left+what.width && y >= what.top && y <= what.top+what.height
+}
+
+func (what box) encloses(other box) bool {
+	return what.contains(other.left, other.top) && what.contains(other.left+other.width, other.top+other.height) &&
+		what.width*what.height > other.width*other.height
+}
+
+func (what box) center() (float64, float64) {
+	return what.left + what.width/2, what.top + what.height/2
+}
+
+// addTrustBoundaries maps boundary rectangles of one diagram to trust boundaries. Each asset is placed into the smallest
+// boundary around its center and each boundary is nested into the smallest boundary enclosing it.
+func (what *builder) addTrustBoundaries(boundaries []box, names map[string]string, assets []box) {
+	innermost := func(contains func(box) bool, self string) string {
+		result := ""
+		smallest := 0.0
+		for _, boundary := range boundaries {
+			if boundary.elementId == self || !contains(boundary) {
+				continue
+			}
+			if area := boundary.width * boundary.height; len(result) == 0 || area < smallest {
+				result, smallest = boundary.elementId, area
+			}
+		}
+		return result
+	}
+
+	inside := make(map[string][]string)
+	for _, asset := range assets {
+		x, y := asset.center()
+		parent := innermost(func(boundary box) bool { return boundary.contains(x, y) }, "")
+		id, ok := what.assetIds[asset.elementId]
+		if !ok || len(parent) == 0 {
+			continue
+		}
+		if boundary, exists := what.bounded[id]; exists {
+			if boundary != parent {
+				what.unmapped("technical asset %q is drawn inside several trust boundaries, only the first one was kept", what.assetTitles[asset.elementId])
+			}
+			continue
+		}
+		what.bounded[id] = parent
+		inside[parent] = append(inside[parent], id)
+	}
+
+	boundaryIds := make(map[string]string)
+	for _, boundary := range boundaries {
+		name := what.title(names[boundary.elementId], "trust boundary")
+		title := name
+		for count := 2; ; count++ {
+			if _, exists := what.model.TrustBoundaries[title]; !exists {
+				break
+			}
+			title = fmt.Sprintf("%v (%d)", name, count)
+		}
+
+		boundaryIds[boundary.elementId] = what.newId(title)
+		what.model.TrustBoundaries[title] = input.TrustBoundary{
+			ID:                    boundaryIds[boundary.elementId],
+			Description:           title,
+			Type:                  types.NetworkOnPrem.String(),
+			TechnicalAssetsInside: inside[boundary.elementId],
+		}
+	}
+
+	for _, boundary := range boundaries {
+		parent := innermost(func(candidate box) bool { return candidate.encloses(boundary) }, boundary.elementId)
+		if len(parent) == 0 {
+			continue
+		}
+		for title, parentBoundary := range what.model.TrustBoundaries {
+			if parentBoundary.ID == boundaryIds[parent] {
+				parentBoundary.TrustBoundariesNested = append(parentBoundary.TrustBoundariesNested, boundaryIds[boundary.elementId])
+				what.model.TrustBoundaries[title] = parentBoundary
+			}
+		}
+	}
+}
+
+// mapProtocol maps the protocol name of the source tool to a Threagile protocol. Unknown names yield unknown-protocol
+// and false.
+func mapProtocol(name string, encrypted bool) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := protocolAliases[name]; ok {
+		name = alias.String()
+	}
+
+	protocol, err := types.ParseProtocol(name)
+	if err != nil {
+		return types.UnknownProtocol.String(), false
+	}
+
+	if encrypted && !protocol.IsEncrypted() {
+		switch protocol {
+		case types.HTTP:
+			protocol = types.HTTPS
+		case types.WS:
+			protocol = types.WSS
+		default:
+			if encryptedProtocol, encryptedErr := types.ParseProtocol(protocol.String() + "-encrypted"); encryptedErr == nil {
+				protocol = encryptedProtocol
+			}
+		}
+	}
+
+	return protocol.String(), true
+}
+
+var protocolAliases = map[string]types.Protocol{
+	"tls":   types.BinaryEncrypted,
+	"tcp":   types.BINARY,
+	"udp":   types.BINARY,
+	"rpc":   types.BINARY,
+	"grpc":  types.BINARY,
+	"amqp":  types.BINARY,
+	"ipsec": types.BinaryEncrypted,
+	"sql":   types.SqlAccessProtocol,
+	"smtps": types.SmtpEncrypted,
+	"ipc":   types.InterProcessCommunication,
+}
@@ -0,0 +1,268 @@
+package importer
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const threatDragonV2 = `{
+  "version": "2.2.0",
+  "summary": {"title": "Shop", "owner": "Jane", "description": "Online shop"},
+  "detail": {
+    "contributors": [{"name": "Joe"}],
+    "diagrams": [{
+      "title": "Main",
+      "cells": [
+        {"id": "b1", "shape": "trust-boundary-box", "position": {"x": 0, "y": 0}, "size": {"width": 500, "height": 500},
+         "data": {"type": "tm.Boundary", "name": "Data Center"}},
+        {"id": "b2", "shape": "trust-boundary-box", "position": {"x": 250, "y": 250}, "size": {"width": 200, "height": 200},
+         "data": {"type": "tm.Boundary", "name": "Backend"}},
+        {"id": "a1", "shape": "actor", "position": {"x": 600, "y": 100}, "size": {"width": 100, "height": 50},
+         "data": {"type": "tm.Actor", "name": "Customer"}},
+        {"id": "p1", "shape": "process", "position": {"x": 100, "y": 100}, "size": {"width": 60, "height": 60},
+         "data": {"type": "tm.Process", "name": "Web Shop", "isWebApplication": true,
+                  "threats": [{"title": "Spoofed session"}]}},
+        {"id": "s1", "shape": "store", "position": {"x": 300, "y": 300}, "size": {"width": 80, "height": 40},
+         "data": {"type": "tm.Store", "name": "Orders", "isEncrypted": true}},
+        {"id": "f1", "shape": "flow", "source": {"cell": "a1"}, "target": {"cell": "p1"},
+         "data": {"type": "tm.Flow", "name": "Browse", "protocol": "HTTP", "isEncrypted": true}},
+        {"id": "f2", "shape": "flow", "source": {"cell": "p1"}, "target": {"cell": "s1"},
+         "data": {"type": "tm.Flow", "name": "Store order", "protocol": "carrier pigeon"}},
+        {"id": "f3", "shape": "flow", "source": {"cell": "p1"}, "target": {"x": 1, "y": 2},
+         "data": {"type": "tm.Flow", "name": "Dangling"}},
+        {"id": "t1", "shape": "td-text-block", "data": {"type": "tm.Text", "name": "Note"}}
+      ]
+    }]
+  }
+}`
+
+func TestImportThreatDragon(t *testing.T) {
+	result, err := ImportThreatDragon([]byte(threatDragonV2))
+	require.NoError(t, err)
+
+	model := result.Model
+	assert.Equal(t, "Shop", model.Title)
+	assert.Equal(t, "Jane", model.Author.Name)
+	assert.Len(t, model.Contributors, 1)
+	require.Len(t, model.TechnicalAssets, 3)
+
+	customer := model.TechnicalAssets["Customer"]
+	assert.Equal(t, "customer", customer.ID)
+	assert.Equal(t, "external-entity", customer.Type)
+	assert.Equal(t, "https", customer.CommunicationLinks["Browse"].Protocol)
+	assert.Equal(t, "web-shop", customer.CommunicationLinks["Browse"].Target)
+
+	shop := model.TechnicalAssets["Web Shop"]
+	assert.Equal(t, "web-application", shop.Technology)
+	assert.Equal(t, "unknown-protocol", shop.CommunicationLinks["Store order"].Protocol)
+	assert.Len(t, shop.CommunicationLinks, 1)
+
+	orders := model.TechnicalAssets["Orders"]
+	assert.Equal(t, "datastore", orders.Type)
+	assert.Equal(t, "transparent", orders.Encryption)
+
+	require.Len(t, model.TrustBoundaries, 2)
+	assert.Equal(t, []string{"web-shop"}, model.TrustBoundaries["Data Center"].TechnicalAssetsInside)
+	assert.Equal(t, []string{"backend"}, model.TrustBoundaries["Data Center"].TrustBoundariesNested)
+	assert.Equal(t, []string{"orders"}, model.TrustBoundaries["Backend"].TechnicalAssetsInside)
+
+	assert.Contains(t, result.Unmapped, `threat "Spoofed session" of "Web Shop" (Threagile generates its own risks)`)
+	assert.Contains(t, result.Unmapped, `protocol "carrier pigeon" of data flow "Store order"`)
+	assert.Contains(t, result.Unmapped, `data flow "Dangling" on diagram "Main" is not connected to two elements`)
+	assert.Contains(t, result.Unmapped, `element "Note" of type "tm.Text" on diagram "Main"`)
+}
+
+func TestImportThreatDragon_Version1(t *testing.T) {
+	result, err := ImportThreatDragon([]byte(`{
+  "summary": {"title": "Legacy"},
+  "detail": {"diagrams": [{"title": "Old", "diagramJson": {"cells": [
+    {"id": "a", "type": "tm.Actor", "name": "User", "position": {"x": 0, "y": 0}, "size": {"width": 10, "height": 10}},
+    {"id": "p", "type": "tm.Process", "name": "App", "outOfScope": true, "reasonOutOfScope": "third party"},
+    {"id": "f", "type": "tm.Flow", "source": {"id": "a"}, "target": {"id": "p"}, "protocol": "ssh",
+     "labels": [{"attrs": {"text": {"text": "Login"}}}]},
+    {"id": "b", "type": "tm.Boundary", "source": {"x": 0, "y": 0}, "target": {"x": 5, "y": 5}}
+  ]}}]}
+}`))
+	require.NoError(t, err)
+
+	model := result.Model
+	require.Len(t, model.TechnicalAssets, 2)
+	assert.True(t, model.TechnicalAssets["App"].OutOfScope)
+	assert.Equal(t, "third party", model.TechnicalAssets["App"].JustificationOutOfScope)
+	assert.Equal(t, "ssh", model.TechnicalAssets["User"].CommunicationLinks["Login"].Protocol)
+	assert.Empty(t, model.TrustBoundaries)
+	assert.Len(t, result.Unmapped, 1)
+}
+
+func TestImportThreatDragon_Invalid(t *testing.T) {
+	_, err := ImportThreatDragon([]byte(`{"summary": {"title": "Empty"}}`))
+	assert.Error(t, err)
+
+	_, err = ImportThreatDragon([]byte(`<xml/>`))
+	assert.Error(t, err)
+}
+
+const tmtModelFile = `<ThreatModel xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.Model" xmlns:i="http://www.w3.org/2001/XMLSchema-instance">
+  <DrawingSurfaceList>
+    <DrawingSurfaceModel xmlns:z="http://schemas.microsoft.com/2003/10/Serialization/">
+      <Borders xmlns:a="http://schemas.microsoft.com/2003/10/Serialization/Arrays">
+        <a:KeyValueOfguidanyType>
+          <a:Key>g-boundary</a:Key>
+          <a:Value i:type="BorderBoundary">
+            <GenericTypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">GE.TB.B</GenericTypeId>
+            <Guid xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">g-boundary</Guid>
+            <Properties xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:StringDisplayAttribute">
+                <b:DisplayName>Name</b:DisplayName><b:Value i:type="c:string" xmlns:c="http://www.w3.org/2001/XMLSchema">Azure</b:Value>
+              </anyType>
+            </Properties>
+            <TypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">SE.TB.B.TMCore.Azure</TypeId>
+            <Height>400</Height><Left>0</Left><Top>0</Top><Width>400</Width>
+          </a:Value>
+        </a:KeyValueOfguidanyType>
+        <a:KeyValueOfguidanyType>
+          <a:Key>g-browser</a:Key>
+          <a:Value i:type="StencilEllipse">
+            <GenericTypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">GE.EI</GenericTypeId>
+            <Guid xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">g-browser</Guid>
+            <Properties xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:StringDisplayAttribute">
+                <b:DisplayName>Name</b:DisplayName><b:Value i:type="c:string" xmlns:c="http://www.w3.org/2001/XMLSchema">Browser</b:Value>
+              </anyType>
+            </Properties>
+            <TypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">SE.EI.TMCore.Browser</TypeId>
+            <Height>100</Height><Left>500</Left><Top>50</Top><Width>100</Width>
+          </a:Value>
+        </a:KeyValueOfguidanyType>
+        <a:KeyValueOfguidanyType>
+          <a:Key>g-web</a:Key>
+          <a:Value i:type="StencilEllipse">
+            <GenericTypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">GE.P</GenericTypeId>
+            <Guid xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">g-web</Guid>
+            <Properties xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:StringDisplayAttribute">
+                <b:DisplayName>Name</b:DisplayName><b:Value i:type="c:string" xmlns:c="http://www.w3.org/2001/XMLSchema">Web Server</b:Value>
+              </anyType>
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:BooleanDisplayAttribute">
+                <b:DisplayName>Out Of Scope</b:DisplayName><b:Value i:type="c:boolean" xmlns:c="http://www.w3.org/2001/XMLSchema">false</b:Value>
+              </anyType>
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:ListDisplayAttribute">
+                <b:DisplayName>Code Type</b:DisplayName><b:Value xmlns:c="http://schemas.microsoft.com/2003/10/Serialization/Arrays" i:type="c:ArrayOfstring"><c:string>Managed</c:string><c:string>Unmanaged</c:string></b:Value><b:SelectedIndex>1</b:SelectedIndex>
+              </anyType>
+            </Properties>
+            <TypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">SE.P.TMCore.WebServer</TypeId>
+            <Height>100</Height><Left>100</Left><Top>100</Top><Width>100</Width>
+          </a:Value>
+        </a:KeyValueOfguidanyType>
+        <a:KeyValueOfguidanyType>
+          <a:Key>g-mainframe</a:Key>
+          <a:Value i:type="StencilParallelLines">
+            <GenericTypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">GE.DS</GenericTypeId>
+            <Guid xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">g-mainframe</Guid>
+            <Properties xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:StringDisplayAttribute">
+                <b:DisplayName>Name</b:DisplayName><b:Value i:type="c:string" xmlns:c="http://www.w3.org/2001/XMLSchema">Tape Archive</b:Value>
+              </anyType>
+            </Properties>
+            <TypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">SE.DS.Custom.Tape</TypeId>
+            <Height>50</Height><Left>150</Left><Top>250</Top><Width>100</Width>
+          </a:Value>
+        </a:KeyValueOfguidanyType>
+      </Borders>
+      <Header>Diagram 1</Header>
+      <Lines xmlns:a="http://schemas.microsoft.com/2003/10/Serialization/Arrays">
+        <a:KeyValueOfguidanyType>
+          <a:Key>g-flow</a:Key>
+          <a:Value i:type="Connector">
+            <GenericTypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">GE.DF</GenericTypeId>
+            <Guid xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">g-flow</Guid>
+            <Properties xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:StringDisplayAttribute">
+                <b:DisplayName>Name</b:DisplayName><b:Value i:type="c:string" xmlns:c="http://www.w3.org/2001/XMLSchema">Request</b:Value>
+              </anyType>
+            </Properties>
+            <TypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">SE.DF.TMCore.HTTPS</TypeId>
+            <SourceGuid>g-browser</SourceGuid><TargetGuid>g-web</TargetGuid>
+          </a:Value>
+        </a:KeyValueOfguidanyType>
+        <a:KeyValueOfguidanyType>
+          <a:Key>g-line</a:Key>
+          <a:Value i:type="LineBoundary">
+            <GenericTypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">GE.TB.L</GenericTypeId>
+            <Guid xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">g-line</Guid>
+            <Properties xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">
+              <anyType xmlns:b="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase.Attributes" i:type="b:StringDisplayAttribute">
+                <b:DisplayName>Name</b:DisplayName><b:Value i:type="c:string" xmlns:c="http://www.w3.org/2001/XMLSchema">Internet Boundary</b:Value>
+              </anyType>
+            </Properties>
+            <TypeId xmlns="http://schemas.datacontract.org/2004/07/ThreatModeling.KnowledgeBase">SE.TB.L.TMCore.Internet</TypeId>
+          </a:Value>
+        </a:KeyValueOfguidanyType>
+      </Lines>
+    </DrawingSurfaceModel>
+  </DrawingSurfaceList>
+  <MetaInformation>
+    <Contributors>Jane, Joe</Contributors>
+    <HighLevelSystemDescription>Web application</HighLevelSystemDescription>
+    <Owner>Jane</Owner>
+    <ThreatModelName>Portal</ThreatModelName>
+  </MetaInformation>
+  <ThreatInstances xmlns:a="http://schemas.microsoft.com/2003/10/Serialization/Arrays">
+    <a:KeyValueOfstringThreatpc_P0_PhOB><a:Key>1</a:Key></a:KeyValueOfstringThreatpc_P0_PhOB>
+  </ThreatInstances>
+</ThreatModel>`
+
+func TestImportTMT(t *testing.T) {
+	result, err := ImportTMT([]byte(tmtModelFile))
+	require.NoError(t, err)
+
+	model := result.Model
+	assert.Equal(t, "Portal", model.Title)
+	assert.Equal(t, "Web application", model.AppDescription.Description)
+	assert.Len(t, model.Contributors, 2)
+	require.Len(t, model.TechnicalAssets, 3)
+
+	browser := model.TechnicalAssets["Browser"]
+	assert.Equal(t, "browser", browser.Technology)
+	assert.Equal(t, "https", browser.CommunicationLinks["Request"].Protocol)
+	assert.Equal(t, "web-server", browser.CommunicationLinks["Request"].Target)
+	assert.Equal(t, "web-server", model.TechnicalAssets["Web Server"].Technology)
+	assert.Equal(t, "unknown-technology", model.TechnicalAssets["Tape Archive"].Technology)
+
+	require.Len(t, model.TrustBoundaries, 1)
+	assert.ElementsMatch(t, []string{"web-server", "tape-archive"}, model.TrustBoundaries["Azure"].TechnicalAssetsInside)
+
+	assert.Contains(t, result.Unmapped, `1 threat instance(s) (Threagile generates its own risks)`)
+	assert.Contains(t, result.Unmapped, `stencil "SE.DS.Custom.Tape" of "Tape Archive" (imported as unknown-technology)`)
+	assert.Contains(t, result.Unmapped, `trust boundary line "Internet Boundary" on diagram "Diagram 1" (only border boundaries can be mapped to trust boundaries)`)
+}
+
+func TestTMTProperty_SelectedListValue(t *testing.T) {
+	var source tmtModel
+	require.NoError(t, xml.Unmarshal([]byte(tmtModelFile), &source))
+	assert.Equal(t, "Unmanaged", source.Surfaces[0].Borders[2].property("Code Type"))
+}
+
+func TestImportFile_DetectsFormat(t *testing.T) {
+	dir := t.TempDir()
+	tm7 := filepath.Join(dir, "model.tm7")
+	require.NoError(t, os.WriteFile(tm7, []byte(tmtModelFile), 0600))
+	td := filepath.Join(dir, "model.json")
+	require.NoError(t, os.WriteFile(td, []byte(threatDragonV2), 0600))
+
+	result, err := ImportFile(tm7, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Portal", result.Model.Title)
+
+	result, err = ImportFile(td, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Shop", result.Model.Title)
+
+	_, err = ImportFile(td, "visio")
+	assert.Error(t, err)
+}
@@ -0,0 +1,276 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// threatDragonModel covers both the version 1 (JointJS based) and the version 2 (X6 based) file layout of OWASP Threat Dragon
+type threatDragonModel struct {
+	Version string `json:"version"`
+	Summary struct {
+		Title       string `json:"title"`
+		Owner       string `json:"owner"`
+		Description string `json:"description"`
+	} `json:"summary"`
+	Detail struct {
+		Contributors []struct {
+			Name string `json:"name"`
+		} `json:"contributors"`
+		Reviewer string                `json:"reviewer"`
+		Diagrams []threatDragonDiagram `json:"diagrams"`
+	} `json:"detail"`
+}
+
+type threatDragonDiagram struct {
+	Title       string              `json:"title"`
+	Cells       []threatDragonCell  `json:"cells"`
+	DiagramJson *threatDragonCellV1 `json:"diagramJson"`
+}
+
+type threatDragonCellV1 struct {
+	Cells []threatDragonCell `json:"cells"`
+}
+
+type threatDragonCell struct {
+	Id       string `json:"id"`
+	Shape    string `json:"shape"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Position *struct {
+		X float64 `json:"x"`
+		Y float64 `json:"y"`
+	} `json:"position"`
+	Size *struct {
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	} `json:"size"`
+	Source threatDragonEndpoint `json:"source"`
+	Target threatDragonEndpoint `json:"target"`
+	Attrs  struct {
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	} `json:"attrs"`
+	Labels []struct {
+		Attrs struct {
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"attrs"`
+	} `json:"labels"`
+	Data *threatDragonData `json:"data"`
+
+	threatDragonData // version 1 keeps the element properties directly in the cell
+}
+
+type threatDragonEndpoint struct {
+	Cell string `json:"cell"`
+	Id   string `json:"id"`
+}
+
+type threatDragonData struct {
+	Type             string               `json:"type"`
+	Name             string               `json:"name"`
+	Description      string               `json:"description"`
+	OutOfScope       bool                 `json:"outOfScope"`
+	ReasonOutOfScope string               `json:"reasonOutOfScope"`
+	IsEncrypted      bool                 `json:"isEncrypted"`
+	IsPublicNetwork  bool                 `json:"isPublicNetwork"`
+	IsBidirectional  bool                 `json:"isBidirectional"`
+	Protocol         string               `json:"protocol"`
+	IsALog           bool                 `json:"isALog"`
+	IsWebApplication bool                 `json:"isWebApplication"`
+	Threats          []threatDragonThreat `json:"threats"`
+}
+
+type threatDragonThreat struct {
+	Title string `json:"title"`
+}
+
+func (what threatDragonCell) data() threatDragonData {
+	if what.Data != nil {
+		return *what.Data
+	}
+	return what.threatDragonData
+}
+
+func (what threatDragonCell) kind() string {
+	if kind := what.data().Type; len(kind) > 0 {
+		return kind
+	}
+	if len(what.Type) > 0 {
+		return what.Type
+	}
+	return what.Shape
+}
+
+func (what threatDragonCell) name() string {
+	if name := what.data().Name; len(name) > 0 {
+		return name
+	}
+	if len(what.Attrs.Text.Text) > 0 {
+		return what.Attrs.Text.Text
+	}
+	for _, label := range what.Labels {
+		if len(label.Attrs.Text.Text) > 0 {
+			return label.Attrs.Text.Text
+		}
+	}
+	return what.Name
+}
+
+func (what threatDragonCell) box() (box, bool) {
+	if what.Position == nil || what.Size == nil {
+		return box{}, false
+	}
+	return box{elementId: what.Id, left: what.Position.X, top: what.Position.Y, width: what.Size.Width, height: what.Size.Height}, true
+}
+
+func (what threatDragonEndpoint) id() string {
+	if len(what.Cell) > 0 {
+		return what.Cell
+	}
+	return what.Id
+}
+
+// ImportThreatDragon converts an OWASP Threat Dragon model (JSON): actors, processes and stores become technical assets,
+// boundary boxes become trust boundaries and data flows become communication links. Threats are not imported since
+// Threagile derives its own risks from the model.
+func ImportThreatDragon(data []byte) (*Result, error) {
+	var source threatDragonModel
+	unmarshalError := json.Unmarshal(data, &source)
+	if unmarshalError != nil {
+		return nil, fmt.Errorf("unable to parse Threat Dragon model: %w", unmarshalError)
+	}
+
+	if len(source.Detail.Diagrams) == 0 {
+		return nil, fmt.Errorf("no diagrams found in Threat Dragon model")
+	}
+
+	result := newBuilder("OWASP Threat Dragon", source.Summary.Title)
+	result.model.Author = input.Author{Name: source.Summary.Owner}
+	result.model.AppDescription = input.Overview{Description: source.Summary.Description}
+	for _, contributor := range source.Detail.Contributors {
+		if len(contributor.Name) > 0 {
+			result.model.Contributors = append(result.model.Contributors, input.Author{Name: contributor.Name})
+		}
+	}
+	if len(source.Detail.Reviewer) > 0 {
+		result.unmapped("reviewer %q", source.Detail.Reviewer)
+	}
+
+	for _, diagram := range source.Detail.Diagrams {
+		cells := diagram.Cells
+		if diagram.DiagramJson != nil {
+			cells = append(cells, diagram.DiagramJson.Cells...)
+		}
+
+		result.importThreatDragonDiagram(diagram.Title, cells)
+	}
+
+	return result.result, nil
+}
+
+func (what *builder) importThreatDragonDiagram(diagramTitle string, cells []threatDragonCell) {
+	assetBoxes := make([]box, 0)
+	boundaryBoxes := make([]box, 0)
+	boundaryNames := make(map[string]string)
+	flows := make([]threatDragonCell, 0)
+
+	for _, cell := range cells {
+		data := cell.data()
+		var assetType types.TechnicalAssetType
+		technology := types.UnknownTechnology
+
+		switch strings.ToLower(cell.kind()) {
+		case "tm.actor", "actor":
+			assetType = types.ExternalEntity
+
+		case "tm.process", "process":
+			assetType = types.Process
+			if data.IsWebApplication {
+				technology = types.WebApplication
+			}
+
+		case "tm.store", "store":
+			assetType = types.Datastore
+			technology = types.Database
+			if data.IsALog {
+				technology = types.Monitoring
+			}
+
+		case "tm.flow", "flow":
+			flows = append(flows, cell)
+			continue
+
+		case "tm.boundary", "trust-boundary-box", "trust-boundary-curve", "trust-broundary-curve":
+			if boundaryBox, ok := cell.box(); ok && cell.Shape == "trust-boundary-box" {
+				boundaryBoxes = append(boundaryBoxes, boundaryBox)
+				boundaryNames[cell.Id] = cell.name()
+			} else {
+				what.unmapped("trust boundary line %q on diagram %q (only boundary boxes can be mapped to trust boundaries)", cell.name(), diagramTitle)
+			}
+			continue
+
+		default:
+			what.unmapped("element %q of type %q on diagram %q", cell.name(), cell.kind(), diagramTitle)
+			continue
+		}
+
+		title := what.addTechnicalAsset(cell.Id, cell.name(), assetType, technology)
+		asset := what.model.TechnicalAssets[title]
+		if len(data.Description) > 0 {
+			asset.Description = data.Description
+		}
+		if data.OutOfScope {
+			asset.OutOfScope = true
+			asset.JustificationOutOfScope = data.ReasonOutOfScope
+		}
+		if data.IsEncrypted && assetType == types.Datastore {
+			asset.Encryption = types.Transparent.String()
+		}
+		what.model.TechnicalAssets[title] = asset
+
+		if cellBox, ok := cell.box(); ok {
+			assetBoxes = append(assetBoxes, cellBox)
+		}
+		what.unmappedThreats(data.Threats, title)
+	}
+
+	for _, flow := range flows {
+		data := flow.data()
+		protocol, known := mapProtocol(data.Protocol, data.IsEncrypted)
+		if !known && len(data.Protocol) > 0 {
+			what.unmapped("protocol %q of data flow %q", data.Protocol, flow.name())
+		}
+
+		link := input.CommunicationLink{
+			Description: data.Description,
+			Protocol:    protocol,
+		}
+		if !what.addCommunicationLink(flow.Source.id(), flow.Target.id(), flow.name(), link) {
+			what.unmapped("data flow %q on diagram %q is not connected to two elements", flow.name(), diagramTitle)
+			continue
+		}
+		if data.IsBidirectional {
+			what.addCommunicationLink(flow.Target.id(), flow.Source.id(), flow.name(), link)
+		}
+		if data.IsPublicNetwork {
+			what.unmapped("public network flag of data flow %q (model it with trust boundaries or an internet facing asset)", flow.name())
+		}
+		what.unmappedThreats(data.Threats, flow.name())
+	}
+
+	what.addTrustBoundaries(boundaryBoxes, boundaryNames, assetBoxes)
+}
+
+func (what *builder) unmappedThreats(threats []threatDragonThreat, elementTitle string) {
+	for _, threat := range threats {
+		what.unmapped("threat %q of %q (Threagile generates its own risks)", threat.Title, elementTitle)
+	}
+}
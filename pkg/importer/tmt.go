@@ -0,0 +1,244 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// tmtModel is the part of a Microsoft Threat Modeling Tool (.tm7) data contract file needed for the import.
+// Elements are matched by local name only, since the file uses a different XML namespace on nearly every level.
+type tmtModel struct {
+	XMLName  xml.Name     `xml:"ThreatModel"`
+	Surfaces []tmtSurface `xml:"DrawingSurfaceList>DrawingSurfaceModel"`
+	Meta     struct {
+		Assumptions                string `xml:"Assumptions"`
+		Contributors               string `xml:"Contributors"`
+		ExternalDependencies       string `xml:"ExternalDependencies"`
+		HighLevelSystemDescription string `xml:"HighLevelSystemDescription"`
+		Owner                      string `xml:"Owner"`
+		Reviewer                   string `xml:"Reviewer"`
+		ThreatModelName            string `xml:"ThreatModelName"`
+	} `xml:"MetaInformation"`
+	Notes   []string `xml:"Notes>Note>Message"`
+	Threats []struct {
+		Id string `xml:"Key"`
+	} `xml:"ThreatInstances>KeyValueOfstringThreatpc_P0_PhOB"`
+}
+
+type tmtSurface struct {
+	Header  string       `xml:"Header"`
+	Borders []tmtElement `xml:"Borders>KeyValueOfguidanyType>Value"`
+	Lines   []tmtElement `xml:"Lines>KeyValueOfguidanyType>Value"`
+}
+
+type tmtElement struct {
+	GenericTypeId string        `xml:"GenericTypeId"`
+	Guid          string        `xml:"Guid"`
+	TypeId        string        `xml:"TypeId"`
+	Properties    []tmtProperty `xml:"Properties>anyType"`
+	Left          float64       `xml:"Left"`
+	Top           float64       `xml:"Top"`
+	Width         float64       `xml:"Width"`
+	Height        float64       `xml:"Height"`
+	SourceGuid    string        `xml:"SourceGuid"`
+	TargetGuid    string        `xml:"TargetGuid"`
+}
+
+type tmtProperty struct {
+	DisplayName string `xml:"DisplayName"`
+	Value       struct {
+		Text   string   `xml:",chardata"`
+		Values []string `xml:"string"`
+	} `xml:"Value"`
+	SelectedIndex int `xml:"SelectedIndex"`
+}
+
+func (what tmtElement) property(displayName string) string {
+	for _, property := range what.Properties {
+		if !strings.EqualFold(property.DisplayName, displayName) {
+			continue
+		}
+		if values := property.Value.Values; len(values) > 0 {
+			if property.SelectedIndex >= 0 && property.SelectedIndex < len(values) {
+				return strings.TrimSpace(values[property.SelectedIndex])
+			}
+			return ""
+		}
+		return strings.TrimSpace(property.Value.Text)
+	}
+	return ""
+}
+
+func (what tmtElement) name() string {
+	return what.property("Name")
+}
+
+// stencil returns the last part of the type ID, e.g. "WebServer" for "SE.P.TMCore.WebServer"
+func (what tmtElement) stencil() string {
+	return what.TypeId[strings.LastIndex(what.TypeId, ".")+1:]
+}
+
+// tmtTechnologies maps the stencils of the default templates onto technologies
+var tmtTechnologies = map[string]string{
+	"webserver":     types.WebServer,
+	"webapp":        types.WebApplication,
+	"websvc":        types.WebServiceREST,
+	"webapi":        types.WebServiceREST,
+	"browser":       types.Browser,
+	"browserclient": types.Browser,
+	"thickclient":   types.Desktop,
+	"winapp":        types.Desktop,
+	"mobileclient":  types.MobileApp,
+	"authprovider":  types.IdentityProvider,
+	"sql":           types.Database,
+	"sqldatabase":   types.Database,
+	"nosql":         types.Database,
+	"fs":            types.FileServer,
+	"filesystem":    types.FileServer,
+	"cloudstorage":  types.BlockStorage,
+	"cache":         types.Database,
+	"registry":      types.LocalFileSystem,
+	"configfile":    types.LocalFileSystem,
+	"plugin":        types.Library,
+	"iotdevice":     types.IoTDevice,
+}
+
+// tmtProtocols maps the data flow stencils of the default templates onto protocols
+var tmtProtocols = map[string]types.Protocol{
+	"http":      types.HTTP,
+	"https":     types.HTTPS,
+	"binary":    types.BINARY,
+	"ipsec":     types.BinaryEncrypted,
+	"rpc":       types.BINARY,
+	"udp":       types.BINARY,
+	"smb":       types.SMB,
+	"namedpipe": types.InterProcessCommunication,
+	"alpc":      types.InterProcessCommunication,
+	"ioctl":     types.InterProcessCommunication,
+}
+
+// ImportTMT converts a Microsoft Threat Modeling Tool model (.tm7): processes, data stores and external interactors become
+// technical assets, border boundaries become trust boundaries and data flows become communication links. Threat
+// instances are not imported since Threagile derives its own risks from the model.
+func ImportTMT(data []byte) (*Result, error) {
+	var source tmtModel
+	unmarshalError := xml.Unmarshal(data, &source)
+	if unmarshalError != nil {
+		return nil, fmt.Errorf("unable to parse Threat Modeling Tool model: %w", unmarshalError)
+	}
+
+	if len(source.Surfaces) == 0 {
+		return nil, fmt.Errorf("no diagrams found in Threat Modeling Tool model")
+	}
+
+	result := newBuilder("Microsoft Threat Modeling Tool", strings.TrimSpace(source.Meta.ThreatModelName))
+	result.model.Author = input.Author{Name: strings.TrimSpace(source.Meta.Owner)}
+	result.model.AppDescription = input.Overview{Description: strings.TrimSpace(source.Meta.HighLevelSystemDescription)}
+	for _, contributor := range strings.FieldsFunc(source.Meta.Contributors, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		if contributor = strings.TrimSpace(contributor); len(contributor) > 0 {
+			result.model.Contributors = append(result.model.Contributors, input.Author{Name: contributor})
+		}
+	}
+
+	if len(strings.TrimSpace(source.Meta.Reviewer)) > 0 {
+		result.unmapped("reviewer %q", strings.TrimSpace(source.Meta.Reviewer))
+	}
+	if len(strings.TrimSpace(source.Meta.Assumptions)) > 0 {
+		result.unmapped("assumptions of the model")
+	}
+	if len(strings.TrimSpace(source.Meta.ExternalDependencies)) > 0 {
+		result.unmapped("external dependencies of the model")
+	}
+	if len(source.Notes) > 0 {
+		result.unmapped("%d note(s) of the model", len(source.Notes))
+	}
+	if len(source.Threats) > 0 {
+		result.unmapped("%d threat instance(s) (Threagile generates its own risks)", len(source.Threats))
+	}
+
+	for _, surface := range source.Surfaces {
+		result.importTMTSurface(surface)
+	}
+
+	return result.result, nil
+}
+
+func (what *builder) importTMTSurface(surface tmtSurface) {
+	assetBoxes := make([]box, 0)
+	boundaryBoxes := make([]box, 0)
+	boundaryNames := make(map[string]string)
+
+	for _, element := range surface.Borders {
+		var assetType types.TechnicalAssetType
+		switch element.GenericTypeId {
+		case "GE.EI":
+			assetType = types.ExternalEntity
+
+		case "GE.P":
+			assetType = types.Process
+
+		case "GE.DS":
+			assetType = types.Datastore
+
+		case "GE.TB.B":
+			boundaryBoxes = append(boundaryBoxes, box{elementId: element.Guid, left: element.Left, top: element.Top, width: element.Width, height: element.Height})
+			boundaryNames[element.Guid] = element.name()
+			continue
+
+		default:
+			what.unmapped("element %q of type %q on diagram %q", element.name(), element.TypeId, surface.Header)
+			continue
+		}
+
+		technology, ok := tmtTechnologies[strings.ToLower(element.stencil())]
+		if !ok {
+			technology = types.UnknownTechnology
+			if !strings.HasPrefix(element.TypeId, "GE.") {
+				what.unmapped("stencil %q of %q (imported as %v)", element.TypeId, element.name(), technology)
+			}
+		}
+
+		title := what.addTechnicalAsset(element.Guid, element.name(), assetType, technology)
+		asset := what.model.TechnicalAssets[title]
+		if strings.EqualFold(element.property("Out Of Scope"), "true") {
+			asset.OutOfScope = true
+			asset.JustificationOutOfScope = element.property("Reason For Out Of Scope")
+		}
+		if strings.EqualFold(element.stencil(), "User") || strings.EqualFold(element.stencil(), "Human") {
+			asset.UsedAsClientByHuman = true
+		}
+		what.model.TechnicalAssets[title] = asset
+
+		assetBoxes = append(assetBoxes, box{elementId: element.Guid, left: element.Left, top: element.Top, width: element.Width, height: element.Height})
+	}
+
+	for _, element := range surface.Lines {
+		switch element.GenericTypeId {
+		case "GE.DF":
+			protocol, ok := tmtProtocols[strings.ToLower(element.stencil())]
+			if !ok {
+				protocol = types.UnknownProtocol
+				if !strings.HasPrefix(element.TypeId, "GE.") {
+					what.unmapped("stencil %q of data flow %q (imported as %v)", element.TypeId, element.name(), protocol)
+				}
+			}
+
+			link := input.CommunicationLink{Protocol: protocol.String()}
+			if !what.addCommunicationLink(element.SourceGuid, element.TargetGuid, element.name(), link) {
+				what.unmapped("data flow %q on diagram %q is not connected to two elements", element.name(), surface.Header)
+			}
+
+		case "GE.TB.L":
+			what.unmapped("trust boundary line %q on diagram %q (only border boundaries can be mapped to trust boundaries)", element.name(), surface.Header)
+
+		default:
+			what.unmapped("connector %q of type %q on diagram %q", element.name(), element.TypeId, surface.Header)
+		}
+	}
+
+	what.addTrustBoundaries(boundaryBoxes, boundaryNames, assetBoxes)
+}
@@ -6,7 +6,6 @@ package input
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"slices"
@@ -14,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/mpvl/unique"
+	"github.com/threagile/threagile/pkg/types"
 
 	"gopkg.in/yaml.v3"
 )
@@ -49,6 +49,8 @@ type Model struct { // TODO: Eventually remove this and directly use ParsedModel
 	DiagramTweakLayoutLeftToRight                 bool                      `yaml:"diagram_tweak_layout_left_to_right,omitempty" json:"diagram_tweak_layout_left_to_right,omitempty"`
 	DiagramTweakInvisibleConnectionsBetweenAssets []string                  `yaml:"diagram_tweak_invisible_connections_between_assets,omitempty" json:"diagram_tweak_invisible_connections_between_assets,omitempty"`
 	DiagramTweakSameRankAssets                    []string                  `yaml:"diagram_tweak_same_rank_assets,omitempty" json:"diagram_tweak_same_rank_assets,omitempty"`
+
+	Positions Positions `yaml:"-" json:"-"`
 }
 
 func (model *Model) Defaults() *Model {
@@ -62,6 +64,7 @@ func (model *Model) Defaults() *Model {
 		SharedRuntimes:       make(map[string]SharedRuntime),
 		CustomRiskCategories: make(RiskCategories, 0),
 		RiskTracking:         make(map[string]RiskTracking),
+		Positions:            make(Positions),
 	}
 
 	return model
@@ -70,18 +73,20 @@ func (model *Model) Defaults() *Model {
 func (model *Model) Load(inputFilename string) error {
 	modelYaml, readError := os.ReadFile(filepath.Clean(inputFilename))
 	if readError != nil {
-		log.Fatal("Unable to read model file: ", readError)
+		return fmt.Errorf("unable to read model file: %w", readError)
 	}
 
 	unmarshalError := yaml.Unmarshal(modelYaml, &model)
 	if unmarshalError != nil {
-		log.Fatal("Unable to parse model yaml: ", unmarshalError)
+		return types.NewPositionError(&types.Position{File: inputFilename}, fmt.Errorf("unable to parse model yaml: %w", unmarshalError))
 	}
 
+	model.addPositions(inputFilename, modelYaml)
+
 	for _, includeFile := range model.Includes {
 		mergeError := model.Merge(filepath.Dir(inputFilename), includeFile)
 		if mergeError != nil {
-			log.Fatalf("Unable to merge model include %q: %v", includeFile, mergeError)
+			return fmt.Errorf("unable to merge model include %q: %w", includeFile, mergeError)
 		}
 	}
 
@@ -89,23 +94,32 @@ func (model *Model) Load(inputFilename string) error {
 }
 
 func (model *Model) Merge(dir string, includeFilename string) error {
-	modelYaml, readError := os.ReadFile(filepath.Clean(filepath.Join(dir, includeFilename)))
+	includePath := filepath.Join(dir, includeFilename)
+	modelYaml, readError := os.ReadFile(filepath.Clean(includePath))
 	if readError != nil {
 		return fmt.Errorf("unable to read model file: %w", readError)
 	}
 
+	return model.MergeYaml(dir, includeFilename, modelYaml)
+}
+
+// MergeYaml merges a partial model given as yaml into the model the same way as an include file named includeFilename
+func (model *Model) MergeYaml(dir string, includeFilename string, modelYaml []byte) error {
+	includePath := filepath.Join(dir, includeFilename)
 	var fileStructure map[string]any
 	unmarshalStructureError := yaml.Unmarshal(modelYaml, &fileStructure)
 	if unmarshalStructureError != nil {
-		return fmt.Errorf("unable to parse model structure: %w", unmarshalStructureError)
+		return types.NewPositionError(&types.Position{File: includePath}, fmt.Errorf("unable to parse model structure: %w", unmarshalStructureError))
 	}
 
 	var includedModel Model
 	unmarshalError := yaml.Unmarshal(modelYaml, &includedModel)
 	if unmarshalError != nil {
-		return fmt.Errorf("unable to parse model yaml: %w", unmarshalError)
+		return types.NewPositionError(&types.Position{File: includePath}, fmt.Errorf("unable to parse model yaml: %w", unmarshalError))
 	}
 
+	model.addPositions(includePath, modelYaml)
+
 	var mergeError error
 	for item := range fileStructure {
 		switch strings.ToLower(item) {
@@ -261,6 +275,24 @@ func (model *Model) Merge(dir string, includeFilename string) error {
 	return nil
 }
 
+func (model *Model) addPositions(filename string, modelYaml []byte) {
+	var node yaml.Node
+	if yaml.Unmarshal(modelYaml, &node) != nil {
+		return
+	}
+
+	if model.Positions == nil {
+		model.Positions = make(Positions)
+	}
+
+	model.Positions.Add(filename, &node)
+}
+
+// Position returns the source position of the model element at the given path, like ("technical_assets", title, "usage")
+func (model *Model) Position(path ...string) *types.Position {
+	return model.Positions.Get(path...)
+}
+
 func (model *Model) AddTagToModelInput(tag string, dryRun bool, changes *[]string) {
 	tag = NormalizeTag(tag)
 
@@ -0,0 +1,82 @@
+package input
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Positions maps the path of a model element, like "technical_assets/Some Asset/usage", to its source position.
+// Sequence items are addressed by their 'id' if they have one, and by their index otherwise.
+type Positions map[string]*types.Position
+
+// Add records the positions of all mapping keys and sequence items of a parsed model file.
+// Positions already known (e.g. from the including file) are kept.
+func (what Positions) Add(filename string, node *yaml.Node) {
+	what.add(filename, node, "")
+}
+
+func (what Positions) add(filename string, node *yaml.Node, path string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			what.add(filename, child, path)
+		}
+
+	case yaml.MappingNode:
+		for n := 0; n+1 < len(node.Content); n += 2 {
+			key, value := node.Content[n], node.Content[n+1]
+			what.set(filename, key, joinPath(path, key.Value))
+			what.add(filename, value, joinPath(path, key.Value))
+		}
+
+	case yaml.SequenceNode:
+		for n, item := range node.Content {
+			itemPath := joinPath(path, sequenceItemName(item, n))
+			what.set(filename, item, itemPath)
+			what.add(filename, item, itemPath)
+		}
+	}
+}
+
+func (what Positions) set(filename string, node *yaml.Node, path string) {
+	if _, ok := what[path]; ok {
+		return
+	}
+
+	what[path] = &types.Position{File: filename, Line: node.Line, Column: node.Column}
+}
+
+// Get returns the position of the model element at the given path, or of its closest known parent
+func (what Positions) Get(path ...string) *types.Position {
+	for n := len(path); n > 0; n-- {
+		position, ok := what[strings.Join(path[:n], "/")]
+		if ok {
+			return position
+		}
+	}
+
+	return nil
+}
+
+func joinPath(path string, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+
+	return path + "/" + name
+}
+
+func sequenceItemName(item *yaml.Node, index int) string {
+	if item.Kind == yaml.MappingNode {
+		for n := 0; n+1 < len(item.Content); n += 2 {
+			if item.Content[n].Value == "id" && len(item.Content[n+1].Value) > 0 {
+				return item.Content[n+1].Value
+			}
+		}
+	}
+
+	return strconv.Itoa(index)
+}
@@ -0,0 +1,459 @@
+package macros
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+type AddIdentityProviderMacro struct {
+	macroState            map[string][]string
+	questionsAnswered     []string
+	separateIdentityStore bool
+}
+
+var ssoProtocols = []string{
+	"OIDC",
+	"SAML",
+}
+var identityStoreTypes = []string{
+	"LDAP Directory",
+	"Database",
+}
+
+func NewAddIdentityProvider() *AddIdentityProviderMacro {
+	return &AddIdentityProviderMacro{
+		macroState:        make(map[string][]string),
+		questionsAnswered: make([]string, 0),
+	}
+}
+
+func (m *AddIdentityProviderMacro) GetMacroDetails() MacroDetails {
+	return MacroDetails{
+		ID:          "add-identity-provider",
+		Title:       "Add Identity Provider",
+		Description: "This model macro adds an identity provider (single sign-on) with its identity store to the model and lets the selected assets authenticate their users through it.",
+	}
+}
+
+func (m *AddIdentityProviderMacro) GetNextQuestion(parsedModel *types.Model) (nextQuestion MacroQuestion, err error) {
+	for _, question := range m.questions(parsedModel) {
+		if _, answered := m.macroState[question.ID]; answered || !m.isAsked(question.ID, parsedModel) {
+			continue
+		}
+		return question, nil
+	}
+	return NoMoreQuestions(), nil
+}
+
+// isAsked skips the questions which do not apply to the model or to the answers given so far
+func (m *AddIdentityProviderMacro) isAsked(questionID string, parsedModel *types.Model) bool {
+	switch questionID {
+	case "relying-parties":
+		return len(m.relyingPartyCandidates(parsedModel)) > 0
+	case "identity-store-type":
+		return m.separateIdentityStore
+	case "trust-boundary-type":
+		_, exists := parsedModel.TrustBoundaries["identity-network"]
+		return !exists
+	}
+	return true
+}
+
+func (m *AddIdentityProviderMacro) questions(parsedModel *types.Model) []MacroQuestion {
+	return []MacroQuestion{
+		{
+			ID:              "idp-name",
+			Title:           "What product is used as the identity provider?",
+			Description:     "This name affects the technical asset's title and ID plus also the tags used.",
+			PossibleAnswers: nil,
+			MultiSelect:     false,
+			DefaultAnswer:   "",
+		},
+		{
+			ID:              "sso-protocol",
+			Title:           "Which protocol is used for the single sign-on (OpenID Connect or SAML)?",
+			Description:     "This selection affects the authentication of the communication links.",
+			PossibleAnswers: ssoProtocols,
+			MultiSelect:     false,
+			DefaultAnswer:   ssoProtocols[0],
+		},
+		{
+			ID:              "relying-parties",
+			Title:           "Select all technical assets that authenticate their users through the identity provider:",
+			Description:     "Links from human-used clients to these assets are switched to end user identity propagation, and the clients get a login link to the identity provider.",
+			PossibleAnswers: m.relyingPartyCandidates(parsedModel),
+			MultiSelect:     true,
+			DefaultAnswer:   "",
+		},
+		{
+			ID:              "two-factor",
+			Title:           "Do users log in with a second factor?",
+			Description:     "This selection affects the authentication of the login links.",
+			PossibleAnswers: []string{"Yes", "No"},
+			MultiSelect:     false,
+			DefaultAnswer:   "Yes",
+		},
+		{
+			ID:              "separate-identity-store",
+			Title:           "Does the identity provider use a separate identity store?",
+			Description:     "Without a separate identity store the identities are stored by the identity provider itself (which the missing-identity-store rule still reports).",
+			PossibleAnswers: []string{"Yes", "No"},
+			MultiSelect:     false,
+			DefaultAnswer:   "Yes",
+		},
+		{
+			ID:              "identity-store-type",
+			Title:           "What type of identity store is used?",
+			Description:     "This selection affects the technology of the identity store and its communication link.",
+			PossibleAnswers: identityStoreTypes,
+			MultiSelect:     false,
+			DefaultAnswer:   "",
+		},
+		{
+			ID:          "trust-boundary-type",
+			Title:       "Of which type shall the trust boundary isolating the identity provider be?",
+			Description: "",
+			PossibleAnswers: []string{types.NetworkOnPrem.String(),
+				types.NetworkDedicatedHoster.String(),
+				types.NetworkVirtualLAN.String(),
+				types.NetworkCloudProvider.String(),
+				types.NetworkCloudSecurityGroup.String(),
+				types.NetworkPolicyNamespaceIsolation.String()},
+			MultiSelect:   false,
+			DefaultAnswer: types.NetworkOnPrem.String(),
+		},
+	}
+}
+
+func (m *AddIdentityProviderMacro) relyingPartyCandidates(parsedModel *types.Model) []string {
+	possibleAnswers := make([]string, 0)
+	for id, techAsset := range parsedModel.TechnicalAssets {
+		if !techAsset.Technologies.GetAttribute(types.IsIdentityRelated) {
+			possibleAnswers = append(possibleAnswers, id)
+		}
+	}
+	sort.Strings(possibleAnswers)
+	return possibleAnswers
+}
+
+func (m *AddIdentityProviderMacro) ApplyAnswer(questionID string, answer ...string) (message string, validResult bool, err error) {
+	m.macroState[questionID] = answer
+	m.questionsAnswered = append(m.questionsAnswered, questionID)
+	switch questionID {
+	case "separate-identity-store":
+		m.separateIdentityStore = strings.EqualFold(m.macroState["separate-identity-store"][0], "yes")
+	}
+
+	return "Answer processed", true, nil
+}
+
+func (m *AddIdentityProviderMacro) GoBack() (message string, validResult bool, err error) {
+	if len(m.questionsAnswered) == 0 {
+		return "Cannot go back further", false, nil
+	}
+	lastQuestionID := m.questionsAnswered[len(m.questionsAnswered)-1]
+	m.questionsAnswered = m.questionsAnswered[:len(m.questionsAnswered)-1]
+	delete(m.macroState, lastQuestionID)
+	if lastQuestionID == "separate-identity-store" {
+		m.separateIdentityStore = false
+	}
+	return "Undo successful", true, nil
+}
+
+func (m *AddIdentityProviderMacro) GetFinalChangeImpact(modelInput *input.Model, parsedModel *types.Model) (changes []string, message string, validResult bool, err error) {
+	changeLogCollector := make([]string, 0)
+	message, validResult, err = m.applyChange(modelInput, parsedModel, &changeLogCollector, true)
+	return changeLogCollector, message, validResult, err
+}
+
+func (m *AddIdentityProviderMacro) Execute(modelInput *input.Model, parsedModel *types.Model) (message string, validResult bool, err error) {
+	changeLogCollector := make([]string, 0)
+	message, validResult, err = m.applyChange(modelInput, parsedModel, &changeLogCollector, false)
+	return message, validResult, err
+}
+
+func (m *AddIdentityProviderMacro) applyChange(modelInput *input.Model, parsedModel *types.Model, changeLogCollector *[]string, dryRun bool) (message string, validResult bool, err error) {
+	idpName := m.macroState["idp-name"][0]
+	modelInput.AddTagToModelInput(idpName, dryRun, changeLogCollector)
+
+	saml := m.macroState["sso-protocol"][0] == ssoProtocols[1]
+	twoFactor := strings.EqualFold(m.macroState["two-factor"][0], "yes")
+
+	if _, exists := parsedModel.DataAssets["identity-data"]; !exists {
+		if existing, taken := modelInput.DataAssets["Identity Data"]; taken {
+			return fmt.Sprintf("data asset title 'Identity Data' is already used by data asset '%v'", existing.ID), false, nil
+		}
+		dataAsset := input.DataAsset{
+			ID:                     "identity-data",
+			Description:            "User identities with their credentials, profiles and group memberships managed by the identity provider",
+			Usage:                  types.Business.String(),
+			Tags:                   []string{},
+			Origin:                 "",
+			Owner:                  "",
+			Quantity:               types.Many.String(),
+			Confidentiality:        types.StrictlyConfidential.String(),
+			Integrity:              types.Critical.String(),
+			Availability:           types.Critical.String(),
+			JustificationCiaRating: "Identity data is rated as being 'strictly-confidential' since it allows to impersonate any user.",
+		}
+		*changeLogCollector = append(*changeLogCollector, "adding data asset: identity-data")
+		if !dryRun {
+			if modelInput.DataAssets == nil {
+				modelInput.DataAssets = make(map[string]input.DataAsset)
+			}
+			modelInput.DataAssets["Identity Data"] = dataAsset
+		}
+	}
+
+	idpID := types.MakeID(idpName) + "-idp"
+	storeID := types.MakeID(idpName) + "-identity-store"
+	identityAssets := []string{idpID}
+
+	if m.separateIdentityStore {
+		identityAssets = append(identityAssets, storeID)
+		if _, exists := parsedModel.TechnicalAssets[storeID]; !exists {
+			tech := types.IdentityStoreLDAP
+			if m.macroState["identity-store-type"][0] == identityStoreTypes[1] {
+				tech = types.IdentityStoreDatabase
+			}
+			techAsset := input.TechnicalAsset{
+				ID:                      storeID,
+				Description:             idpName + " Identity Store",
+				Type:                    types.Datastore.String(),
+				Usage:                   types.Business.String(),
+				UsedAsClientByHuman:     false,
+				OutOfScope:              false,
+				JustificationOutOfScope: "",
+				Size:                    types.Component.String(),
+				Technology:              tech,
+				Tags:                    []string{input.NormalizeTag(idpName)},
+				Internet:                false,
+				Machine:                 types.Virtual.String(),
+				Encryption:              types.NoneEncryption.String(),
+				Owner:                   "",
+				Confidentiality:         types.StrictlyConfidential.String(),
+				Integrity:               types.Critical.String(),
+				Availability:            types.Critical.String(),
+				JustificationCiaRating:  "The identity store is rated as 'strictly-confidential' since it stores the identity data.",
+				MultiTenant:             false,
+				Redundant:               false,
+				CustomDevelopedParts:    false,
+				DataAssetsProcessed:     []string{"identity-data"},
+				DataAssetsStored:        []string{"identity-data"},
+				DataFormatsAccepted:     nil,
+				CommunicationLinks:      nil,
+			}
+			*changeLogCollector = append(*changeLogCollector, "adding technical asset: "+storeID)
+			if !dryRun {
+				modelInput.TechnicalAssets[idpName+" Identity Store"] = techAsset
+			}
+		}
+	}
+
+	if _, exists := parsedModel.TechnicalAssets[idpID]; !exists {
+		commLinks := make(map[string]input.CommunicationLink)
+		if m.separateIdentityStore {
+			storeLink := input.CommunicationLink{
+				Target:                 storeID,
+				Description:            "Identity Store Access",
+				Protocol:               types.LDAPS.String(),
+				Authentication:         types.Credentials.String(),
+				Authorization:          types.TechnicalUser.String(),
+				Tags:                   []string{},
+				VPN:                    false,
+				IpFiltered:             false,
+				Readonly:               false,
+				Usage:                  types.Business.String(),
+				DataAssetsSent:         []string{"identity-data"},
+				DataAssetsReceived:     []string{"identity-data"},
+				DiagramTweakWeight:     0,
+				DiagramTweakConstraint: false,
+			}
+			if m.macroState["identity-store-type"][0] == identityStoreTypes[1] {
+				storeLink.Protocol = types.SqlAccessProtocolEncrypted.String()
+			}
+			commLinks["Identity Store Access"] = storeLink
+		}
+
+		techAsset := input.TechnicalAsset{
+			ID:                      idpID,
+			Description:             idpName + " Identity Provider",
+			Type:                    types.Process.String(),
+			Usage:                   types.Business.String(),
+			UsedAsClientByHuman:     false,
+			OutOfScope:              false,
+			JustificationOutOfScope: "",
+			Size:                    types.Service.String(),
+			Technology:              types.IdentityProvider,
+			Tags:                    []string{input.NormalizeTag(idpName)},
+			Internet:                false,
+			Machine:                 types.Virtual.String(),
+			Encryption:              types.NoneEncryption.String(),
+			Owner:                   "",
+			Confidentiality:         types.StrictlyConfidential.String(),
+			Integrity:               types.Critical.String(),
+			Availability:            types.Critical.String(),
+			JustificationCiaRating:  "The identity provider is rated as 'strictly-confidential' since it processes the identity data and issues the login sessions of all users.",
+			MultiTenant:             false,
+			Redundant:               false,
+			CustomDevelopedParts:    false,
+			DataAssetsProcessed:     []string{"identity-data"},
+			DataAssetsStored:        nil,
+			DataFormatsAccepted:     nil,
+			CommunicationLinks:      commLinks,
+		}
+		if !m.separateIdentityStore {
+			techAsset.DataAssetsStored = []string{"identity-data"}
+		}
+		*changeLogCollector = append(*changeLogCollector, "adding technical asset (including communication links): "+idpID)
+		if !dryRun {
+			modelInput.TechnicalAssets[idpName+" Identity Provider"] = techAsset
+		}
+	}
+
+	// after the login the users present a token (OIDC) or a session cookie (SAML) to the relying parties
+	userAuthentication := types.Token.String()
+	backChannelAuthentication := types.Credentials.String()
+	if saml {
+		userAuthentication = types.SessionId.String()
+		backChannelAuthentication = types.ClientCertificate.String()
+	}
+
+	humanClients := make(map[string]bool)
+	for _, relyingPartyID := range m.macroState["relying-parties"] {
+		relyingParty, exists := parsedModel.TechnicalAssets[relyingPartyID]
+		if !exists {
+			continue
+		}
+		if relyingParty.UsedAsClientByHuman {
+			humanClients[relyingPartyID] = true
+			continue
+		}
+
+		for _, commLink := range parsedModel.IncomingTechnicalCommunicationLinksMappedByTargetId[relyingPartyID] {
+			caller := parsedModel.TechnicalAssets[commLink.SourceId]
+			if !caller.UsedAsClientByHuman {
+				continue
+			}
+			humanClients[caller.Id] = true
+
+			if commLink.Authentication.String() == userAuthentication && commLink.Authorization == types.EndUserIdentityPropagation {
+				continue
+			}
+			*changeLogCollector = append(*changeLogCollector, "updating communication link (authentication via identity provider): "+commLink.Id)
+			if !dryRun {
+				link := modelInput.TechnicalAssets[caller.Title].CommunicationLinks[commLink.Title]
+				link.Authentication = userAuthentication
+				link.Authorization = types.EndUserIdentityPropagation.String()
+				modelInput.TechnicalAssets[caller.Title].CommunicationLinks[commLink.Title] = link
+			}
+		}
+
+		// the relying party validates the tokens or assertions with the identity provider
+		linkTitle := "Identity Provider Access (" + idpID + ")"
+		if _, exists := modelInput.TechnicalAssets[relyingParty.Title].CommunicationLinks[linkTitle]; !exists {
+			backChannelLink := input.CommunicationLink{
+				Target:                 idpID,
+				Description:            "Validation of logins at the identity provider via " + m.macroState["sso-protocol"][0],
+				Protocol:               types.HTTPS.String(),
+				Authentication:         backChannelAuthentication,
+				Authorization:          types.TechnicalUser.String(),
+				Tags:                   []string{},
+				VPN:                    false,
+				IpFiltered:             false,
+				Readonly:               true,
+				Usage:                  types.Business.String(),
+				DataAssetsSent:         nil,
+				DataAssetsReceived:     []string{"identity-data"},
+				DiagramTweakWeight:     0,
+				DiagramTweakConstraint: false,
+			}
+			*changeLogCollector = append(*changeLogCollector, "adding communication link: "+relyingPartyID+" -> "+idpID)
+			if !dryRun {
+				m.addCommunicationLink(modelInput, relyingParty.Title, linkTitle, backChannelLink)
+			}
+		}
+	}
+
+	loginAuthentication := types.Credentials.String()
+	if twoFactor {
+		loginAuthentication = types.TwoFactor.String()
+	}
+	for _, clientID := range mapKeys(humanClients) {
+		client := parsedModel.TechnicalAssets[clientID]
+		linkTitle := "Login (" + idpID + ")"
+		if _, exists := modelInput.TechnicalAssets[client.Title].CommunicationLinks[linkTitle]; exists {
+			continue
+		}
+		loginLink := input.CommunicationLink{
+			Target:                 idpID,
+			Description:            "Login of the user at the identity provider via " + m.macroState["sso-protocol"][0],
+			Protocol:               types.HTTPS.String(),
+			Authentication:         loginAuthentication,
+			Authorization:          types.EndUserIdentityPropagation.String(),
+			Tags:                   []string{},
+			VPN:                    false,
+			IpFiltered:             false,
+			Readonly:               false,
+			Usage:                  types.Business.String(),
+			DataAssetsSent:         []string{"identity-data"},
+			DataAssetsReceived:     nil,
+			DiagramTweakWeight:     0,
+			DiagramTweakConstraint: false,
+		}
+		*changeLogCollector = append(*changeLogCollector, "adding communication link: "+clientID+" -> "+idpID)
+		if !dryRun {
+			m.addCommunicationLink(modelInput, client.Title, linkTitle, loginLink)
+		}
+	}
+
+	// identity-related assets need their own network segment
+	if existingBoundary, exists := parsedModel.TrustBoundaries["identity-network"]; exists {
+		assetsInside := make([]string, 0)
+		for _, assetID := range identityAssets {
+			if _, contained := parsedModel.DirectContainingTrustBoundaryMappedByTechnicalAssetId[assetID]; !contained && !slices.Contains(existingBoundary.TechnicalAssetsInside, assetID) {
+				assetsInside = append(assetsInside, assetID)
+			}
+		}
+		if len(assetsInside) > 0 {
+			*changeLogCollector = append(*changeLogCollector, "filling existing trust boundary: identity-network")
+			if !dryRun {
+				trustBoundary := modelInput.TrustBoundaries[existingBoundary.Title]
+				trustBoundary.TechnicalAssetsInside = append(trustBoundary.TechnicalAssetsInside, assetsInside...)
+				modelInput.TrustBoundaries[existingBoundary.Title] = trustBoundary
+			}
+		}
+	} else {
+		trustBoundary := input.TrustBoundary{
+			ID:                    "identity-network",
+			Description:           "Identity Network",
+			Type:                  m.macroState["trust-boundary-type"][0],
+			Tags:                  []string{},
+			TechnicalAssetsInside: identityAssets,
+			TrustBoundariesNested: nil,
+		}
+		*changeLogCollector = append(*changeLogCollector, "adding trust boundary: identity-network")
+		if !dryRun {
+			if modelInput.TrustBoundaries == nil {
+				modelInput.TrustBoundaries = make(map[string]input.TrustBoundary)
+			}
+			modelInput.TrustBoundaries["Identity Network"] = trustBoundary
+		}
+	}
+
+	return "Changeset valid", true, nil
+}
+
+func (m *AddIdentityProviderMacro) addCommunicationLink(modelInput *input.Model, assetTitle string, linkTitle string, link input.CommunicationLink) {
+	techAsset := modelInput.TechnicalAssets[assetTitle]
+	if techAsset.CommunicationLinks == nil {
+		techAsset.CommunicationLinks = make(map[string]input.CommunicationLink)
+	}
+	techAsset.CommunicationLinks[linkTitle] = link
+	modelInput.TechnicalAssets[assetTitle] = techAsset
+}
@@ -0,0 +1,157 @@
+package macros
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// newIdentityProviderTestModel has a browser calling a web server with credentials; the browser is the only human-used
+// client, so it gets the login link once the web server is selected as relying party
+func newIdentityProviderTestModel(t *testing.T) (modelInput *input.Model, inputFile string, parsedModel *types.Model) {
+	t.Helper()
+
+	modelInput = &input.Model{
+		Title: "Identity Provider",
+		TechnicalAssets: map[string]input.TechnicalAsset{
+			"Browser": {ID: "browser", UsedAsClientByHuman: true, CommunicationLinks: map[string]input.CommunicationLink{
+				"Web Access": {Target: "web", Authentication: types.Credentials.String(), Authorization: types.TechnicalUser.String()},
+			}},
+			"Web Server": {ID: "web"},
+		},
+		DataAssets:      map[string]input.DataAsset{},
+		TrustBoundaries: map[string]input.TrustBoundary{},
+	}
+	inputFile = filepath.Join(t.TempDir(), "threagile.yaml")
+	data, err := yaml.Marshal(modelInput)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(inputFile, data, 0600))
+
+	webAccess := &types.CommunicationLink{
+		Id:             "browser>web-access",
+		Title:          "Web Access",
+		SourceId:       "browser",
+		TargetId:       "web",
+		Authentication: types.Credentials,
+		Authorization:  types.TechnicalUser,
+	}
+	parsedModel = &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"browser": {Id: "browser", Title: "Browser", UsedAsClientByHuman: true, CommunicationLinks: []*types.CommunicationLink{webAccess}},
+			"web":     {Id: "web", Title: "Web Server"},
+		},
+		DataAssets:      map[string]*types.DataAsset{},
+		TrustBoundaries: map[string]*types.TrustBoundary{},
+		IncomingTechnicalCommunicationLinksMappedByTargetId: map[string][]*types.CommunicationLink{"web": {webAccess}},
+	}
+
+	return modelInput, inputFile, parsedModel
+}
+
+func TestAddIdentityProvider(t *testing.T) {
+	modelInput, inputFile, parsedModel := newIdentityProviderTestModel(t)
+
+	err := ExecuteModelMacroWithAnswers(modelInput, inputFile, parsedModel, "add-identity-provider", nil, MacroAnswers{
+		"idp-name":                {"Keycloak"},
+		"sso-protocol":            {"OIDC"},
+		"relying-parties":         {"web"},
+		"two-factor":              {"Yes"},
+		"separate-identity-store": {"Yes"},
+		"identity-store-type":     {"Database"},
+		"trust-boundary-type":     {types.NetworkVirtualLAN.String()},
+	})
+	require.NoError(t, err)
+
+	written := new(input.Model)
+	require.NoError(t, written.Load(inputFile))
+	assert.Equal(t, "identity-data", written.DataAssets["Identity Data"].ID)
+	require.Contains(t, written.TechnicalAssets, "Keycloak Identity Provider")
+	require.Contains(t, written.TechnicalAssets, "Keycloak Identity Store")
+	assert.Equal(t, types.IdentityStoreDatabase, written.TechnicalAssets["Keycloak Identity Store"].Technology)
+	assert.Equal(t, types.SqlAccessProtocolEncrypted.String(), written.TechnicalAssets["Keycloak Identity Provider"].CommunicationLinks["Identity Store Access"].Protocol)
+
+	browserLinks := written.TechnicalAssets["Browser"].CommunicationLinks
+	assert.Equal(t, types.Token.String(), browserLinks["Web Access"].Authentication)
+	assert.Equal(t, types.EndUserIdentityPropagation.String(), browserLinks["Web Access"].Authorization)
+	assert.Equal(t, types.TwoFactor.String(), browserLinks["Login (keycloak-idp)"].Authentication)
+	assert.Equal(t, "keycloak-idp", written.TechnicalAssets["Web Server"].CommunicationLinks["Identity Provider Access (keycloak-idp)"].Target)
+
+	assert.Equal(t, types.NetworkVirtualLAN.String(), written.TrustBoundaries["Identity Network"].Type)
+	assert.Equal(t, []string{"keycloak-idp", "keycloak-identity-store"}, written.TrustBoundaries["Identity Network"].TechnicalAssetsInside)
+}
+
+func TestAddIdentityProviderQuestions(t *testing.T) {
+	_, _, parsedModel := newIdentityProviderTestModel(t)
+	parsedModel.TechnicalAssets = map[string]*types.TechnicalAsset{}
+	parsedModel.TrustBoundaries["identity-network"] = &types.TrustBoundary{Id: "identity-network", Title: "Identity Network"}
+
+	macro := NewAddIdentityProvider()
+	asked := make([]string, 0)
+	for {
+		question, err := macro.GetNextQuestion(parsedModel)
+		require.NoError(t, err)
+		if question.NoMoreQuestions() {
+			break
+		}
+		asked = append(asked, question.ID)
+		_, _, err = macro.ApplyAnswer(question.ID, "No")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"idp-name", "sso-protocol", "two-factor", "separate-identity-store"}, asked,
+		"questions are skipped without relying party candidates, without a separate identity store and with an existing trust boundary")
+
+	_, _, err := macro.GoBack()
+	require.NoError(t, err)
+	_, _, err = macro.ApplyAnswer("separate-identity-store", "Yes")
+	require.NoError(t, err)
+	question, err := macro.GetNextQuestion(parsedModel)
+	require.NoError(t, err)
+	assert.Equal(t, "identity-store-type", question.ID, "changing an answer asks the questions depending on it")
+}
+
+func TestAddIdentityProviderExistingTrustBoundary(t *testing.T) {
+	modelInput, inputFile, parsedModel := newIdentityProviderTestModel(t)
+	modelInput.TrustBoundaries["Identity Network"] = input.TrustBoundary{ID: "identity-network", TechnicalAssetsInside: []string{"directory"}}
+	parsedModel.TrustBoundaries["identity-network"] = &types.TrustBoundary{Id: "identity-network", Title: "Identity Network", TechnicalAssetsInside: []string{"directory"}}
+
+	err := ExecuteModelMacroWithAnswers(modelInput, inputFile, parsedModel, "add-identity-provider", nil, MacroAnswers{
+		"idp-name":                {"Keycloak"},
+		"sso-protocol":            {"SAML"},
+		"relying-parties":         {"web"},
+		"two-factor":              {"No"},
+		"separate-identity-store": {"No"},
+	})
+	require.NoError(t, err)
+
+	written := new(input.Model)
+	require.NoError(t, written.Load(inputFile))
+	assert.Equal(t, []string{"directory", "keycloak-idp"}, written.TrustBoundaries["Identity Network"].TechnicalAssetsInside)
+	assert.Len(t, written.TrustBoundaries, 1)
+}
+
+func TestAddIdentityProviderDataAssetTitleTaken(t *testing.T) {
+	modelInput, inputFile, parsedModel := newIdentityProviderTestModel(t)
+	modelInput.DataAssets["Identity Data"] = input.DataAsset{ID: "customer-identities"}
+	parsedModel.DataAssets["customer-identities"] = &types.DataAsset{Id: "customer-identities", Title: "Identity Data"}
+
+	err := ExecuteModelMacroWithAnswers(modelInput, inputFile, parsedModel, "add-identity-provider", nil, MacroAnswers{
+		"idp-name":                {"Keycloak"},
+		"sso-protocol":            {"OIDC"},
+		"relying-parties":         {"web"},
+		"two-factor":              {"Yes"},
+		"separate-identity-store": {"No"},
+		"trust-boundary-type":     {types.NetworkOnPrem.String()},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "data asset title 'Identity Data' is already used by data asset 'customer-identities'")
+	assert.Equal(t, "customer-identities", modelInput.DataAssets["Identity Data"].ID, "the existing data asset is kept")
+}
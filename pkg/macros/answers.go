@@ -0,0 +1,167 @@
+package macros
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// MacroAnswers maps question IDs to the answers replayed instead of asking on stdin; single answers may be given as scalar
+type MacroAnswers map[string]MacroAnswer
+
+type MacroAnswer []string
+
+func (what *MacroAnswer) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var values []string
+		err := node.Decode(&values)
+		*what = values
+		return err
+	}
+
+	var value string
+	err := node.Decode(&value)
+	*what = MacroAnswer{value}
+	return err
+}
+
+func LoadMacroAnswers(filename string) (MacroAnswers, error) {
+	data, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read macro answers file: %w", err)
+	}
+
+	answers := make(MacroAnswers)
+	err = yaml.Unmarshal(data, &answers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse macro answers file %q: %w", filename, err)
+	}
+
+	return answers, nil
+}
+
+// ExecuteModelMacroWithAnswers runs a macro without any interaction: the answers are replayed in the order the macro asks
+// its questions. Every question asked needs an answer and every answer has to be used, so a typo in a question ID fails
+// the macro instead of silently using a default. The changes are printed before the model file is updated.
+func ExecuteModelMacroWithAnswers(modelInput *input.Model, inputFile string, parsedModel *types.Model, macroID string, customMacros []Macros, answers MacroAnswers) error {
+	macros, err := GetMacroByID(macroID, customMacros)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Executing model macro:", macros.GetMacroDetails().ID)
+	asked := make(map[string]bool)
+	for {
+		nextQuestion, err := macros.GetNextQuestion(parsedModel)
+		if err != nil {
+			return err
+		}
+		if nextQuestion.NoMoreQuestions() {
+			break
+		}
+		if asked[nextQuestion.ID] {
+			return fmt.Errorf("macro %q asked question %q again after it was answered", macroID, nextQuestion.ID)
+		}
+		asked[nextQuestion.ID] = true
+
+		answer, err := nextQuestion.replayAnswer(answers)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%v: %v\n", nextQuestion.Title, strings.Join(answer, ", "))
+
+		message, validResult, err := macros.ApplyAnswer(nextQuestion.ID, answer...)
+		if err != nil {
+			return err
+		}
+		if !validResult {
+			return fmt.Errorf("invalid answer %q to question %q: %v", strings.Join(answer, ", "), nextQuestion.ID, message)
+		}
+	}
+
+	unusedAnswers := make([]string, 0)
+	for questionID := range answers {
+		if !asked[questionID] {
+			unusedAnswers = append(unusedAnswers, questionID)
+		}
+	}
+	if len(unusedAnswers) > 0 {
+		sort.Strings(unusedAnswers)
+		return fmt.Errorf("macro %q did not ask the answered questions %q (unknown question ids or skipped due to other answers)", macroID, unusedAnswers)
+	}
+
+	changes, message, validResult, err := macros.GetFinalChangeImpact(modelInput, parsedModel)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	fmt.Println("The following changes will be applied:")
+	for _, change := range changes {
+		fmt.Println(" -", change)
+	}
+	fmt.Println()
+	if !validResult {
+		return fmt.Errorf("macro %q cannot be applied: %v", macroID, message)
+	}
+
+	message, validResult, err = macros.Execute(modelInput, parsedModel)
+	if err != nil {
+		return err
+	}
+	if !validResult {
+		return fmt.Errorf("macro %q failed: %v", macroID, message)
+	}
+	fmt.Println(message)
+
+	return writeModelFile(modelInput, inputFile)
+}
+
+// replayAnswer returns the answer given for the question, with constrained values in their canonical spelling
+func (what MacroQuestion) replayAnswer(answers MacroAnswers) ([]string, error) {
+	answer, ok := answers[what.ID]
+	if !ok {
+		hint := what.possibleAnswersHint()
+		if len(what.DefaultAnswer) > 0 {
+			hint += fmt.Sprintf(" (default: %v)", what.DefaultAnswer)
+		}
+		return nil, fmt.Errorf("no answer given for question %q (%v)%v", what.ID, what.Title, hint)
+	}
+
+	return what.CheckAnswer(answer)
+}
+
+// CheckAnswer validates an answer to the question and returns constrained values in their canonical spelling
+func (what MacroQuestion) CheckAnswer(answer []string) ([]string, error) {
+	if !what.MultiSelect && len(answer) != 1 {
+		return nil, fmt.Errorf("question %q (%v) expects exactly one answer, got %d", what.ID, what.Title, len(answer))
+	}
+
+	result := make([]string, 0, len(answer))
+	for _, value := range answer {
+		if !what.IsMatchingValueConstraint(value) {
+			return nil, fmt.Errorf("answer %q to question %q does not match any allowed value%v", value, what.ID, what.possibleAnswersHint())
+		}
+		for _, possibleAnswer := range what.PossibleAnswers {
+			if strings.EqualFold(possibleAnswer, value) {
+				value = possibleAnswer
+				break
+			}
+		}
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+func (what MacroQuestion) possibleAnswersHint() string {
+	if !what.IsValueConstrained() {
+		return ""
+	}
+	return " (allowed: " + strings.Join(what.PossibleAnswers, ", ") + ")"
+}
@@ -0,0 +1,142 @@
+package macros
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// testAnswersMacro asks for a name (with default), a kind (constrained) and clients (multi-select), then sets the model
+// title from the answers
+type testAnswersMacro struct {
+	answers map[string][]string
+}
+
+var testAnswersQuestions = []MacroQuestion{
+	{ID: "name", Title: "Name", DefaultAnswer: "Vault"},
+	{ID: "kind", Title: "Kind", PossibleAnswers: []string{"Database", "File"}},
+	{ID: "clients", Title: "Clients", PossibleAnswers: []string{"web", "app"}, MultiSelect: true},
+}
+
+func (what *testAnswersMacro) GetMacroDetails() MacroDetails {
+	return MacroDetails{ID: "test-answers", Title: "Test Answers"}
+}
+
+func (what *testAnswersMacro) GetNextQuestion(*types.Model) (MacroQuestion, error) {
+	for _, question := range testAnswersQuestions {
+		if _, answered := what.answers[question.ID]; !answered {
+			return question, nil
+		}
+	}
+	return NoMoreQuestions(), nil
+}
+
+func (what *testAnswersMacro) ApplyAnswer(questionID string, answer ...string) (string, bool, error) {
+	what.answers[questionID] = answer
+	return "Answer processed", true, nil
+}
+
+func (what *testAnswersMacro) GoBack() (string, bool, error) {
+	return "Cannot go back", false, nil
+}
+
+func (what *testAnswersMacro) GetFinalChangeImpact(*input.Model, *types.Model) ([]string, string, bool, error) {
+	return []string{"set the title"}, "Changeset valid", true, nil
+}
+
+func (what *testAnswersMacro) Execute(modelInput *input.Model, _ *types.Model) (string, bool, error) {
+	modelInput.Title = what.answers["name"][0] + " " + what.answers["kind"][0] + " " + strings.Join(what.answers["clients"], "+")
+	return "Model macro executed", true, nil
+}
+
+func executeTestAnswersMacro(t *testing.T, answers MacroAnswers) (string, error) {
+	t.Helper()
+
+	inputFile := filepath.Join(t.TempDir(), "threagile.yaml")
+	require.NoError(t, os.WriteFile(inputFile, []byte("title: Original\n"), 0600))
+
+	modelInput := &input.Model{Title: "Original"}
+	err := ExecuteModelMacroWithAnswers(modelInput, inputFile, &types.Model{}, "test-answers", []Macros{&testAnswersMacro{answers: make(map[string][]string)}}, answers)
+
+	written, readError := os.ReadFile(inputFile)
+	require.NoError(t, readError)
+
+	return string(written), err
+}
+
+func TestLoadMacroAnswers(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "answers.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("name: Corporate Vault\nclients:\n  - web\n  - app\n"), 0600))
+
+	answers, err := LoadMacroAnswers(filename)
+
+	require.NoError(t, err)
+	assert.Equal(t, MacroAnswers{
+		"name":    {"Corporate Vault"},
+		"clients": {"web", "app"},
+	}, answers)
+}
+
+func TestExecuteModelMacroWithAnswers(t *testing.T) {
+	written, err := executeTestAnswersMacro(t, MacroAnswers{
+		"name":    {"Corporate Vault"},
+		"kind":    {"database"},
+		"clients": {"WEB", "app"},
+	})
+
+	require.NoError(t, err)
+	assert.Contains(t, written, "title: Corporate Vault Database web+app", "constrained answers use the canonical spelling")
+}
+
+func TestExecuteModelMacroWithAnswersFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		answers MacroAnswers
+		errors  []string
+	}{
+		{
+			name:    "missing answer with default",
+			answers: MacroAnswers{"kind": {"File"}, "clients": {"web"}},
+			errors:  []string{`no answer given for question "name"`, "default: Vault"},
+		},
+		{
+			name:    "missing answer with allowed values",
+			answers: MacroAnswers{"name": {"Vault"}, "clients": {"web"}},
+			errors:  []string{`no answer given for question "kind"`, "allowed: Database, File"},
+		},
+		{
+			name:    "misspelled question id",
+			answers: MacroAnswers{"name": {"Vault"}, "kind": {"File"}, "clients": {"web"}, "client": {"app"}},
+			errors:  []string{`did not ask the answered questions ["client"]`},
+		},
+		{
+			name:    "value not allowed",
+			answers: MacroAnswers{"name": {"Vault"}, "kind": {"Cloud"}, "clients": {"web"}},
+			errors:  []string{`answer "Cloud" to question "kind" does not match any allowed value`},
+		},
+		{
+			name:    "several answers to single select question",
+			answers: MacroAnswers{"name": {"Vault", "Safe"}, "kind": {"File"}, "clients": {"web"}},
+			errors:  []string{`question "name" (Name) expects exactly one answer, got 2`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			written, err := executeTestAnswersMacro(t, test.answers)
+
+			require.Error(t, err)
+			for _, expected := range test.errors {
+				assert.Contains(t, err.Error(), expected)
+			}
+			assert.Equal(t, "title: Original\n", written, "the model file must not be touched")
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package macros
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// CustomMacrosFolder is the folder inside the plugin folder custom model macros are loaded from
+const CustomMacrosFolder = "macros"
+
+// ListCustomMacros loads the custom model macros of the plugin folder: yaml files are template macros, executable files
+// are macro plugins asked via stdin/stdout. Macros that cannot be loaded are reported and skipped.
+func ListCustomMacros(pluginFolder string, reporter types.ProgressReporter) []Macros {
+	result := make([]Macros, 0)
+	folder := filepath.Join(pluginFolder, CustomMacrosFolder)
+	entries, readError := os.ReadDir(folder)
+	if readError != nil {
+		if !os.IsNotExist(readError) {
+			reporter.Warnf("Unable to read custom model macros from %q: %v", folder, readError)
+		}
+		return result
+	}
+
+	for _, entry := range entries {
+		filename := filepath.Join(folder, entry.Name())
+		info, infoError := entry.Info()
+		if infoError != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		var macro Macros
+		var loadError error
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml":
+			macro, loadError = loadTemplateMacro(filename)
+
+		default:
+			if info.Mode().Perm()&0111 == 0 {
+				continue
+			}
+			macro, loadError = loadPluginMacro(filename)
+		}
+
+		if loadError != nil {
+			reporter.Warnf("Custom model macro %q not loaded: %v", entry.Name(), loadError)
+			continue
+		}
+
+		reporter.Infof("Custom model macro loaded: %v", macro.GetMacroDetails().ID)
+		result = append(result, macro)
+	}
+
+	return result
+}
+
+// customMacro is a loaded custom model macro: it provides fresh instances without any answers, so it is loaded once and
+// used for any number of macro runs
+type customMacro interface {
+	Macros
+	newInstance() Macros
+}
+
+// recordedAnswers keeps the answers given so far in the order the questions were asked
+type recordedAnswers []recordedAnswer
+
+type recordedAnswer struct {
+	QuestionID string   `yaml:"question_id"`
+	Answer     []string `yaml:"answer"`
+}
+
+func (what *recordedAnswers) add(questionID string, answer []string) {
+	*what = append(*what, recordedAnswer{QuestionID: questionID, Answer: answer})
+}
+
+func (what *recordedAnswers) back() (message string, validResult bool, err error) {
+	if len(*what) == 0 {
+		return "Cannot go back further", false, nil
+	}
+	*what = (*what)[:len(*what)-1]
+	return "Undo successful", true, nil
+}
+
+func (what recordedAnswers) get(questionID string) ([]string, bool) {
+	for index := len(what) - 1; index >= 0; index-- {
+		if what[index].QuestionID == questionID {
+			return what[index].Answer, true
+		}
+	}
+	return nil, false
+}
+
+// listModelChanges describes the elements added or changed from one model input to the other
+func listModelChanges(before *input.Model, after *input.Model) []string {
+	changes := make([]string, 0)
+	for _, tag := range after.TagsAvailable {
+		if !slices.Contains(before.TagsAvailable, tag) {
+			changes = append(changes, "adding tag: "+tag)
+		}
+	}
+	changes = append(changes, listMapChanges("data asset", before.DataAssets, after.DataAssets)...)
+	changes = append(changes, listMapChanges("technical asset", before.TechnicalAssets, after.TechnicalAssets)...)
+	changes = append(changes, listMapChanges("trust boundary", before.TrustBoundaries, after.TrustBoundaries)...)
+	changes = append(changes, listMapChanges("shared runtime", before.SharedRuntimes, after.SharedRuntimes)...)
+	return changes
+}
+
+func listMapChanges[T any](kind string, before map[string]T, after map[string]T) []string {
+	changes := make([]string, 0)
+	for _, key := range mapKeys(after) {
+		previous, exists := before[key]
+		if !exists {
+			changes = append(changes, fmt.Sprintf("adding %v: %v", kind, key))
+			continue
+		}
+
+		// compare the yaml, nil and empty lists are the same in the model file
+		previousYaml, _ := yaml.Marshal(previous)
+		currentYaml, _ := yaml.Marshal(after[key])
+		if !bytes.Equal(previousYaml, currentYaml) {
+			changes = append(changes, fmt.Sprintf("updating %v: %v", kind, key))
+		}
+	}
+	return changes
+}
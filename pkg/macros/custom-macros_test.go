@@ -0,0 +1,261 @@
+package macros
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// testMacroPluginEnv makes the test binary act as macro plugin
+const testMacroPluginEnv = "THREAGILE_TEST_MACRO_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if len(os.Getenv(testMacroPluginEnv)) > 0 {
+		serveTestMacroPlugin(os.Args[1])
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// serveTestMacroPlugin handles a step of the add-tag macro, which asks for a tag and adds it to the available tags
+func serveTestMacroPlugin(step string) {
+	var request struct {
+		Answers    recordedAnswers `yaml:"answers"`
+		Answer     []string        `yaml:"answer"`
+		ModelInput *input.Model    `yaml:"model_input"`
+	}
+	data, _ := io.ReadAll(os.Stdin)
+	_ = yaml.Unmarshal(data, &request)
+
+	var response any
+	switch step {
+	case "-get-info":
+		response = MacroDetails{ID: "add-tag", Title: "Add Tag"}
+
+	case "-get-next-question":
+		response = NoMoreQuestions()
+		if _, answered := request.Answers.get("tag"); !answered {
+			response = MacroQuestion{ID: "tag", Title: "Which tag should be added?"}
+		}
+
+	case "-apply-answer":
+		response = pluginMacroResponse{Message: "Answer processed", Valid: len(request.Answer) == 1 && len(request.Answer[0]) > 0}
+
+	case "-get-final-change-impact":
+		tag, _ := request.Answers.get("tag")
+		response = pluginMacroResponse{Changes: []string{"adding tag: " + tag[0]}, Message: "Changeset valid", Valid: true}
+
+	case "-execute":
+		tag, _ := request.Answers.get("tag")
+		request.ModelInput.TagsAvailable = append(request.ModelInput.TagsAvailable, tag[0])
+		response = pluginMacroResponse{Message: "Tag added", Valid: true, ModelInput: request.ModelInput}
+	}
+
+	output, _ := yaml.Marshal(response)
+	_, _ = os.Stdout.Write(output)
+}
+
+type testProgressReporter struct {
+	warnings []string
+}
+
+func (what *testProgressReporter) Info(...any)               {}
+func (what *testProgressReporter) Infof(string, ...any)      {}
+func (what *testProgressReporter) Error(a ...any)            { what.Warn(a...) }
+func (what *testProgressReporter) Errorf(f string, a ...any) { what.Warnf(f, a...) }
+func (what *testProgressReporter) Warn(a ...any) {
+	what.warnings = append(what.warnings, fmt.Sprint(a...))
+}
+func (what *testProgressReporter) Warnf(format string, a ...any) {
+	what.warnings = append(what.warnings, fmt.Sprintf(format, a...))
+}
+
+const testTemplateMacro = `id: add-waf
+title: Add Web Application Firewall
+questions:
+  - id: target
+    title: Which technical asset should be protected by the WAF?
+    possible_answers_from: technical_assets
+  - id: boundary
+    title: Should the WAF be placed into a trust boundary?
+    possible_answers: ["Yes", "No"]
+    default_answer: "No"
+  - id: boundary-id
+    title: Which trust boundary?
+    possible_answers_from: trust_boundaries
+    ask_if:
+      boundary: "Yes"
+model: |
+  technical_assets:
+    Web Application Firewall:
+      id: waf
+      communication_links:
+        Forward:
+          target: {{ answer "target" }}
+  {{- if eq (answer "boundary") "Yes" }}
+  trust_boundaries:
+    {{ boundaryTitle (answer "boundary-id") }}:
+      id: {{ answer "boundary-id" }}
+      technical_assets_inside:
+        - waf
+  {{- end }}
+`
+
+// writeTestCustomMacros writes a template macro, the test binary as macro plugin and some files which are no macros
+// into the macros folder of a new plugin folder
+func writeTestCustomMacros(t *testing.T) string {
+	t.Helper()
+
+	pluginFolder := t.TempDir()
+	folder := filepath.Join(pluginFolder, CustomMacrosFolder)
+	require.NoError(t, os.Mkdir(folder, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "add-waf.yaml"), []byte(testTemplateMacro), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "broken.yml"), []byte("title: Without ID\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "README.md"), []byte("not executable\n"), 0600))
+
+	plugin, err := os.ReadFile(os.Args[0])
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "add-tag"), plugin, 0700)) // #nosec G306
+	t.Setenv(testMacroPluginEnv, "serve")
+
+	return pluginFolder
+}
+
+func newCustomMacrosTestModel(t *testing.T) (modelInput *input.Model, inputFile string, parsedModel *types.Model) {
+	t.Helper()
+
+	modelInput = &input.Model{
+		Title:           "Custom Macros",
+		TechnicalAssets: map[string]input.TechnicalAsset{"Web Server": {ID: "web"}},
+		TrustBoundaries: map[string]input.TrustBoundary{"DMZ": {ID: "dmz", TechnicalAssetsInside: []string{"web"}}},
+	}
+	inputFile = filepath.Join(t.TempDir(), "threagile.yaml")
+	data, err := yaml.Marshal(modelInput)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(inputFile, data, 0600))
+
+	parsedModel = &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{"web": {Id: "web", Title: "Web Server"}},
+		TrustBoundaries: map[string]*types.TrustBoundary{"dmz": {Id: "dmz", Title: "DMZ", TechnicalAssetsInside: []string{"web"}}},
+	}
+
+	return modelInput, inputFile, parsedModel
+}
+
+func TestListCustomMacros(t *testing.T) {
+	reporter := new(testProgressReporter)
+
+	customMacros := ListCustomMacros(writeTestCustomMacros(t), reporter)
+
+	ids := make([]string, 0)
+	for _, macro := range customMacros {
+		ids = append(ids, macro.GetMacroDetails().ID)
+	}
+	assert.Equal(t, []string{"add-tag", "add-waf"}, ids)
+	require.Len(t, reporter.warnings, 1)
+	assert.Contains(t, reporter.warnings[0], `"broken.yml" not loaded: macro needs an id and a title`)
+
+	assert.Empty(t, ListCustomMacros(filepath.Join(t.TempDir(), "missing"), reporter))
+	assert.Len(t, reporter.warnings, 1, "a missing macros folder is fine")
+}
+
+func TestExecuteTemplateMacro(t *testing.T) {
+	customMacros := ListCustomMacros(writeTestCustomMacros(t), new(testProgressReporter))
+	modelInput, inputFile, parsedModel := newCustomMacrosTestModel(t)
+
+	macro, err := GetMacroByID("add-waf", customMacros)
+	require.NoError(t, err)
+	question, err := macro.GetNextQuestion(parsedModel)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, question.PossibleAnswers, "the answers are taken from the model")
+
+	err = ExecuteModelMacroWithAnswers(modelInput, inputFile, parsedModel, "add-waf", customMacros, MacroAnswers{
+		"target":      {"web"},
+		"boundary":    {"yes"},
+		"boundary-id": {"dmz"},
+	})
+	require.NoError(t, err)
+
+	written := new(input.Model)
+	require.NoError(t, written.Load(inputFile))
+	require.Contains(t, written.TechnicalAssets, "Web Application Firewall")
+	assert.Equal(t, "web", written.TechnicalAssets["Web Application Firewall"].CommunicationLinks["Forward"].Target)
+	assert.Equal(t, []string{"web", "waf"}, written.TrustBoundaries["DMZ"].TechnicalAssetsInside, "the snippet is merged like an include")
+}
+
+func TestExecuteTemplateMacroSkippedQuestion(t *testing.T) {
+	customMacros := ListCustomMacros(writeTestCustomMacros(t), new(testProgressReporter))
+	modelInput, inputFile, parsedModel := newCustomMacrosTestModel(t)
+
+	err := ExecuteModelMacroWithAnswers(modelInput, inputFile, parsedModel, "add-waf", customMacros, MacroAnswers{
+		"target":   {"web"},
+		"boundary": {"No"},
+	})
+	require.NoError(t, err)
+
+	written := new(input.Model)
+	require.NoError(t, written.Load(inputFile))
+	assert.Contains(t, written.TechnicalAssets, "Web Application Firewall")
+	assert.Equal(t, []string{"web"}, written.TrustBoundaries["DMZ"].TechnicalAssetsInside)
+}
+
+func TestExecutePluginMacro(t *testing.T) {
+	customMacros := ListCustomMacros(writeTestCustomMacros(t), new(testProgressReporter))
+	modelInput, inputFile, parsedModel := newCustomMacrosTestModel(t)
+
+	macro, err := GetMacroByID("add-tag", customMacros)
+	require.NoError(t, err)
+	assert.Equal(t, "Add Tag", macro.GetMacroDetails().Title)
+
+	err = ExecuteModelMacroWithAnswers(modelInput, inputFile, parsedModel, "add-tag", customMacros, MacroAnswers{"tag": {"pci"}})
+	require.NoError(t, err)
+
+	written := new(input.Model)
+	require.NoError(t, written.Load(inputFile))
+	assert.Equal(t, []string{"pci"}, written.TagsAvailable)
+	assert.Contains(t, written.TechnicalAssets, "Web Server", "the plugin returns the whole model")
+}
+
+func TestPluginMacroRejectedAnswer(t *testing.T) {
+	customMacros := ListCustomMacros(writeTestCustomMacros(t), new(testProgressReporter))
+	modelInput, inputFile, parsedModel := newCustomMacrosTestModel(t)
+
+	err := ExecuteModelMacroWithAnswers(modelInput, inputFile, parsedModel, "add-tag", customMacros, MacroAnswers{"tag": {""}})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid answer "" to question "tag"`)
+}
+
+func TestGetMacroByIDFreshInstance(t *testing.T) {
+	customMacros := ListCustomMacros(writeTestCustomMacros(t), new(testProgressReporter))
+	_, _, parsedModel := newCustomMacrosTestModel(t)
+
+	for _, test := range []struct{ id, questionID, answer string }{{"add-waf", "target", "web"}, {"add-tag", "tag", "pci"}} {
+		t.Run(test.id, func(t *testing.T) {
+			used, err := GetMacroByID(test.id, customMacros)
+			require.NoError(t, err)
+			_, valid, err := used.ApplyAnswer(test.questionID, test.answer)
+			require.NoError(t, err)
+			require.True(t, valid)
+			next, err := used.GetNextQuestion(parsedModel)
+			require.NoError(t, err)
+			require.NotEqual(t, test.questionID, next.ID)
+
+			fresh, err := GetMacroByID(test.id, customMacros)
+			require.NoError(t, err)
+			question, err := fresh.GetNextQuestion(parsedModel)
+			require.NoError(t, err)
+			assert.Equal(t, test.questionID, question.ID, "the answers of another use are not shared")
+		})
+	}
+}
@@ -31,6 +31,7 @@ func ListBuiltInMacros() []Macros {
 	return []Macros{
 		NewBuildPipeline(),
 		NewAddVault(),
+		NewAddIdentityProvider(),
 		NewPrettyPrint(),
 		newRemoveUnusedTags(),
 		NewSeedRiskTracking(),
@@ -38,25 +39,23 @@ func ListBuiltInMacros() []Macros {
 	}
 }
 
-func ListCustomMacros() []Macros {
-	// TODO: implement
-	return []Macros{}
-}
-
-func GetMacroByID(id string) (Macros, error) {
+// GetMacroByID looks up a built-in or custom model macro; custom macros are provided as fresh instance without answers
+func GetMacroByID(id string, customMacros []Macros) (Macros, error) {
 	builtinMacros := ListBuiltInMacros()
-	customMacros := ListCustomMacros()
 	allMacros := append(builtinMacros, customMacros...)
 	for _, macro := range allMacros {
 		if macro.GetMacroDetails().ID == id {
+			if custom, ok := macro.(customMacro); ok {
+				return custom.newInstance(), nil
+			}
 			return macro, nil
 		}
 	}
 	return nil, fmt.Errorf("unknown macro id: %v", id)
 }
 
-func ExecuteModelMacro(modelInput *input.Model, inputFile string, parsedModel *types.Model, macroID string) error {
-	macros, err := GetMacroByID(macroID)
+func ExecuteModelMacro(modelInput *input.Model, inputFile string, parsedModel *types.Model, macroID string, customMacros []Macros) error {
+	macros, err := GetMacroByID(macroID, customMacros)
 	if err != nil {
 		return err
 	}
@@ -241,27 +240,7 @@ func ExecuteModelMacro(modelInput *input.Model, inputFile string, parsedModel *t
 			}
 			fmt.Println(message)
 			fmt.Println()
-			backupFilename := inputFile + ".backup"
-			fmt.Println("Creating backup model file:", backupFilename) // TODO add random files in /dev/shm space?
-			_, err = copyFile(inputFile, backupFilename)
-			if err != nil {
-				return err
-			}
-			fmt.Println("Updating model")
-			yamlBytes, err := yaml.Marshal(modelInput)
-			if err != nil {
-				return err
-			}
-			/*
-				yamlBytes = model.ReformatYAML(yamlBytes)
-			*/
-			fmt.Println("Writing model file:", inputFile)
-			err = os.WriteFile(inputFile, yamlBytes, 0400)
-			if err != nil {
-				return err
-			}
-			fmt.Println("Model file successfully updated")
-			return nil
+			return writeModelFile(modelInput, inputFile)
 
 		case "no", "n":
 			fmt.Println("Quitting without executing the model macro")
@@ -270,6 +249,30 @@ func ExecuteModelMacro(modelInput *input.Model, inputFile string, parsedModel *t
 	}
 }
 
+func writeModelFile(modelInput *input.Model, inputFile string) error {
+	backupFilename := inputFile + ".backup"
+	fmt.Println("Creating backup model file:", backupFilename) // TODO add random files in /dev/shm space?
+	_, err := copyFile(inputFile, backupFilename)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Updating model")
+	yamlBytes, err := yaml.Marshal(modelInput)
+	if err != nil {
+		return err
+	}
+	/*
+		yamlBytes = model.ReformatYAML(yamlBytes)
+	*/
+	fmt.Println("Writing model file:", inputFile)
+	err = os.WriteFile(inputFile, yamlBytes, 0400)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Model file successfully updated")
+	return nil
+}
+
 func printBorder(length int, bold bool) {
 	char := "-"
 	if bold {
@@ -307,14 +310,18 @@ func copyFile(src, dst string) (int64, error) {
 }
 
 type MacroDetails struct {
-	ID, Title, Description string
+	ID          string `yaml:"id" json:"id"`
+	Title       string `yaml:"title" json:"title"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
 }
 
 type MacroQuestion struct {
-	ID, Title, Description string
-	PossibleAnswers        []string
-	MultiSelect            bool
-	DefaultAnswer          string
+	ID              string   `yaml:"id" json:"id"`
+	Title           string   `yaml:"title,omitempty" json:"title,omitempty"`
+	Description     string   `yaml:"description,omitempty" json:"description,omitempty"`
+	PossibleAnswers []string `yaml:"possible_answers,omitempty" json:"possible_answers,omitempty"`
+	MultiSelect     bool     `yaml:"multi_select,omitempty" json:"multi_select,omitempty"`
+	DefaultAnswer   string   `yaml:"default_answer,omitempty" json:"default_answer,omitempty"`
 }
 
 const NoMoreQuestionsID = ""
@@ -0,0 +1,122 @@
+package macros
+
+import (
+	"fmt"
+
+	"github.com/threagile/threagile/internal/runner"
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// pluginMacro is a custom model macro implemented by an external program. Each step runs the program once with the step
+// as parameter, the request as yaml on stdin and the response as yaml on stdout. The program keeps no state: the answers
+// given so far are part of every request.
+type pluginMacro struct {
+	runner  *runner.Runner
+	details MacroDetails
+	answers recordedAnswers
+}
+
+type pluginMacroRequest struct {
+	Answers    recordedAnswers `yaml:"answers"`
+	QuestionID string          `yaml:"question_id,omitempty"`
+	Answer     []string        `yaml:"answer,omitempty"`
+	ModelInput *input.Model    `yaml:"model_input,omitempty"`
+	Model      *types.Model    `yaml:"model,omitempty"`
+}
+
+type pluginMacroResponse struct {
+	Changes    []string     `yaml:"changes,omitempty"`
+	Message    string       `yaml:"message,omitempty"`
+	Valid      bool         `yaml:"valid"`
+	ModelInput *input.Model `yaml:"model_input,omitempty"`
+}
+
+func loadPluginMacro(filename string) (*pluginMacro, error) {
+	pluginRunner, loadError := new(runner.Runner).Load(filename)
+	if loadError != nil {
+		return nil, loadError
+	}
+
+	macro := &pluginMacro{
+		runner:  pluginRunner,
+		answers: make(recordedAnswers, 0),
+	}
+
+	runError := macro.runner.Run(nil, &macro.details, "-get-info")
+	if runError != nil {
+		return nil, fmt.Errorf("failed to get info: %w", runError)
+	}
+
+	if len(macro.details.ID) == 0 || len(macro.details.Title) == 0 {
+		return nil, fmt.Errorf("macro needs an id and a title")
+	}
+
+	return macro, nil
+}
+
+func (m *pluginMacro) newInstance() Macros {
+	return &pluginMacro{
+		runner:  &runner.Runner{Filename: m.runner.Filename},
+		details: m.details,
+		answers: make(recordedAnswers, 0),
+	}
+}
+
+func (m *pluginMacro) GetMacroDetails() MacroDetails {
+	return m.details
+}
+
+func (m *pluginMacro) GetNextQuestion(model *types.Model) (nextQuestion MacroQuestion, err error) {
+	runError := m.runner.Run(&pluginMacroRequest{Answers: m.answers, Model: model}, &nextQuestion, "-get-next-question")
+	if runError != nil {
+		return NoMoreQuestions(), fmt.Errorf("failed to get next question of custom model macro %q: %w", m.details.ID, runError)
+	}
+
+	return nextQuestion, nil
+}
+
+func (m *pluginMacro) ApplyAnswer(questionID string, answer ...string) (message string, validResult bool, err error) {
+	var response pluginMacroResponse
+	runError := m.runner.Run(&pluginMacroRequest{Answers: m.answers, QuestionID: questionID, Answer: answer}, &response, "-apply-answer")
+	if runError != nil {
+		return "", false, fmt.Errorf("failed to apply answer to custom model macro %q: %w", m.details.ID, runError)
+	}
+
+	if response.Valid {
+		m.answers.add(questionID, answer)
+	}
+
+	return response.Message, response.Valid, nil
+}
+
+func (m *pluginMacro) GoBack() (message string, validResult bool, err error) {
+	return m.answers.back()
+}
+
+func (m *pluginMacro) GetFinalChangeImpact(modelInput *input.Model, model *types.Model) (changes []string, message string, validResult bool, err error) {
+	var response pluginMacroResponse
+	runError := m.runner.Run(&pluginMacroRequest{Answers: m.answers, ModelInput: modelInput, Model: model}, &response, "-get-final-change-impact")
+	if runError != nil {
+		return nil, "", false, fmt.Errorf("failed to get change impact of custom model macro %q: %w", m.details.ID, runError)
+	}
+
+	return response.Changes, response.Message, response.Valid, nil
+}
+
+func (m *pluginMacro) Execute(modelInput *input.Model, model *types.Model) (message string, validResult bool, err error) {
+	var response pluginMacroResponse
+	runError := m.runner.Run(&pluginMacroRequest{Answers: m.answers, ModelInput: modelInput, Model: model}, &response, "-execute")
+	if runError != nil {
+		return "", false, fmt.Errorf("failed to execute custom model macro %q: %w", m.details.ID, runError)
+	}
+
+	if response.Valid {
+		if response.ModelInput == nil {
+			return "custom model macro returned no model", false, nil
+		}
+		*modelInput = *response.ModelInput
+	}
+
+	return response.Message, response.Valid, nil
+}
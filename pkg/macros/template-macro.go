@@ -0,0 +1,257 @@
+package macros
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// templateMacro is a custom model macro declared in yaml: it asks its questions in order and merges the model snippet,
+// rendered as Go template with the answers, into the model like an include file
+type templateMacro struct {
+	filename string
+	details  MacroDetails
+	spec     templateMacroSpec
+	model    *template.Template
+	answers  recordedAnswers
+}
+
+type templateMacroSpec struct {
+	ID          string                  `yaml:"id"`
+	Title       string                  `yaml:"title"`
+	Description string                  `yaml:"description"`
+	Questions   []templateMacroQuestion `yaml:"questions"`
+	Model       string                  `yaml:"model"`
+}
+
+type templateMacroQuestion struct {
+	MacroQuestion `yaml:",inline"`
+
+	// PossibleAnswersFrom offers the IDs of the model elements of that kind as possible answers
+	PossibleAnswersFrom string `yaml:"possible_answers_from,omitempty"`
+	// AskIf limits the question to the case that all given questions were answered with the given values
+	AskIf map[string]string `yaml:"ask_if,omitempty"`
+}
+
+var templateMacroElementIDs = map[string]func(model *types.Model) []string{
+	"technical_assets": func(model *types.Model) []string { return mapKeys(model.TechnicalAssets) },
+	"data_assets":      func(model *types.Model) []string { return mapKeys(model.DataAssets) },
+	"trust_boundaries": func(model *types.Model) []string { return mapKeys(model.TrustBoundaries) },
+	"shared_runtimes":  func(model *types.Model) []string { return mapKeys(model.SharedRuntimes) },
+}
+
+func loadTemplateMacro(filename string) (*templateMacro, error) {
+	data, readError := os.ReadFile(filepath.Clean(filename))
+	if readError != nil {
+		return nil, readError
+	}
+
+	var spec templateMacroSpec
+	unmarshalError := yaml.Unmarshal(data, &spec)
+	if unmarshalError != nil {
+		return nil, fmt.Errorf("unable to parse macro yaml: %w", unmarshalError)
+	}
+
+	if len(spec.ID) == 0 || len(spec.Title) == 0 {
+		return nil, fmt.Errorf("macro needs an id and a title")
+	}
+
+	questionIDs := make(map[string]bool)
+	for _, question := range spec.Questions {
+		if len(question.ID) == 0 || questionIDs[question.ID] {
+			return nil, fmt.Errorf("missing or duplicate question id %q", question.ID)
+		}
+		questionIDs[question.ID] = true
+
+		if _, ok := templateMacroElementIDs[question.PossibleAnswersFrom]; len(question.PossibleAnswersFrom) > 0 && !ok {
+			return nil, fmt.Errorf("unknown possible_answers_from %q of question %q (expected one of technical_assets, data_assets, trust_boundaries, shared_runtimes)", question.PossibleAnswersFrom, question.ID)
+		}
+	}
+
+	macro := &templateMacro{
+		filename: filename,
+		details:  MacroDetails{ID: spec.ID, Title: spec.Title, Description: spec.Description},
+		spec:     spec,
+		answers:  make(recordedAnswers, 0),
+	}
+
+	var parseError error
+	macro.model, parseError = template.New(filepath.Base(filename)).Funcs(macro.templateFunctions(nil)).Parse(spec.Model)
+	if parseError != nil {
+		return nil, fmt.Errorf("unable to parse model template: %w", parseError)
+	}
+
+	return macro, nil
+}
+
+func (m *templateMacro) newInstance() Macros {
+	return &templateMacro{
+		filename: m.filename,
+		details:  m.details,
+		spec:     m.spec,
+		model:    m.model,
+		answers:  make(recordedAnswers, 0),
+	}
+}
+
+func (m *templateMacro) GetMacroDetails() MacroDetails {
+	return m.details
+}
+
+func (m *templateMacro) GetNextQuestion(model *types.Model) (nextQuestion MacroQuestion, err error) {
+	for _, question := range m.spec.Questions {
+		if _, answered := m.answers.get(question.ID); answered || !m.isAsked(question) {
+			continue
+		}
+
+		nextQuestion = question.MacroQuestion
+		if elementIDs, ok := templateMacroElementIDs[question.PossibleAnswersFrom]; ok {
+			nextQuestion.PossibleAnswers = elementIDs(model)
+		}
+		return nextQuestion, nil
+	}
+
+	return NoMoreQuestions(), nil
+}
+
+func (m *templateMacro) isAsked(question templateMacroQuestion) bool {
+	for questionID, expected := range question.AskIf {
+		answer, answered := m.answers.get(questionID)
+		if !answered || !containsFold(answer, expected) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *templateMacro) ApplyAnswer(questionID string, answer ...string) (message string, validResult bool, err error) {
+	m.answers.add(questionID, answer)
+	return "Answer processed", true, nil
+}
+
+func (m *templateMacro) GoBack() (message string, validResult bool, err error) {
+	return m.answers.back()
+}
+
+func (m *templateMacro) GetFinalChangeImpact(modelInput *input.Model, model *types.Model) (changes []string, message string, validResult bool, err error) {
+	modelYaml, renderError := m.render(model)
+	if renderError != nil {
+		return nil, renderError.Error(), false, nil
+	}
+
+	changed, cloneError := cloneModelInput(modelInput)
+	if cloneError != nil {
+		return nil, "", false, cloneError
+	}
+
+	mergeError := changed.MergeYaml(filepath.Dir(m.filename), filepath.Base(m.filename), modelYaml)
+	if mergeError != nil {
+		return nil, fmt.Sprintf("unable to apply the changes: %v", mergeError), false, nil
+	}
+
+	return listModelChanges(modelInput, changed), "Changeset valid", true, nil
+}
+
+func (m *templateMacro) Execute(modelInput *input.Model, model *types.Model) (message string, validResult bool, err error) {
+	modelYaml, renderError := m.render(model)
+	if renderError != nil {
+		return renderError.Error(), false, nil
+	}
+
+	mergeError := modelInput.MergeYaml(filepath.Dir(m.filename), filepath.Base(m.filename), modelYaml)
+	if mergeError != nil {
+		return fmt.Sprintf("unable to apply the changes: %v", mergeError), false, nil
+	}
+
+	return "Model macro " + m.details.ID + " applied", true, nil
+}
+
+func (m *templateMacro) render(model *types.Model) ([]byte, error) {
+	modelTemplate, cloneError := m.model.Clone()
+	if cloneError != nil {
+		return nil, cloneError
+	}
+
+	var buffer bytes.Buffer
+	executeError := modelTemplate.Funcs(m.templateFunctions(model)).Execute(&buffer, model)
+	if executeError != nil {
+		return nil, fmt.Errorf("unable to render model template: %w", executeError)
+	}
+	return buffer.Bytes(), nil
+}
+
+// templateFunctions are available in the model template in addition to the parsed model passed as dot
+func (m *templateMacro) templateFunctions(model *types.Model) template.FuncMap {
+	return template.FuncMap{
+		"answer": func(questionID string) string {
+			answer, _ := m.answers.get(questionID)
+			return strings.Join(answer, ", ")
+		},
+		"answers": func(questionID string) []string {
+			answer, _ := m.answers.get(questionID)
+			return answer
+		},
+		"id": func(text string) string {
+			return strings.Trim(strings.Map(func(r rune) rune {
+				if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+					return r
+				}
+				return '-'
+			}, strings.ToLower(text)), "-")
+		},
+		"quote": strconv.Quote,
+		"assetTitle": func(id string) (string, error) {
+			if asset, ok := model.TechnicalAssets[id]; ok {
+				return asset.Title, nil
+			}
+			return "", fmt.Errorf("unknown technical asset %q", id)
+		},
+		"boundaryTitle": func(id string) (string, error) {
+			if boundary, ok := model.TrustBoundaries[id]; ok {
+				return boundary.Title, nil
+			}
+			return "", fmt.Errorf("unknown trust boundary %q", id)
+		},
+	}
+}
+
+func cloneModelInput(modelInput *input.Model) (*input.Model, error) {
+	data, marshalError := yaml.Marshal(modelInput)
+	if marshalError != nil {
+		return nil, marshalError
+	}
+
+	clone := new(input.Model).Defaults()
+	unmarshalError := yaml.Unmarshal(data, clone)
+	if unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	return clone, nil
+}
+
+func containsFold(values []string, expected string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func mapKeys[T any](items map[string]T) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
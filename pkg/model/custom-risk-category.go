@@ -2,9 +2,16 @@ package model
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"gopkg.in/yaml.v3"
+
+	"github.com/threagile/threagile/internal/runner"
+	"github.com/threagile/threagile/pkg/input"
 	"github.com/threagile/threagile/pkg/types"
 )
 
@@ -12,7 +19,8 @@ type CustomRiskCategory struct {
 	types.RiskCategory `json:"risk_category" yaml:"risk_category,omitempty"`
 
 	Tags   []string `json:"tags,omitempty" yaml:"tags,omitempty"`
-	runner *runner
+	runner *runner.Runner
+	plugin *persistentPlugin
 }
 
 func (what *CustomRiskCategory) Init(category *types.RiskCategory, tags []string) *CustomRiskCategory {
@@ -33,6 +41,10 @@ func (what *CustomRiskCategory) SupportedTags() []string {
 }
 
 func (what *CustomRiskCategory) GenerateRisks(parsedModel *types.Model) ([]*types.Risk, error) {
+	if what.plugin != nil {
+		return what.plugin.generateRisks(parsedModel, what.ID)
+	}
+
 	if what.runner == nil {
 		return nil, nil
 	}
@@ -46,7 +58,32 @@ func (what *CustomRiskCategory) GenerateRisks(parsedModel *types.Model) ([]*type
 	return generatedRisks, nil
 }
 
-func LoadCustomRiskRules(pluginDir string, pluginFiles []string, reporter types.ProgressReporter) types.RiskRules {
+// precomputedRiskCategory is a custom risk rule of another process, which already generated its risks for the model
+// (see GenerateCustomRisks), so they are just handed out here instead of running the plugin again
+type precomputedRiskCategory struct {
+	CustomRiskCategory `yaml:",inline"`
+
+	Risks []*types.Risk `json:"risks,omitempty" yaml:"risks,omitempty"`
+}
+
+func (what *precomputedRiskCategory) GenerateRisks(*types.Model) ([]*types.Risk, error) {
+	return what.Risks, nil
+}
+
+type customRiskRulesConfigReader interface {
+	GetPluginFolder() string
+	GetRiskRulePlugins() []string
+	GetPersistentRiskRulePlugins() []string
+	GetPluginTimeout() int
+	GetCustomRisksFile() string
+}
+
+// LoadCustomRiskRules loads the risk rule plugins of the config: plugins run once per request as well as persistent
+// plugins, which are started here and kept running, and the risks some other process generated with its custom risk rules
+func LoadCustomRiskRules(config customRiskRulesConfigReader, reporter types.ProgressReporter) types.RiskRules {
+	pluginDir := config.GetPluginFolder()
+	pluginFiles := config.GetRiskRulePlugins()
+
 	customRiskRuleList := make([]string, 0)
 	customRiskRules := make(types.RiskRules)
 	if len(pluginFiles) > 0 {
@@ -54,7 +91,7 @@ func LoadCustomRiskRules(pluginDir string, pluginFiles []string, reporter types.
 
 		for _, pluginFile := range pluginFiles {
 			if len(pluginFile) > 0 {
-				newRunner, loadError := new(runner).Load(filepath.Join(pluginDir, pluginFile))
+				newRunner, loadError := new(runner.Runner).Load(filepath.Join(pluginDir, pluginFile))
 				if loadError != nil {
 					reporter.Error(fmt.Sprintf("WARNING: Custom risk rule %q not loaded: %v\n", pluginFile, loadError))
 				}
@@ -75,5 +112,118 @@ func LoadCustomRiskRules(pluginDir string, pluginFiles []string, reporter types.
 		reporter.Info("Loaded custom risk rules:", strings.Join(customRiskRuleList, ", "))
 	}
 
+	for _, pluginFile := range config.GetPersistentRiskRulePlugins() {
+		if len(pluginFile) == 0 {
+			continue
+		}
+
+		categories, loadError := loadPersistentPlugin(filepath.Join(pluginDir, pluginFile), time.Duration(config.GetPluginTimeout())*time.Second)
+		if loadError != nil {
+			reporter.Error(fmt.Sprintf("WARNING: Persistent custom risk rule plugin %q not loaded: %v\n", pluginFile, loadError))
+			continue
+		}
+
+		for _, risk := range categories {
+			customRiskRules[risk.ID] = risk
+			reporter.Info("Custom risk rule loaded:", risk.ID)
+		}
+	}
+
+	if len(config.GetCustomRisksFile()) > 0 {
+		categories, readError := readCustomRisks(config.GetCustomRisksFile())
+		if readError != nil {
+			reporter.Error(fmt.Sprintf("WARNING: Custom risks %q not loaded: %v\n", config.GetCustomRisksFile(), readError))
+		}
+
+		for _, risk := range categories {
+			customRiskRules[risk.ID] = risk
+			reporter.Info("Custom risk rule loaded:", risk.ID)
+		}
+	}
+
 	return customRiskRules
 }
+
+type customRisksConfigReader interface {
+	technologyMapConfigReader
+
+	GetSkipRiskRules() []string
+}
+
+// GenerateCustomRisks reads the given model file, generates the risks of the custom risk rules only and writes them as
+// yaml to the writer. Written to a file, another process can load them as custom risk rules (see LoadCustomRiskRules)
+// without running the plugins again.
+func GenerateCustomRisks(filename string, writer io.Writer, config customRisksConfigReader, builtinRiskRules types.RiskRules, customRiskRules types.RiskRules, progressReporter types.ProgressReporter) error {
+	modelInput := new(input.Model).Defaults()
+	loadError := modelInput.Load(filename)
+	if loadError != nil {
+		return fmt.Errorf("unable to load model yaml: %w", loadError)
+	}
+
+	parsedModel, parseError := ParseModel(config, modelInput, builtinRiskRules, customRiskRules)
+	if parseError != nil {
+		return fmt.Errorf("unable to parse model yaml: %w", parseError)
+	}
+
+	_ = applyRAA(parsedModel, progressReporter)
+
+	skippedRules := make(map[string]bool)
+	for _, id := range config.GetSkipRiskRules() {
+		skippedRules[id] = true
+	}
+
+	categories := make([]*precomputedRiskCategory, 0)
+	for _, id := range sortedKeys(customRiskRules) {
+		if skippedRules[id] {
+			continue
+		}
+
+		rule := customRiskRules[id]
+		generatedRisks, riskError := rule.GenerateRisks(parsedModel)
+		if riskError != nil {
+			progressReporter.Warnf("Error generating risks for %q: %v", id, riskError)
+		}
+
+		categories = append(categories, &precomputedRiskCategory{
+			CustomRiskCategory: CustomRiskCategory{RiskCategory: *rule.Category(), Tags: rule.SupportedTags()},
+			Risks:              generatedRisks,
+		})
+	}
+
+	encoder := yaml.NewEncoder(writer)
+	encodeError := encoder.Encode(categories)
+	if encodeError != nil {
+		return fmt.Errorf("unable to write custom risks: %w", encodeError)
+	}
+
+	return encoder.Close()
+}
+
+func readCustomRisks(filename string) ([]*precomputedRiskCategory, error) {
+	data, readError := os.ReadFile(filepath.Clean(filename))
+	if readError != nil {
+		return nil, readError
+	}
+
+	categories := make([]*precomputedRiskCategory, 0)
+	unmarshalError := yaml.Unmarshal(data, &categories)
+	if unmarshalError != nil {
+		return nil, unmarshalError
+	}
+
+	return categories, nil
+}
+
+// CloseCustomRiskRules stops the persistent plugins among the given rules
+func CloseCustomRiskRules(rules types.RiskRules) {
+	closed := make(map[*persistentPlugin]bool)
+	for _, rule := range rules {
+		customRule, ok := rule.(*CustomRiskCategory)
+		if !ok || customRule.plugin == nil || closed[customRule.plugin] {
+			continue
+		}
+
+		closed[customRule.plugin] = true
+		_ = customRule.plugin.process.Close()
+	}
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+type ModelDiff struct {
+	NewRisks      []*types.Risk `json:"new_risks,omitempty" yaml:"new_risks,omitempty"`
+	ResolvedRisks []*types.Risk `json:"resolved_risks,omitempty" yaml:"resolved_risks,omitempty"`
+	ChangedRisks  []*RiskChange `json:"changed_risks,omitempty" yaml:"changed_risks,omitempty"`
+
+	AddedTechnicalAssets      []*ElementChange `json:"added_technical_assets,omitempty" yaml:"added_technical_assets,omitempty"`
+	RemovedTechnicalAssets    []*ElementChange `json:"removed_technical_assets,omitempty" yaml:"removed_technical_assets,omitempty"`
+	AddedCommunicationLinks   []*ElementChange `json:"added_communication_links,omitempty" yaml:"added_communication_links,omitempty"`
+	RemovedCommunicationLinks []*ElementChange `json:"removed_communication_links,omitempty" yaml:"removed_communication_links,omitempty"`
+	AddedDataAssets           []*ElementChange `json:"added_data_assets,omitempty" yaml:"added_data_assets,omitempty"`
+	RemovedDataAssets         []*ElementChange `json:"removed_data_assets,omitempty" yaml:"removed_data_assets,omitempty"`
+}
+
+type RiskChange struct {
+	SyntheticId string         `json:"synthetic_id,omitempty" yaml:"synthetic_id,omitempty"`
+	CategoryId  string         `json:"category,omitempty" yaml:"category,omitempty"`
+	Title       string         `json:"title,omitempty" yaml:"title,omitempty"`
+	Changes     []*FieldChange `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+type FieldChange struct {
+	Field    string `json:"field,omitempty" yaml:"field,omitempty"`
+	Baseline string `json:"baseline,omitempty" yaml:"baseline,omitempty"`
+	Current  string `json:"current,omitempty" yaml:"current,omitempty"`
+}
+
+type ElementChange struct {
+	Id    string `json:"id,omitempty" yaml:"id,omitempty"`
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+}
+
+// DiffModels compares the generated risks and the model elements of two analyzed models
+func DiffModels(baseline *types.Model, current *types.Model) *ModelDiff {
+	diff := new(ModelDiff)
+
+	for _, id := range sortedKeys(current.GeneratedRisksBySyntheticId) {
+		currentRisk := current.GeneratedRisksBySyntheticId[id]
+		baselineRisk, ok := baseline.GeneratedRisksBySyntheticId[id]
+		if !ok {
+			diff.NewRisks = append(diff.NewRisks, withCurrentStatus(current, currentRisk))
+			continue
+		}
+
+		changes := diffRisk(baseline, baselineRisk, current, currentRisk)
+		if len(changes) > 0 {
+			diff.ChangedRisks = append(diff.ChangedRisks, &RiskChange{
+				SyntheticId: currentRisk.SyntheticId,
+				CategoryId:  currentRisk.CategoryId,
+				Title:       currentRisk.Title,
+				Changes:     changes,
+			})
+		}
+	}
+
+	for _, id := range sortedKeys(baseline.GeneratedRisksBySyntheticId) {
+		if _, ok := current.GeneratedRisksBySyntheticId[id]; !ok {
+			diff.ResolvedRisks = append(diff.ResolvedRisks, withCurrentStatus(baseline, baseline.GeneratedRisksBySyntheticId[id]))
+		}
+	}
+
+	diff.AddedTechnicalAssets, diff.RemovedTechnicalAssets = diffElements(technicalAssetTitles(baseline), technicalAssetTitles(current))
+	diff.AddedCommunicationLinks, diff.RemovedCommunicationLinks = diffElements(communicationLinkTitles(baseline), communicationLinkTitles(current))
+	diff.AddedDataAssets, diff.RemovedDataAssets = diffElements(dataAssetTitles(baseline), dataAssetTitles(current))
+
+	return diff
+}
+
+func (what *ModelDiff) IsEmpty() bool {
+	return len(what.NewRisks) == 0 && len(what.ResolvedRisks) == 0 && len(what.ChangedRisks) == 0 &&
+		len(what.AddedTechnicalAssets) == 0 && len(what.RemovedTechnicalAssets) == 0 &&
+		len(what.AddedCommunicationLinks) == 0 && len(what.RemovedCommunicationLinks) == 0 &&
+		len(what.AddedDataAssets) == 0 && len(what.RemovedDataAssets) == 0
+}
+
+func diffRisk(baseline *types.Model, baselineRisk *types.Risk, current *types.Model, currentRisk *types.Risk) []*FieldChange {
+	changes := make([]*FieldChange, 0)
+	addChange := func(field string, baselineValue string, currentValue string) {
+		if baselineValue != currentValue {
+			changes = append(changes, &FieldChange{Field: field, Baseline: baselineValue, Current: currentValue})
+		}
+	}
+
+	addChange("severity", baselineRisk.Severity.String(), currentRisk.Severity.String())
+	addChange("exploitation_likelihood", baselineRisk.ExploitationLikelihood.String(), currentRisk.ExploitationLikelihood.String())
+	addChange("exploitation_impact", baselineRisk.ExploitationImpact.String(), currentRisk.ExploitationImpact.String())
+	addChange("status", baseline.GetRiskTrackingWithDefault(baselineRisk).Status.String(), current.GetRiskTrackingWithDefault(currentRisk).Status.String())
+
+	return changes
+}
+
+func withCurrentStatus(parsedModel *types.Model, risk *types.Risk) *types.Risk {
+	result := *risk
+	result.RiskStatus = parsedModel.GetRiskTrackingWithDefault(risk).Status
+	return &result
+}
+
+func diffElements(baseline map[string]string, current map[string]string) ([]*ElementChange, []*ElementChange) {
+	added := make([]*ElementChange, 0)
+	for _, id := range sortedKeys(current) {
+		if _, ok := baseline[id]; !ok {
+			added = append(added, &ElementChange{Id: id, Title: current[id]})
+		}
+	}
+
+	removed := make([]*ElementChange, 0)
+	for _, id := range sortedKeys(baseline) {
+		if _, ok := current[id]; !ok {
+			removed = append(removed, &ElementChange{Id: id, Title: baseline[id]})
+		}
+	}
+
+	return added, removed
+}
+
+func technicalAssetTitles(parsedModel *types.Model) map[string]string {
+	titles := make(map[string]string)
+	for id, techAsset := range parsedModel.TechnicalAssets {
+		titles[id] = techAsset.Title
+	}
+	return titles
+}
+
+func communicationLinkTitles(parsedModel *types.Model) map[string]string {
+	titles := make(map[string]string)
+	for id, link := range parsedModel.CommunicationLinks {
+		titles[id] = link.Title
+	}
+	return titles
+}
+
+func dataAssetTitles(parsedModel *types.Model) map[string]string {
+	titles := make(map[string]string)
+	for id, dataAsset := range parsedModel.DataAssets {
+		titles[id] = dataAsset.Title
+	}
+	return titles
+}
+
+func sortedKeys[T any](items map[string]T) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.ToLower(keys[i]) < strings.ToLower(keys[j])
+	})
+
+	return keys
+}
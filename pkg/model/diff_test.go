@@ -0,0 +1,79 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+func TestDiffModels_NoChanges(t *testing.T) {
+	baseline := createDiffModel(&types.Risk{SyntheticId: "rule@ta1", Severity: types.HighSeverity})
+	current := createDiffModel(&types.Risk{SyntheticId: "rule@ta1", Severity: types.HighSeverity})
+
+	diff := DiffModels(baseline, current)
+
+	assert.True(t, diff.IsEmpty())
+}
+
+func TestDiffModels_NewAndResolvedRisks(t *testing.T) {
+	baseline := createDiffModel(&types.Risk{SyntheticId: "rule@ta1"})
+	current := createDiffModel(&types.Risk{SyntheticId: "rule@ta2"})
+
+	diff := DiffModels(baseline, current)
+
+	assert.Len(t, diff.NewRisks, 1)
+	assert.Equal(t, "rule@ta2", diff.NewRisks[0].SyntheticId)
+	assert.Len(t, diff.ResolvedRisks, 1)
+	assert.Equal(t, "rule@ta1", diff.ResolvedRisks[0].SyntheticId)
+	assert.Empty(t, diff.ChangedRisks)
+}
+
+func TestDiffModels_ChangedSeverityAndStatus(t *testing.T) {
+	baseline := createDiffModel(&types.Risk{SyntheticId: "rule@ta1", Severity: types.MediumSeverity})
+	current := createDiffModel(&types.Risk{SyntheticId: "rule@ta1", Severity: types.HighSeverity})
+	current.RiskTracking["rule@ta1"] = &types.RiskTracking{SyntheticRiskId: "rule@ta1", Status: types.Mitigated}
+
+	diff := DiffModels(baseline, current)
+
+	assert.Len(t, diff.ChangedRisks, 1)
+	assert.Equal(t, []*FieldChange{
+		{Field: "severity", Baseline: "medium", Current: "high"},
+		{Field: "status", Baseline: "unchecked", Current: "mitigated"},
+	}, diff.ChangedRisks[0].Changes)
+}
+
+func TestDiffModels_AddedAndRemovedElements(t *testing.T) {
+	baseline := createDiffModel()
+	baseline.TechnicalAssets["ta1"] = &types.TechnicalAsset{Id: "ta1", Title: "Asset 1"}
+	baseline.DataAssets["da1"] = &types.DataAsset{Id: "da1", Title: "Data 1"}
+
+	current := createDiffModel()
+	current.TechnicalAssets["ta2"] = &types.TechnicalAsset{Id: "ta2", Title: "Asset 2"}
+	current.CommunicationLinks["ta2>link"] = &types.CommunicationLink{Id: "ta2>link", Title: "Link"}
+
+	diff := DiffModels(baseline, current)
+
+	assert.Equal(t, []*ElementChange{{Id: "ta2", Title: "Asset 2"}}, diff.AddedTechnicalAssets)
+	assert.Equal(t, []*ElementChange{{Id: "ta1", Title: "Asset 1"}}, diff.RemovedTechnicalAssets)
+	assert.Equal(t, []*ElementChange{{Id: "ta2>link", Title: "Link"}}, diff.AddedCommunicationLinks)
+	assert.Empty(t, diff.RemovedCommunicationLinks)
+	assert.Empty(t, diff.AddedDataAssets)
+	assert.Equal(t, []*ElementChange{{Id: "da1", Title: "Data 1"}}, diff.RemovedDataAssets)
+}
+
+func createDiffModel(risks ...*types.Risk) *types.Model {
+	parsedModel := &types.Model{
+		TechnicalAssets:             make(map[string]*types.TechnicalAsset),
+		CommunicationLinks:          make(map[string]*types.CommunicationLink),
+		DataAssets:                  make(map[string]*types.DataAsset),
+		RiskTracking:                make(map[string]*types.RiskTracking),
+		GeneratedRisksBySyntheticId: make(map[string]*types.Risk),
+	}
+
+	for _, risk := range risks {
+		parsedModel.GeneratedRisksBySyntheticId[risk.SyntheticId] = risk
+	}
+
+	return parsedModel
+}
@@ -84,36 +84,37 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 		usage, err := types.ParseUsage(asset.Usage)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'usage' value of data asset %q: %v", title, asset.Usage)
+			return nil, errorAt(modelInput.Position("data_assets", title, "usage"), "unknown 'usage' value of data asset %q: %v", title, asset.Usage)
 		}
 		quantity, err := types.ParseQuantity(asset.Quantity)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'quantity' value of data asset %q: %v", title, asset.Quantity)
+			return nil, errorAt(modelInput.Position("data_assets", title, "quantity"), "unknown 'quantity' value of data asset %q: %v", title, asset.Quantity)
 		}
 		confidentiality, err := types.ParseConfidentiality(asset.Confidentiality)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'confidentiality' value of data asset %q: %v", title, asset.Confidentiality)
+			return nil, errorAt(modelInput.Position("data_assets", title, "confidentiality"), "unknown 'confidentiality' value of data asset %q: %v", title, asset.Confidentiality)
 		}
 		integrity, err := types.ParseCriticality(asset.Integrity)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'integrity' value of data asset %q: %v", title, asset.Integrity)
+			return nil, errorAt(modelInput.Position("data_assets", title, "integrity"), "unknown 'integrity' value of data asset %q: %v", title, asset.Integrity)
 		}
 		availability, err := types.ParseCriticality(asset.Availability)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'availability' value of data asset %q: %v", title, asset.Availability)
+			return nil, errorAt(modelInput.Position("data_assets", title, "availability"), "unknown 'availability' value of data asset %q: %v", title, asset.Availability)
 		}
 
 		err = checkIdSyntax(id)
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("data_assets", title), err)
 		}
 		if _, exists := parsedModel.DataAssets[id]; exists {
-			return nil, fmt.Errorf("duplicate id used: %v", id)
+			return nil, errorAt(modelInput.Position("data_assets", title), "duplicate id used: %v", id)
 		}
 		tags, err := parsedModel.CheckTags(lowerCaseAndTrim(asset.Tags), "data asset '"+title+"'")
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("data_assets", title), err)
 		}
+		parsedModel.AddPosition("data_assets", id, modelInput.Position("data_assets", title))
 		parsedModel.DataAssets[id] = &types.DataAsset{
 			Id:                     id,
 			Title:                  title,
@@ -137,7 +138,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 		usage, err := types.ParseUsage(asset.Usage)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'usage' value of technical asset %q: %v", title, asset.Usage)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "usage"), "unknown 'usage' value of technical asset %q: %v", title, asset.Usage)
 		}
 
 		var dataAssetsStored = make([]string, 0)
@@ -150,7 +151,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 				err := parsedModel.CheckDataAssetTargetExists(referencedAsset, fmt.Sprintf("technical asset %q", title))
 				if err != nil {
-					return nil, err
+					return nil, types.NewPositionError(modelInput.Position("technical_assets", title), err)
 				}
 				dataAssetsStored = append(dataAssetsStored, referencedAsset)
 			}
@@ -166,7 +167,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 				err := parsedModel.CheckDataAssetTargetExists(referencedAsset, "technical asset '"+title+"'")
 				if err != nil {
-					return nil, err
+					return nil, types.NewPositionError(modelInput.Position("technical_assets", title), err)
 				}
 				dataAssetsProcessed = append(dataAssetsProcessed, referencedAsset)
 			}
@@ -174,11 +175,11 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 		technicalAssetType, err := types.ParseTechnicalAssetType(asset.Type)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'type' value of technical asset %q: %v", title, asset.Type)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "type"), "unknown 'type' value of technical asset %q: %v", title, asset.Type)
 		}
 		technicalAssetSize, err := types.ParseTechnicalAssetSize(asset.Size)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'size' value of technical asset %q: %v", title, asset.Size)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "size"), "unknown 'size' value of technical asset %q: %v", title, asset.Size)
 		}
 
 		technicalAssetTechnologies := make([]*types.Technology, 0)
@@ -190,7 +191,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 		for _, technologyName := range allTechnologies {
 			technicalAssetTechnology := technologies.Get(technologyName)
 			if technicalAssetTechnology == nil {
-				return nil, fmt.Errorf("unknown 'technology' value of technical asset %q: %v", title, asset.Technology)
+				return nil, errorAt(modelInput.Position("technical_assets", title, "technology"), "unknown 'technology' value of technical asset %q: %v", title, asset.Technology)
 			}
 
 			technicalAssetTechnologies = append(technicalAssetTechnologies, technicalAssetTechnology)
@@ -198,23 +199,23 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 		encryption, err := types.ParseEncryptionStyle(asset.Encryption)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'encryption' value of technical asset %q: %v", title, asset.Encryption)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "encryption"), "unknown 'encryption' value of technical asset %q: %v", title, asset.Encryption)
 		}
 		technicalAssetMachine, err := types.ParseTechnicalAssetMachine(asset.Machine)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'machine' value of technical asset %q: %v", title, asset.Machine)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "machine"), "unknown 'machine' value of technical asset %q: %v", title, asset.Machine)
 		}
 		confidentiality, err := types.ParseConfidentiality(asset.Confidentiality)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'confidentiality' value of technical asset %q: %v", title, asset.Confidentiality)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "confidentiality"), "unknown 'confidentiality' value of technical asset %q: %v", title, asset.Confidentiality)
 		}
 		integrity, err := types.ParseCriticality(asset.Integrity)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'integrity' value of technical asset %q: %v", title, asset.Integrity)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "integrity"), "unknown 'integrity' value of technical asset %q: %v", title, asset.Integrity)
 		}
 		availability, err := types.ParseCriticality(asset.Availability)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'availability' value of technical asset %q: %v", title, asset.Availability)
+			return nil, errorAt(modelInput.Position("technical_assets", title, "availability"), "unknown 'availability' value of technical asset %q: %v", title, asset.Availability)
 		}
 
 		dataFormatsAccepted := make([]types.DataFormat, 0)
@@ -222,7 +223,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 			for _, dataFormatName := range asset.DataFormatsAccepted {
 				dataFormat, err := types.ParseDataFormat(dataFormatName)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'data_formats_accepted' value of technical asset %q: %v", title, dataFormatName)
+					return nil, errorAt(modelInput.Position("technical_assets", title, "data_formats_accepted"), "unknown 'data_formats_accepted' value of technical asset %q: %v", title, dataFormatName)
 				}
 				dataFormatsAccepted = append(dataFormatsAccepted, dataFormat)
 			}
@@ -237,19 +238,19 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 				authentication, err := types.ParseAuthentication(commLink.Authentication)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'authentication' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Authentication)
+					return nil, errorAt(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle, "authentication"), "unknown 'authentication' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Authentication)
 				}
 				authorization, err := types.ParseAuthorization(commLink.Authorization)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'authorization' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Authorization)
+					return nil, errorAt(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle, "authorization"), "unknown 'authorization' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Authorization)
 				}
 				usage, err := types.ParseUsage(commLink.Usage)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'usage' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Usage)
+					return nil, errorAt(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle, "usage"), "unknown 'usage' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Usage)
 				}
 				protocol, err := types.ParseProtocol(commLink.Protocol)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'protocol' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Protocol)
+					return nil, errorAt(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle, "protocol"), "unknown 'protocol' value of technical asset %q communication link %q: %v", title, commLinkTitle, commLink.Protocol)
 				}
 
 				if commLink.DataAssetsSent != nil {
@@ -258,7 +259,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 						if !contains(dataAssetsSent, referencedAsset) {
 							err := parsedModel.CheckDataAssetTargetExists(referencedAsset, fmt.Sprintf("communication link %q of technical asset %q", commLinkTitle, title))
 							if err != nil {
-								return nil, err
+								return nil, types.NewPositionError(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle), err)
 							}
 
 							dataAssetsSent = append(dataAssetsSent, referencedAsset)
@@ -278,7 +279,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 						err := parsedModel.CheckDataAssetTargetExists(referencedAsset, "communication link '"+commLinkTitle+"' of technical asset '"+title+"'")
 						if err != nil {
-							return nil, err
+							return nil, types.NewPositionError(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle), err)
 						}
 						dataAssetsReceived = append(dataAssetsReceived, referencedAsset)
 
@@ -295,11 +296,11 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 				dataFlowTitle := fmt.Sprintf("%v", commLinkTitle)
 				commLinkId, err := createDataFlowId(id, dataFlowTitle)
 				if err != nil {
-					return nil, err
+					return nil, types.NewPositionError(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle), err)
 				}
 				tags, err := parsedModel.CheckTags(lowerCaseAndTrim(commLink.Tags), "communication link '"+commLinkTitle+"' of technical asset '"+title+"'")
 				if err != nil {
-					return nil, err
+					return nil, types.NewPositionError(modelInput.Position("technical_assets", title, "communication_links", commLinkTitle), err)
 				}
 				commLink := &types.CommunicationLink{
 					Id:                     commLinkId,
@@ -320,6 +321,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 					DiagramTweakWeight:     weight,
 					DiagramTweakConstraint: !commLink.DiagramTweakConstraint,
 				}
+				parsedModel.AddPosition("communication_links", commLink.Id, modelInput.Position("technical_assets", title, "communication_links", commLinkTitle))
 				communicationLinks = append(communicationLinks, commLink)
 				// track all comm links
 				parsedModel.CommunicationLinks[commLink.Id] = commLink
@@ -331,15 +333,16 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 		err = checkIdSyntax(id)
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("technical_assets", title), err)
 		}
 		if _, exists := parsedModel.TechnicalAssets[id]; exists {
-			return nil, fmt.Errorf("duplicate id used: %v", id)
+			return nil, errorAt(modelInput.Position("technical_assets", title), "duplicate id used: %v", id)
 		}
 		tags, err := parsedModel.CheckTags(lowerCaseAndTrim(asset.Tags), fmt.Sprintf("technical asset %q", title))
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("technical_assets", title), err)
 		}
+		parsedModel.AddPosition("technical_assets", id, modelInput.Position("technical_assets", title))
 		parsedModel.TechnicalAssets[id] = &types.TechnicalAsset{
 			Id:                      id,
 			Usage:                   usage,
@@ -399,7 +402,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 			}
 			targetTechAsset := parsedModel.TechnicalAssets[commLink.TargetId]
 			if targetTechAsset == nil {
-				return nil, fmt.Errorf("missing target technical asset %q for communication link: %q", commLink.TargetId, commLink.Title)
+				return nil, errorAt(parsedModel.GetPosition("communication_links", commLink.Id), "missing target technical asset %q for communication link: %q", commLink.TargetId, commLink.Title)
 			}
 			dataAssetsProcessedByTarget := targetTechAsset.DataAssetsProcessed
 			for _, dataAssetSent := range commLink.DataAssetsSent {
@@ -431,10 +434,10 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 				technicalAssetsInside[i] = strings.ToLower(parsedInsideAsset)
 				_, found := parsedModel.TechnicalAssets[technicalAssetsInside[i]]
 				if !found {
-					return nil, fmt.Errorf("missing referenced technical asset %q at trust boundary %q", technicalAssetsInside[i], title)
+					return nil, errorAt(modelInput.Position("trust_boundaries", title), "missing referenced technical asset %q at trust boundary %q", technicalAssetsInside[i], title)
 				}
 				if checklistToAvoidAssetBeingModeledInMultipleTrustBoundaries[technicalAssetsInside[i]] {
-					return nil, fmt.Errorf("referenced technical asset %q at trust boundary %q is modeled in multiple trust boundaries", technicalAssetsInside[i], title)
+					return nil, errorAt(modelInput.Position("trust_boundaries", title), "referenced technical asset %q at trust boundary %q is modeled in multiple trust boundaries", technicalAssetsInside[i], title)
 				}
 				checklistToAvoidAssetBeingModeledInMultipleTrustBoundaries[technicalAssetsInside[i]] = true
 				//fmt.Println("asset "+technicalAssetsInside[i]+" at i="+strconv.Itoa(i))
@@ -452,11 +455,11 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 		trustBoundaryType, err := types.ParseTrustBoundary(boundary.Type)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'type' of trust boundary %q: %v", title, boundary.Type)
+			return nil, errorAt(modelInput.Position("trust_boundaries", title, "type"), "unknown 'type' of trust boundary %q: %v", title, boundary.Type)
 		}
 		tags, err := parsedModel.CheckTags(lowerCaseAndTrim(boundary.Tags), fmt.Sprintf("trust boundary %q", title))
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("trust_boundaries", title), err)
 		}
 		trustBoundary := &types.TrustBoundary{
 			Id:                    id,
@@ -469,11 +472,12 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 		}
 		err = checkIdSyntax(id)
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("trust_boundaries", title), err)
 		}
 		if _, exists := parsedModel.TrustBoundaries[id]; exists {
-			return nil, fmt.Errorf("duplicate id used: %v", id)
+			return nil, errorAt(modelInput.Position("trust_boundaries", title), "duplicate id used: %v", id)
 		}
+		parsedModel.AddPosition("trust_boundaries", id, modelInput.Position("trust_boundaries", title))
 		parsedModel.TrustBoundaries[id] = trustBoundary
 		for _, technicalAsset := range trustBoundary.TechnicalAssetsInside {
 			parsedModel.DirectContainingTrustBoundaryMappedByTechnicalAssetId[technicalAsset] = trustBoundary
@@ -498,14 +502,14 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 				assetId := fmt.Sprintf("%v", parsedRunningAsset)
 				err := parsedModel.CheckTechnicalAssetExists(assetId, "shared runtime '"+title+"'", false)
 				if err != nil {
-					return nil, err
+					return nil, types.NewPositionError(modelInput.Position("shared_runtimes", title), err)
 				}
 				technicalAssetsRunning[i] = assetId
 			}
 		}
 		tags, err := parsedModel.CheckTags(lowerCaseAndTrim(inputRuntime.Tags), "shared runtime '"+title+"'")
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("shared_runtimes", title), err)
 		}
 		sharedRuntime := &types.SharedRuntime{
 			Id:                     id,
@@ -516,11 +520,12 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 		}
 		err = checkIdSyntax(id)
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("shared_runtimes", title), err)
 		}
 		if _, exists := parsedModel.SharedRuntimes[id]; exists {
-			return nil, fmt.Errorf("duplicate id used: %v", id)
+			return nil, errorAt(modelInput.Position("shared_runtimes", title), "duplicate id used: %v", id)
 		}
+		parsedModel.AddPosition("shared_runtimes", id, modelInput.Position("shared_runtimes", title))
 		parsedModel.SharedRuntimes[id] = sharedRuntime
 	}
 
@@ -536,12 +541,12 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 	for _, customRiskCategoryCategory := range modelInput.CustomRiskCategories {
 		function, err := types.ParseRiskFunction(customRiskCategoryCategory.Function)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'function' value of individual risk category %q: %v", customRiskCategoryCategory.Title, customRiskCategoryCategory.Function)
+			return nil, errorAt(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "function"), "unknown 'function' value of individual risk category %q: %v", customRiskCategoryCategory.Title, customRiskCategoryCategory.Function)
 		}
 
 		stride, err := types.ParseSTRIDE(customRiskCategoryCategory.STRIDE)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'stride' value of individual risk category  %q: %v", customRiskCategoryCategory.Title, customRiskCategoryCategory.STRIDE)
+			return nil, errorAt(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "stride"), "unknown 'stride' value of individual risk category  %q: %v", customRiskCategoryCategory.Title, customRiskCategoryCategory.STRIDE)
 		}
 
 		cat := &types.RiskCategory{
@@ -569,11 +574,11 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 
 		err = checkIdSyntax(customRiskCategoryCategory.ID)
 		if err != nil {
-			return nil, err
+			return nil, types.NewPositionError(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID), err)
 		}
 
 		if !parsedModel.CustomRiskCategories.Add(cat) {
-			return nil, fmt.Errorf("duplicate id used: %v", customRiskCategoryCategory.ID)
+			return nil, errorAt(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID), "duplicate id used: %v", customRiskCategoryCategory.ID)
 		}
 
 		// NOW THE INDIVIDUAL RISK INSTANCES:
@@ -585,22 +590,22 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 				var dataBreachTechnicalAssetIDs []string
 				severity, err := types.ParseRiskSeverity(individualRiskInstance.Severity)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'severity' value of individual risk instance %q: %v", title, individualRiskInstance.Severity)
+					return nil, errorAt(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title, "severity"), "unknown 'severity' value of individual risk instance %q: %v", title, individualRiskInstance.Severity)
 				}
 				exploitationLikelihood, err := types.ParseRiskExploitationLikelihood(individualRiskInstance.ExploitationLikelihood)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'exploitation_likelihood' value of individual risk instance %q: %v", title, individualRiskInstance.ExploitationLikelihood)
+					return nil, errorAt(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title, "exploitation_likelihood"), "unknown 'exploitation_likelihood' value of individual risk instance %q: %v", title, individualRiskInstance.ExploitationLikelihood)
 				}
 				exploitationImpact, err := types.ParseRiskExploitationImpact(individualRiskInstance.ExploitationImpact)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'exploitation_impact' value of individual risk instance %q: %v", title, individualRiskInstance.ExploitationImpact)
+					return nil, errorAt(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title, "exploitation_impact"), "unknown 'exploitation_impact' value of individual risk instance %q: %v", title, individualRiskInstance.ExploitationImpact)
 				}
 
 				if len(individualRiskInstance.MostRelevantDataAsset) > 0 {
 					mostRelevantDataAssetId = fmt.Sprintf("%v", individualRiskInstance.MostRelevantDataAsset)
 					err := parsedModel.CheckDataAssetTargetExists(mostRelevantDataAssetId, fmt.Sprintf("individual risk %q", title))
 					if err != nil {
-						return nil, err
+						return nil, types.NewPositionError(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title), err)
 					}
 				}
 
@@ -608,7 +613,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 					mostRelevantTechnicalAssetId = fmt.Sprintf("%v", individualRiskInstance.MostRelevantTechnicalAsset)
 					err := parsedModel.CheckTechnicalAssetExists(mostRelevantTechnicalAssetId, fmt.Sprintf("individual risk %q", title), false)
 					if err != nil {
-						return nil, err
+						return nil, types.NewPositionError(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title), err)
 					}
 				}
 
@@ -616,7 +621,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 					mostRelevantCommunicationLinkId = fmt.Sprintf("%v", individualRiskInstance.MostRelevantCommunicationLink)
 					err := parsedModel.CheckCommunicationLinkExists(mostRelevantCommunicationLinkId, fmt.Sprintf("individual risk %q", title))
 					if err != nil {
-						return nil, err
+						return nil, types.NewPositionError(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title), err)
 					}
 				}
 
@@ -624,7 +629,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 					mostRelevantTrustBoundaryId = fmt.Sprintf("%v", individualRiskInstance.MostRelevantTrustBoundary)
 					err := parsedModel.CheckTrustBoundaryExists(mostRelevantTrustBoundaryId, fmt.Sprintf("individual risk %q", title))
 					if err != nil {
-						return nil, err
+						return nil, types.NewPositionError(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title), err)
 					}
 				}
 
@@ -632,13 +637,13 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 					mostRelevantSharedRuntimeId = fmt.Sprintf("%v", individualRiskInstance.MostRelevantSharedRuntime)
 					err := parsedModel.CheckSharedRuntimeExists(mostRelevantSharedRuntimeId, fmt.Sprintf("individual risk %q", title))
 					if err != nil {
-						return nil, err
+						return nil, types.NewPositionError(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title), err)
 					}
 				}
 
 				dataBreachProbability, err = types.ParseDataBreachProbability(individualRiskInstance.DataBreachProbability)
 				if err != nil {
-					return nil, fmt.Errorf("unknown 'data_breach_probability' value of individual risk instance %q: %v", title, individualRiskInstance.DataBreachProbability)
+					return nil, errorAt(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title, "data_breach_probability"), "unknown 'data_breach_probability' value of individual risk instance %q: %v", title, individualRiskInstance.DataBreachProbability)
 				}
 
 				if individualRiskInstance.DataBreachTechnicalAssets != nil {
@@ -647,7 +652,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 						assetId := fmt.Sprintf("%v", parsedReferencedAsset)
 						err := parsedModel.CheckTechnicalAssetExists(assetId, fmt.Sprintf("data breach technical assets of individual risk %q", title), false)
 						if err != nil {
-							return nil, err
+							return nil, types.NewPositionError(modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title), err)
 						}
 						dataBreachTechnicalAssetIDs[i] = assetId
 					}
@@ -667,6 +672,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 					MostRelevantSharedRuntimeId:     mostRelevantSharedRuntimeId,
 					DataBreachProbability:           dataBreachProbability,
 					DataBreachTechnicalAssetIDs:     dataBreachTechnicalAssetIDs,
+					Position:                        modelInput.Position("custom_risk_categories", customRiskCategoryCategory.ID, "risks_identified", title),
 				})
 			}
 		}
@@ -683,13 +689,13 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 			var parseError error
 			date, parseError = time.Parse("2006-01-02", riskTracking.Date)
 			if parseError != nil {
-				return nil, fmt.Errorf("unable to parse 'date' of risk tracking %q: %v", syntheticRiskId, riskTracking.Date)
+				return nil, errorAt(modelInput.Position("risk_tracking", syntheticRiskId, "date"), "unable to parse 'date' of risk tracking %q: %v", syntheticRiskId, riskTracking.Date)
 			}
 		}
 
 		status, err := types.ParseRiskStatus(riskTracking.Status)
 		if err != nil {
-			return nil, fmt.Errorf("unknown 'status' value of risk tracking %q: %v", syntheticRiskId, riskTracking.Status)
+			return nil, errorAt(modelInput.Position("risk_tracking", syntheticRiskId, "status"), "unknown 'status' value of risk tracking %q: %v", syntheticRiskId, riskTracking.Status)
 		}
 
 		tracking := &types.RiskTracking{
@@ -701,6 +707,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 			Status:          status,
 		}
 
+		parsedModel.AddPosition("risk_tracking", syntheticRiskId, modelInput.Position("risk_tracking", syntheticRiskId))
 		parsedModel.RiskTracking[syntheticRiskId] = tracking
 	}
 
@@ -709,7 +716,7 @@ func ParseModel(config technologyMapConfigReader, modelInput *input.Model, built
 		for _, commLink := range technicalAsset.CommunicationLinks {
 			err := parsedModel.CheckTechnicalAssetExists(commLink.TargetId, "communication link '"+commLink.Title+"' of technical asset '"+technicalAsset.Title+"'", false)
 			if err != nil {
-				return nil, err
+				return nil, types.NewPositionError(parsedModel.GetPosition("communication_links", commLink.Id), err)
 			}
 		}
 	}
@@ -752,6 +759,10 @@ func convertAuthors(authors []input.Author) []*types.Author {
 	return result
 }
 
+func errorAt(position *types.Position, format string, a ...any) error {
+	return types.NewPositionError(position, fmt.Errorf(format, a...))
+}
+
 func checkIdSyntax(id string) error {
 	validIdSyntax := regexp.MustCompile(`^[a-zA-Z0-9\-]+$`)
 	if !validIdSyntax.MatchString(id) {
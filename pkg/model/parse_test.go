@@ -5,6 +5,9 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package model
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/uuid"
@@ -149,6 +152,47 @@ func TestInferAvailability_ExpectHighestAvailability(t *testing.T) {
 	assert.Equal(t, types.Operational, parsedModel.TechnicalAssets[taWithArchiveAvailabilityDataAsset.ID].Availability)
 }
 
+func TestParseModel_ErrorWithPosition(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "threagile.yaml")
+	modelYaml := `business_criticality: archive
+technical_assets:
+  Some Asset:
+    id: some-asset
+    usage: business
+    type: process
+    size: system
+    technology: unknown-technology
+    encryption: none
+    machine: virtual
+    confidentiality: public
+    integrity: archive
+    availability: fancy
+`
+	assert.NoError(t, os.WriteFile(filename, []byte(modelYaml), 0600))
+
+	modelInput := new(input.Model).Defaults()
+	assert.NoError(t, modelInput.Load(filename))
+
+	_, err := ParseModel(&mockConfig{}, modelInput, make(types.RiskRules), make(types.RiskRules))
+
+	var positionError *types.PositionError
+	assert.True(t, errors.As(err, &positionError))
+	assert.Equal(t, &types.Position{File: filename, Line: 13, Column: 5}, positionError.Position)
+	assert.Contains(t, err.Error(), filename+":13:5: unknown 'availability'")
+}
+
+func TestParseModel_ElementPositions(t *testing.T) {
+	modelInput := createInputModel(map[string]input.TechnicalAsset{"Some Asset": createTechnicalAsset(types.Public, types.Archive, types.Archive)}, make(map[string]input.DataAsset))
+	modelInput.Positions = input.Positions{"technical_assets/Some Asset": {File: "threagile.yaml", Line: 3, Column: 3}}
+
+	parsedModel, err := ParseModel(&mockConfig{}, modelInput, make(types.RiskRules), make(types.RiskRules))
+
+	assert.NoError(t, err)
+	id := modelInput.TechnicalAssets["Some Asset"].ID
+	assert.Equal(t, &types.Position{File: "threagile.yaml", Line: 3, Column: 3}, parsedModel.GetPosition("technical_assets", id))
+	assert.Equal(t, 3, parsedModel.GetRiskPosition(&types.Risk{MostRelevantTechnicalAssetId: id}).Line)
+}
+
 func createInputModel(technicalAssets map[string]input.TechnicalAsset, dataAssets map[string]input.DataAsset) *input.Model {
 	return &input.Model{
 		TechnicalAssets: technicalAssets,
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/threagile/threagile/internal/runner"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// persistentPlugin is a risk rule plugin started once for all the risk categories it declares. It gets the model only
+// once and then answers a generate request per category, see docs/custom-risk-rules.md for the protocol.
+type persistentPlugin struct {
+	mutex   sync.Mutex
+	process *runner.Process
+
+	// model is the model last sent to the plugin; holding it also keeps its address from being reused for another model
+	model *types.Model
+}
+
+type setModelParams struct {
+	Model *types.Model `json:"model"`
+}
+
+type generateRisksParams struct {
+	Category string `json:"category"`
+}
+
+func loadPersistentPlugin(filename string, timeout time.Duration) ([]*CustomRiskCategory, error) {
+	process, startError := new(runner.Process).Start(filename, timeout, "-serve")
+	if startError != nil {
+		return nil, startError
+	}
+
+	categories := make([]*CustomRiskCategory, 0)
+	infoError := process.Call("get_info", nil, &categories)
+	if infoError != nil {
+		_ = process.Close()
+		return nil, infoError
+	}
+
+	if len(categories) == 0 {
+		_ = process.Close()
+		return nil, fmt.Errorf("plugin %q declares no risk categories", filename)
+	}
+
+	plugin := &persistentPlugin{process: process}
+	for _, category := range categories {
+		category.plugin = plugin
+	}
+
+	return categories, nil
+}
+
+func (what *persistentPlugin) generateRisks(parsedModel *types.Model, categoryID string) ([]*types.Risk, error) {
+	what.mutex.Lock()
+	defer what.mutex.Unlock()
+
+	if what.model != parsedModel {
+		what.model = nil
+		modelError := what.process.Call("set_model", &setModelParams{Model: parsedModel}, nil)
+		if modelError != nil {
+			return nil, fmt.Errorf("failed to send model to custom risk rule %q: %w", categoryID, modelError)
+		}
+
+		what.model = parsedModel
+	}
+
+	generatedRisks := make([]*types.Risk, 0)
+	generateError := what.process.Call("generate_risks", &generateRisksParams{Category: categoryID}, &generatedRisks)
+	if generateError != nil {
+		return nil, fmt.Errorf("failed to generate risks for custom risk rule %q: %w", categoryID, generateError)
+	}
+
+	return generatedRisks, nil
+}
@@ -0,0 +1,250 @@
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/threagile/threagile/internal/runner"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// testPluginEnv makes the test binary act as persistent risk rule plugin, the value selects its behavior
+const testPluginEnv = "THREAGILE_TEST_PERSISTENT_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if behavior := os.Getenv(testPluginEnv); len(behavior) > 0 {
+		serveTestPlugin(behavior)
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// serveTestPlugin declares two risk categories and generates a risk per technical asset for each of them; the titles
+// tell how often the model has been sent
+func serveTestPlugin(behavior string) {
+	switch behavior {
+	case "hang":
+		_, _ = fmt.Fprintln(os.Stderr, "still starting")
+		time.Sleep(time.Minute)
+		return
+
+	case "exit":
+		_, _ = fmt.Fprintln(os.Stderr, "no rules found")
+		os.Exit(3)
+	}
+
+	var parsedModel *types.Model
+	modelsReceived := 0
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var request struct {
+			ID     int64           `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+
+		_ = json.Unmarshal(scanner.Bytes(), &request)
+		response := map[string]any{"jsonrpc": "2.0", "id": request.ID}
+
+		switch request.Method {
+		case "get_info":
+			response["result"] = []*CustomRiskCategory{
+				{RiskCategory: types.RiskCategory{ID: "first", Title: "First"}, Tags: []string{"first-tag"}},
+				{RiskCategory: types.RiskCategory{ID: "second", Title: "Second"}},
+			}
+
+		case "set_model":
+			var params setModelParams
+			_ = json.Unmarshal(request.Params, &params)
+			parsedModel = params.Model
+			modelsReceived++
+			response["result"] = true
+
+		case "generate_risks":
+			var params generateRisksParams
+			_ = json.Unmarshal(request.Params, &params)
+
+			if params.Category == "second" {
+				_, _ = fmt.Fprintln(os.Stderr, "second rule is broken")
+				response["error"] = map[string]any{"code": -32000, "message": "rule failed"}
+				break
+			}
+
+			risks := make([]*types.Risk, 0)
+			for _, id := range sortedKeys(parsedModel.TechnicalAssets) {
+				risks = append(risks, &types.Risk{
+					CategoryId:  params.Category,
+					Severity:    types.HighSeverity,
+					Title:       fmt.Sprintf("model received %d time(s)", modelsReceived),
+					SyntheticId: params.Category + "@" + id,
+				})
+			}
+			response["result"] = risks
+		}
+
+		data, _ := json.Marshal(response)
+		_, _ = fmt.Println(string(data))
+	}
+}
+
+func loadTestPlugin(t *testing.T, behavior string, timeout time.Duration) ([]*CustomRiskCategory, error) {
+	t.Helper()
+
+	t.Setenv(testPluginEnv, behavior)
+
+	return loadPersistentPlugin(os.Args[0], timeout)
+}
+
+func TestPersistentPluginGenerateRisks(t *testing.T) {
+	categories, err := loadTestPlugin(t, "serve", 10*time.Second)
+	require.NoError(t, err)
+	require.Len(t, categories, 2)
+	defer func() { _ = categories[0].plugin.process.Close() }()
+
+	assert.Equal(t, "first", categories[0].ID)
+	assert.Equal(t, []string{"first-tag"}, categories[0].SupportedTags())
+	assert.Equal(t, "second", categories[1].ID)
+	assert.Same(t, categories[0].plugin, categories[1].plugin)
+
+	parsedModel := &types.Model{TechnicalAssets: map[string]*types.TechnicalAsset{
+		"web-server": {Id: "web-server", Title: "Web Server"},
+		"database":   {Id: "database", Title: "Database"},
+	}}
+
+	risks, err := categories[0].GenerateRisks(parsedModel)
+	require.NoError(t, err)
+	require.Len(t, risks, 2)
+	assert.Equal(t, "first@database", risks[0].SyntheticId)
+	assert.Equal(t, types.HighSeverity, risks[0].Severity)
+	assert.Equal(t, "model received 1 time(s)", risks[0].Title)
+
+	risks, err = categories[0].GenerateRisks(parsedModel)
+	require.NoError(t, err)
+	assert.Equal(t, "model received 1 time(s)", risks[0].Title)
+
+	risks, err = categories[0].GenerateRisks(&types.Model{TechnicalAssets: parsedModel.TechnicalAssets})
+	require.NoError(t, err)
+	assert.Equal(t, "model received 2 time(s)", risks[0].Title)
+
+	_, err = categories[1].GenerateRisks(parsedModel)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rule failed")
+	assert.Eventually(t, func() bool {
+		return strings.Contains(categories[1].plugin.process.ErrorOutput(), "second rule is broken")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPersistentPluginTimeout(t *testing.T) {
+	start := time.Now()
+	_, err := loadTestPlugin(t, "hang", 500*time.Millisecond)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not answer \"get_info\"")
+	assert.Less(t, time.Since(start), 30*time.Second)
+}
+
+func TestPersistentPluginCloseWithoutTimeout(t *testing.T) {
+	t.Setenv(testPluginEnv, "hang")
+	process, err := new(runner.Process).Start(os.Args[0], 0, "-serve")
+	require.NoError(t, err)
+
+	// the plugin neither answers nor exits when its stdin is closed, so it has to be killed even without timeout
+	start := time.Now()
+	require.NoError(t, process.Close())
+	assert.Less(t, time.Since(start), 30*time.Second)
+}
+
+func TestPersistentPluginExit(t *testing.T) {
+	_, err := loadTestPlugin(t, "exit", 10*time.Second)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 3")
+	assert.Contains(t, err.Error(), "no rules found")
+}
+
+func TestCloseCustomRiskRules(t *testing.T) {
+	categories, err := loadTestPlugin(t, "serve", 10*time.Second)
+	require.NoError(t, err)
+
+	rules := make(types.RiskRules)
+	for _, category := range categories {
+		rules[category.ID] = category
+	}
+
+	CloseCustomRiskRules(rules)
+
+	_, err = categories[0].GenerateRisks(&types.Model{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exited")
+}
+
+type customRisksTestConfig struct {
+	mockConfig
+}
+
+func (what *customRisksTestConfig) GetSkipRiskRules() []string {
+	return []string{"skipped-rule"}
+}
+
+type skippedTestRule struct {
+	testRule
+}
+
+func (what *skippedTestRule) Category() *types.RiskCategory {
+	return &types.RiskCategory{ID: "skipped-rule", Title: "Skipped Rule"}
+}
+
+func TestCustomRisksFile(t *testing.T) {
+	var ruleTest RuleTest
+	require.NoError(t, yaml.Unmarshal([]byte(testRuleTestYAML), &ruleTest))
+	ruleTest.Model["business_criticality"] = types.Important.String()
+	modelData, err := yaml.Marshal(ruleTest.Model)
+	require.NoError(t, err)
+	modelFile := filepath.Join(t.TempDir(), "threagile.yaml")
+	require.NoError(t, os.WriteFile(modelFile, modelData, 0600))
+
+	// the server generates the risks of its custom risk rules and hands them over to the sub-process as file
+	filename := filepath.Join(t.TempDir(), "custom-risks.yaml")
+	file, err := os.Create(filename)
+	require.NoError(t, err)
+	customRiskRules := types.RiskRules{"test-rule": new(testRule), "skipped-rule": new(skippedTestRule)}
+	err = GenerateCustomRisks(modelFile, file, &customRisksTestConfig{}, make(types.RiskRules), customRiskRules, testProgressReporter{})
+	require.NoError(t, file.Close())
+	require.NoError(t, err)
+
+	loaded, err := readCustomRisks(filename)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1, "skipped rules are left out")
+	assert.Equal(t, "Test Rule", loaded[0].Category().Title)
+	assert.Empty(t, loaded[0].SupportedTags())
+
+	// the risks are generated for the model already, whatever model they are asked for
+	risks, err := loaded[0].GenerateRisks(&types.Model{})
+	require.NoError(t, err)
+	require.Len(t, risks, 2)
+	assert.Equal(t, "test-rule@database", risks[0].SyntheticId)
+	assert.Equal(t, "test-rule@web-server", risks[1].SyntheticId)
+	assert.Equal(t, types.ElevatedSeverity, risks[1].Severity)
+}
+
+func TestCustomRiskCategoryWithoutPlugin(t *testing.T) {
+	category := new(CustomRiskCategory).Init(&types.RiskCategory{ID: "custom"}, []string{"tag"})
+
+	risks, err := category.GenerateRisks(&types.Model{})
+
+	require.NoError(t, err)
+	assert.Empty(t, risks)
+}
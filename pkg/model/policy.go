@@ -0,0 +1,229 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// RiskPolicy is a set of rules deciding whether an analysis run fails, like
+//
+//	any unchecked critical
+//	more than 3 high not mitigated
+//	at least 1 elevated+ in-discussion
+//
+// Risks of allowed categories are ignored by all rules.
+type RiskPolicy struct {
+	Rules             []*RiskPolicyRule
+	AllowedCategories []string
+}
+
+type RiskPolicyRule struct {
+	Text             string
+	MaxAllowed       int
+	Severity         types.RiskSeverity
+	OrHigher         bool
+	Statuses         []types.RiskStatus
+	ExcludedStatuses []types.RiskStatus
+}
+
+type RiskPolicyViolation struct {
+	Rule    string   `json:"rule,omitempty" yaml:"rule,omitempty"`
+	Allowed int      `json:"allowed" yaml:"allowed"`
+	Found   int      `json:"found" yaml:"found"`
+	RiskIDs []string `json:"risk_ids,omitempty" yaml:"risk_ids,omitempty"`
+}
+
+const stillAtRiskStatus = "still-at-risk"
+
+func ParseRiskPolicy(rules []string, allowedCategories []string) (*RiskPolicy, error) {
+	policy := &RiskPolicy{
+		Rules:             make([]*RiskPolicyRule, 0),
+		AllowedCategories: make([]string, 0),
+	}
+
+	for _, category := range allowedCategories {
+		category = strings.TrimSpace(category)
+		if len(category) > 0 {
+			policy.AllowedCategories = append(policy.AllowedCategories, strings.ToLower(category))
+		}
+	}
+
+	for _, text := range rules {
+		if len(strings.TrimSpace(text)) == 0 {
+			continue
+		}
+
+		rule, parseError := ParseRiskPolicyRule(text)
+		if parseError != nil {
+			return nil, parseError
+		}
+
+		policy.Rules = append(policy.Rules, rule)
+	}
+
+	return policy, nil
+}
+
+func ParseRiskPolicyRule(text string) (*RiskPolicyRule, error) {
+	rule := &RiskPolicyRule{
+		Text:     strings.TrimSpace(text),
+		Severity: types.LowSeverity,
+		OrHigher: true,
+	}
+
+	words := strings.Fields(strings.ToLower(rule.Text))
+	rest, quantifierError := rule.parseQuantifier(words)
+	if quantifierError != nil {
+		return nil, fmt.Errorf("invalid risk policy %q: %w", text, quantifierError)
+	}
+
+	hasSeverity := false
+	for n := 0; n < len(rest); n++ {
+		word := rest[n]
+		switch word {
+		case "risk", "risks":
+			continue
+
+		case "not":
+			if n+1 >= len(rest) {
+				return nil, fmt.Errorf("invalid risk policy %q: missing status after 'not'", text)
+			}
+
+			n++
+			statuses, statusError := parseRiskPolicyStatus(rest[n])
+			if statusError != nil {
+				return nil, fmt.Errorf("invalid risk policy %q: %w", text, statusError)
+			}
+
+			rule.ExcludedStatuses = append(rule.ExcludedStatuses, statuses...)
+
+		case "or":
+			if n+1 >= len(rest) || (rest[n+1] != "higher" && rest[n+1] != "above") {
+				return nil, fmt.Errorf("invalid risk policy %q: expected 'or higher'", text)
+			}
+
+			n++
+			rule.OrHigher = true
+
+		default:
+			severity, severityError := types.ParseRiskSeverity(strings.TrimSuffix(word, "+"))
+			if severityError == nil {
+				if hasSeverity {
+					return nil, fmt.Errorf("invalid risk policy %q: more than one severity", text)
+				}
+
+				hasSeverity = true
+				rule.Severity = severity
+				rule.OrHigher = strings.HasSuffix(word, "+")
+				continue
+			}
+
+			statuses, statusError := parseRiskPolicyStatus(word)
+			if statusError != nil {
+				return nil, fmt.Errorf("invalid risk policy %q: unexpected %q", text, word)
+			}
+
+			rule.Statuses = append(rule.Statuses, statuses...)
+		}
+	}
+
+	return rule, nil
+}
+
+func (what *RiskPolicyRule) parseQuantifier(words []string) ([]string, error) {
+	switch {
+	case len(words) >= 1 && words[0] == "any":
+		what.MaxAllowed = 0
+		return words[1:], nil
+
+	case len(words) >= 3 && words[0] == "more" && words[1] == "than":
+		count, countError := strconv.Atoi(words[2])
+		if countError != nil || count < 0 {
+			return nil, fmt.Errorf("invalid count %q", words[2])
+		}
+
+		what.MaxAllowed = count
+		return words[3:], nil
+
+	case len(words) >= 3 && words[0] == "at" && words[1] == "least":
+		count, countError := strconv.Atoi(words[2])
+		if countError != nil || count < 1 {
+			return nil, fmt.Errorf("invalid count %q", words[2])
+		}
+
+		what.MaxAllowed = count - 1
+		return words[3:], nil
+	}
+
+	return nil, fmt.Errorf("expected 'any', 'more than <n>' or 'at least <n>'")
+}
+
+func parseRiskPolicyStatus(value string) ([]types.RiskStatus, error) {
+	if value == stillAtRiskStatus {
+		statuses := make([]types.RiskStatus, 0)
+		for _, status := range types.RiskStatusValues() {
+			if status.(types.RiskStatus).IsStillAtRisk() {
+				statuses = append(statuses, status.(types.RiskStatus))
+			}
+		}
+
+		return statuses, nil
+	}
+
+	status, statusError := types.ParseRiskStatus(value)
+	if statusError != nil {
+		return nil, fmt.Errorf("unknown risk status %q", value)
+	}
+
+	return []types.RiskStatus{status}, nil
+}
+
+func (what *RiskPolicyRule) Matches(risk *types.Risk, status types.RiskStatus) bool {
+	if what.OrHigher {
+		if risk.Severity < what.Severity {
+			return false
+		}
+	} else if risk.Severity != what.Severity {
+		return false
+	}
+
+	if len(what.Statuses) > 0 && !slices.Contains(what.Statuses, status) {
+		return false
+	}
+
+	return !slices.Contains(what.ExcludedStatuses, status)
+}
+
+// Evaluate returns a violation for every rule matching more risks than it allows
+func (what *RiskPolicy) Evaluate(parsedModel *types.Model) []*RiskPolicyViolation {
+	violations := make([]*RiskPolicyViolation, 0)
+	for _, rule := range what.Rules {
+		riskIDs := make([]string, 0)
+		for _, risk := range parsedModel.AllRisks() {
+			if slices.Contains(what.AllowedCategories, strings.ToLower(risk.CategoryId)) {
+				continue
+			}
+
+			if rule.Matches(risk, parsedModel.GetRiskTrackingWithDefault(risk).Status) {
+				riskIDs = append(riskIDs, risk.SyntheticId)
+			}
+		}
+
+		if len(riskIDs) > rule.MaxAllowed {
+			sort.Strings(riskIDs)
+			violations = append(violations, &RiskPolicyViolation{
+				Rule:    rule.Text,
+				Allowed: rule.MaxAllowed,
+				Found:   len(riskIDs),
+				RiskIDs: riskIDs,
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+func TestParseRiskPolicyRule_Any(t *testing.T) {
+	rule, err := ParseRiskPolicyRule("any unchecked critical")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rule.MaxAllowed)
+	assert.Equal(t, types.CriticalSeverity, rule.Severity)
+	assert.False(t, rule.OrHigher)
+	assert.Equal(t, []types.RiskStatus{types.Unchecked}, rule.Statuses)
+	assert.Empty(t, rule.ExcludedStatuses)
+}
+
+func TestParseRiskPolicyRule_MoreThanNotMitigated(t *testing.T) {
+	rule, err := ParseRiskPolicyRule("More than 3 high risks not mitigated")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, rule.MaxAllowed)
+	assert.Equal(t, types.HighSeverity, rule.Severity)
+	assert.Empty(t, rule.Statuses)
+	assert.Equal(t, []types.RiskStatus{types.Mitigated}, rule.ExcludedStatuses)
+}
+
+func TestParseRiskPolicyRule_AtLeastOrHigher(t *testing.T) {
+	rule, err := ParseRiskPolicyRule("at least 2 elevated or higher still-at-risk")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rule.MaxAllowed)
+	assert.Equal(t, types.ElevatedSeverity, rule.Severity)
+	assert.True(t, rule.OrHigher)
+	assert.Equal(t, []types.RiskStatus{types.Unchecked, types.InDiscussion, types.Accepted, types.InProgress}, rule.Statuses)
+}
+
+func TestParseRiskPolicyRule_Invalid(t *testing.T) {
+	for _, text := range []string{"", "some critical", "more than x high", "any high critical", "any high not", "any unknown"} {
+		_, err := ParseRiskPolicyRule(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestRiskPolicy_Evaluate(t *testing.T) {
+	parsedModel := &types.Model{
+		GeneratedRisksByCategory: map[string][]*types.Risk{
+			"rule-a": {
+				{CategoryId: "rule-a", SyntheticId: "rule-a@ta1", Severity: types.CriticalSeverity},
+				{CategoryId: "rule-a", SyntheticId: "rule-a@ta2", Severity: types.CriticalSeverity},
+			},
+			"rule-b": {
+				{CategoryId: "rule-b", SyntheticId: "rule-b@ta1", Severity: types.HighSeverity},
+			},
+		},
+		RiskTracking: map[string]*types.RiskTracking{
+			"rule-a@ta2": {SyntheticRiskId: "rule-a@ta2", Status: types.Mitigated},
+		},
+	}
+
+	policy, err := ParseRiskPolicy([]string{"any unchecked critical", "more than 1 high+ not mitigated", "any medium"}, nil)
+	assert.NoError(t, err)
+
+	violations := policy.Evaluate(parsedModel)
+	assert.Equal(t, []*RiskPolicyViolation{
+		{Rule: "any unchecked critical", Allowed: 0, Found: 1, RiskIDs: []string{"rule-a@ta1"}},
+		{Rule: "more than 1 high+ not mitigated", Allowed: 1, Found: 2, RiskIDs: []string{"rule-a@ta1", "rule-b@ta1"}},
+	}, violations)
+}
+
+func TestRiskPolicy_EvaluateAllowedCategories(t *testing.T) {
+	parsedModel := &types.Model{
+		GeneratedRisksByCategory: map[string][]*types.Risk{
+			"rule-a": {
+				{CategoryId: "rule-a", SyntheticId: "rule-a@ta1", Severity: types.CriticalSeverity},
+			},
+		},
+	}
+
+	policy, err := ParseRiskPolicy([]string{"any critical"}, []string{"Rule-A"})
+	assert.NoError(t, err)
+	assert.Empty(t, policy.Evaluate(parsedModel))
+}
@@ -5,12 +5,17 @@ import (
 	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/threagile/threagile/pkg/input"
 	"github.com/threagile/threagile/pkg/types"
 )
 
+const notFoundInModel = "(not found in model)"
+
+var htmlTagExpression = regexp.MustCompile(`</?[a-zA-Z]+>`)
+
 type ReadResult struct {
 	ModelInput       *input.Model
 	ParsedModel      *types.Model
@@ -19,14 +24,171 @@ type ReadResult struct {
 	CustomRiskRules  types.RiskRules
 }
 
-type explainRiskConfig interface {
+type explainRiskReporter interface {
+	Println(a ...any)
+	Printf(format string, a ...any)
 }
 
-type explainRiskReporter interface {
+type riskExplainer interface {
+	ExplainRisk(parsedModel *types.Model, riskID string) ([]string, error)
+}
+
+func (what ReadResult) ExplainRisk(risk string, reporter explainRiskReporter) error {
+	if what.ParsedModel == nil {
+		return fmt.Errorf("no model loaded")
+	}
+
+	generatedRisk, ok := what.ParsedModel.GeneratedRisksBySyntheticId[strings.ToLower(strings.TrimSpace(risk))]
+	if !ok {
+		return fmt.Errorf("risk %q not found in model (synthetic risk ids are listed in the risks json and excel outputs)", risk)
+	}
+
+	category := what.ParsedModel.GetRiskCategory(generatedRisk.CategoryId)
+	if category == nil {
+		return fmt.Errorf("risk category %q of risk %q not found", generatedRisk.CategoryId, risk)
+	}
+
+	reporter.Printf("Risk: %v\n", generatedRisk.SyntheticId)
+	reporter.Printf("Title: %v\n", removeTags(generatedRisk.Title))
+	reporter.Printf("Severity: %v (likelihood: %v, impact: %v)\n", generatedRisk.Severity, generatedRisk.ExploitationLikelihood, generatedRisk.ExploitationImpact)
+	reporter.Printf("Data breach probability: %v\n", generatedRisk.DataBreachProbability)
+	if generatedRisk.Position != nil {
+		reporter.Printf("Position: %v\n", generatedRisk.Position)
+	}
+	reporter.Println()
+
+	reporter.Println("Risk category:")
+	reporter.Printf("    %v: %v\n", category.ID, category.Title)
+	reporter.Printf("    STRIDE: %v, function: %v, CWE: %v\n", category.STRIDE.Title(), category.Function.Title(), category.CWE)
+	reporter.Printf("    %v\n", removeTags(category.Description))
+	reporter.Println()
+
+	reporter.Println("Affected model elements:")
+	for _, line := range what.explainRiskElements(generatedRisk) {
+		reporter.Printf("    %v\n", line)
+	}
+	reporter.Println()
+
+	reporter.Println("Risk explanation:")
+	printExplanation(reporter, generatedRisk.RiskExplanation)
+	reporter.Println()
+
+	reporter.Println("Rating explanation:")
+	printExplanation(reporter, generatedRisk.RatingExplanation)
+	reporter.Println()
+
+	reporter.Println("Risk tracking:")
+	tracking := what.ParsedModel.GetRiskTracking(generatedRisk)
+	if tracking == nil {
+		reporter.Printf("    status: %v (not tracked)\n", types.Unchecked)
+	} else {
+		reporter.Printf("    status: %v\n", tracking.Status)
+		reporter.Printf("    justification: %v\n", tracking.Justification)
+		reporter.Printf("    ticket: %v\n", tracking.Ticket)
+		reporter.Printf("    checked by: %v\n", tracking.CheckedBy)
+		if !tracking.Date.IsZero() {
+			reporter.Printf("    date: %v\n", tracking.Date.Format("2006-01-02"))
+		}
+	}
+
+	rule := what.riskRule(generatedRisk.CategoryId)
+	explainer, isExplainer := rule.(riskExplainer)
+	if isExplainer {
+		trace, traceError := explainer.ExplainRisk(what.ParsedModel, generatedRisk.SyntheticId)
+		if traceError != nil {
+			return fmt.Errorf("failed to trace script rule %q: %w", generatedRisk.CategoryId, traceError)
+		}
+
+		reporter.Println()
+		reporter.Println("Evaluated condition trace:")
+		printExplanation(reporter, trace)
+	}
+
+	return nil
+}
+
+func (what ReadResult) explainRiskElements(risk *types.Risk) []string {
+	lines := make([]string, 0)
+	if len(risk.MostRelevantTechnicalAssetId) > 0 {
+		title := notFoundInModel
+		if techAsset, ok := what.ParsedModel.TechnicalAssets[risk.MostRelevantTechnicalAssetId]; ok {
+			title = techAsset.Title
+		}
+		lines = append(lines, fmt.Sprintf("technical asset %q: %v", risk.MostRelevantTechnicalAssetId, title))
+	}
+
+	if len(risk.MostRelevantCommunicationLinkId) > 0 {
+		title := notFoundInModel
+		if link, ok := what.ParsedModel.CommunicationLinks[risk.MostRelevantCommunicationLinkId]; ok {
+			title = fmt.Sprintf("%v (%v -> %v)", link.Title, link.SourceId, link.TargetId)
+		}
+		lines = append(lines, fmt.Sprintf("communication link %q: %v", risk.MostRelevantCommunicationLinkId, title))
+	}
+
+	if len(risk.MostRelevantTrustBoundaryId) > 0 {
+		title := notFoundInModel
+		if trustBoundary, ok := what.ParsedModel.TrustBoundaries[risk.MostRelevantTrustBoundaryId]; ok {
+			title = trustBoundary.Title
+		}
+		lines = append(lines, fmt.Sprintf("trust boundary %q: %v", risk.MostRelevantTrustBoundaryId, title))
+	}
+
+	if len(risk.MostRelevantSharedRuntimeId) > 0 {
+		title := notFoundInModel
+		if sharedRuntime, ok := what.ParsedModel.SharedRuntimes[risk.MostRelevantSharedRuntimeId]; ok {
+			title = sharedRuntime.Title
+		}
+		lines = append(lines, fmt.Sprintf("shared runtime %q: %v", risk.MostRelevantSharedRuntimeId, title))
+	}
+
+	if len(risk.MostRelevantDataAssetId) > 0 {
+		title := notFoundInModel
+		if dataAsset, ok := what.ParsedModel.DataAssets[risk.MostRelevantDataAssetId]; ok {
+			title = dataAsset.Title
+		}
+		lines = append(lines, fmt.Sprintf("data asset %q: %v", risk.MostRelevantDataAssetId, title))
+	}
+
+	if len(risk.DataBreachTechnicalAssetIDs) > 0 {
+		lines = append(lines, fmt.Sprintf("data breach technical assets: %v", strings.Join(risk.DataBreachTechnicalAssetIDs, ", ")))
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "none")
+	}
+
+	return lines
 }
 
-func (what ReadResult) ExplainRisk(cfg explainRiskConfig, risk string, reporter explainRiskReporter) error {
-	return fmt.Errorf("not implemented")
+func (what ReadResult) riskRule(categoryID string) types.RiskRule {
+	for id, rule := range what.CustomRiskRules {
+		if strings.EqualFold(id, categoryID) {
+			return rule
+		}
+	}
+
+	for id, rule := range what.BuiltinRiskRules {
+		if strings.EqualFold(id, categoryID) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+func printExplanation(reporter explainRiskReporter, lines []string) {
+	if len(lines) == 0 {
+		reporter.Println("    none recorded")
+		return
+	}
+
+	for _, line := range lines {
+		reporter.Printf("    %v\n", removeTags(line))
+	}
+}
+
+func removeTags(text string) string {
+	return htmlTagExpression.ReplaceAllString(text, "")
 }
 
 // TODO: consider about splitting this function into smaller ones for better reusability
@@ -76,24 +238,29 @@ type configReader interface {
 	GetProgressReporter() types.ProgressReporter
 }
 
-func ReadAndAnalyzeModel(config configReader, builtinRiskRules types.RiskRules, progressReporter types.ProgressReporter) (*ReadResult, error) {
+func ReadAndAnalyzeModel(config configReader, builtinRiskRules types.RiskRules, customRiskRules types.RiskRules, progressReporter types.ProgressReporter) (*ReadResult, error) {
 	progressReporter.Infof("Writing into output directory: %v", config.GetOutputFolder())
-	progressReporter.Infof("Parsing model: %v", config.GetInputFile())
 
-	customRiskRules := LoadCustomRiskRules(config.GetPluginFolder(), config.GetRiskRulePlugins(), progressReporter)
+	result, analysisError := ReadAndAnalyzeModelFile(config.GetInputFile(), config, builtinRiskRules, customRiskRules, progressReporter)
+	if analysisError == nil {
+		writeToFile("model yaml", result.ParsedModel, config.GetImportedInputFile(), progressReporter)
+	}
+
+	return result, analysisError
+}
+
+// ReadAndAnalyzeModelFile reads and analyzes the given model file instead of the input file of the config; the custom
+// risk rules are loaded by the caller (see LoadCustomRiskRules), so they can be shared by several models
+func ReadAndAnalyzeModelFile(filename string, config configReader, builtinRiskRules types.RiskRules, customRiskRules types.RiskRules, progressReporter types.ProgressReporter) (*ReadResult, error) {
+	progressReporter.Infof("Parsing model: %v", filename)
 
 	modelInput := new(input.Model).Defaults()
-	loadError := modelInput.Load(config.GetInputFile())
+	loadError := modelInput.Load(filename)
 	if loadError != nil {
 		return nil, fmt.Errorf("unable to load model yaml: %w", loadError)
 	}
 
-	result, analysisError := AnalyzeModel(modelInput, config, builtinRiskRules, customRiskRules, progressReporter)
-	if analysisError == nil {
-		writeToFile("model yaml", result.ParsedModel, config.GetImportedInputFile(), progressReporter)
-	}
-
-	return result, analysisError
+	return AnalyzeModel(modelInput, config, builtinRiskRules, customRiskRules, progressReporter)
 }
 
 func AnalyzeModel(modelInput *input.Model, config configReader, builtinRiskRules types.RiskRules, customRiskRules types.RiskRules, progressReporter types.ProgressReporter) (*ReadResult, error) {
@@ -171,6 +338,7 @@ func applyRiskGeneration(parsedModel *types.Model, rules types.RiskRules,
 	for _, category := range parsedModel.SortedRiskCategories() {
 		someRisks := parsedModel.SortedRisksOfCategory(category)
 		for _, risk := range someRisks {
+			risk.Position = parsedModel.GetRiskPosition(risk)
 			parsedModel.GeneratedRisksBySyntheticId[strings.ToLower(risk.SyntheticId)] = risk
 		}
 	}
@@ -0,0 +1,218 @@
+package model
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/risks/script"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// RuleTest is a regression test for a risk rule: a model snippet along with the risks the rule is expected to generate
+// for it
+type RuleTest struct {
+	Name     string          `json:"name,omitempty" yaml:"name,omitempty"`
+	Rule     string          `json:"rule,omitempty" yaml:"rule,omitempty"`
+	Model    map[string]any  `json:"model,omitempty" yaml:"model,omitempty"`
+	Expected []*ExpectedRisk `json:"expected,omitempty" yaml:"expected,omitempty"`
+	File     string          `json:"-" yaml:"-"`
+}
+
+// ExpectedRisk identifies a risk by its synthetic id; severity and title are only compared if given
+type ExpectedRisk struct {
+	SyntheticId string `json:"synthetic_id,omitempty" yaml:"synthetic_id,omitempty"`
+	Severity    string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`
+}
+
+type RuleTestResult struct {
+	Test        *RuleTest
+	Differences []string
+	Error       error
+}
+
+func (what RuleTestResult) Passed() bool {
+	return what.Error == nil && len(what.Differences) == 0
+}
+
+// FindRuleTests loads the rule tests of the given files and directories (including subdirectories), sorted by file name
+func FindRuleTests(paths ...string) ([]*RuleTest, error) {
+	filenames := make([]string, 0)
+	for _, path := range paths {
+		walkError := filepath.WalkDir(path, func(filename string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !entry.IsDir() && (filename == path || script.IsRuleTestFile(filename)) {
+				filenames = append(filenames, filename)
+			}
+
+			return nil
+		})
+
+		if walkError != nil {
+			return nil, fmt.Errorf("unable to find rule tests in %q: %w", path, walkError)
+		}
+	}
+
+	sort.Strings(filenames)
+
+	tests := make([]*RuleTest, 0)
+	for _, filename := range filenames {
+		test, loadError := LoadRuleTest(filename)
+		if loadError != nil {
+			return nil, loadError
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests, nil
+}
+
+func LoadRuleTest(filename string) (*RuleTest, error) {
+	data, readError := os.ReadFile(filepath.Clean(filename))
+	if readError != nil {
+		return nil, fmt.Errorf("unable to read rule test: %w", readError)
+	}
+
+	test := new(RuleTest)
+	unmarshalError := yaml.Unmarshal(data, test)
+	if unmarshalError != nil {
+		return nil, types.NewPositionError(&types.Position{File: filename}, fmt.Errorf("unable to parse rule test: %w", unmarshalError))
+	}
+
+	if len(test.Rule) == 0 {
+		return nil, fmt.Errorf("rule test %q names no rule to test", filename)
+	}
+
+	test.File = filename
+	if len(test.Name) == 0 {
+		test.Name = filepath.Base(filename)
+	}
+
+	return test, nil
+}
+
+// Run generates the risks of the rule under test for the model snippet of the test and compares them to the expected
+// ones; rules are looked up by risk category id in the given rules
+func (what *RuleTest) Run(config technologyMapConfigReader, builtinRiskRules types.RiskRules, customRiskRules types.RiskRules, progressReporter types.ProgressReporter) RuleTestResult {
+	result := RuleTestResult{Test: what}
+
+	rule, ok := customRiskRules[what.Rule]
+	if !ok {
+		rule, ok = builtinRiskRules[what.Rule]
+	}
+
+	if !ok {
+		result.Error = fmt.Errorf("unknown risk rule %q", what.Rule)
+		return result
+	}
+
+	parsedModel, parseError := what.parseModel(config, builtinRiskRules, customRiskRules)
+	if parseError != nil {
+		result.Error = parseError
+		return result
+	}
+
+	applyRAA(parsedModel, progressReporter)
+	parsedModel.AddToListOfSupportedTags(rule.SupportedTags())
+
+	generatedRisks, riskError := rule.GenerateRisks(parsedModel)
+	if riskError != nil {
+		result.Error = fmt.Errorf("unable to generate risks: %w", riskError)
+		return result
+	}
+
+	result.Differences = what.compare(generatedRisks)
+	return result
+}
+
+func (what *RuleTest) parseModel(config technologyMapConfigReader, builtinRiskRules types.RiskRules, customRiskRules types.RiskRules) (*types.Model, error) {
+	modelYaml, marshalError := yaml.Marshal(what.Model)
+	if marshalError != nil {
+		return nil, fmt.Errorf("unable to read model of rule test: %w", marshalError)
+	}
+
+	modelInput := new(input.Model).Defaults()
+	unmarshalError := yaml.Unmarshal(modelYaml, modelInput)
+	if unmarshalError != nil {
+		return nil, fmt.Errorf("unable to parse model of rule test: %w", unmarshalError)
+	}
+
+	for _, includeFile := range modelInput.Includes {
+		mergeError := modelInput.Merge(filepath.Dir(what.File), includeFile)
+		if mergeError != nil {
+			return nil, fmt.Errorf("unable to merge model include %q: %w", includeFile, mergeError)
+		}
+	}
+
+	// most rules don't care, so don't make every test state it
+	if len(modelInput.BusinessCriticality) == 0 {
+		modelInput.BusinessCriticality = types.Important.String()
+	}
+
+	parsedModel, parseError := ParseModel(config, modelInput, builtinRiskRules, customRiskRules)
+	if parseError != nil {
+		return nil, fmt.Errorf("unable to parse model of rule test: %w", parseError)
+	}
+
+	return parsedModel, nil
+}
+
+// compare lists the differences between expected and generated risks in diff style, i.e. expected values prefixed
+// with '-' and generated ones prefixed with '+'
+func (what *RuleTest) compare(generatedRisks []*types.Risk) []string {
+	generated := make(map[string]*types.Risk)
+	for _, risk := range generatedRisks {
+		generated[risk.SyntheticId] = risk
+	}
+
+	expected := make(map[string]*ExpectedRisk)
+	for _, risk := range what.Expected {
+		expected[risk.SyntheticId] = risk
+	}
+
+	differences := make([]string, 0)
+	for _, risk := range what.Expected {
+		generatedRisk, ok := generated[risk.SyntheticId]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("- %v", risk.SyntheticId))
+			continue
+		}
+
+		fieldDifferences := make([]string, 0)
+		if len(risk.Severity) > 0 && !strings.EqualFold(risk.Severity, generatedRisk.Severity.String()) {
+			fieldDifferences = append(fieldDifferences,
+				fmt.Sprintf("    - severity: %v", risk.Severity),
+				fmt.Sprintf("    + severity: %v", generatedRisk.Severity))
+		}
+
+		if len(risk.Title) > 0 && risk.Title != generatedRisk.Title {
+			fieldDifferences = append(fieldDifferences,
+				fmt.Sprintf("    - title: %v", risk.Title),
+				fmt.Sprintf("    + title: %v", generatedRisk.Title))
+		}
+
+		if len(fieldDifferences) > 0 {
+			differences = append(differences, fmt.Sprintf("  %v", risk.SyntheticId))
+			differences = append(differences, fieldDifferences...)
+		}
+	}
+
+	for _, id := range sortedKeys(generated) {
+		if _, ok := expected[id]; !ok {
+			differences = append(differences, fmt.Sprintf("+ %v (severity: %v, title: %v)", id, generated[id].Severity, generated[id].Title))
+		}
+	}
+
+	return differences
+}
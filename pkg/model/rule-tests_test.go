@@ -0,0 +1,170 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+const testRuleTestYAML = `
+name: internet facing assets
+rule: test-rule
+model:
+  technical_assets:
+    Web Server:
+      id: web-server
+      type: process
+      usage: business
+      size: system
+      technology: web-server
+      machine: virtual
+      encryption: none
+      internet: true
+      confidentiality: internal
+      integrity: important
+      availability: important
+    Database:
+      id: database
+      type: datastore
+      usage: business
+      size: component
+      technology: database
+      machine: virtual
+      encryption: none
+      confidentiality: confidential
+      integrity: critical
+      availability: critical
+expected:
+`
+
+type testRule struct{}
+
+func (what *testRule) Category() *types.RiskCategory {
+	return &types.RiskCategory{ID: "test-rule", Title: "Test Rule"}
+}
+
+func (what *testRule) SupportedTags() []string {
+	return []string{}
+}
+
+func (what *testRule) GenerateRisks(parsedModel *types.Model) ([]*types.Risk, error) {
+	risks := make([]*types.Risk, 0)
+	for _, id := range sortedKeys(parsedModel.TechnicalAssets) {
+		severity := types.MediumSeverity
+		if parsedModel.TechnicalAssets[id].Internet {
+			severity = types.ElevatedSeverity
+		}
+
+		risks = append(risks, &types.Risk{
+			CategoryId:                   "test-rule",
+			Severity:                     severity,
+			Title:                        "Test Rule at " + parsedModel.TechnicalAssets[id].Title,
+			SyntheticId:                  "test-rule@" + id,
+			MostRelevantTechnicalAssetId: id,
+		})
+	}
+
+	return risks, nil
+}
+
+type testProgressReporter struct{}
+
+func (what testProgressReporter) Info(...any)           {}
+func (what testProgressReporter) Warn(...any)           {}
+func (what testProgressReporter) Error(...any)          {}
+func (what testProgressReporter) Infof(string, ...any)  {}
+func (what testProgressReporter) Warnf(string, ...any)  {}
+func (what testProgressReporter) Errorf(string, ...any) {}
+
+func writeRuleTest(t *testing.T, dir string, name string, expected string) string {
+	t.Helper()
+
+	filename := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0700))
+	require.NoError(t, os.WriteFile(filename, []byte(testRuleTestYAML+expected), 0600))
+
+	return filename
+}
+
+func runRuleTest(t *testing.T, expected string) RuleTestResult {
+	t.Helper()
+
+	test, err := LoadRuleTest(writeRuleTest(t, t.TempDir(), "test-rule.test.yaml", expected))
+	require.NoError(t, err)
+
+	rules := types.RiskRules{"test-rule": new(testRule)}
+	return test.Run(&mockConfig{}, make(types.RiskRules), rules, testProgressReporter{})
+}
+
+func TestRuleTestPass(t *testing.T) {
+	result := runRuleTest(t, `
+  - synthetic_id: test-rule@database
+    severity: medium
+  - synthetic_id: test-rule@web-server
+    severity: elevated
+    title: Test Rule at Web Server
+`)
+
+	assert.NoError(t, result.Error)
+	assert.Empty(t, result.Differences)
+	assert.True(t, result.Passed())
+	assert.Equal(t, "internet facing assets", result.Test.Name)
+}
+
+func TestRuleTestDifferences(t *testing.T) {
+	result := runRuleTest(t, `
+  - synthetic_id: test-rule@web-server
+    severity: medium
+  - synthetic_id: test-rule@missing
+`)
+
+	assert.NoError(t, result.Error)
+	assert.False(t, result.Passed())
+	assert.Equal(t, []string{
+		"  test-rule@web-server",
+		"    - severity: medium",
+		"    + severity: elevated",
+		"- test-rule@missing",
+		"+ test-rule@database (severity: medium, title: Test Rule at Database)",
+	}, result.Differences)
+}
+
+func TestRuleTestUnknownRule(t *testing.T) {
+	test := &RuleTest{Name: "unknown", Rule: "unknown-rule"}
+
+	result := test.Run(&mockConfig{}, make(types.RiskRules), make(types.RiskRules), testProgressReporter{})
+
+	require.Error(t, result.Error)
+	assert.Contains(t, result.Error.Error(), "unknown-rule")
+	assert.False(t, result.Passed())
+}
+
+func TestFindRuleTests(t *testing.T) {
+	dir := t.TempDir()
+	writeRuleTest(t, dir, "b.test.yaml", "")
+	writeRuleTest(t, dir, "nested/a.test.yml", "")
+	writeRuleTest(t, dir, "rule.yaml", "")
+	explicit := writeRuleTest(t, t.TempDir(), "explicit.yaml", "")
+
+	tests, err := FindRuleTests(dir, explicit)
+
+	require.NoError(t, err)
+	require.Len(t, tests, 3)
+	assert.Equal(t, filepath.Join(dir, "b.test.yaml"), tests[0].File)
+	assert.Equal(t, filepath.Join(dir, "nested", "a.test.yml"), tests[1].File)
+	assert.Equal(t, explicit, tests[2].File)
+}
+
+func TestLoadRuleTestWithoutRule(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "empty.test.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("name: no rule\n"), 0600))
+
+	_, err := LoadRuleTest(filename)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,524 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+type ValidationSeverity string
+
+const (
+	ValidationError   ValidationSeverity = "error"
+	ValidationWarning ValidationSeverity = "warning"
+	ValidationInfo    ValidationSeverity = "info"
+)
+
+type ValidationProblem struct {
+	Severity ValidationSeverity `json:"severity" yaml:"severity"`
+	Check    string             `json:"check" yaml:"check"`
+	Message  string             `json:"message" yaml:"message"`
+	Position *types.Position    `json:"position,omitempty" yaml:"position,omitempty"`
+}
+
+func (what *ValidationProblem) String() string {
+	if what.Position == nil {
+		return fmt.Sprintf("%v: %v [%v]", what.Severity, what.Message, what.Check)
+	}
+
+	return fmt.Sprintf("%v: %v: %v [%v]", what.Position, what.Severity, what.Message, what.Check)
+}
+
+type ValidationResult struct {
+	Problems []*ValidationProblem `json:"problems" yaml:"problems"`
+}
+
+func (what *ValidationResult) Count(severity ValidationSeverity) int {
+	count := 0
+	for _, problem := range what.Problems {
+		if problem.Severity == severity {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (what *ValidationResult) HasErrors() bool {
+	return what.Count(ValidationError) > 0
+}
+
+// ValidateModelFile loads a model including all of its includes and reports all problems found in it
+func ValidateModelFile(filename string, config technologyMapConfigReader) *ValidationResult {
+	modelInput := new(input.Model).Defaults()
+	loadError := modelInput.Load(filename)
+	if loadError != nil {
+		result := &ValidationResult{Problems: make([]*ValidationProblem, 0)}
+		result.addLoadError(loadError)
+		return result
+	}
+
+	return ValidateModel(modelInput, config)
+}
+
+// ValidateModel lints a loaded model without generating risks. Unlike ParseModel it does not stop at the first problem.
+func ValidateModel(modelInput *input.Model, config technologyMapConfigReader) *ValidationResult {
+	validator := &modelValidator{
+		modelInput: modelInput,
+		result:     &ValidationResult{Problems: make([]*ValidationProblem, 0)},
+		usedTags:   make(map[string]bool),
+	}
+
+	validator.technologies = make(types.TechnologyMap)
+	technologiesLoadError := validator.technologies.LoadWithConfig(config, "technologies.yaml")
+	if technologiesLoadError != nil {
+		validator.add(ValidationError, "technologies", nil, "error loading technologies: %v", technologiesLoadError)
+		return validator.result
+	}
+
+	validator.validate()
+
+	if !validator.result.HasErrors() {
+		_, parseError := ParseModel(config, modelInput, make(types.RiskRules), make(types.RiskRules))
+		if parseError != nil {
+			var positionError *types.PositionError
+			if errors.As(parseError, &positionError) {
+				validator.add(ValidationError, "parse", positionError.Position, "%v", positionError.Err)
+			} else {
+				validator.add(ValidationError, "parse", nil, "%v", parseError)
+			}
+		}
+	}
+
+	validator.result.sort()
+	return validator.result
+}
+
+var yamlLineExpression = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+func (what *ValidationResult) addLoadError(loadError error) {
+	var position *types.Position
+	var positionError *types.PositionError
+	if errors.As(loadError, &positionError) {
+		position = positionError.Position
+	}
+
+	messages := []string{loadError.Error()}
+	var typeError *yaml.TypeError
+	if errors.As(loadError, &typeError) {
+		messages = typeError.Errors
+	} else if errors.As(loadError, &positionError) {
+		messages = []string{positionError.Err.Error()}
+	}
+
+	for _, message := range messages {
+		problem := &ValidationProblem{Severity: ValidationError, Check: "yaml", Message: message, Position: position}
+		if position != nil {
+			parts := yamlLineExpression.FindStringSubmatch(strings.TrimPrefix(message, "unable to parse model yaml: "))
+			if len(parts) == 3 {
+				line, _ := strconv.Atoi(parts[1])
+				problem.Position = &types.Position{File: position.File, Line: line}
+				problem.Message = parts[2]
+			}
+		}
+
+		what.Problems = append(what.Problems, problem)
+	}
+}
+
+func (what *ValidationResult) sort() {
+	sort.SliceStable(what.Problems, func(i, j int) bool {
+		left, right := what.Problems[i].Position, what.Problems[j].Position
+		switch {
+		case left == nil || right == nil:
+			return left == nil && right != nil
+		case left.File != right.File:
+			return left.File < right.File
+		case left.Line != right.Line:
+			return left.Line < right.Line
+		default:
+			return left.Column < right.Column
+		}
+	})
+}
+
+type modelValidator struct {
+	modelInput   *input.Model
+	technologies types.TechnologyMap
+	result       *ValidationResult
+
+	dataAssetIds       map[string]string
+	technicalAssetIds  map[string]string
+	trustBoundaryIds   map[string]string
+	sharedRuntimeIds   map[string]string
+	communicationLinks map[string]string
+	usedDataAssets     map[string]bool
+	usedTags           map[string]bool
+}
+
+func (what *modelValidator) add(severity ValidationSeverity, check string, position *types.Position, format string, a ...any) {
+	what.result.Problems = append(what.result.Problems, &ValidationProblem{
+		Severity: severity,
+		Check:    check,
+		Message:  fmt.Sprintf(format, a...),
+		Position: position,
+	})
+}
+
+func (what *modelValidator) position(path ...string) *types.Position {
+	return what.modelInput.Position(path...)
+}
+
+func (what *modelValidator) validate() {
+	checkValue(what, types.ParseCriticality, what.modelInput.BusinessCriticality, "business_criticality of the model", "business_criticality")
+	if len(what.modelInput.Date) > 0 {
+		if _, dateError := time.Parse("2006-01-02", what.modelInput.Date); dateError != nil {
+			what.add(ValidationError, "unknown-value", what.position("date"), "unable to parse 'date' value of model file (expected format: '2006-01-02'): %v", what.modelInput.Date)
+		}
+	}
+
+	what.dataAssetIds = what.collectIds("data_assets", sortedKeys(what.modelInput.DataAssets), func(title string) string { return what.modelInput.DataAssets[title].ID })
+	what.technicalAssetIds = what.collectIds("technical_assets", sortedKeys(what.modelInput.TechnicalAssets), func(title string) string { return what.modelInput.TechnicalAssets[title].ID })
+	what.trustBoundaryIds = what.collectIds("trust_boundaries", sortedKeys(what.modelInput.TrustBoundaries), func(title string) string { return what.modelInput.TrustBoundaries[title].ID })
+	what.sharedRuntimeIds = what.collectIds("shared_runtimes", sortedKeys(what.modelInput.SharedRuntimes), func(title string) string { return what.modelInput.SharedRuntimes[title].ID })
+	what.communicationLinks = what.collectCommunicationLinks()
+	what.usedDataAssets = make(map[string]bool)
+
+	what.validateDataAssets()
+	what.validateTechnicalAssets()
+	what.validateTrustBoundaries()
+	what.validateSharedRuntimes()
+	what.validateCustomRiskCategories()
+	what.validateRiskTracking()
+	what.validateTags()
+	what.validateUnusedDataAssets()
+}
+
+// collectIds checks id syntax and uniqueness of one kind of model elements and maps their ids to their titles
+func (what *modelValidator) collectIds(kind string, titles []string, idOf func(string) string) map[string]string {
+	ids := make(map[string]string)
+	lowerTitles := make(map[string]string)
+	for _, title := range titles {
+		id := idOf(title)
+		position := what.position(kind, title, "id")
+		if idError := checkIdSyntax(id); idError != nil {
+			what.add(ValidationError, "invalid-id", position, "%v of %v %q", idError, kindName(kind), title)
+		}
+
+		if other, exists := ids[id]; exists {
+			what.add(ValidationError, "duplicate-id", position, "duplicate id %q used by %v %q and %q", id, kindName(kind), other, title)
+		} else {
+			ids[id] = title
+		}
+
+		if other, exists := lowerTitles[strings.ToLower(title)]; exists {
+			what.add(ValidationWarning, "duplicate-title", what.position(kind, title), "%v titles %q and %q only differ in case", kindName(kind), other, title)
+		} else {
+			lowerTitles[strings.ToLower(title)] = title
+		}
+	}
+
+	return ids
+}
+
+func (what *modelValidator) collectCommunicationLinks() map[string]string {
+	links := make(map[string]string)
+	for _, title := range sortedKeys(what.modelInput.TechnicalAssets) {
+		asset := what.modelInput.TechnicalAssets[title]
+		for _, linkTitle := range sortedKeys(asset.CommunicationLinks) {
+			linkId, _ := createDataFlowId(asset.ID, linkTitle)
+			if other, exists := links[linkId]; exists {
+				what.add(ValidationError, "duplicate-id", what.position("technical_assets", title, "communication_links", linkTitle),
+					"communication links %q and %q of technical asset %q result in the same id %q", other, linkTitle, title, linkId)
+				continue
+			}
+
+			links[linkId] = linkTitle
+		}
+	}
+
+	return links
+}
+
+func (what *modelValidator) validateDataAssets() {
+	for _, title := range sortedKeys(what.modelInput.DataAssets) {
+		asset := what.modelInput.DataAssets[title]
+		where := fmt.Sprintf("data asset %q", title)
+		path := []string{"data_assets", title}
+
+		checkValue(what, types.ParseUsage, asset.Usage, where, append(path, "usage")...)
+		checkValue(what, types.ParseQuantity, asset.Quantity, where, append(path, "quantity")...)
+		checkValue(what, types.ParseConfidentiality, asset.Confidentiality, where, append(path, "confidentiality")...)
+		checkValue(what, types.ParseCriticality, asset.Integrity, where, append(path, "integrity")...)
+		checkValue(what, types.ParseCriticality, asset.Availability, where, append(path, "availability")...)
+		what.checkTags(asset.Tags, where, append(path, "tags")...)
+	}
+}
+
+func (what *modelValidator) validateTechnicalAssets() {
+	for _, title := range sortedKeys(what.modelInput.TechnicalAssets) {
+		asset := what.modelInput.TechnicalAssets[title]
+		where := fmt.Sprintf("technical asset %q", title)
+		path := []string{"technical_assets", title}
+
+		checkValue(what, types.ParseUsage, asset.Usage, where, append(path, "usage")...)
+		checkValue(what, types.ParseTechnicalAssetType, asset.Type, where, append(path, "type")...)
+		checkValue(what, types.ParseTechnicalAssetSize, asset.Size, where, append(path, "size")...)
+		checkValue(what, types.ParseEncryptionStyle, asset.Encryption, where, append(path, "encryption")...)
+		checkValue(what, types.ParseTechnicalAssetMachine, asset.Machine, where, append(path, "machine")...)
+		checkValue(what, types.ParseConfidentiality, asset.Confidentiality, where, append(path, "confidentiality")...)
+		checkValue(what, types.ParseCriticality, asset.Integrity, where, append(path, "integrity")...)
+		checkValue(what, types.ParseCriticality, asset.Availability, where, append(path, "availability")...)
+		for n, dataFormat := range asset.DataFormatsAccepted {
+			checkValue(what, types.ParseDataFormat, dataFormat, where, append(path, "data_formats_accepted", strconv.Itoa(n))...)
+		}
+
+		if len(asset.Technology) > 0 && what.technologies.Get(asset.Technology) == nil {
+			what.add(ValidationError, "unknown-value", what.position(append(path, "technology")...), "unknown 'technology' value of %v: %v", where, asset.Technology)
+		}
+		for n, technology := range asset.Technologies {
+			if what.technologies.Get(technology) == nil {
+				what.add(ValidationError, "unknown-value", what.position(append(path, "technologies", strconv.Itoa(n))...), "unknown 'technologies' value of %v: %v", where, technology)
+			}
+		}
+
+		what.checkReferences(what.dataAssetIds, "data asset", asset.DataAssetsProcessed, where, append(path, "data_assets_processed")...)
+		what.checkReferences(what.dataAssetIds, "data asset", asset.DataAssetsStored, where, append(path, "data_assets_stored")...)
+		what.markDataAssetsUsed(asset.DataAssetsProcessed, asset.DataAssetsStored)
+		what.checkTags(asset.Tags, where, append(path, "tags")...)
+
+		for _, linkTitle := range sortedKeys(asset.CommunicationLinks) {
+			what.validateCommunicationLink(title, asset, linkTitle, asset.CommunicationLinks[linkTitle])
+		}
+	}
+}
+
+func (what *modelValidator) validateCommunicationLink(assetTitle string, asset input.TechnicalAsset, title string, link input.CommunicationLink) {
+	where := fmt.Sprintf("communication link %q of technical asset %q", title, assetTitle)
+	path := []string{"technical_assets", assetTitle, "communication_links", title}
+
+	checkValue(what, types.ParseAuthentication, link.Authentication, where, append(path, "authentication")...)
+	checkValue(what, types.ParseAuthorization, link.Authorization, where, append(path, "authorization")...)
+	checkValue(what, types.ParseUsage, link.Usage, where, append(path, "usage")...)
+	checkValue(what, types.ParseProtocol, link.Protocol, where, append(path, "protocol")...)
+
+	if _, exists := what.technicalAssetIds[link.Target]; !exists {
+		what.add(ValidationError, "missing-reference", what.position(append(path, "target")...), "missing target technical asset %q of %v", link.Target, where)
+	} else if link.Target == asset.ID {
+		what.add(ValidationWarning, "self-reference", what.position(append(path, "target")...), "%v targets its own technical asset", where)
+	}
+
+	what.checkReferences(what.dataAssetIds, "data asset", link.DataAssetsSent, where, append(path, "data_assets_sent")...)
+	what.checkReferences(what.dataAssetIds, "data asset", link.DataAssetsReceived, where, append(path, "data_assets_received")...)
+	what.markDataAssetsUsed(link.DataAssetsSent, link.DataAssetsReceived)
+	what.checkTags(link.Tags, where, append(path, "tags")...)
+}
+
+func (what *modelValidator) validateTrustBoundaries() {
+	assetBoundaries := make(map[string]string)
+	parentBoundaries := make(map[string]string)
+	for _, title := range sortedKeys(what.modelInput.TrustBoundaries) {
+		boundary := what.modelInput.TrustBoundaries[title]
+		where := fmt.Sprintf("trust boundary %q", title)
+		path := []string{"trust_boundaries", title}
+
+		checkValue(what, types.ParseTrustBoundary, boundary.Type, where, append(path, "type")...)
+		what.checkTags(boundary.Tags, where, append(path, "tags")...)
+
+		for n, assetId := range boundary.TechnicalAssetsInside {
+			assetId = strings.ToLower(assetId)
+			position := what.position(append(path, "technical_assets_inside", strconv.Itoa(n))...)
+			if _, exists := what.technicalAssetIds[assetId]; !exists {
+				what.add(ValidationError, "missing-reference", position, "missing referenced technical asset %q at %v", assetId, where)
+				continue
+			}
+
+			if other, exists := assetBoundaries[assetId]; exists {
+				what.add(ValidationError, "multiple-trust-boundaries", position, "technical asset %q is modeled in trust boundaries %q and %q", assetId, other, title)
+				continue
+			}
+
+			assetBoundaries[assetId] = title
+		}
+
+		for n, boundaryId := range boundary.TrustBoundariesNested {
+			position := what.position(append(path, "trust_boundaries_nested", strconv.Itoa(n))...)
+			if _, exists := what.trustBoundaryIds[boundaryId]; !exists {
+				what.add(ValidationError, "missing-reference", position, "missing referenced nested trust boundary %q at %v", boundaryId, where)
+				continue
+			}
+
+			if boundaryId == boundary.ID {
+				what.add(ValidationError, "self-reference", position, "%v is nested into itself", where)
+				continue
+			}
+
+			if other, exists := parentBoundaries[boundaryId]; exists {
+				what.add(ValidationWarning, "multiple-trust-boundaries", position, "trust boundary %q is nested into trust boundaries %q and %q", boundaryId, other, title)
+				continue
+			}
+
+			parentBoundaries[boundaryId] = title
+		}
+	}
+
+	for _, title := range sortedKeys(what.modelInput.TechnicalAssets) {
+		asset := what.modelInput.TechnicalAssets[title]
+		if _, inside := assetBoundaries[strings.ToLower(asset.ID)]; !inside && !asset.OutOfScope {
+			what.add(ValidationInfo, "missing-trust-boundary", what.position("technical_assets", title), "technical asset %q is not inside any trust boundary", title)
+		}
+	}
+}
+
+func (what *modelValidator) validateSharedRuntimes() {
+	for _, title := range sortedKeys(what.modelInput.SharedRuntimes) {
+		runtime := what.modelInput.SharedRuntimes[title]
+		where := fmt.Sprintf("shared runtime %q", title)
+		path := []string{"shared_runtimes", title}
+
+		what.checkReferences(what.technicalAssetIds, "technical asset", runtime.TechnicalAssetsRunning, where, append(path, "technical_assets_running")...)
+		what.checkTags(runtime.Tags, where, append(path, "tags")...)
+	}
+}
+
+func (what *modelValidator) validateCustomRiskCategories() {
+	categoryIds := make(map[string]bool)
+	for _, category := range what.modelInput.CustomRiskCategories {
+		where := fmt.Sprintf("individual risk category %q", category.Title)
+		path := []string{"custom_risk_categories", category.ID}
+
+		checkValue(what, types.ParseRiskFunction, category.Function, where, append(path, "function")...)
+		checkValue(what, types.ParseSTRIDE, category.STRIDE, where, append(path, "stride")...)
+		if idError := checkIdSyntax(category.ID); idError != nil {
+			what.add(ValidationError, "invalid-id", what.position(append(path, "id")...), "%v of %v", idError, where)
+		}
+
+		if categoryIds[category.ID] {
+			what.add(ValidationError, "duplicate-id", what.position(path...), "duplicate id used: %v", category.ID)
+		}
+		categoryIds[category.ID] = true
+
+		for _, title := range sortedKeys(category.RisksIdentified) {
+			what.validateRiskIdentified(category.ID, title, category.RisksIdentified[title])
+		}
+	}
+}
+
+func (what *modelValidator) validateRiskIdentified(categoryId string, title string, risk input.RiskIdentified) {
+	where := fmt.Sprintf("individual risk instance %q", title)
+	path := []string{"custom_risk_categories", categoryId, "risks_identified", title}
+
+	checkValue(what, types.ParseRiskSeverity, risk.Severity, where, append(path, "severity")...)
+	checkValue(what, types.ParseRiskExploitationLikelihood, risk.ExploitationLikelihood, where, append(path, "exploitation_likelihood")...)
+	checkValue(what, types.ParseRiskExploitationImpact, risk.ExploitationImpact, where, append(path, "exploitation_impact")...)
+	checkValue(what, types.ParseDataBreachProbability, risk.DataBreachProbability, where, append(path, "data_breach_probability")...)
+
+	what.checkReference(what.dataAssetIds, "data asset", risk.MostRelevantDataAsset, where, append(path, "most_relevant_data_asset")...)
+	what.checkReference(what.technicalAssetIds, "technical asset", risk.MostRelevantTechnicalAsset, where, append(path, "most_relevant_technical_asset")...)
+	what.checkReference(what.communicationLinks, "communication link", risk.MostRelevantCommunicationLink, where, append(path, "most_relevant_communication_link")...)
+	what.checkReference(what.trustBoundaryIds, "trust boundary", risk.MostRelevantTrustBoundary, where, append(path, "most_relevant_trust_boundary")...)
+	what.checkReference(what.sharedRuntimeIds, "shared runtime", risk.MostRelevantSharedRuntime, where, append(path, "most_relevant_shared_runtime")...)
+	what.checkReferences(what.technicalAssetIds, "technical asset", risk.DataBreachTechnicalAssets, where, append(path, "data_breach_technical_assets")...)
+}
+
+func (what *modelValidator) validateRiskTracking() {
+	for _, syntheticRiskId := range sortedKeys(what.modelInput.RiskTracking) {
+		tracking := what.modelInput.RiskTracking[syntheticRiskId]
+		where := fmt.Sprintf("risk tracking %q", syntheticRiskId)
+		path := []string{"risk_tracking", syntheticRiskId}
+
+		checkValue(what, types.ParseRiskStatus, tracking.Status, where, append(path, "status")...)
+		if len(tracking.Date) > 0 {
+			if _, dateError := time.Parse("2006-01-02", tracking.Date); dateError != nil {
+				what.add(ValidationError, "unknown-value", what.position(append(path, "date")...), "unable to parse 'date' of %v: %v", where, tracking.Date)
+			}
+		}
+	}
+}
+
+func (what *modelValidator) validateTags() {
+	for n, tag := range what.modelInput.TagsAvailable {
+		if !what.usedTags[normalizeTag(tag)] {
+			what.add(ValidationWarning, "unused-tag", what.position("tags_available", strconv.Itoa(n)), "tag %q is available but not used", tag)
+		}
+	}
+}
+
+func (what *modelValidator) validateUnusedDataAssets() {
+	for _, title := range sortedKeys(what.modelInput.DataAssets) {
+		if !what.usedDataAssets[what.modelInput.DataAssets[title].ID] {
+			what.add(ValidationWarning, "unused-data-asset", what.position("data_assets", title), "data asset %q is neither processed, stored nor transferred by any technical asset", title)
+		}
+	}
+}
+
+func (what *modelValidator) checkTags(tags []string, where string, path ...string) {
+	available := make(map[string]bool)
+	for _, tag := range what.modelInput.TagsAvailable {
+		available[normalizeTag(tag)] = true
+	}
+
+	for n, tag := range tags {
+		what.usedTags[normalizeTag(tag)] = true
+		if !available[normalizeTag(tag)] {
+			what.add(ValidationError, "unknown-tag", what.position(append(path, strconv.Itoa(n))...), "missing referenced tag %q at %v", tag, where)
+		}
+	}
+}
+
+func (what *modelValidator) checkReferences(ids map[string]string, kind string, references []string, where string, path ...string) {
+	for n, reference := range references {
+		what.checkReference(ids, kind, reference, where, append(path, strconv.Itoa(n))...)
+	}
+}
+
+func (what *modelValidator) checkReference(ids map[string]string, kind string, reference string, where string, path ...string) {
+	if len(reference) == 0 {
+		return
+	}
+
+	if _, exists := ids[reference]; !exists {
+		what.add(ValidationError, "missing-reference", what.position(path...), "missing referenced %v %q at %v", kind, reference, where)
+	}
+}
+
+func (what *modelValidator) markDataAssetsUsed(lists ...[]string) {
+	for _, list := range lists {
+		for _, id := range list {
+			what.usedDataAssets[id] = true
+		}
+	}
+}
+
+func checkValue[T any](validator *modelValidator, parse func(string) (T, error), value string, where string, path ...string) {
+	if _, parseError := parse(value); parseError != nil {
+		validator.add(ValidationError, "unknown-value", validator.position(path...), "unknown '%v' value of %v: %v", path[len(path)-1], where, value)
+	}
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+func kindName(kind string) string {
+	switch kind {
+	case "data_assets":
+		return "data asset"
+	case "technical_assets":
+		return "technical asset"
+	case "trust_boundaries":
+		return "trust boundary"
+	case "shared_runtimes":
+		return "shared runtime"
+	}
+
+	return kind
+}
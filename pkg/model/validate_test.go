@@ -0,0 +1,96 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModelFile_Valid(t *testing.T) {
+	result := ValidateModelFile(writeValidateModel(t, ""), &mockConfig{})
+
+	assert.False(t, result.HasErrors(), result.Problems)
+	assert.Equal(t, 0, result.Count(ValidationWarning))
+}
+
+func TestValidateModelFile_Problems(t *testing.T) {
+	filename := writeValidateModel(t, `
+    data_assets_processed:
+      - missing-data
+    communication_links:
+      Broken Link:
+        target: missing-asset
+        description: link to nowhere
+        protocol: fancy
+        authentication: none
+        authorization: none
+        usage: business
+`)
+
+	result := ValidateModelFile(filename, &mockConfig{})
+
+	assert.True(t, result.HasErrors())
+	assertProblem(t, result, ValidationError, "missing-reference", filename, 28)
+	assertProblem(t, result, ValidationError, "missing-reference", filename, 31)
+	assertProblem(t, result, ValidationError, "unknown-value", filename, 33)
+}
+
+func TestValidateModelFile_YamlError(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "threagile.yaml")
+	assert.NoError(t, os.WriteFile(filename, []byte("title: [unclosed\n"), 0600))
+
+	result := ValidateModelFile(filename, &mockConfig{})
+
+	assert.True(t, result.HasErrors())
+	assert.Equal(t, "yaml", result.Problems[0].Check)
+	assert.Equal(t, filename, result.Problems[0].Position.File)
+}
+
+func assertProblem(t *testing.T, result *ValidationResult, severity ValidationSeverity, check string, filename string, line int) {
+	t.Helper()
+
+	for _, problem := range result.Problems {
+		if problem.Severity == severity && problem.Check == check && problem.Position != nil && problem.Position.File == filename && problem.Position.Line == line {
+			return
+		}
+	}
+
+	t.Errorf("expected %v %q problem at %v:%v, got %v", severity, check, filename, line, result.Problems)
+}
+
+func writeValidateModel(t *testing.T, assetExtra string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "threagile.yaml")
+	modelYaml := `threagile_version: 1.0.0
+title: Validate Test
+business_criticality: important
+data_assets:
+  Some Data:
+    id: some-data
+    usage: business
+    quantity: few
+    confidentiality: internal
+    integrity: important
+    availability: important
+technical_assets:
+  Some Asset:
+    id: some-asset
+    usage: business
+    type: process
+    size: system
+    technology: web-server
+    encryption: none
+    machine: virtual
+    confidentiality: internal
+    integrity: important
+    availability: important
+    data_assets_stored:
+      - some-data
+` + assetExtra
+	assert.NoError(t, os.WriteFile(filename, []byte(modelYaml), 0600))
+
+	return filename
+}
@@ -207,6 +207,7 @@ func (adoc adocReport) writeMainLine(line string) {
 func (adoc adocReport) WriteReport(model *types.Model,
 	dataFlowDiagramFilenamePNG string,
 	dataAssetDiagramFilenamePNG string,
+	riskCategoryDiagramFilenamesPNG map[string]string,
 	modelFilename string,
 	skipRiskRules []string,
 	buildTimestamp string,
@@ -299,7 +300,7 @@ func (adoc adocReport) WriteReport(model *types.Model,
 	if err != nil {
 		return fmt.Errorf("error creating questions: %w", err)
 	}
-	err = adoc.writeRiskCategories()
+	err = adoc.writeRiskCategories(riskCategoryDiagramFilenamesPNG)
 	if err != nil {
 		return fmt.Errorf("error creating risk categories: %w", err)
 	}
@@ -1542,7 +1543,7 @@ func (adoc adocReport) riskTrackingStatus(f *os.File, risk *types.Risk) {
 	}
 }
 
-func (adoc adocReport) riskCategories(f *os.File) {
+func (adoc adocReport) riskCategories(f *os.File, riskCategoryDiagramFilenamesPNG map[string]string) {
 	writeLine(f, "= Identified Risks by Vulnerability category")
 	writeLine(f, "In total *"+strconv.Itoa(totalRiskCount(adoc.model))+" potential risks* have been identified during the threat modeling process "+
 		"of which "+
@@ -1626,6 +1627,17 @@ func (adoc adocReport) riskCategories(f *os.File) {
 		writeLine(f, "\n\n*Check*\n")
 		writeLine(f, category.Check)
 
+		// affected part of the data-flow diagram
+		if diagramFilenamePNG, ok := riskCategoryDiagramFilenamesPNG[category.ID]; ok {
+			writeLine(f, "")
+			writeLine(f, "=== Affected Data-Flow")
+			writeLine(f, "")
+			writeLine(f, "The following excerpt of the data-flow diagram shows only the technical assets affected by this risk category, "+
+				"their direct neighbours and the trust boundaries around them. "+
+				"The most relevant communication links are highlighted as thick light blue arrows.")
+			writeLine(f, "\nimage::images/"+filepath.Base(diagramFilenamePNG)+"[]")
+		}
+
 		// risk details
 		writeLine(f, "")
 		writeLine(f, "=== Risk Findings")
@@ -1672,17 +1684,23 @@ func (adoc adocReport) riskCategories(f *os.File) {
 	}
 }
 
-func (adoc adocReport) writeRiskCategories() error {
+func (adoc adocReport) writeRiskCategories(riskCategoryDiagramFilenamesPNG map[string]string) error {
 	filename := "170_RiskCategories.adoc"
 	f, err := os.Create(filepath.Join(adoc.targetDirectory, filename))
 	defer func() { _ = f.Close() }()
 	if err != nil {
 		return err
 	}
+	for _, diagramFilenamePNG := range riskCategoryDiagramFilenamesPNG {
+		err = copyFile(diagramFilenamePNG, filepath.Join(adoc.imagesDir, filepath.Base(diagramFilenamePNG)))
+		if err != nil {
+			return fmt.Errorf("could not copy risk category diagram: %w", err)
+		}
+	}
 	adoc.writeMainLine("<<<")
 	adoc.writeMainLine("include::" + filename + "[leveloffset=+1]")
 
-	adoc.riskCategories(f)
+	adoc.riskCategories(f, riskCategoryDiagramFilenamesPNG)
 	return nil
 }
 
@@ -26,6 +26,7 @@ const (
 	ExtremeLightBlue     = "#DDFFFF"
 	LightBlue            = "#77FFFF"
 	Brown                = "#8C4C17"
+	HighlightedLink      = "#00A0FF"
 )
 
 func darkenHexColor(hexString string) string {
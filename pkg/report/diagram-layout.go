@@ -0,0 +1,665 @@
+package report
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/wcharczuk/go-chart"
+	"golang.org/x/image/font"
+)
+
+// The native layout is a simplified layered (Sugiyama style) layout: every trust boundary is laid out on its own
+// and then treated as a single block inside its parent, which keeps clusters rectangular and free of overlaps.
+// Within each level, blocks are ranked along the edges, ordered by barycenters and aligned to their neighbours.
+
+const (
+	diagramNodePaddingX  = 10.0
+	diagramNodePaddingY  = 6.0
+	diagramLineSpacing   = 1.25
+	diagramTextSafety    = 1.08
+	diagramBoldSafety    = 1.14
+	diagramPeripheryGap  = 4.0
+	diagramArrowLength   = 16.0
+	diagramArrowWidth    = 6.0
+	diagramPageMargin    = 20.0
+	diagramOrderingSweep = 4
+)
+
+var (
+	diagramFontLock  sync.Mutex
+	diagramFontFaces = make(map[float64]font.Face)
+)
+
+func diagramFont() (*truetype.Font, error) {
+	return chart.GetDefaultFont()
+}
+
+// measureDiagramText returns the width of the given text in points.
+func measureDiagramText(text string, size float64) float64 {
+	diagramFontLock.Lock()
+	defer diagramFontLock.Unlock()
+
+	face, ok := diagramFontFaces[size]
+	if !ok {
+		f, err := diagramFont()
+		if err != nil {
+			return float64(len(text)) * size * 0.6
+		}
+		face = truetype.NewFace(f, &truetype.Options{Size: size, DPI: diagramPointsPerInch})
+		diagramFontFaces[size] = face
+	}
+	return float64(font.MeasureString(face, text)) / 64.0
+}
+
+// diagramItem is either a node or an already laid out cluster being positioned inside its parent cluster.
+type diagramItem struct {
+	node    *diagramNode
+	cluster *diagramCluster
+	index   int
+	rank    int
+	order   float64
+	main    float64
+	cross   float64
+}
+
+func (what *diagramItem) size() (float64, float64) {
+	if what.node != nil {
+		return what.node.width, what.node.height
+	}
+	return what.cluster.width, what.cluster.height
+}
+
+func (what *diagramItem) setPosition(x, y float64) {
+	if what.node != nil {
+		what.node.x = x + what.node.width/2
+		what.node.y = y + what.node.height/2
+		return
+	}
+	what.cluster.x = x
+	what.cluster.y = y
+}
+
+type diagramItemEdge struct {
+	from, to   int
+	weight     float64
+	constraint bool
+}
+
+func (what *diagram) layout() {
+	what.measureCluster(what.root)
+	what.layoutCluster(what.root)
+
+	titleHeight := 0.0
+	if len(what.title) > 0 {
+		titleHeight = diagramFontSizeTitle*diagramLineSpacing + diagramPageMargin
+	}
+	what.translateCluster(what.root, diagramPageMargin, diagramPageMargin+titleHeight)
+	what.width = math.Max(what.root.width, measureDiagramText(what.title, diagramFontSizeTitle)) + 2*diagramPageMargin
+	what.height = what.root.height + titleHeight + 2*diagramPageMargin
+
+	what.routeEdges()
+}
+
+func (what *diagram) measureCluster(cluster *diagramCluster) {
+	for _, node := range cluster.nodes {
+		node.measure()
+	}
+	for _, nested := range cluster.clusters {
+		what.measureCluster(nested)
+	}
+}
+
+func (what *diagramNode) measure() {
+	textWidth, textHeight := 0.0, 0.0
+	for _, row := range what.rows {
+		for _, line := range row {
+			textWidth = math.Max(textWidth, line.width())
+			textHeight += line.size * diagramLineSpacing
+		}
+	}
+	if what.compartments {
+		textHeight += float64(len(what.rows)) * 4
+	}
+
+	width := textWidth + 2*diagramNodePaddingX
+	height := textHeight + 2*diagramNodePaddingY
+	switch what.shape {
+	case "ellipse":
+		width, height = width*math.Sqrt2, height*math.Sqrt2
+	case "cylinder":
+		height += 3 * what.cylinderCap(width)
+	case "octagon":
+		width, height = width+height*0.6, height*1.2
+	}
+	if what.peripheries > 1 {
+		width += 2 * diagramPeripheryGap * float64(what.peripheries-1)
+		height += 2 * diagramPeripheryGap * float64(what.peripheries-1)
+	}
+	what.width = math.Max(width, 54)
+	what.height = math.Max(height, 36)
+}
+
+// width returns the width of the text including some safety margin for viewers substituting the font.
+func (what diagramText) width() float64 {
+	if what.bold {
+		return measureDiagramText(what.text, what.size) * diagramBoldSafety
+	}
+	return measureDiagramText(what.text, what.size) * diagramTextSafety
+}
+
+func (what *diagramNode) cylinderCap(width float64) float64 {
+	return math.Min(math.Max(width*0.06, 6), 14)
+}
+
+func (what *diagramCluster) labelHeight() float64 {
+	if len(what.label) == 0 {
+		return 0
+	}
+	return diagramFontSizeCluster*diagramLineSpacing + 10
+}
+
+// layoutCluster positions the nested clusters and nodes of the given cluster relative to its top left corner and sets its size.
+func (what *diagram) layoutCluster(cluster *diagramCluster) {
+	items := make([]*diagramItem, 0)
+	owner := make(map[string]int)
+	for _, nested := range cluster.clusters {
+		what.layoutCluster(nested)
+		item := &diagramItem{cluster: nested, index: len(items)}
+		for _, id := range nested.nodeIds() {
+			owner[id] = item.index
+		}
+		items = append(items, item)
+	}
+	for _, node := range cluster.nodes {
+		item := &diagramItem{node: node, index: len(items)}
+		owner[node.id] = item.index
+		items = append(items, item)
+	}
+
+	edges := make([]diagramItemEdge, 0)
+	for _, edge := range what.edges {
+		from, fromOk := owner[edge.from]
+		to, toOk := owner[edge.to]
+		if !fromOk || !toOk || from == to {
+			continue
+		}
+		edges = append(edges, diagramItemEdge{from: from, to: to, weight: float64(edge.weight + 1), constraint: edge.constraint})
+	}
+
+	sameRank := make([][]int, 0)
+	for _, group := range what.sameRank {
+		indexes := make([]int, 0)
+		for _, id := range group {
+			if index, ok := owner[id]; ok {
+				indexes = append(indexes, index)
+			}
+		}
+		if len(indexes) > 1 {
+			sameRank = append(sameRank, indexes)
+		}
+	}
+
+	ranks := rankDiagramItems(items, edges, sameRank)
+	orderDiagramItems(ranks, edges)
+	contentWidth, contentHeight := what.positionDiagramItems(ranks, edges)
+
+	labelWidth := 0.0
+	if len(cluster.label) > 0 {
+		labelWidth = measureDiagramText(cluster.label, diagramFontSizeCluster)*diagramTextSafety + 20
+	}
+	offsetX := cluster.margin + math.Max(0, labelWidth-contentWidth)/2
+	offsetY := cluster.margin + cluster.labelHeight()
+	for _, item := range items {
+		if what.leftToRight {
+			item.setPosition(offsetX+item.main, offsetY+item.cross)
+		} else {
+			item.setPosition(offsetX+item.cross, offsetY+item.main)
+		}
+	}
+	cluster.width = math.Max(contentWidth, labelWidth) + 2*cluster.margin
+	cluster.height = contentHeight + 2*cluster.margin + cluster.labelHeight()
+}
+
+func (what *diagramCluster) nodeIds() []string {
+	ids := make([]string, 0)
+	for _, node := range what.nodes {
+		ids = append(ids, node.id)
+	}
+	for _, nested := range what.clusters {
+		ids = append(ids, nested.nodeIds()...)
+	}
+	return ids
+}
+
+// translateCluster turns the relative positions into absolute ones.
+func (what *diagram) translateCluster(cluster *diagramCluster, x, y float64) {
+	cluster.x += x
+	cluster.y += y
+	for _, node := range cluster.nodes {
+		node.x += cluster.x
+		node.y += cluster.y
+	}
+	for _, nested := range cluster.clusters {
+		what.translateCluster(nested, cluster.x, cluster.y)
+	}
+}
+
+// rankDiagramItems assigns ranks along the constraining edges (longest path) after breaking cycles and returns the items per rank.
+func rankDiagramItems(items []*diagramItem, edges []diagramItemEdge, sameRank [][]int) [][]*diagramItem {
+	successors := make(map[int][]int)
+	for _, edge := range edges {
+		if edge.constraint {
+			successors[edge.from] = append(successors[edge.from], edge.to)
+		}
+	}
+
+	// break cycles by dropping edges pointing back onto the current depth first search path
+	acyclic := make([][2]int, 0)
+	state := make(map[int]int)
+	var visit func(int)
+	visit = func(index int) {
+		state[index] = 1
+		for _, next := range successors[index] {
+			switch state[next] {
+			case 0:
+				acyclic = append(acyclic, [2]int{index, next})
+				visit(next)
+			case 2:
+				acyclic = append(acyclic, [2]int{index, next})
+			}
+		}
+		state[index] = 2
+	}
+	for _, item := range items {
+		if state[item.index] == 0 {
+			visit(item.index)
+		}
+	}
+
+	for iteration := 0; iteration <= len(items); iteration++ {
+		changed := false
+		for _, edge := range acyclic {
+			if items[edge[1]].rank < items[edge[0]].rank+1 {
+				items[edge[1]].rank = items[edge[0]].rank + 1
+				changed = true
+			}
+		}
+		for _, group := range sameRank {
+			maxRank := 0
+			for _, index := range group {
+				maxRank = max(maxRank, items[index].rank)
+			}
+			for _, index := range group {
+				if items[index].rank != maxRank {
+					items[index].rank = maxRank
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	ranks := make([][]*diagramItem, 0)
+	for _, item := range items {
+		for len(ranks) <= item.rank {
+			ranks = append(ranks, make([]*diagramItem, 0))
+		}
+		item.order = float64(len(ranks[item.rank]))
+		ranks[item.rank] = append(ranks[item.rank], item)
+	}
+	return ranks
+}
+
+// orderDiagramItems reduces edge crossings by sorting each rank by the barycenter of the neighbours in the adjacent ranks.
+func orderDiagramItems(ranks [][]*diagramItem, edges []diagramItemEdge) {
+	byIndex := make(map[int]*diagramItem)
+	for _, rank := range ranks {
+		for _, item := range rank {
+			byIndex[item.index] = item
+		}
+	}
+
+	sweep := func(rank []*diagramItem, neighbourRank func(int) bool) {
+		barycenters := make(map[int]float64)
+		for _, item := range rank {
+			sum, weights := 0.0, 0.0
+			for _, edge := range edges {
+				var other *diagramItem
+				switch item.index {
+				case edge.from:
+					other = byIndex[edge.to]
+				case edge.to:
+					other = byIndex[edge.from]
+				default:
+					continue
+				}
+				if neighbourRank(other.rank) {
+					sum += other.order * edge.weight
+					weights += edge.weight
+				}
+			}
+			if weights > 0 {
+				barycenters[item.index] = sum / weights
+			} else {
+				barycenters[item.index] = item.order
+			}
+		}
+		sort.SliceStable(rank, func(i, j int) bool {
+			return barycenters[rank[i].index] < barycenters[rank[j].index]
+		})
+		for i, item := range rank {
+			item.order = float64(i)
+		}
+	}
+
+	for iteration := 0; iteration < diagramOrderingSweep; iteration++ {
+		for r := 1; r < len(ranks); r++ {
+			sweep(ranks[r], func(other int) bool { return other < r })
+		}
+		for r := len(ranks) - 2; r >= 0; r-- {
+			sweep(ranks[r], func(other int) bool { return other > r })
+		}
+	}
+}
+
+// positionDiagramItems places the ordered items and returns the size of the content.
+func (what *diagram) positionDiagramItems(ranks [][]*diagramItem, edges []diagramItemEdge) (float64, float64) {
+	depthAndBreadth := func(item *diagramItem) (float64, float64) {
+		width, height := item.size()
+		if what.leftToRight {
+			return width, height
+		}
+		return height, width
+	}
+
+	// main axis: ranks one after another, each item centered within its rank
+	main := 0.0
+	for r, rank := range ranks {
+		rankDepth := 0.0
+		for _, item := range rank {
+			depth, _ := depthAndBreadth(item)
+			rankDepth = math.Max(rankDepth, depth)
+		}
+		for _, item := range rank {
+			depth, _ := depthAndBreadth(item)
+			item.main = main + (rankDepth-depth)/2
+		}
+		main += rankDepth
+		if r < len(ranks)-1 && len(rank) > 0 {
+			main += what.ranksep
+		}
+	}
+
+	// cross axis: align each item with its already placed neighbours while keeping the order and spacing
+	placed := make(map[int]*diagramItem)
+	for _, rank := range ranks {
+		desired := make([]float64, len(rank))
+		for i, item := range rank {
+			_, breadth := depthAndBreadth(item)
+			sum, weights := 0.0, 0.0
+			for _, edge := range edges {
+				var other *diagramItem
+				switch item.index {
+				case edge.from:
+					other = placed[edge.to]
+				case edge.to:
+					other = placed[edge.from]
+				}
+				if other != nil {
+					_, otherBreadth := depthAndBreadth(other)
+					sum += (other.cross + otherBreadth/2) * edge.weight
+					weights += edge.weight
+				}
+			}
+			desired[i] = math.NaN()
+			if weights > 0 {
+				desired[i] = sum/weights - breadth/2
+			}
+		}
+
+		end := math.Inf(-1)
+		for i, item := range rank {
+			_, breadth := depthAndBreadth(item)
+			position := desired[i]
+			if math.IsNaN(position) {
+				position = end + what.nodesep
+				if math.IsInf(end, -1) {
+					position = 0
+				}
+			}
+			if !math.IsInf(end, -1) {
+				position = math.Max(position, end+what.nodesep)
+			}
+			item.cross = position
+			end = position + breadth
+		}
+		for _, item := range rank {
+			placed[item.index] = item
+		}
+	}
+
+	minCross, maxCross := math.Inf(1), math.Inf(-1)
+	for _, item := range placed {
+		_, breadth := depthAndBreadth(item)
+		minCross = math.Min(minCross, item.cross)
+		maxCross = math.Max(maxCross, item.cross+breadth)
+	}
+	if len(placed) == 0 {
+		return 0, 0
+	}
+	for _, item := range placed {
+		item.cross -= minCross
+	}
+
+	if what.leftToRight {
+		return main, maxCross - minCross
+	}
+	return maxCross - minCross, main
+}
+
+// edge routing ===============================================================================
+
+const (
+	diagramSideAfter = iota
+	diagramSideBefore
+	diagramSideCrossAfter
+	diagramSideCrossBefore
+)
+
+type diagramPort struct {
+	edge   *diagramEdge
+	node   *diagramNode
+	other  *diagramNode
+	side   int
+	isHead bool
+	offset float64
+}
+
+func (what *diagram) nodesById() map[string]*diagramNode {
+	nodes := make(map[string]*diagramNode)
+	var collect func(*diagramCluster)
+	collect = func(cluster *diagramCluster) {
+		for _, node := range cluster.nodes {
+			nodes[node.id] = node
+		}
+		for _, nested := range cluster.clusters {
+			collect(nested)
+		}
+	}
+	collect(what.root)
+	return nodes
+}
+
+// toAxes converts x and y into main and cross axis coordinates (and vice versa, as the conversion is symmetric).
+func (what *diagram) toAxes(x, y float64) (float64, float64) {
+	if what.leftToRight {
+		return x, y
+	}
+	return y, x
+}
+
+func (what *diagram) routeEdges() {
+	nodes := what.nodesById()
+
+	ports := make(map[*diagramNode]map[int][]*diagramPort)
+	addPort := func(port *diagramPort) {
+		if ports[port.node] == nil {
+			ports[port.node] = make(map[int][]*diagramPort)
+		}
+		ports[port.node][port.side] = append(ports[port.node][port.side], port)
+	}
+
+	routed := make([]*diagramEdge, 0)
+	portsOfEdge := make(map[*diagramEdge][2]*diagramPort)
+	for _, edge := range what.edges {
+		source, target := nodes[edge.from], nodes[edge.to]
+		if edge.invisible || source == nil || target == nil || source == target {
+			continue
+		}
+		sourceSide, targetSide := what.edgeSides(source, target)
+		tail := &diagramPort{edge: edge, node: source, other: target, side: sourceSide}
+		head := &diagramPort{edge: edge, node: target, other: source, side: targetSide, isHead: true}
+		addPort(tail)
+		addPort(head)
+		portsOfEdge[edge] = [2]*diagramPort{tail, head}
+		routed = append(routed, edge)
+	}
+
+	// spread the ports of each side of a node according to the position of the nodes on the other end
+	for node, sides := range ports {
+		for side, list := range sides {
+			sort.SliceStable(list, func(i, j int) bool {
+				iMain, iCross := what.toAxes(list[i].other.x, list[i].other.y)
+				jMain, jCross := what.toAxes(list[j].other.x, list[j].other.y)
+				if side == diagramSideCrossAfter || side == diagramSideCrossBefore {
+					return iMain < jMain
+				}
+				return iCross < jCross
+			})
+			_, breadth := what.toAxes(node.width, node.height)
+			if side == diagramSideCrossAfter || side == diagramSideCrossBefore {
+				breadth, _ = what.toAxes(node.width, node.height)
+			}
+			span := breadth * 0.7
+			if node.shape == "ellipse" {
+				span = breadth * 0.5
+			}
+			for i, port := range list {
+				port.offset = span * (float64(i+1)/float64(len(list)+1) - 0.5)
+			}
+		}
+	}
+
+	for i, edge := range routed {
+		tail, head := portsOfEdge[edge][0], portsOfEdge[edge][1]
+		start, end := what.portPoint(tail), what.portPoint(head)
+		startMain, startCross := what.toAxes(start.x, start.y)
+		endMain, endCross := what.toAxes(end.x, end.y)
+		crossSides := tail.side == diagramSideCrossAfter || tail.side == diagramSideCrossBefore
+
+		// spread parallel segments between two ranks a bit to keep them distinguishable
+		fraction := 0.3 + 0.4*math.Mod(float64(i)*0.618, 1)
+		point := func(main, cross float64) diagramPoint {
+			x, y := what.toAxes(main, cross)
+			return diagramPoint{x: x, y: y}
+		}
+
+		switch what.edgeLayout {
+		case "ortho":
+			if crossSides {
+				middle := startCross + (endCross-startCross)*fraction
+				edge.points = []diagramPoint{start, point(startMain, middle), point(endMain, middle), end}
+			} else {
+				middle := startMain + (endMain-startMain)*fraction
+				edge.points = []diagramPoint{start, point(middle, startCross), point(middle, endCross), end}
+			}
+		case "spline", "curved":
+			edge.curved = true
+			if crossSides {
+				middle := (startCross + endCross) / 2
+				edge.points = []diagramPoint{start, point(startMain, middle), point(endMain, middle), end}
+			} else {
+				middle := (startMain + endMain) / 2
+				edge.points = []diagramPoint{start, point(middle, startCross), point(middle, endCross), end}
+			}
+		default:
+			edge.points = []diagramPoint{start, end}
+		}
+	}
+}
+
+// edgeSides determines which sides of the source and target node are used by an edge.
+func (what *diagram) edgeSides(source *diagramNode, target *diagramNode) (int, int) {
+	sourceMain, sourceCross := what.toAxes(source.x, source.y)
+	targetMain, targetCross := what.toAxes(target.x, target.y)
+	sourceDepth, _ := what.toAxes(source.width, source.height)
+	targetDepth, _ := what.toAxes(target.width, target.height)
+
+	if targetMain-targetDepth/2 >= sourceMain+sourceDepth/2 {
+		return diagramSideAfter, diagramSideBefore
+	}
+	if targetMain+targetDepth/2 <= sourceMain-sourceDepth/2 {
+		return diagramSideBefore, diagramSideAfter
+	}
+	if targetCross >= sourceCross {
+		return diagramSideCrossAfter, diagramSideCrossBefore
+	}
+	return diagramSideCrossBefore, diagramSideCrossAfter
+}
+
+// portPoint returns the point on the outline of the node where the port is attached.
+func (what *diagram) portPoint(port *diagramPort) diagramPoint {
+	node := port.node
+	main, cross := what.toAxes(node.x, node.y)
+	depth, breadth := what.toAxes(node.width, node.height)
+	halfDepth, halfBreadth := depth/2, breadth/2
+
+	var pointMain, pointCross float64
+	switch port.side {
+	case diagramSideAfter, diagramSideBefore:
+		reach := halfDepth
+		if node.shape == "ellipse" {
+			reach = halfDepth * math.Sqrt(math.Max(0, 1-math.Pow(port.offset/halfBreadth, 2)))
+		}
+		pointCross = cross + port.offset
+		pointMain = main + reach
+		if port.side == diagramSideBefore {
+			pointMain = main - reach
+		}
+	default:
+		reach := halfBreadth
+		if node.shape == "ellipse" {
+			reach = halfBreadth * math.Sqrt(math.Max(0, 1-math.Pow(port.offset/halfDepth, 2)))
+		}
+		pointMain = main + port.offset
+		pointCross = cross + reach
+		if port.side == diagramSideCrossBefore {
+			pointCross = cross - reach
+		}
+	}
+
+	x, y := what.toAxes(pointMain, pointCross)
+	return diagramPoint{x: x, y: y}
+}
+
+// labelPoint returns the anchor of the edge label: the middle of the edge.
+func (what *diagramEdge) labelPoint() diagramPoint {
+	switch {
+	case len(what.points) == 4 && what.curved:
+		p0, p1, p2, p3 := what.points[0], what.points[1], what.points[2], what.points[3]
+		return diagramPoint{
+			x: 0.125*p0.x + 0.375*p1.x + 0.375*p2.x + 0.125*p3.x,
+			y: 0.125*p0.y + 0.375*p1.y + 0.375*p2.y + 0.125*p3.y,
+		}
+	case len(what.points) == 4:
+		return diagramPoint{x: (what.points[1].x + what.points[2].x) / 2, y: (what.points[1].y + what.points[2].y) / 2}
+	case len(what.points) >= 2:
+		first, last := what.points[0], what.points[len(what.points)-1]
+		return diagramPoint{x: (first.x + last.x) / 2, y: (first.y + last.y) / 2}
+	}
+	return diagramPoint{}
+}
@@ -0,0 +1,523 @@
+package report
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wcharczuk/go-chart/drawing"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+const (
+	GraphvizDiagramRenderer = "graphviz"
+	NativeDiagramRenderer   = "native"
+)
+
+// GenerateDataFlowDiagramNativeImage renders the data flow diagram without graphviz: as PNG and alongside as SVG.
+func GenerateDataFlowDiagramNativeImage(parsedModel *types.Model, targetDir string, dataFlowDiagramFilenamePNG string, dpi int,
+	addModelTitle bool, addLegend bool, progressReporter progressReporter) error {
+	progressReporter.Info("Rendering data flow diagram natively")
+	dataFlowDiagram, err := makeDataFlowDiagram(parsedModel, addModelTitle, addLegend)
+	if err != nil {
+		return fmt.Errorf("error while generating data flow diagram: %w", err)
+	}
+	return writeNativeDiagram(dataFlowDiagram, filepath.Join(targetDir, dataFlowDiagramFilenamePNG), dpi)
+}
+
+// GenerateDataAssetDiagramNativeImage renders the data asset diagram without graphviz: as PNG and alongside as SVG.
+func GenerateDataAssetDiagramNativeImage(parsedModel *types.Model, targetDir string, dataAssetDiagramFilenamePNG string, dpi int,
+	progressReporter progressReporter) error {
+	progressReporter.Info("Rendering data asset diagram natively")
+	return writeNativeDiagram(makeDataAssetDiagram(parsedModel), filepath.Join(targetDir, dataAssetDiagramFilenamePNG), dpi)
+}
+
+// DiagramFilenameSVG returns the name of the SVG file written next to the given PNG file by the native renderer.
+func DiagramFilenameSVG(filenamePNG string) string {
+	return strings.TrimSuffix(filenamePNG, filepath.Ext(filenamePNG)) + ".svg"
+}
+
+func writeNativeDiagram(nativeDiagram *diagram, filenamePNG string, dpi int) error {
+	nativeDiagram.layout()
+
+	filenameSVG := DiagramFilenameSVG(filenamePNG)
+	svgFile, err := os.Create(filepath.Clean(filenameSVG))
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", filenameSVG, err)
+	}
+	defer func() { _ = svgFile.Close() }()
+	svgWriter := bufio.NewWriter(svgFile)
+	_, _ = svgWriter.WriteString(svgXmlDeclaration)
+	nativeDiagram.draw(newSvgDiagramCanvas(svgWriter, nativeDiagram.width, nativeDiagram.height))
+	err = svgWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", filenameSVG, err)
+	}
+
+	pngCanvas, err := newPngDiagramCanvas(nativeDiagram.width, nativeDiagram.height, float64(dpi)/diagramPointsPerInch)
+	if err != nil {
+		return fmt.Errorf("error creating diagram image: %w", err)
+	}
+	nativeDiagram.draw(pngCanvas)
+	pngFile, err := os.Create(filepath.Clean(filenamePNG))
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", filenamePNG, err)
+	}
+	defer func() { _ = pngFile.Close() }()
+	err = png.Encode(pngFile, pngCanvas.image)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", filenamePNG, err)
+	}
+	return nil
+}
+
+// drawing ===============================================================================
+
+type diagramPathOperation struct {
+	operation byte // M (move), L (line), C (cubic curve) or Z (close)
+	points    []float64
+}
+
+type diagramPath []diagramPathOperation
+
+func (what *diagramPath) moveTo(x, y float64) {
+	*what = append(*what, diagramPathOperation{operation: 'M', points: []float64{x, y}})
+}
+
+func (what *diagramPath) lineTo(x, y float64) {
+	*what = append(*what, diagramPathOperation{operation: 'L', points: []float64{x, y}})
+}
+
+func (what *diagramPath) curveTo(x1, y1, x2, y2, x, y float64) {
+	*what = append(*what, diagramPathOperation{operation: 'C', points: []float64{x1, y1, x2, y2, x, y}})
+}
+
+func (what *diagramPath) close() {
+	*what = append(*what, diagramPathOperation{operation: 'Z'})
+}
+
+// ellipse adds a closed ellipse approximated by four cubic curves.
+func (what *diagramPath) ellipse(cx, cy, rx, ry float64) {
+	const k = 0.5523
+	what.moveTo(cx+rx, cy)
+	what.curveTo(cx+rx, cy+k*ry, cx+k*rx, cy+ry, cx, cy+ry)
+	what.curveTo(cx-k*rx, cy+ry, cx-rx, cy+k*ry, cx-rx, cy)
+	what.curveTo(cx-rx, cy-k*ry, cx-k*rx, cy-ry, cx, cy-ry)
+	what.curveTo(cx+k*rx, cy-ry, cx+rx, cy-k*ry, cx+rx, cy)
+	what.close()
+}
+
+func (what *diagramPath) rectangle(x, y, width, height float64) {
+	what.moveTo(x, y)
+	what.lineTo(x+width, y)
+	what.lineTo(x+width, y+height)
+	what.lineTo(x, y+height)
+	what.close()
+}
+
+// diagramCanvas is implemented by the SVG and the PNG output of the native renderer.
+type diagramCanvas interface {
+	beginGroup(class string, id string, title string)
+	endGroup()
+	drawPath(path diagramPath, fillColor string, strokeColor string, penWidth float64, style string)
+	drawText(text diagramText, x float64, baseline float64)
+	finish()
+}
+
+func (what *diagram) draw(canvas diagramCanvas) {
+	background := diagramPath{}
+	background.rectangle(0, 0, what.width, what.height)
+	canvas.drawPath(background, "#FFFFFF", "", 0, "solid")
+
+	if len(what.title) > 0 {
+		canvas.drawText(diagramText{text: what.title, size: diagramFontSizeTitle, color: Black}, what.width/2, diagramPageMargin+diagramFontSizeTitle)
+	}
+
+	for _, cluster := range what.root.clusters {
+		what.drawCluster(canvas, cluster)
+	}
+	what.drawNodes(canvas, what.root)
+	for _, edge := range what.edges {
+		what.drawEdge(canvas, edge)
+	}
+	canvas.finish()
+}
+
+func (what *diagram) drawCluster(canvas diagramCanvas, cluster *diagramCluster) {
+	canvas.beginGroup(cluster.class, cluster.id, cluster.label)
+	outline := diagramPath{}
+	outline.rectangle(cluster.x, cluster.y, cluster.width, cluster.height)
+	canvas.drawPath(outline, cluster.bgColor, cluster.color, cluster.penWidth, cluster.style)
+	if len(cluster.label) > 0 {
+		canvas.drawText(diagramText{text: cluster.label, size: diagramFontSizeCluster, color: cluster.fontColor, bold: cluster.class != "legend"},
+			cluster.x+cluster.width/2, cluster.y+cluster.margin/2+diagramFontSizeCluster)
+	}
+	for _, nested := range cluster.clusters {
+		what.drawCluster(canvas, nested)
+	}
+	canvas.endGroup()
+}
+
+func (what *diagram) drawNodes(canvas diagramCanvas, cluster *diagramCluster) {
+	for _, nested := range cluster.clusters {
+		what.drawNodes(canvas, nested)
+	}
+	for _, node := range cluster.nodes {
+		what.drawNode(canvas, node)
+	}
+}
+
+func (what *diagram) drawNode(canvas diagramCanvas, node *diagramNode) {
+	title := ""
+	if len(node.rows) > 1 {
+		title = node.rows[1][0].text
+	} else if len(node.rows) > 0 && len(node.rows[0]) > 0 {
+		title = node.rows[0][0].text
+	}
+	canvas.beginGroup(node.class, node.id, title)
+
+	for periphery := 0; periphery < node.peripheries; periphery++ {
+		fill := ""
+		if periphery == 0 {
+			fill = node.fillColor
+		}
+		what.drawShape(canvas, node, diagramPeripheryGap*float64(periphery), fill)
+	}
+
+	textHeight := 0.0
+	for _, row := range node.rows {
+		for _, line := range row {
+			textHeight += line.size * diagramLineSpacing
+		}
+	}
+	if node.compartments {
+		textHeight += float64(len(node.rows)) * 4
+	}
+
+	y := node.y - textHeight/2
+	if node.shape == "cylinder" {
+		// keep the text below the lid
+		y += node.cylinderCap(node.width) / 2
+	}
+	for _, row := range node.rows {
+		rowTop := y
+		if node.compartments {
+			y += 2
+		}
+		rowWidth := 0.0
+		for _, line := range row {
+			rowWidth = math.Max(rowWidth, line.width())
+			canvas.drawText(line, node.x, y+line.size)
+			y += line.size * diagramLineSpacing
+		}
+		if node.compartments {
+			y += 2
+			compartment := diagramPath{}
+			compartment.rectangle(node.x-rowWidth/2-4, rowTop, rowWidth+8, y-rowTop)
+			canvas.drawPath(compartment, "", node.borderColor, 1.0, "solid")
+		}
+	}
+	canvas.endGroup()
+}
+
+// drawShape draws the outline of a node shrunk by inset (peripheries are drawn from outside to inside).
+func (what *diagram) drawShape(canvas diagramCanvas, node *diagramNode, inset float64, fillColor string) {
+	left, top := node.x-node.width/2+inset, node.y-node.height/2+inset
+	width, height := node.width-2*inset, node.height-2*inset
+
+	shape := diagramPath{}
+	switch node.shape {
+	case "ellipse":
+		shape.ellipse(node.x, node.y, width/2, height/2)
+	case "cylinder":
+		capHeight := node.cylinderCap(node.width)
+		const k = 0.5523
+		rx := width / 2
+		shape.moveTo(left, top+capHeight)
+		shape.lineTo(left, top+height-capHeight)
+		shape.curveTo(left, top+height-capHeight+k*capHeight, node.x-k*rx, top+height, node.x, top+height)
+		shape.curveTo(node.x+k*rx, top+height, left+width, top+height-capHeight+k*capHeight, left+width, top+height-capHeight)
+		shape.lineTo(left+width, top+capHeight)
+		shape.curveTo(left+width, top+capHeight-k*capHeight, node.x+k*rx, top, node.x, top)
+		shape.curveTo(node.x-k*rx, top, left, top+capHeight-k*capHeight, left, top+capHeight)
+		shape.close()
+		canvas.drawPath(shape, fillColor, node.borderColor, node.penWidth, node.borderStyle)
+
+		lid := diagramPath{}
+		lid.moveTo(left, top+capHeight)
+		lid.curveTo(left, top+capHeight+k*capHeight, node.x-k*rx, top+2*capHeight, node.x, top+2*capHeight)
+		lid.curveTo(node.x+k*rx, top+2*capHeight, left+width, top+capHeight+k*capHeight, left+width, top+capHeight)
+		canvas.drawPath(lid, "", node.borderColor, node.penWidth, node.borderStyle)
+		return
+	case "octagon":
+		corner := math.Min(width, height) * 0.3
+		shape.moveTo(left+corner, top)
+		shape.lineTo(left+width-corner, top)
+		shape.lineTo(left+width, top+corner)
+		shape.lineTo(left+width, top+height-corner)
+		shape.lineTo(left+width-corner, top+height)
+		shape.lineTo(left+corner, top+height)
+		shape.lineTo(left, top+height-corner)
+		shape.lineTo(left, top+corner)
+		shape.close()
+	default:
+		shape.rectangle(left, top, width, height)
+	}
+	canvas.drawPath(shape, fillColor, node.borderColor, node.penWidth, node.borderStyle)
+}
+
+func (what *diagram) drawEdge(canvas diagramCanvas, edge *diagramEdge) {
+	if len(edge.points) < 2 {
+		return
+	}
+	canvas.beginGroup(edge.class, edge.id, edge.label)
+
+	// shorten the line by the arrow, so that it does not show through open arrow heads
+	points := append([]diagramPoint{}, edge.points...)
+	end := points[len(points)-1]
+	previous := points[len(points)-2]
+	length := math.Hypot(end.x-previous.x, end.y-previous.y)
+	directionX, directionY := 0.0, 1.0
+	if length > 0 {
+		directionX, directionY = (end.x-previous.x)/length, (end.y-previous.y)/length
+	}
+	arrowLength := math.Min(diagramArrowLength, length)
+	if !edge.curved {
+		points[len(points)-1] = diagramPoint{x: end.x - directionX*arrowLength, y: end.y - directionY*arrowLength}
+	}
+
+	line := diagramPath{}
+	line.moveTo(points[0].x, points[0].y)
+	if edge.curved && len(points) == 4 {
+		arrowBase := diagramPoint{x: end.x - directionX*arrowLength, y: end.y - directionY*arrowLength}
+		line.curveTo(points[1].x, points[1].y, points[2].x, points[2].y, arrowBase.x, arrowBase.y)
+	} else {
+		for _, point := range points[1:] {
+			line.lineTo(point.x, point.y)
+		}
+	}
+	canvas.drawPath(line, "", edge.color, edge.penWidth, edge.style)
+
+	arrow := diagramPath{}
+	baseX, baseY := end.x-directionX*arrowLength, end.y-directionY*arrowLength
+	arrow.moveTo(end.x, end.y)
+	arrow.lineTo(baseX-directionY*diagramArrowWidth, baseY+directionX*diagramArrowWidth)
+	arrow.lineTo(baseX+directionY*diagramArrowWidth, baseY-directionX*diagramArrowWidth)
+	arrow.close()
+	arrowFill := edge.color
+	if edge.arrowHead == "empty" {
+		arrowFill = "#FFFFFF"
+	}
+	canvas.drawPath(arrow, arrowFill, edge.color, edge.penWidth, "solid")
+
+	if len(edge.label) > 0 {
+		anchor := edge.labelPoint()
+		labelWidth := measureDiagramText(edge.label, diagramFontSizeEdge)
+		canvas.drawText(diagramText{text: edge.label, size: diagramFontSizeEdge, color: edge.labelColor}, anchor.x+labelWidth/2+4, anchor.y-4)
+	}
+	canvas.endGroup()
+}
+
+func diagramDashes(style string) []float64 {
+	switch style {
+	case "dotted":
+		return []float64{2, 4}
+	case "dashed":
+		return []float64{10, 6}
+	}
+	return nil
+}
+
+// parseDiagramColor parses #RRGGBB and #RRGGBBAA colors as well as the few named colors used in the diagrams.
+func parseDiagramColor(value string) color.RGBA {
+	switch strings.ToLower(value) {
+	case "black":
+		return color.RGBA{A: 0xFF}
+	case "white":
+		return color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	case "blue":
+		return color.RGBA{B: 0xFF, A: 0xFF}
+	case "green":
+		return color.RGBA{G: 0x80, A: 0xFF}
+	case "lightgrey":
+		return color.RGBA{R: 0xD3, G: 0xD3, B: 0xD3, A: 0xFF}
+	}
+	colorBytes, err := hex.DecodeString(strings.TrimPrefix(value, "#"))
+	if err != nil || len(colorBytes) < 3 {
+		return color.RGBA{A: 0xFF}
+	}
+	result := color.RGBA{R: colorBytes[0], G: colorBytes[1], B: colorBytes[2], A: 0xFF}
+	if len(colorBytes) > 3 {
+		result.A = colorBytes[3]
+	}
+	return result
+}
+
+// SVG ===============================================================================
+
+// the SVG canvas writes a bare svg element, the declaration is only prepended for standalone files (not when inlined into HTML)
+const svgXmlDeclaration = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+`
+
+type svgDiagramCanvas struct {
+	writer *bufio.Writer
+}
+
+func newSvgDiagramCanvas(writer *bufio.Writer, width float64, height float64) *svgDiagramCanvas {
+	_, _ = fmt.Fprintf(writer, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0fpt" height="%.0fpt" viewBox="0 0 %.2f %.2f" font-family="Roboto, Helvetica, Arial, sans-serif">
+`, width, height, width, height)
+	return &svgDiagramCanvas{writer: writer}
+}
+
+func (what *svgDiagramCanvas) beginGroup(class string, id string, title string) {
+	_, _ = fmt.Fprintf(what.writer, `<g class="%s"`, html.EscapeString(class))
+	if len(id) > 0 {
+		_, _ = fmt.Fprintf(what.writer, ` data-id="%s"`, html.EscapeString(id))
+	}
+	_, _ = what.writer.WriteString(">\n")
+	if len(title) > 0 {
+		_, _ = fmt.Fprintf(what.writer, "<title>%s</title>\n", html.EscapeString(title))
+	}
+}
+
+func (what *svgDiagramCanvas) endGroup() {
+	_, _ = what.writer.WriteString("</g>\n")
+}
+
+func (what *svgDiagramCanvas) drawPath(path diagramPath, fillColor string, strokeColor string, penWidth float64, style string) {
+	var data strings.Builder
+	for _, operation := range path {
+		data.WriteByte(operation.operation)
+		for _, value := range operation.points {
+			data.WriteString(fmt.Sprintf(" %.2f", value))
+		}
+		data.WriteByte(' ')
+	}
+
+	_, _ = fmt.Fprintf(what.writer, `<path d="%s"%s`, strings.TrimSpace(data.String()), svgColorAttributes("fill", fillColor))
+	if len(strokeColor) > 0 && penWidth > 0 {
+		_, _ = fmt.Fprintf(what.writer, `%s stroke-width="%.2f"`, svgColorAttributes("stroke", strokeColor), penWidth)
+		if dashes := diagramDashes(style); len(dashes) > 0 {
+			_, _ = fmt.Fprintf(what.writer, ` stroke-dasharray="%.0f,%.0f"`, dashes[0], dashes[1])
+		}
+	}
+	_, _ = what.writer.WriteString("/>\n")
+}
+
+func (what *svgDiagramCanvas) drawText(text diagramText, x float64, baseline float64) {
+	weight := ""
+	if text.bold {
+		weight = ` font-weight="bold"`
+	}
+	_, _ = fmt.Fprintf(what.writer, `<text x="%.2f" y="%.2f" text-anchor="middle" font-size="%.0f"%s%s>%s</text>
+`, x, baseline, text.size, weight, svgColorAttributes("fill", text.color), html.EscapeString(text.text))
+}
+
+func (what *svgDiagramCanvas) finish() {
+	_, _ = what.writer.WriteString("</svg>\n")
+}
+
+func svgColorAttributes(attribute string, value string) string {
+	if len(value) == 0 {
+		return fmt.Sprintf(` %s="none"`, attribute)
+	}
+	rgba := parseDiagramColor(value)
+	result := fmt.Sprintf(` %s="#%02X%02X%02X"`, attribute, rgba.R, rgba.G, rgba.B)
+	if rgba.A != 0xFF {
+		result += fmt.Sprintf(` %s-opacity="%.2f"`, attribute, float64(rgba.A)/0xFF)
+	}
+	return result
+}
+
+// PNG ===============================================================================
+
+type pngDiagramCanvas struct {
+	image   *image.RGBA
+	context *drawing.RasterGraphicContext
+}
+
+func newPngDiagramCanvas(width float64, height float64, scale float64) (*pngDiagramCanvas, error) {
+	img := image.NewRGBA(image.Rect(0, 0, int(math.Ceil(width*scale)), int(math.Ceil(height*scale))))
+	context, err := drawing.NewRasterGraphicContext(img)
+	if err != nil {
+		return nil, err
+	}
+	diagramFontFace, err := diagramFont()
+	if err != nil {
+		return nil, err
+	}
+	// the raster context scales glyphs by font size times DPI in 26.6 fixed point units, so 64 renders one point per unit
+	context.SetDPI(64)
+	context.SetFont(diagramFontFace)
+	context.Scale(scale, scale)
+	return &pngDiagramCanvas{image: img, context: context}, nil
+}
+
+func (what *pngDiagramCanvas) beginGroup(string, string, string) {
+}
+
+func (what *pngDiagramCanvas) endGroup() {
+}
+
+func (what *pngDiagramCanvas) drawPath(path diagramPath, fillColor string, strokeColor string, penWidth float64, style string) {
+	if len(fillColor) > 0 {
+		what.tracePath(path)
+		what.context.SetFillColor(parseDiagramColor(fillColor))
+		what.context.Fill()
+	}
+	if len(strokeColor) > 0 && penWidth > 0 {
+		what.tracePath(path)
+		what.context.SetStrokeColor(parseDiagramColor(strokeColor))
+		what.context.SetLineWidth(penWidth)
+		what.context.SetLineDash(diagramDashes(style), 0)
+		what.context.Stroke()
+	}
+}
+
+func (what *pngDiagramCanvas) tracePath(path diagramPath) {
+	what.context.BeginPath()
+	for _, operation := range path {
+		switch operation.operation {
+		case 'M':
+			what.context.MoveTo(operation.points[0], operation.points[1])
+		case 'L':
+			what.context.LineTo(operation.points[0], operation.points[1])
+		case 'C':
+			what.context.CubicCurveTo(operation.points[0], operation.points[1], operation.points[2], operation.points[3], operation.points[4], operation.points[5])
+		case 'Z':
+			what.context.Close()
+		}
+	}
+}
+
+func (what *pngDiagramCanvas) drawText(text diagramText, x float64, baseline float64) {
+	textColor := parseDiagramColor(text.color)
+	what.context.SetFontSize(text.size)
+	what.context.SetFillColor(textColor)
+	what.context.BeginPath()
+	_, err := what.context.CreateStringPath(text.text, x-measureDiagramText(text.text, text.size)/2, baseline)
+	if err != nil {
+		return
+	}
+	if text.bold {
+		// the embedded font has no bold variant, so thicken the glyphs instead
+		what.context.SetStrokeColor(textColor)
+		what.context.SetLineWidth(text.size * 0.04)
+		what.context.SetLineDash(nil, 0)
+		what.context.FillStroke()
+		return
+	}
+	what.context.Fill()
+}
+
+func (what *pngDiagramCanvas) finish() {
+}
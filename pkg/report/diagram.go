@@ -0,0 +1,486 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// diagram is the renderer independent description of a diagram as used by the native (graphviz-free) renderer.
+// It mirrors what the DOT writers emit: nested clusters for trust boundaries, styled nodes and styled edges.
+type diagram struct {
+	title       string
+	leftToRight bool
+	nodesep     float64
+	ranksep     float64
+	edgeLayout  string
+	root        *diagramCluster
+	edges       []*diagramEdge
+	sameRank    [][]string
+	width       float64
+	height      float64
+}
+
+type diagramCluster struct {
+	id        string
+	class     string
+	label     string
+	color     string
+	bgColor   string
+	fontColor string
+	style     string
+	penWidth  float64
+	margin    float64
+	clusters  []*diagramCluster
+	nodes     []*diagramNode
+	x, y      float64
+	width     float64
+	height    float64
+}
+
+type diagramNode struct {
+	id           string
+	class        string
+	shape        string
+	rows         [][]diagramText
+	compartments bool
+	fillColor    string
+	borderColor  string
+	borderStyle  string
+	penWidth     float64
+	peripheries  int
+	x, y         float64
+	width        float64
+	height       float64
+}
+
+type diagramText struct {
+	text  string
+	size  float64
+	color string
+	bold  bool
+}
+
+type diagramEdge struct {
+	id         string
+	class      string
+	from       string
+	to         string
+	color      string
+	style      string
+	penWidth   float64
+	arrowHead  string
+	label      string
+	labelColor string
+	constraint bool
+	weight     int
+	invisible  bool
+	points     []diagramPoint
+	curved     bool
+}
+
+type diagramPoint struct {
+	x, y float64
+}
+
+const (
+	diagramFontSizeTitle   = 40
+	diagramFontSizeCluster = 21
+	diagramFontSizeNode    = 20
+	diagramFontSizeSmall   = 15
+	diagramFontSizeEdge    = 18
+	diagramPointsPerInch   = 72
+)
+
+func makeDataFlowDiagram(parsedModel *types.Model, addModelTitle bool, addLegend bool) (*diagram, error) {
+	result := &diagram{
+		leftToRight: parsedModel.DiagramTweakLayoutLeftToRight,
+		nodesep:     36,
+		ranksep:     90,
+		edgeLayout:  "ortho",
+		root:        &diagramCluster{},
+	}
+	if addModelTitle {
+		result.title = parsedModel.Title
+	}
+	if parsedModel.DiagramTweakNodesep > 0 {
+		result.nodesep = float64(parsedModel.DiagramTweakNodesep * diagramPointsPerInch)
+	}
+	if parsedModel.DiagramTweakRanksep > 0 {
+		result.ranksep = float64(parsedModel.DiagramTweakRanksep * diagramPointsPerInch)
+	}
+	if len(parsedModel.DiagramTweakEdgeLayout) > 0 {
+		switch parsedModel.DiagramTweakEdgeLayout {
+		case "spline", "polyline", "ortho", "curved", "false":
+			result.edgeLayout = parsedModel.DiagramTweakEdgeLayout
+		default:
+			return nil, fmt.Errorf("unknown value for diagram_tweak_edge_layout (spline, polyline, ortho, curved, false): %s", parsedModel.DiagramTweakEdgeLayout)
+		}
+	}
+
+	if addLegend {
+		legendClusters := makeLegendClusters()
+		result.root.clusters = append(result.root.clusters, legendClusters...)
+		// stack the legends instead of placing them all side by side
+		for i := 1; i < len(legendClusters); i++ {
+			result.edges = append(result.edges, &diagramEdge{from: legendClusters[i-1].nodes[0].id, to: legendClusters[i].nodes[0].id, constraint: true, invisible: true})
+		}
+	}
+
+	// Trust Boundaries ===============================================================================
+	clustersById := make(map[string]*diagramCluster)
+	keys := make([]string, 0)
+	for k := range parsedModel.TrustBoundaries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		trustBoundary := parsedModel.TrustBoundaries[key]
+		if len(trustBoundary.TechnicalAssetsInside) == 0 && len(trustBoundary.TrustBoundariesNested) == 0 {
+			continue
+		}
+		color, fontColor, bgColor, style := rgbHexColorTwilight(), rgbHexColorTwilight(), "#FAFAFA", "dashed"
+		penWidth := 4.5
+		if len(trustBoundary.TrustBoundariesNested) > 0 {
+			penWidth = 5.5
+		}
+		if parsedModel.FindParentTrustBoundary(trustBoundary) != nil {
+			bgColor = "#F1F1F1"
+		}
+		if trustBoundary.Type == types.NetworkPolicyNamespaceIsolation {
+			fontColor, bgColor = "#222222", "#DFF4FF"
+		}
+		if trustBoundary.Type == types.ExecutionEnvironment {
+			fontColor, bgColor, style = "#555555", "#FFFFF0", "dotted"
+		}
+		clustersById[trustBoundary.Id] = &diagramCluster{
+			id:        trustBoundary.Id,
+			class:     "trust-boundary",
+			label:     trustBoundary.Title + " (" + trustBoundary.Type.String() + ")",
+			color:     color,
+			bgColor:   bgColor,
+			fontColor: fontColor,
+			style:     style,
+			penWidth:  penWidth,
+			margin:    30,
+		}
+	}
+	for _, key := range keys {
+		cluster, ok := clustersById[key]
+		if !ok {
+			continue
+		}
+		trustBoundary := parsedModel.TrustBoundaries[key]
+		nested := trustBoundary.TrustBoundariesNested
+		sort.Strings(nested)
+		for _, nestedId := range nested {
+			if nestedCluster, ok := clustersById[nestedId]; ok {
+				cluster.clusters = append(cluster.clusters, nestedCluster)
+			}
+		}
+		if parent := parsedModel.FindParentTrustBoundary(trustBoundary); parent == nil || clustersById[parent.Id] == nil {
+			result.root.clusters = append(result.root.clusters, cluster)
+		}
+	}
+
+	// Technical Assets ===============================================================================
+	var techAssets []*types.TechnicalAsset
+	for _, techAsset := range parsedModel.TechnicalAssets {
+		techAssets = append(techAssets, techAsset)
+	}
+	sort.Sort(types.ByOrderAndIdSort(techAssets))
+	for _, technicalAsset := range techAssets {
+		node := makeTechAssetDiagramNode(parsedModel, technicalAsset)
+		if cluster, ok := clustersById[parsedModel.GetTechnicalAssetTrustBoundaryId(technicalAsset)]; ok {
+			cluster.nodes = append(cluster.nodes, node)
+		} else {
+			result.root.nodes = append(result.root.nodes, node)
+		}
+	}
+
+	// Data Flows (Technical Communication Links) ===============================================================================
+	for _, technicalAsset := range techAssets {
+		for _, dataFlow := range technicalAsset.CommunicationLinks {
+			arrowHead := "normal"
+			if dataFlow.Readonly {
+				arrowHead = "empty"
+			}
+			penWidth, _ := strconv.ParseFloat(determineArrowPenWidth(dataFlow, parsedModel), 64)
+			edge := &diagramEdge{
+				id:         dataFlow.Id,
+				class:      "communication-link",
+				from:       technicalAsset.Id,
+				to:         dataFlow.TargetId,
+				color:      determineArrowColor(dataFlow, parsedModel),
+				style:      determineArrowLineStyle(dataFlow),
+				penWidth:   penWidth,
+				arrowHead:  arrowHead,
+				constraint: dataFlow.DiagramTweakConstraint,
+				weight:     dataFlow.DiagramTweakWeight,
+			}
+			if !parsedModel.DiagramTweakSuppressEdgeLabels {
+				edge.label = dataFlow.Protocol.String()
+				edge.labelColor = determineLabelColor(dataFlow, parsedModel)
+			}
+			result.edges = append(result.edges, edge)
+		}
+	}
+
+	invisibleConnections, err := diagramInvisibleConnections(parsedModel)
+	if err != nil {
+		return nil, fmt.Errorf("error while making diagram invisible connections tweaks: %w", err)
+	}
+	for _, connection := range invisibleConnections {
+		result.edges = append(result.edges, &diagramEdge{from: connection[0], to: connection[1], constraint: true, invisible: true})
+	}
+
+	result.sameRank, err = diagramSameRankAssets(parsedModel)
+	if err != nil {
+		return nil, fmt.Errorf("error while making diagram same-rank node tweaks: %w", err)
+	}
+
+	return result, nil
+}
+
+func makeDataAssetDiagram(parsedModel *types.Model) *diagram {
+	result := &diagram{
+		leftToRight: true,
+		nodesep:     1.0 * diagramPointsPerInch,
+		ranksep:     3.0 * diagramPointsPerInch,
+		edgeLayout:  "false",
+		root:        &diagramCluster{},
+	}
+
+	// Technical Assets ===============================================================================
+	techAssets := make([]*types.TechnicalAsset, 0)
+	for _, techAsset := range parsedModel.TechnicalAssets {
+		techAssets = append(techAssets, techAsset)
+	}
+	sort.Sort(types.ByOrderAndIdSort(techAssets))
+	for _, technicalAsset := range techAssets {
+		if len(technicalAsset.DataAssetsStored) > 0 || len(technicalAsset.DataAssetsProcessed) > 0 {
+			result.root.nodes = append(result.root.nodes, makeSimplifiedTechAssetDiagramNode(parsedModel, technicalAsset))
+		}
+	}
+
+	// Data Assets ===============================================================================
+	dataAssets := make([]*types.DataAsset, 0)
+	for _, dataAsset := range parsedModel.DataAssets {
+		dataAssets = append(dataAssets, dataAsset)
+	}
+	sortByDataAssetDataBreachProbabilityAndTitle(parsedModel, dataAssets)
+	for _, dataAsset := range dataAssets {
+		result.root.nodes = append(result.root.nodes, makeDataAssetDiagramNode(parsedModel, dataAsset))
+	}
+
+	// Data Asset to Tech Asset links ===============================================================================
+	for _, technicalAsset := range techAssets {
+		for _, sourceId := range technicalAsset.DataAssetsStored {
+			result.edges = append(result.edges, &diagramEdge{from: sourceId, to: technicalAsset.Id, color: "#0000FF", style: "solid", penWidth: 1.0, arrowHead: "normal", constraint: true})
+		}
+		for _, sourceId := range technicalAsset.DataAssetsProcessed {
+			if !contains(technicalAsset.DataAssetsStored, sourceId) { // here only if not already drawn above
+				result.edges = append(result.edges, &diagramEdge{from: sourceId, to: technicalAsset.Id, color: "#666666", style: "dashed", penWidth: 1.0, arrowHead: "normal", constraint: true})
+			}
+		}
+	}
+
+	return result
+}
+
+func makeTechAssetDiagramNode(parsedModel *types.Model, technicalAsset *types.TechnicalAsset) *diagramNode {
+	var shape string
+	switch technicalAsset.Type {
+	case types.ExternalEntity:
+		shape = "box"
+	case types.Process:
+		shape = "ellipse"
+	case types.Datastore:
+		shape = "cylinder"
+	}
+	if technicalAsset.UsedAsClientByHuman {
+		shape = "octagon"
+	}
+
+	// RAA = Relative Attacker Attractiveness
+	attackerAttractivenessLabel := "RAA: " + fmt.Sprintf("%.0f", technicalAsset.RAA) + " %"
+	if technicalAsset.OutOfScope {
+		attackerAttractivenessLabel = "RAA: out of scope"
+	}
+
+	penWidth, _ := strconv.ParseFloat(determineShapeBorderPenWidth(technicalAsset, parsedModel), 64)
+	return &diagramNode{
+		id:    technicalAsset.Id,
+		class: "technical-asset",
+		shape: shape,
+		rows: [][]diagramText{
+			{
+				{text: technicalAsset.Technologies.String(), size: diagramFontSizeSmall, color: DarkBlue},
+				{text: technicalAsset.Size.String(), size: diagramFontSizeSmall, color: LightGray},
+			},
+			{{text: technicalAsset.Title, size: diagramFontSizeNode, color: determineTechnicalAssetLabelColor(technicalAsset, parsedModel), bold: true}},
+			{{text: attackerAttractivenessLabel, size: diagramFontSizeSmall, color: "#603112"}},
+		},
+		compartments: technicalAsset.MultiTenant,
+		fillColor:    determineShapeFillColor(technicalAsset, parsedModel),
+		borderColor:  determineShapeBorderColor(technicalAsset, parsedModel),
+		borderStyle:  determineShapeBorderLineStyle(technicalAsset),
+		penWidth:     penWidth,
+		peripheries:  determineShapePeripheries(technicalAsset),
+	}
+}
+
+func makeSimplifiedTechAssetDiagramNode(parsedModel *types.Model, technicalAsset *types.TechnicalAsset) *diagramNode {
+	color := rgbHexColorOutOfScope()
+	if !technicalAsset.OutOfScope {
+		generatedRisks := parsedModel.GeneratedRisks(technicalAsset)
+		switch types.HighestSeverityStillAtRisk(generatedRisks) {
+		case types.CriticalSeverity:
+			color = rgbHexColorCriticalRisk()
+		case types.HighSeverity:
+			color = rgbHexColorHighRisk()
+		case types.ElevatedSeverity:
+			color = rgbHexColorElevatedRisk()
+		case types.MediumSeverity:
+			color = rgbHexColorMediumRisk()
+		case types.LowSeverity:
+			color = rgbHexColorLowRisk()
+		default:
+			color = "#444444" // since black is too dark here as fill color
+		}
+		if len(types.ReduceToOnlyStillAtRisk(generatedRisks)) == 0 {
+			color = "#444444" // since black is too dark here as fill color
+		}
+	}
+	return &diagramNode{
+		id:          technicalAsset.Id,
+		class:       "technical-asset",
+		shape:       "box",
+		rows:        [][]diagramText{{{text: technicalAsset.Title, size: diagramFontSizeNode, color: "#FFFFFF", bold: true}}},
+		fillColor:   color,
+		borderColor: color,
+		borderStyle: "solid",
+		penWidth:    3.0,
+		peripheries: 1,
+	}
+}
+
+func makeDataAssetDiagramNode(parsedModel *types.Model, dataAsset *types.DataAsset) *diagramNode {
+	var color string
+	switch identifiedDataBreachProbabilityStillAtRisk(parsedModel, dataAsset) {
+	case types.Probable:
+		color = rgbHexColorHighRisk()
+	case types.Possible:
+		color = rgbHexColorMediumRisk()
+	case types.Improbable:
+		color = rgbHexColorLowRisk()
+	default:
+		color = "#444444" // since black is too dark here as fill color
+	}
+	if !isDataBreachPotentialStillAtRisk(parsedModel, dataAsset) {
+		color = "#444444" // since black is too dark here as fill color
+	}
+	return &diagramNode{
+		id:          dataAsset.Id,
+		class:       "data-asset",
+		shape:       "ellipse",
+		rows:        [][]diagramText{{{text: dataAsset.Title, size: diagramFontSizeNode, color: "#FFFFFF", bold: true}}},
+		fillColor:   color,
+		borderColor: color,
+		borderStyle: "solid",
+		penWidth:    3.0,
+		peripheries: 1,
+	}
+}
+
+func makeLegendClusters() []*diagramCluster {
+	legend := func(id string, label string, nodes ...*diagramNode) *diagramCluster {
+		return &diagramCluster{id: id, class: "legend", label: label, color: "#D3D3D3", fontColor: Black, style: "dashed", penWidth: 1.0, margin: 20, nodes: nodes}
+	}
+	return []*diagramCluster{
+		legend("shape_legend", "Shape legend",
+			makeLegendDiagramNode("external_entity_item", "External Entity", false, Black, "box", "solid", 2.0, VeryLightGray, Black),
+			makeLegendDiagramNode("process_item", "Process", false, Black, "ellipse", "solid", 2.0, VeryLightGray, Black),
+			makeLegendDiagramNode("datastore_item", "Datastore", false, Black, "cylinder", "solid", 2.0, VeryLightGray, Black),
+			makeLegendDiagramNode("used_as_client_item", "Used as client", false, Black, "octagon", "solid", 2.0, VeryLightGray, Black)),
+		legend("tenant_legend", "Tenant legend",
+			makeLegendDiagramNode("single_tenant", "Single tenant", false, Black, "box", "solid", 2.0, VeryLightGray, Black),
+			makeLegendDiagramNode("multi_tenant", "Multitenant", true, Black, "box", "solid", 2.0, VeryLightGray, Black)),
+		legend("label_legend", "Label color legend",
+			makeLegendDiagramNode("mission_critical", "Mission Critical Asset", false, Red, "box", "solid", 3.0, VeryLightGray, Red),
+			makeLegendDiagramNode("critical", "Critical Asset", false, Amber, "box", "solid", 3.0, VeryLightGray, Amber),
+			makeLegendDiagramNode("other", "Important and Other Assets", false, Black, "box", "solid", 2.0, VeryLightGray, Black)),
+		legend("border_line_legend", "Border line legend",
+			makeLegendDiagramNode("dotted", "Model forgery attempt", false, Black, "box", "dotted", 2.0, VeryLightGray, Black),
+			makeLegendDiagramNode("solid", "Normal", false, Black, "box", "solid", 2.0, VeryLightGray, Black)),
+		legend("fill_legend", "Shape fill legend (darker for physical machines, brighter for container and even more brighter for serverless)",
+			makeLegendDiagramNode("invalid_item", "No data processed or stored, or using unknown technology, or no communication links", false, Black, "box", "solid", 2.0, LightPink, Black),
+			makeLegendDiagramNode("internet", "Asset used over the internet", false, Black, "box", "solid", 2.0, ExtremeLightBlue, Black),
+			makeLegendDiagramNode("out_of_scope", "Out of scope", false, Black, "box", "solid", 2.0, OutOfScopeFancy, Black),
+			makeLegendDiagramNode("custom_developed_part", "Custom developed part", false, Black, "box", "solid", 2.0, CustomDevelopedParts, Black),
+			makeLegendDiagramNode("other_assets", "Other assets", false, Black, "box", "solid", 2.0, VeryLightGray, Black)),
+	}
+}
+
+func makeLegendDiagramNode(id, title string, compartments bool, labelColor, shape, borderLineStyle string, borderPenWidth float64, shapeFillColor, shapeBorderColor string) *diagramNode {
+	return &diagramNode{
+		id:    "legend_" + id,
+		class: "legend",
+		shape: shape,
+		rows: [][]diagramText{
+			{
+				{text: "list of technologies", size: diagramFontSizeSmall, color: DarkBlue},
+				{text: "technical asset size", size: diagramFontSizeSmall, color: LightGray},
+			},
+			{{text: title, size: diagramFontSizeNode, color: labelColor, bold: true}},
+			{{text: "attacker attractiveness level", size: diagramFontSizeSmall, color: Black}},
+		},
+		compartments: compartments,
+		fillColor:    shapeFillColor,
+		borderColor:  shapeBorderColor,
+		borderStyle:  borderLineStyle,
+		penWidth:     borderPenWidth,
+		peripheries:  1,
+	}
+}
+
+// diagramSameRankAssets returns the groups of technical asset ids of the diagram_tweak_same_rank_assets setting.
+func diagramSameRankAssets(parsedModel *types.Model) ([][]string, error) {
+	groups := make([][]string, 0)
+	for _, sameRank := range parsedModel.DiagramTweakSameRankAssets {
+		assetIDs := strings.Split(sameRank, ":")
+		for _, id := range assetIDs {
+			err := parsedModel.CheckTechnicalAssetExists(id, "diagram tweak same-rank", true)
+			if err != nil {
+				return nil, fmt.Errorf("error while checking technical asset existence: %w", err)
+			}
+			if len(parsedModel.GetTechnicalAssetTrustBoundaryId(parsedModel.TechnicalAssets[id])) > 0 {
+				return nil, fmt.Errorf("technical assets (referenced in same rank diagram tweak) are inside trust boundaries: %v", parsedModel.DiagramTweakSameRankAssets)
+			}
+		}
+		groups = append(groups, assetIDs)
+	}
+	return groups, nil
+}
+
+// diagramInvisibleConnections returns the source and target technical asset ids of the diagram_tweak_invisible_connections_between_assets setting.
+func diagramInvisibleConnections(parsedModel *types.Model) ([][2]string, error) {
+	connections := make([][2]string, 0)
+	for _, invisibleConnections := range parsedModel.DiagramTweakInvisibleConnectionsBetweenAssets {
+		assetIDs := strings.Split(invisibleConnections, ":")
+		if len(assetIDs) != 2 {
+			continue
+		}
+		for _, id := range assetIDs {
+			err := parsedModel.CheckTechnicalAssetExists(id, "diagram tweak connections", true)
+			if err != nil {
+				return nil, fmt.Errorf("error while checking technical asset existence: %w", err)
+			}
+		}
+		connections = append(connections, [2]string{assetIDs[0], assetIDs[1]})
+	}
+	return connections, nil
+}
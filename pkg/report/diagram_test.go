@@ -0,0 +1,206 @@
+package report
+
+import (
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// newDiagramTestModel has a browser outside of any trust boundary, a web server in the "cloud" boundary and a
+// database in the "database-network" boundary nested in "cloud"
+func newDiagramTestModel() *types.Model {
+	browser := &types.TechnicalAsset{Id: "browser", Title: "Browser", Type: types.ExternalEntity, UsedAsClientByHuman: true,
+		CommunicationLinks: []*types.CommunicationLink{{Id: "browser>web", SourceId: "browser", TargetId: "web-server", Protocol: types.HTTPS}}}
+	webServer := &types.TechnicalAsset{Id: "web-server", Title: "Web Server", Type: types.Process,
+		CommunicationLinks: []*types.CommunicationLink{{Id: "web>db", SourceId: "web-server", TargetId: "database", Protocol: types.JdbcEncrypted, Readonly: true}}}
+	database := &types.TechnicalAsset{Id: "database", Title: "Database", Type: types.Datastore}
+
+	return &types.Model{
+		Title: "Diagram Test",
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			browser.Id:   browser,
+			webServer.Id: webServer,
+			database.Id:  database,
+		},
+		TrustBoundaries: map[string]*types.TrustBoundary{
+			"cloud": {Id: "cloud", Title: "Cloud", Type: types.NetworkCloudProvider,
+				TechnicalAssetsInside: []string{"web-server"}, TrustBoundariesNested: []string{"database-network"}},
+			"database-network": {Id: "database-network", Title: "Database Network", Type: types.NetworkCloudSecurityGroup,
+				TechnicalAssetsInside: []string{"database"}},
+			"empty": {Id: "empty", Title: "Empty", Type: types.NetworkOnPrem},
+		},
+	}
+}
+
+func diagramEdgeById(t *testing.T, d *diagram, id string) *diagramEdge {
+	t.Helper()
+
+	for _, edge := range d.edges {
+		if edge.id == id {
+			return edge
+		}
+	}
+
+	require.Failf(t, "edge not found", "edge %q", id)
+	return nil
+}
+
+func assertNodeInsideCluster(t *testing.T, node *diagramNode, cluster *diagramCluster) {
+	t.Helper()
+
+	assert.GreaterOrEqual(t, node.x-node.width/2, cluster.x, "%v left of %v", node.id, cluster.id)
+	assert.LessOrEqual(t, node.x+node.width/2, cluster.x+cluster.width, "%v right of %v", node.id, cluster.id)
+	assert.GreaterOrEqual(t, node.y-node.height/2, cluster.y, "%v above %v", node.id, cluster.id)
+	assert.LessOrEqual(t, node.y+node.height/2, cluster.y+cluster.height, "%v below %v", node.id, cluster.id)
+}
+
+func nodeOverlapsCluster(node *diagramNode, cluster *diagramCluster) bool {
+	return node.x+node.width/2 > cluster.x && node.x-node.width/2 < cluster.x+cluster.width &&
+		node.y+node.height/2 > cluster.y && node.y-node.height/2 < cluster.y+cluster.height
+}
+
+func TestDataFlowDiagramNestedTrustBoundaries(t *testing.T) {
+	for _, leftToRight := range []bool{false, true} {
+		parsedModel := newDiagramTestModel()
+		parsedModel.DiagramTweakLayoutLeftToRight = leftToRight
+
+		d, err := makeDataFlowDiagram(parsedModel, true, false)
+		require.NoError(t, err)
+		d.layout()
+
+		// trust boundaries without assets are left out, nested ones end up in their parent
+		require.Len(t, d.root.clusters, 1)
+		cloud := d.root.clusters[0]
+		assert.Equal(t, "cloud", cloud.id)
+		require.Len(t, cloud.clusters, 1)
+		databaseNetwork := cloud.clusters[0]
+		assert.Equal(t, "database-network", databaseNetwork.id)
+
+		require.Len(t, d.root.nodes, 1)
+		browser := d.root.nodes[0]
+		assert.Equal(t, "browser", browser.id)
+		assert.Equal(t, "octagon", browser.shape)
+		require.Len(t, cloud.nodes, 1)
+		webServer := cloud.nodes[0]
+		assert.Equal(t, "ellipse", webServer.shape)
+		require.Len(t, databaseNetwork.nodes, 1)
+		database := databaseNetwork.nodes[0]
+		assert.Equal(t, "cylinder", database.shape)
+
+		assertNodeInsideCluster(t, webServer, cloud)
+		assertNodeInsideCluster(t, database, databaseNetwork)
+		assertNodeInsideCluster(t, database, cloud)
+		assert.False(t, nodeOverlapsCluster(webServer, databaseNetwork), "web server overlaps nested boundary")
+		assert.False(t, nodeOverlapsCluster(browser, cloud), "browser overlaps boundary")
+
+		assert.GreaterOrEqual(t, databaseNetwork.x, cloud.x)
+		assert.GreaterOrEqual(t, databaseNetwork.y, cloud.y)
+		assert.LessOrEqual(t, databaseNetwork.x+databaseNetwork.width, cloud.x+cloud.width)
+		assert.LessOrEqual(t, databaseNetwork.y+databaseNetwork.height, cloud.y+cloud.height)
+
+		assert.LessOrEqual(t, cloud.x+cloud.width, d.width)
+		assert.LessOrEqual(t, cloud.y+cloud.height, d.height)
+		assert.Greater(t, cloud.y, float64(diagramFontSizeTitle), "title space is kept free")
+	}
+}
+
+func TestDataFlowDiagramEdgeLayouts(t *testing.T) {
+	tests := []struct {
+		edgeLayout string
+		points     int
+		curved     bool
+	}{
+		{"", 4, false}, // ortho is the default
+		{"ortho", 4, false},
+		{"spline", 4, true},
+		{"curved", 4, true},
+		{"polyline", 2, false},
+		{"false", 2, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.edgeLayout, func(t *testing.T) {
+			parsedModel := newDiagramTestModel()
+			parsedModel.DiagramTweakEdgeLayout = test.edgeLayout
+
+			d, err := makeDataFlowDiagram(parsedModel, false, false)
+			require.NoError(t, err)
+			d.layout()
+
+			nodes := d.nodesById()
+			for _, id := range []string{"browser>web", "web>db"} {
+				edge := diagramEdgeById(t, d, id)
+				require.Len(t, edge.points, test.points, id)
+				assert.Equal(t, test.curved, edge.curved, id)
+
+				// the edges start and end at the outline of their nodes
+				source, target := nodes[edge.from], nodes[edge.to]
+				start, end := edge.points[0], edge.points[len(edge.points)-1]
+				assert.InDelta(t, source.x, start.x, source.width/2+0.01, id)
+				assert.InDelta(t, source.y, start.y, source.height/2+0.01, id)
+				assert.InDelta(t, target.x, end.x, target.width/2+0.01, id)
+				assert.InDelta(t, target.y, end.y, target.height/2+0.01, id)
+
+				if test.points == 4 && !test.curved {
+					for i := 1; i < len(edge.points); i++ {
+						previous, point := edge.points[i-1], edge.points[i]
+						assert.True(t, math.Abs(previous.x-point.x) < 0.01 || math.Abs(previous.y-point.y) < 0.01,
+							"segment %d of %v is not orthogonal: %v -> %v", i, id, previous, point)
+					}
+				}
+			}
+
+			assert.Equal(t, types.HTTPS.String(), diagramEdgeById(t, d, "browser>web").label)
+			assert.Equal(t, "empty", diagramEdgeById(t, d, "web>db").arrowHead)
+		})
+	}
+}
+
+func TestDataFlowDiagramInvalidEdgeLayout(t *testing.T) {
+	parsedModel := newDiagramTestModel()
+	parsedModel.DiagramTweakEdgeLayout = "zigzag"
+
+	_, err := makeDataFlowDiagram(parsedModel, false, false)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "diagram_tweak_edge_layout")
+	assert.Contains(t, err.Error(), "zigzag")
+}
+
+func TestWriteNativeDiagram(t *testing.T) {
+	d, err := makeDataFlowDiagram(newDiagramTestModel(), true, true)
+	require.NoError(t, err)
+
+	filenamePNG := filepath.Join(t.TempDir(), "data-flow-diagram.png")
+	require.NoError(t, writeNativeDiagram(d, filenamePNG, 144))
+
+	pngFile, err := os.Open(filenamePNG)
+	require.NoError(t, err)
+	defer func() { _ = pngFile.Close() }()
+	config, err := png.DecodeConfig(pngFile)
+	require.NoError(t, err)
+	assert.Equal(t, int(math.Ceil(d.width*2)), config.Width)
+	assert.Equal(t, int(math.Ceil(d.height*2)), config.Height)
+
+	svg, err := os.ReadFile(DiagramFilenameSVG(filenamePNG))
+	require.NoError(t, err)
+	for _, expected := range []string{
+		`<?xml version="1.0"`,
+		`<g class="trust-boundary" data-id="cloud">`,
+		`<g class="trust-boundary" data-id="database-network">`,
+		`<g class="technical-asset" data-id="web-server">`,
+		`<g class="communication-link" data-id="web&gt;db">`,
+		`<title>Cloud (network-cloud-provider)</title>`,
+		`>Diagram Test</text>`,
+		`</svg>`,
+	} {
+		assert.Contains(t, string(svg), expected)
+	}
+}
@@ -0,0 +1,174 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+const (
+	DiffFormatText     = "text"
+	DiffFormatJSON     = "json"
+	DiffFormatMarkdown = "markdown"
+)
+
+func WriteDiff(writer io.Writer, diff *model.ModelDiff, format string) error {
+	switch strings.ToLower(format) {
+	case DiffFormatText, "":
+		return WriteDiffText(writer, diff)
+
+	case DiffFormatJSON:
+		return WriteDiffJSON(writer, diff)
+
+	case DiffFormatMarkdown, "md":
+		return WriteDiffMarkdown(writer, diff)
+
+	default:
+		return fmt.Errorf("unknown diff format %q (expected one of %v, %v, %v)", format, DiffFormatText, DiffFormatJSON, DiffFormatMarkdown)
+	}
+}
+
+func WriteDiffJSON(writer io.Writer, diff *model.ModelDiff) error {
+	jsonBytes, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal diff to JSON: %w", err)
+	}
+
+	_, err = writer.Write(append(jsonBytes, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write diff JSON: %w", err)
+	}
+
+	return nil
+}
+
+func WriteDiffText(writer io.Writer, diff *model.ModelDiff) error {
+	var text strings.Builder
+	if diff.IsEmpty() {
+		text.WriteString("No differences found\n")
+		_, err := io.WriteString(writer, text.String())
+		return err
+	}
+
+	writeRisks := func(title string, risks []*types.Risk) {
+		if len(risks) == 0 {
+			return
+		}
+
+		text.WriteString(fmt.Sprintf("%v (%d):\n", title, len(risks)))
+		for _, risk := range risks {
+			text.WriteString(fmt.Sprintf("  [%v] %v (%v)\n", risk.Severity, risk.SyntheticId, risk.RiskStatus))
+			text.WriteString(fmt.Sprintf("      %v\n", removeFormattingTags(risk.Title)))
+		}
+		text.WriteString("\n")
+	}
+
+	writeRisks("New risks", diff.NewRisks)
+	writeRisks("Resolved risks", diff.ResolvedRisks)
+
+	if len(diff.ChangedRisks) > 0 {
+		text.WriteString(fmt.Sprintf("Changed risks (%d):\n", len(diff.ChangedRisks)))
+		for _, change := range diff.ChangedRisks {
+			text.WriteString(fmt.Sprintf("  %v\n", change.SyntheticId))
+			for _, field := range change.Changes {
+				text.WriteString(fmt.Sprintf("      %v: %v -> %v\n", field.Field, field.Baseline, field.Current))
+			}
+		}
+		text.WriteString("\n")
+	}
+
+	writeElements := func(title string, elements []*model.ElementChange) {
+		if len(elements) == 0 {
+			return
+		}
+
+		text.WriteString(fmt.Sprintf("%v (%d):\n", title, len(elements)))
+		for _, element := range elements {
+			text.WriteString(fmt.Sprintf("  %v: %v\n", element.Id, element.Title))
+		}
+		text.WriteString("\n")
+	}
+
+	writeElements("Added technical assets", diff.AddedTechnicalAssets)
+	writeElements("Removed technical assets", diff.RemovedTechnicalAssets)
+	writeElements("Added communication links", diff.AddedCommunicationLinks)
+	writeElements("Removed communication links", diff.RemovedCommunicationLinks)
+	writeElements("Added data assets", diff.AddedDataAssets)
+	writeElements("Removed data assets", diff.RemovedDataAssets)
+
+	_, err := io.WriteString(writer, text.String())
+	return err
+}
+
+func WriteDiffMarkdown(writer io.Writer, diff *model.ModelDiff) error {
+	var text strings.Builder
+	text.WriteString("## Threat model changes\n\n")
+	if diff.IsEmpty() {
+		text.WriteString("No differences found.\n")
+		_, err := io.WriteString(writer, text.String())
+		return err
+	}
+
+	text.WriteString("| | Count |\n|---|---:|\n")
+	text.WriteString(fmt.Sprintf("| New risks | %d |\n", len(diff.NewRisks)))
+	text.WriteString(fmt.Sprintf("| Resolved risks | %d |\n", len(diff.ResolvedRisks)))
+	text.WriteString(fmt.Sprintf("| Changed risks | %d |\n", len(diff.ChangedRisks)))
+	text.WriteString("\n")
+
+	writeRisks := func(title string, risks []*types.Risk) {
+		if len(risks) == 0 {
+			return
+		}
+
+		text.WriteString(fmt.Sprintf("### %v\n\n", title))
+		text.WriteString("| Severity | Risk | Status | ID |\n|---|---|---|---|\n")
+		for _, risk := range risks {
+			text.WriteString(fmt.Sprintf("| %v | %v | %v | `%v` |\n", risk.Severity.Title(), escapeMarkdownTable(removeFormattingTags(risk.Title)), risk.RiskStatus.Title(), risk.SyntheticId))
+		}
+		text.WriteString("\n")
+	}
+
+	writeRisks("New risks", diff.NewRisks)
+	writeRisks("Resolved risks", diff.ResolvedRisks)
+
+	if len(diff.ChangedRisks) > 0 {
+		text.WriteString("### Changed risks\n\n")
+		text.WriteString("| Risk | Field | Baseline | Current |\n|---|---|---|---|\n")
+		for _, change := range diff.ChangedRisks {
+			for _, field := range change.Changes {
+				text.WriteString(fmt.Sprintf("| `%v` | %v | %v | %v |\n", change.SyntheticId, field.Field, field.Baseline, field.Current))
+			}
+		}
+		text.WriteString("\n")
+	}
+
+	writeElements := func(title string, elements []*model.ElementChange) {
+		if len(elements) == 0 {
+			return
+		}
+
+		text.WriteString(fmt.Sprintf("### %v\n\n", title))
+		for _, element := range elements {
+			text.WriteString(fmt.Sprintf("- `%v`: %v\n", element.Id, escapeMarkdownTable(element.Title)))
+		}
+		text.WriteString("\n")
+	}
+
+	writeElements("Added technical assets", diff.AddedTechnicalAssets)
+	writeElements("Removed technical assets", diff.RemovedTechnicalAssets)
+	writeElements("Added communication links", diff.AddedCommunicationLinks)
+	writeElements("Removed communication links", diff.RemovedCommunicationLinks)
+	writeElements("Added data assets", diff.AddedDataAssets)
+	writeElements("Removed data assets", diff.RemovedDataAssets)
+
+	_, err := io.WriteString(writer, text.String())
+	return err
+}
+
+func escapeMarkdownTable(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
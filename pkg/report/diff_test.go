@@ -0,0 +1,95 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// newDiffTestModelDiff has an entry in every section of a diff
+func newDiffTestModelDiff() *model.ModelDiff {
+	return &model.ModelDiff{
+		NewRisks: []*types.Risk{
+			{CategoryId: "sql-injection", SyntheticId: "sql-injection@db", Title: "<b>SQL Injection</b> at Database", Severity: types.HighSeverity, RiskStatus: types.Unchecked},
+		},
+		ResolvedRisks: []*types.Risk{
+			{CategoryId: "leak", SyntheticId: "leak@web", Title: "Leak at Web | Server", Severity: types.MediumSeverity, RiskStatus: types.Mitigated},
+		},
+		ChangedRisks: []*model.RiskChange{{
+			SyntheticId: "crash@web",
+			CategoryId:  "crash",
+			Title:       "Crash at Web Server",
+			Changes: []*model.FieldChange{
+				{Field: "severity", Baseline: types.LowSeverity.String(), Current: types.ElevatedSeverity.String()},
+				{Field: "status", Baseline: types.Unchecked.String(), Current: types.InProgress.String()},
+			},
+		}},
+		AddedTechnicalAssets:      []*model.ElementChange{{Id: "db", Title: "Database"}},
+		RemovedTechnicalAssets:    []*model.ElementChange{{Id: "cache", Title: "Cache"}},
+		AddedCommunicationLinks:   []*model.ElementChange{{Id: "web>db-access", Title: "DB Access"}},
+		RemovedCommunicationLinks: []*model.ElementChange{{Id: "web>cache-access", Title: "Cache Access"}},
+		AddedDataAssets:           []*model.ElementChange{{Id: "orders", Title: "Orders"}},
+		RemovedDataAssets:         []*model.ElementChange{{Id: "sessions", Title: "Sessions"}},
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	tests := []struct {
+		format string
+		golden string
+	}{
+		{DiffFormatText, "diff.txt"},
+		{DiffFormatMarkdown, "diff.md"},
+		{DiffFormatJSON, "diff.json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var output bytes.Buffer
+			require.NoError(t, WriteDiff(&output, newDiffTestModelDiff(), test.format))
+
+			goldenFilename := filepath.Join("testdata", test.golden)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(goldenFilename, output.Bytes(), 0600))
+			}
+			golden, err := os.ReadFile(goldenFilename)
+			require.NoError(t, err)
+			assert.Equal(t, string(golden), output.String())
+		})
+	}
+}
+
+func TestWriteDiffEmpty(t *testing.T) {
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{DiffFormatText, "No differences found\n"},
+		{DiffFormatMarkdown, "## Threat model changes\n\nNo differences found.\n"},
+		{DiffFormatJSON, "{}\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var output bytes.Buffer
+			require.NoError(t, WriteDiff(&output, new(model.ModelDiff), test.format))
+			assert.Equal(t, test.expected, output.String())
+		})
+	}
+}
+
+func TestWriteDiffUnknownFormat(t *testing.T) {
+	var output bytes.Buffer
+	err := WriteDiff(&output, newDiffTestModelDiff(), "html")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown diff format "html"`)
+	assert.Empty(t, output.String())
+}
@@ -19,10 +19,12 @@ type GenerateCommands struct {
 	RisksJSON           bool
 	TechnicalAssetsJSON bool
 	StatsJSON           bool
+	SARIF               bool
 	RisksExcel          bool
 	TagsExcel           bool
 	ReportPDF           bool
 	ReportADOC          bool
+	ReportHTML          bool
 }
 
 func (c *GenerateCommands) Defaults() *GenerateCommands {
@@ -32,10 +34,12 @@ func (c *GenerateCommands) Defaults() *GenerateCommands {
 		RisksJSON:           true,
 		TechnicalAssetsJSON: true,
 		StatsJSON:           true,
+		SARIF:               true,
 		RisksExcel:          true,
 		TagsExcel:           true,
 		ReportPDF:           true,
 		ReportADOC:          true,
+		ReportHTML:          true,
 	}
 	return c
 }
@@ -54,11 +58,13 @@ type reportConfigReader interface {
 	GetDataFlowDiagramFilenameDOT() string
 	GetDataAssetDiagramFilenameDOT() string
 	GetReportFilename() string
+	GetHtmlReportFilename() string
 	GetExcelRisksFilename() string
 	GetExcelTagsFilename() string
 	GetJsonRisksFilename() string
 	GetJsonTechnicalAssetsFilename() string
 	GetJsonStatsFilename() string
+	GetSarifFilename() string
 	GetTemplateFilename() string
 	GetReportLogoImagePath() string
 
@@ -71,6 +77,7 @@ type reportConfigReader interface {
 	GetRiskExcelColorText() bool
 
 	GetDiagramDPI() int
+	GetDiagramRenderer() string
 	GetMinGraphvizDPI() int
 	GetMaxGraphvizDPI() int
 
@@ -112,8 +119,22 @@ func Generate(config reportConfigReader, readResult *model.ReadResult, commands
 	} else if diagramDPI > config.GetMaxGraphvizDPI() {
 		diagramDPI = config.GetMaxGraphvizDPI()
 	}
+	nativeDiagrams := false
+	switch config.GetDiagramRenderer() {
+	case "", GraphvizDiagramRenderer:
+	case NativeDiagramRenderer:
+		nativeDiagrams = true
+	default:
+		return fmt.Errorf("unknown diagram renderer %q (%s, %s)", config.GetDiagramRenderer(), GraphvizDiagramRenderer, NativeDiagramRenderer)
+	}
 	// Data-flow Diagram rendering
-	if generateDataFlowDiagram {
+	if generateDataFlowDiagram && nativeDiagrams {
+		err := GenerateDataFlowDiagramNativeImage(readResult.ParsedModel, config.GetOutputFolder(), config.GetDataFlowDiagramFilenamePNG(),
+			diagramDPI, config.GetAddModelTitle(), config.GetAddLegend(), progressReporter)
+		if err != nil {
+			return fmt.Errorf("error while generating data flow diagram: %w", err)
+		}
+	} else if generateDataFlowDiagram {
 		gvFile := filepath.Join(config.GetOutputFolder(), config.GetDataFlowDiagramFilenameDOT())
 		if !config.GetKeepDiagramSourceFiles() {
 			tmpFileGV, err := os.CreateTemp(config.GetTempFolder(), config.GetDataFlowDiagramFilenameDOT())
@@ -135,7 +156,13 @@ func Generate(config reportConfigReader, readResult *model.ReadResult, commands
 		}
 	}
 	// Data Asset Diagram rendering
-	if generateDataAssetsDiagram {
+	if generateDataAssetsDiagram && nativeDiagrams {
+		err := GenerateDataAssetDiagramNativeImage(readResult.ParsedModel, config.GetOutputFolder(), config.GetDataAssetDiagramFilenamePNG(),
+			diagramDPI, progressReporter)
+		if err != nil {
+			return fmt.Errorf("error while generating data asset diagram: %w", err)
+		}
+	} else if generateDataAssetsDiagram {
 		gvFile := filepath.Join(config.GetOutputFolder(), config.GetDataAssetDiagramFilenameDOT())
 		if !config.GetKeepDiagramSourceFiles() {
 			tmpFile, err := os.CreateTemp(config.GetTempFolder(), config.GetDataAssetDiagramFilenameDOT())
@@ -156,6 +183,21 @@ func Generate(config reportConfigReader, readResult *model.ReadResult, commands
 		}
 	}
 
+	// focused data-flow diagrams per risk category for the reports
+	riskCategoryDiagrams := make(map[string]string)
+	if commands.ReportPDF || commands.ReportADOC {
+		riskCategoryDiagramsFolder, err := os.MkdirTemp(config.GetTempFolder(), "risk-category-diagrams-")
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.RemoveAll(riskCategoryDiagramsFolder) }()
+		riskCategoryDiagrams, err = GenerateRiskCategoryDiagrams(readResult.ParsedModel, riskCategoryDiagramsFolder, config.GetTempFolder(),
+			diagramDPI, nativeDiagrams, progressReporter)
+		if err != nil {
+			return fmt.Errorf("error while generating risk category diagrams: %w", err)
+		}
+	}
+
 	// risks as risks json
 	if commands.RisksJSON {
 		progressReporter.Info("Writing risks json")
@@ -165,6 +207,15 @@ func Generate(config reportConfigReader, readResult *model.ReadResult, commands
 		}
 	}
 
+	// risks as sarif
+	if commands.SARIF {
+		progressReporter.Info("Writing risks sarif")
+		err := WriteRisksSARIF(readResult.ParsedModel, config.GetInputFile(), config.GetThreagileVersion(), filepath.Join(config.GetOutputFolder(), config.GetSarifFilename()))
+		if err != nil {
+			return fmt.Errorf("error while writing risks sarif: %w", err)
+		}
+	}
+
 	// technical assets json
 	if commands.TechnicalAssetsJSON {
 		progressReporter.Info("Writing technical assets json")
@@ -221,6 +272,7 @@ func Generate(config reportConfigReader, readResult *model.ReadResult, commands
 			filepath.Join(config.GetAppFolder(), config.GetTemplateFilename()),
 			filepath.Join(config.GetOutputFolder(), config.GetDataFlowDiagramFilenamePNG()),
 			filepath.Join(config.GetOutputFolder(), config.GetDataAssetDiagramFilenamePNG()),
+			riskCategoryDiagrams,
 			config.GetInputFile(),
 			config.GetSkipRiskRules(),
 			config.GetBuildTimestamp(),
@@ -255,6 +307,7 @@ func Generate(config reportConfigReader, readResult *model.ReadResult, commands
 		err = adocReporter.WriteReport(readResult.ParsedModel,
 			filepath.Join(config.GetOutputFolder(), config.GetDataFlowDiagramFilenamePNG()),
 			filepath.Join(config.GetOutputFolder(), config.GetDataAssetDiagramFilenamePNG()),
+			riskCategoryDiagrams,
 			config.GetInputFile(),
 			config.GetSkipRiskRules(),
 			config.GetBuildTimestamp(),
@@ -269,6 +322,28 @@ func Generate(config reportConfigReader, readResult *model.ReadResult, commands
 		}
 	}
 
+	if commands.ReportHTML {
+		// hash the YAML input file
+		f, err := os.Open(config.GetInputFile())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, f); err != nil {
+			return err
+		}
+
+		modelHash := hex.EncodeToString(hasher.Sum(nil))
+		// report HTML
+		progressReporter.Info("Writing report html")
+		err = WriteReportHTML(readResult.ParsedModel, filepath.Join(config.GetOutputFolder(), config.GetHtmlReportFilename()),
+			config.GetAddLegend(), config.GetBuildTimestamp(), config.GetThreagileVersion(), modelHash)
+		if err != nil {
+			return fmt.Errorf("error while writing report html: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -18,6 +18,13 @@ func WriteDataFlowDiagramGraphvizDOT(parsedModel *types.Model,
 	diagramFilenameDOT string, dpi int, addModelTitle bool, addLegend bool,
 	progressReporter progressReporter) (*os.File, error) {
 	progressReporter.Info("Writing data flow diagram input")
+	return writeDataFlowDiagramGraphvizDOT(parsedModel, diagramFilenameDOT, dpi, addModelTitle, addLegend, nil)
+}
+
+// writeDataFlowDiagramGraphvizDOT writes the data flow diagram, drawing the communication links with the given IDs highlighted.
+func writeDataFlowDiagramGraphvizDOT(parsedModel *types.Model,
+	diagramFilenameDOT string, dpi int, addModelTitle bool, addLegend bool,
+	highlightedLinks map[string]bool) (*os.File, error) {
 
 	var dotContent strings.Builder
 	dotContent.WriteString("digraph generatedModel { concentrate=false \n")
@@ -99,7 +106,7 @@ func WriteDataFlowDiagramGraphvizDOT(parsedModel *types.Model,
 			splines = "false"
 			drawSpaceLinesForLayoutUnfortunatelyFurtherSeparatesAllRanks = false
 		default:
-			return nil, fmt.Errorf("unknown value for diagram_tweak_suppress_edge_labels (spline, polyline, ortho, curved, false): %s", parsedModel.DiagramTweakEdgeLayout)
+			return nil, fmt.Errorf("unknown value for diagram_tweak_edge_layout (spline, polyline, ortho, curved, false): %s", parsedModel.DiagramTweakEdgeLayout)
 		}
 	}
 	rankdir := "TB"
@@ -272,8 +279,12 @@ func WriteDataFlowDiagramGraphvizDOT(parsedModel *types.Model,
 					dir = "both"
 				}
 			}
-			arrowStyle = ` style="` + determineArrowLineStyle(dataFlow) + `" penwidth="` + determineArrowPenWidth(dataFlow, parsedModel) + `" arrowtail="` + readOrWriteTail + `" arrowhead="` + readOrWriteHead + `" dir="` + dir + `" arrowsize="2.0" `
-			arrowColor = ` color="` + determineArrowColor(dataFlow, parsedModel) + `"`
+			penWidth, color := determineArrowPenWidth(dataFlow, parsedModel), determineArrowColor(dataFlow, parsedModel)
+			if highlightedLinks[dataFlow.Id] {
+				penWidth, color = fmt.Sprintf("%f", highlightedLinkPenWidth), HighlightedLink
+			}
+			arrowStyle = ` style="` + determineArrowLineStyle(dataFlow) + `" penwidth="` + penWidth + `" arrowtail="` + readOrWriteTail + `" arrowhead="` + readOrWriteHead + `" dir="` + dir + `" arrowsize="2.0" `
+			arrowColor = ` color="` + color + `"`
 			tweaks := ""
 			if dataFlow.DiagramTweakWeight > 0 {
 				tweaks += " weight=\"" + strconv.Itoa(dataFlow.DiagramTweakWeight) + "\" "
@@ -470,48 +481,30 @@ func GenerateDataFlowDiagramGraphvizImage(dotFile *os.File, targetDir string,
 
 func makeDiagramSameRankNodeTweaks(parsedModel *types.Model) (string, error) {
 	// see https://stackoverflow.com/questions/25734244/how-do-i-place-nodes-on-the-same-level-in-dot
+	sameRankAssets, err := diagramSameRankAssets(parsedModel)
+	if err != nil {
+		return "", err
+	}
 	tweak := ""
-	if len(parsedModel.DiagramTweakSameRankAssets) > 0 {
-		for _, sameRank := range parsedModel.DiagramTweakSameRankAssets {
-			assetIDs := strings.Split(sameRank, ":")
-			if len(assetIDs) > 0 {
-				tweak += "{ rank=same; "
-				for _, id := range assetIDs {
-					err := parsedModel.CheckTechnicalAssetExists(id, "diagram tweak same-rank", true)
-					if err != nil {
-						return "", fmt.Errorf("error while checking technical asset existence: %w", err)
-					}
-					if len(parsedModel.GetTechnicalAssetTrustBoundaryId(parsedModel.TechnicalAssets[id])) > 0 {
-						return "", fmt.Errorf("technical assets (referenced in same rank diagram tweak) are inside trust boundaries: %v", parsedModel.DiagramTweakSameRankAssets)
-					}
-					tweak += " " + hash(id) + "; "
-				}
-				tweak += " }"
-			}
+	for _, assetIDs := range sameRankAssets {
+		tweak += "{ rank=same; "
+		for _, id := range assetIDs {
+			tweak += " " + hash(id) + "; "
 		}
+		tweak += " }"
 	}
 	return tweak, nil
 }
 
 func makeDiagramInvisibleConnectionsTweaks(parsedModel *types.Model) (string, error) {
 	// see https://stackoverflow.com/questions/2476575/how-to-control-node-placement-in-graphviz-i-e-avoid-edge-crossings
+	invisibleConnections, err := diagramInvisibleConnections(parsedModel)
+	if err != nil {
+		return "", err
+	}
 	tweak := ""
-	if len(parsedModel.DiagramTweakInvisibleConnectionsBetweenAssets) > 0 {
-		for _, invisibleConnections := range parsedModel.DiagramTweakInvisibleConnectionsBetweenAssets {
-			assetIDs := strings.Split(invisibleConnections, ":")
-			if len(assetIDs) == 2 {
-				err := parsedModel.CheckTechnicalAssetExists(assetIDs[0], "diagram tweak connections", true)
-				if err != nil {
-					return "", fmt.Errorf("error while checking technical asset existence: %w", err)
-				}
-				err = parsedModel.CheckTechnicalAssetExists(assetIDs[1], "diagram tweak connections", true)
-				if err != nil {
-					return "", fmt.Errorf("error while checking technical asset existence: %w", err)
-				}
-
-				tweak += "\n" + hash(assetIDs[0]) + " -> " + hash(assetIDs[1]) + " [style=invis]; \n"
-			}
-		}
+	for _, assetIDs := range invisibleConnections {
+		tweak += "\n" + hash(assetIDs[0]) + " -> " + hash(assetIDs[1]) + " [style=invis]; \n"
 	}
 	return tweak, nil
 }
@@ -0,0 +1,314 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+//go:embed html-report.html
+var htmlReportTemplate string
+
+type htmlReport struct {
+	Title            string
+	Author           string
+	Date             string
+	ThreagileVersion string
+	BuildTimestamp   string
+	ModelHash        string
+	Diagram          template.HTML
+	Data             htmlReportData
+}
+
+// htmlReportData is embedded as JSON into the page and drives the interactive parts (details panel and risk tables).
+// Elements are keyed by kind and id (see htmlReportElementKey), as assets, links and trust boundaries may share ids.
+type htmlReportData struct {
+	Elements   map[string]*htmlReportElement `json:"elements"`
+	Risks      []*htmlReportRisk             `json:"risks"`
+	Severities []string                      `json:"severities"`
+	STRIDE     []string                      `json:"stride"`
+	Functions  []string                      `json:"functions"`
+	Statuses   []string                      `json:"statuses"`
+}
+
+type htmlReportElement struct {
+	Id          string      `json:"id"`
+	Type        string      `json:"type"`
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	Properties  [][2]string `json:"properties"`
+	Risks       []string    `json:"risks"`
+}
+
+type htmlReportRisk struct {
+	Id            string   `json:"id"`
+	Title         string   `json:"title"`
+	Category      string   `json:"category"`
+	Severity      string   `json:"severity"`
+	Likelihood    string   `json:"likelihood"`
+	Impact        string   `json:"impact"`
+	STRIDE        string   `json:"stride"`
+	Function      string   `json:"function"`
+	Status        string   `json:"status"`
+	StillAtRisk   bool     `json:"still_at_risk"`
+	Justification string   `json:"justification,omitempty"`
+	Ticket        string   `json:"ticket,omitempty"`
+	CheckedBy     string   `json:"checked_by,omitempty"`
+	Date          string   `json:"date,omitempty"`
+	Element       string   `json:"element,omitempty"`
+	Elements      []string `json:"elements"`
+}
+
+// WriteReportHTML writes a single self-contained HTML file: the data flow diagram as inline SVG (rendered natively,
+// so that assets, links and trust boundaries can be clicked to show their properties and risks) and filterable risk tables.
+func WriteReportHTML(parsedModel *types.Model, filename string, addLegend bool, buildTimestamp string, threagileVersion string, modelHash string) error {
+	dataFlowDiagram, err := makeDataFlowDiagram(parsedModel, false, addLegend)
+	if err != nil {
+		return fmt.Errorf("error while generating data flow diagram: %w", err)
+	}
+	dataFlowDiagram.layout()
+	var svg bytes.Buffer
+	svgWriter := bufio.NewWriter(&svg)
+	dataFlowDiagram.draw(newSvgDiagramCanvas(svgWriter, dataFlowDiagram.width, dataFlowDiagram.height))
+	err = svgWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("error while rendering data flow diagram: %w", err)
+	}
+
+	report := htmlReport{
+		Title:            parsedModel.Title,
+		Date:             parsedModel.Date.Format("2006-01-02"),
+		ThreagileVersion: threagileVersion,
+		BuildTimestamp:   buildTimestamp,
+		ModelHash:        modelHash,
+		Diagram:          template.HTML(svg.String()), // #nosec G203 // the SVG is generated here and escapes all model texts
+		Data:             makeHtmlReportData(parsedModel),
+	}
+	if parsedModel.Date.IsZero() {
+		report.Date = ""
+	}
+	if parsedModel.Author != nil {
+		report.Author = parsedModel.Author.Name
+	}
+
+	page, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return fmt.Errorf("error while parsing html report template: %w", err)
+	}
+	file, err := os.Create(filepath.Clean(filename))
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", filename, err)
+	}
+	defer func() { _ = file.Close() }()
+	writer := bufio.NewWriter(file)
+	err = page.Execute(writer, report)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", filename, err)
+	}
+	err = writer.Flush()
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", filename, err)
+	}
+	return nil
+}
+
+// the element key prefixes have to match the ones the page derives from the classes of the diagram groups
+const (
+	htmlReportTechnicalAssetKind    = "asset"
+	htmlReportCommunicationLinkKind = "link"
+	htmlReportTrustBoundaryKind     = "boundary"
+)
+
+func htmlReportElementKey(kind string, id string) string {
+	return kind + ":" + id
+}
+
+func makeHtmlReportData(parsedModel *types.Model) htmlReportData {
+	data := htmlReportData{Elements: make(map[string]*htmlReportElement)}
+	for _, technicalAsset := range parsedModel.TechnicalAssets {
+		data.Elements[htmlReportElementKey(htmlReportTechnicalAssetKind, technicalAsset.Id)] = makeHtmlReportTechnicalAsset(parsedModel, technicalAsset)
+	}
+	for _, communicationLink := range parsedModel.CommunicationLinks {
+		data.Elements[htmlReportElementKey(htmlReportCommunicationLinkKind, communicationLink.Id)] = makeHtmlReportCommunicationLink(parsedModel, communicationLink)
+	}
+	for _, trustBoundary := range parsedModel.TrustBoundaries {
+		data.Elements[htmlReportElementKey(htmlReportTrustBoundaryKind, trustBoundary.Id)] = makeHtmlReportTrustBoundary(parsedModel, trustBoundary)
+	}
+
+	risks := parsedModel.AllRisks()
+	types.SortByRiskSeverity(risks)
+	data.Risks = make([]*htmlReportRisk, 0, len(risks))
+	for _, risk := range risks {
+		tracking := parsedModel.GetRiskTrackingWithDefault(risk)
+		item := &htmlReportRisk{
+			Id:            risk.SyntheticId,
+			Title:         removeFormattingTags(risk.Title),
+			Category:      risk.CategoryId,
+			Severity:      risk.Severity.String(),
+			Likelihood:    risk.ExploitationLikelihood.String(),
+			Impact:        risk.ExploitationImpact.String(),
+			Status:        tracking.Status.String(),
+			StillAtRisk:   tracking.Status.IsStillAtRisk(),
+			Justification: tracking.Justification,
+			Ticket:        tracking.Ticket,
+			CheckedBy:     tracking.CheckedBy,
+			Elements:      make([]string, 0),
+		}
+		if !tracking.Date.IsZero() {
+			item.Date = tracking.Date.Format("2006-01-02")
+		}
+		if category := parsedModel.GetRiskCategory(risk.CategoryId); category != nil {
+			item.Category = category.Title
+			item.STRIDE = category.STRIDE.String()
+			item.Function = category.Function.String()
+		}
+		for _, key := range []string{
+			htmlReportElementKey(htmlReportCommunicationLinkKind, risk.MostRelevantCommunicationLinkId),
+			htmlReportElementKey(htmlReportTechnicalAssetKind, risk.MostRelevantTechnicalAssetId),
+			htmlReportElementKey(htmlReportTrustBoundaryKind, risk.MostRelevantTrustBoundaryId),
+		} {
+			if element, ok := data.Elements[key]; ok {
+				element.Risks = append(element.Risks, risk.SyntheticId)
+				item.Elements = append(item.Elements, key)
+				if len(item.Element) == 0 {
+					item.Element = element.Title
+				}
+			}
+		}
+		data.Risks = append(data.Risks, item)
+	}
+
+	for _, value := range types.RiskSeverityValues() {
+		data.Severities = append(data.Severities, value.String())
+	}
+	for _, value := range types.STRIDEValues() {
+		data.STRIDE = append(data.STRIDE, value.String())
+	}
+	for _, value := range types.RiskFunctionValues() {
+		data.Functions = append(data.Functions, value.String())
+	}
+	for _, value := range types.RiskStatusValues() {
+		data.Statuses = append(data.Statuses, value.String())
+	}
+	return data
+}
+
+func makeHtmlReportTechnicalAsset(parsedModel *types.Model, technicalAsset *types.TechnicalAsset) *htmlReportElement {
+	properties := [][2]string{
+		{"ID", technicalAsset.Id},
+		{"Type", technicalAsset.Type.String()},
+		{"Usage", technicalAsset.Usage.String()},
+		{"Size", technicalAsset.Size.String()},
+		{"Technologies", technicalAsset.Technologies.String()},
+		{"Machine", technicalAsset.Machine.String()},
+		{"Internet", strconv.FormatBool(technicalAsset.Internet)},
+		{"Multi-Tenant", strconv.FormatBool(technicalAsset.MultiTenant)},
+		{"Redundant", strconv.FormatBool(technicalAsset.Redundant)},
+		{"Custom-Developed Parts", strconv.FormatBool(technicalAsset.CustomDevelopedParts)},
+		{"Used as Client by Human", strconv.FormatBool(technicalAsset.UsedAsClientByHuman)},
+		{"Encryption", technicalAsset.Encryption.String()},
+		{"Owner", technicalAsset.Owner},
+		{"Confidentiality", technicalAsset.Confidentiality.String()},
+		{"Integrity", technicalAsset.Integrity.String()},
+		{"Availability", technicalAsset.Availability.String()},
+		{"CIA Justification", technicalAsset.JustificationCiaRating},
+		{"Data Processed", htmlReportDataAssetTitles(parsedModel, technicalAsset.DataAssetsProcessed)},
+		{"Data Stored", htmlReportDataAssetTitles(parsedModel, technicalAsset.DataAssetsStored)},
+		{"Tags", strings.Join(technicalAsset.Tags, ", ")},
+		{"RAA", fmt.Sprintf("%.0f %%", technicalAsset.RAA)},
+	}
+	if technicalAsset.OutOfScope {
+		properties = append(properties, [2]string{"Out of Scope", technicalAsset.JustificationOutOfScope})
+	}
+	return &htmlReportElement{
+		Id:          technicalAsset.Id,
+		Type:        "Technical Asset",
+		Title:       technicalAsset.Title,
+		Description: technicalAsset.Description,
+		Properties:  properties,
+		Risks:       make([]string, 0),
+	}
+}
+
+func makeHtmlReportCommunicationLink(parsedModel *types.Model, communicationLink *types.CommunicationLink) *htmlReportElement {
+	return &htmlReportElement{
+		Id:          communicationLink.Id,
+		Type:        "Communication Link",
+		Title:       communicationLink.Title,
+		Description: communicationLink.Description,
+		Properties: [][2]string{
+			{"ID", communicationLink.Id},
+			{"Source", htmlReportTechnicalAssetTitle(parsedModel, communicationLink.SourceId)},
+			{"Target", htmlReportTechnicalAssetTitle(parsedModel, communicationLink.TargetId)},
+			{"Protocol", communicationLink.Protocol.String()},
+			{"Authentication", communicationLink.Authentication.String()},
+			{"Authorization", communicationLink.Authorization.String()},
+			{"Usage", communicationLink.Usage.String()},
+			{"VPN", strconv.FormatBool(communicationLink.VPN)},
+			{"IP-Filtered", strconv.FormatBool(communicationLink.IpFiltered)},
+			{"Read-Only", strconv.FormatBool(communicationLink.Readonly)},
+			{"Data Sent", htmlReportDataAssetTitles(parsedModel, communicationLink.DataAssetsSent)},
+			{"Data Received", htmlReportDataAssetTitles(parsedModel, communicationLink.DataAssetsReceived)},
+			{"Tags", strings.Join(communicationLink.Tags, ", ")},
+		},
+		Risks: make([]string, 0),
+	}
+}
+
+func makeHtmlReportTrustBoundary(parsedModel *types.Model, trustBoundary *types.TrustBoundary) *htmlReportElement {
+	assetTitles := make([]string, 0)
+	for _, id := range trustBoundary.TechnicalAssetsInside {
+		assetTitles = append(assetTitles, htmlReportTechnicalAssetTitle(parsedModel, id))
+	}
+	sort.Strings(assetTitles)
+	nestedTitles := make([]string, 0)
+	for _, id := range trustBoundary.TrustBoundariesNested {
+		if nested, ok := parsedModel.TrustBoundaries[id]; ok {
+			nestedTitles = append(nestedTitles, nested.Title)
+		}
+	}
+	sort.Strings(nestedTitles)
+	return &htmlReportElement{
+		Id:          trustBoundary.Id,
+		Type:        "Trust Boundary",
+		Title:       trustBoundary.Title,
+		Description: trustBoundary.Description,
+		Properties: [][2]string{
+			{"ID", trustBoundary.Id},
+			{"Type", trustBoundary.Type.String()},
+			{"Technical Assets Inside", strings.Join(assetTitles, ", ")},
+			{"Nested Trust Boundaries", strings.Join(nestedTitles, ", ")},
+			{"Tags", strings.Join(trustBoundary.Tags, ", ")},
+		},
+		Risks: make([]string, 0),
+	}
+}
+
+func htmlReportTechnicalAssetTitle(parsedModel *types.Model, id string) string {
+	if technicalAsset, ok := parsedModel.TechnicalAssets[id]; ok {
+		return technicalAsset.Title
+	}
+	return id
+}
+
+func htmlReportDataAssetTitles(parsedModel *types.Model, ids []string) string {
+	titles := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if dataAsset, ok := parsedModel.DataAssets[id]; ok {
+			titles = append(titles, dataAsset.Title)
+		} else {
+			titles = append(titles, id)
+		}
+	}
+	sort.Strings(titles)
+	return strings.Join(titles, ", ")
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+func TestMakeHtmlReportDataCollidingIds(t *testing.T) {
+	link := &types.CommunicationLink{Id: "shared", Title: "Shared Link", SourceId: "shared", TargetId: "shared"}
+	parsedModel := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"shared": {Id: "shared", Title: "Shared Asset", CommunicationLinks: []*types.CommunicationLink{link}},
+		},
+		CommunicationLinks: map[string]*types.CommunicationLink{link.Id: link},
+		TrustBoundaries: map[string]*types.TrustBoundary{
+			"shared": {Id: "shared", Title: "Shared Boundary", TechnicalAssetsInside: []string{"shared"}},
+		},
+		GeneratedRisksByCategory: map[string][]*types.Risk{
+			"test": {
+				{CategoryId: "test", SyntheticId: "asset-risk", MostRelevantTechnicalAssetId: "shared"},
+				{CategoryId: "test", SyntheticId: "link-risk", MostRelevantCommunicationLinkId: "shared", MostRelevantTechnicalAssetId: "shared"},
+				{CategoryId: "test", SyntheticId: "boundary-risk", MostRelevantTrustBoundaryId: "shared"},
+			},
+		},
+	}
+
+	data := makeHtmlReportData(parsedModel)
+
+	require.Len(t, data.Elements, 3)
+	tests := []struct {
+		key   string
+		title string
+		risks []string
+	}{
+		{"asset:shared", "Shared Asset", []string{"asset-risk", "link-risk"}},
+		{"link:shared", "Shared Link", []string{"link-risk"}},
+		{"boundary:shared", "Shared Boundary", []string{"boundary-risk"}},
+	}
+	for _, test := range tests {
+		element, ok := data.Elements[test.key]
+		require.True(t, ok, test.key)
+		assert.Equal(t, test.title, element.Title, test.key)
+		assert.ElementsMatch(t, test.risks, element.Risks, test.key)
+	}
+
+	risks := make(map[string]*htmlReportRisk)
+	for _, risk := range data.Risks {
+		risks[risk.Id] = risk
+	}
+	require.Len(t, risks, 3)
+	assert.Equal(t, []string{"asset:shared"}, risks["asset-risk"].Elements)
+	assert.Equal(t, []string{"link:shared", "asset:shared"}, risks["link-risk"].Elements)
+	assert.Equal(t, "Shared Link", risks["link-risk"].Element, "the communication link is the most relevant element")
+	assert.Equal(t, []string{"boundary:shared"}, risks["boundary-risk"].Elements)
+}
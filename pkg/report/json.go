@@ -3,7 +3,11 @@ package report
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/threagile/threagile/pkg/types"
 )
@@ -20,6 +24,18 @@ func WriteRisksJSON(parsedModel *types.Model, filename string) error {
 	return nil
 }
 
+func WriteRisksSARIF(parsedModel *types.Model, modelFilename string, threagileVersion string, filename string) error {
+	jsonBytes, err := json.MarshalIndent(risksSARIF(parsedModel, modelFilename, threagileVersion), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal risks to SARIF: %w", err)
+	}
+	err = os.WriteFile(filename, jsonBytes, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write risks to SARIF file: %w", err)
+	}
+	return nil
+}
+
 // TODO: also a "data assets" json?
 
 func WriteTechnicalAssetsJSON(parsedModel *types.Model, filename string) error {
@@ -96,3 +112,344 @@ type riskStatistics struct {
 	// TODO add also some more like before / after (i.e. with mitigation applied)
 	Risks map[string]map[string]int `yaml:"risks" json:"risks"`
 }
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	// sarifSourceRoot is the base of the artifact URIs, consumers like code scanning resolve it to their checkout
+	sarifSourceRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalUriBaseIds map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []*sarifResult                   `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationUri string       `json:"informationUri,omitempty"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string         `json:"id"`
+	Name             string         `json:"name,omitempty"`
+	ShortDescription sarifMessage   `json:"shortDescription"`
+	FullDescription  sarifMessage   `json:"fullDescription"`
+	HelpUri          string         `json:"helpUri,omitempty"`
+	Help             sarifMessage   `json:"help"`
+	Properties       map[string]any `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifResult struct {
+	RuleId              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []*sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	Properties          map[string]any     `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri       string `json:"uri"`
+	UriBaseId string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status,omitempty"`
+	Justification string `json:"justification,omitempty"`
+}
+
+func risksSARIF(parsedModel *types.Model, modelFilename string, threagileVersion string) *sarifLog {
+	sourceRoot, err := filepath.Abs(filepath.Dir(modelFilename))
+	if err != nil {
+		sourceRoot = filepath.Dir(modelFilename)
+	}
+
+	categories := make([]*types.RiskCategory, 0)
+	categoryIndex := make(map[string]int)
+	addCategory := func(category *types.RiskCategory) {
+		if _, ok := categoryIndex[strings.ToLower(category.ID)]; !ok {
+			categoryIndex[strings.ToLower(category.ID)] = len(categories)
+			categories = append(categories, category)
+		}
+	}
+	for _, category := range parsedModel.CustomRiskCategories {
+		addCategory(category)
+	}
+	for _, category := range parsedModel.BuiltInRiskCategories {
+		addCategory(category)
+	}
+	for categoryId := range parsedModel.GeneratedRisksByCategory {
+		if parsedModel.GetRiskCategory(categoryId) == nil {
+			addCategory(&types.RiskCategory{ID: categoryId, Title: categoryId})
+		}
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].ID < categories[j].ID
+	})
+	for n, category := range categories {
+		categoryIndex[strings.ToLower(category.ID)] = n
+	}
+
+	rules := make([]*sarifRule, 0, len(categories))
+	for _, category := range categories {
+		rules = append(rules, sarifRuleOf(category, parsedModel.GeneratedRisksByCategory[category.ID]))
+	}
+
+	risks := parsedModel.AllRisks()
+	sort.Slice(risks, func(i, j int) bool {
+		return risks[i].SyntheticId < risks[j].SyntheticId
+	})
+
+	results := make([]*sarifResult, 0, len(risks))
+	for _, risk := range risks {
+		tracking := parsedModel.GetRiskTrackingWithDefault(risk)
+		result := &sarifResult{
+			RuleId:    risk.CategoryId,
+			RuleIndex: categoryIndex[strings.ToLower(risk.CategoryId)],
+			Level:     sarifLevel(risk.Severity),
+			Message:   sarifMessage{Text: removeFormattingTags(risk.Title)},
+			Locations: []*sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocationOf(parsedModel.GetRiskPosition(risk), modelFilename, sourceRoot),
+				LogicalLocations: sarifLogicalLocations(parsedModel, risk),
+			}},
+			PartialFingerprints: map[string]string{"threagileSyntheticId": risk.SyntheticId},
+			Properties: map[string]any{
+				"severity":                risk.Severity.String(),
+				"exploitation_likelihood": risk.ExploitationLikelihood.String(),
+				"exploitation_impact":     risk.ExploitationImpact.String(),
+				"risk_status":             tracking.Status.String(),
+				"security-severity":       sarifSecuritySeverity(risk.Severity),
+			},
+		}
+
+		if !tracking.Status.IsStillAtRisk() {
+			result.Suppressions = []sarifSuppression{{
+				Kind:          "external",
+				Status:        "accepted",
+				Justification: tracking.Justification,
+			}}
+		}
+
+		results = append(results, result)
+	}
+
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []*sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "Threagile",
+				Version:        threagileVersion,
+				InformationUri: "https://threagile.io",
+				Rules:          rules,
+			}},
+			OriginalUriBaseIds: map[string]sarifArtifactLocation{
+				sarifSourceRoot: {Uri: sarifFileUri(sourceRoot) + "/"},
+			},
+			Results: results,
+		}},
+	}
+}
+
+func sarifRuleOf(category *types.RiskCategory, risks []*types.Risk) *sarifRule {
+	tags := []string{"security", category.STRIDE.String(), category.Function.String()}
+	if category.CWE > 0 {
+		tags = append(tags, fmt.Sprintf("external/cwe/cwe-%d", category.CWE))
+	}
+
+	properties := map[string]any{
+		"tags":     tags,
+		"stride":   category.STRIDE.String(),
+		"function": category.Function.String(),
+	}
+	if category.CWE > 0 {
+		properties["cwe"] = fmt.Sprintf("CWE-%d", category.CWE)
+	}
+	if len(category.ASVS) > 0 {
+		properties["asvs"] = category.ASVS
+	}
+	if len(risks) > 0 {
+		highestSeverity := types.LowSeverity
+		for _, risk := range risks {
+			if risk.Severity > highestSeverity {
+				highestSeverity = risk.Severity
+			}
+		}
+		properties["security-severity"] = sarifSecuritySeverity(highestSeverity)
+	}
+
+	var markdown strings.Builder
+	markdown.WriteString(fmt.Sprintf("**Mitigation:** %v\n\n", category.Mitigation))
+	if len(category.Check) > 0 {
+		markdown.WriteString(fmt.Sprintf("**Check:** %v\n\n", category.Check))
+	}
+	if len(category.ASVS) > 0 {
+		markdown.WriteString(fmt.Sprintf("**ASVS:** %v\n\n", category.ASVS))
+	}
+	if len(category.CheatSheet) > 0 {
+		markdown.WriteString(fmt.Sprintf("**Cheat Sheet:** <%v>\n\n", category.CheatSheet))
+	}
+	if category.CWE > 0 {
+		markdown.WriteString(fmt.Sprintf("**CWE:** [CWE-%d](https://cwe.mitre.org/data/definitions/%d.html)\n", category.CWE, category.CWE))
+	}
+
+	return &sarifRule{
+		Id:               category.ID,
+		Name:             category.Title,
+		ShortDescription: sarifMessage{Text: removeFormattingTags(category.Title)},
+		FullDescription:  sarifMessage{Text: removeFormattingTags(category.Description)},
+		HelpUri:          category.CheatSheet,
+		Help: sarifMessage{
+			Text:     removeFormattingTags(category.Mitigation),
+			Markdown: removeFormattingTags(strings.TrimSpace(markdown.String())),
+		},
+		Properties: properties,
+	}
+}
+
+func sarifPhysicalLocationOf(position *types.Position, modelFilename string, sourceRoot string) sarifPhysicalLocation {
+	if position == nil {
+		return sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocationOf(modelFilename, sourceRoot)}
+	}
+
+	return sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocationOf(position.File, sourceRoot),
+		Region:           &sarifRegion{StartLine: position.Line, StartColumn: position.Column},
+	}
+}
+
+// sarifArtifactLocationOf refers to files below the folder of the model file relative to the source root, other
+// files (like includes from elsewhere) by their absolute URI
+func sarifArtifactLocationOf(filename string, sourceRoot string) sarifArtifactLocation {
+	absoluteFilename, err := filepath.Abs(filename)
+	if err != nil {
+		return sarifArtifactLocation{Uri: filepath.ToSlash(filename)}
+	}
+
+	relativeFilename, err := filepath.Rel(sourceRoot, absoluteFilename)
+	if err != nil || relativeFilename == ".." || strings.HasPrefix(relativeFilename, ".."+string(filepath.Separator)) {
+		return sarifArtifactLocation{Uri: sarifFileUri(absoluteFilename)}
+	}
+
+	return sarifArtifactLocation{Uri: filepath.ToSlash(relativeFilename), UriBaseId: sarifSourceRoot}
+}
+
+func sarifFileUri(absoluteFilename string) string {
+	path := filepath.ToSlash(absoluteFilename)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // windows drive letter
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func sarifLogicalLocations(parsedModel *types.Model, risk *types.Risk) []sarifLogicalLocation {
+	locations := make([]sarifLogicalLocation, 0)
+	if link, ok := parsedModel.CommunicationLinks[risk.MostRelevantCommunicationLinkId]; ok {
+		locations = append(locations, sarifLogicalLocation{
+			Name:               link.Title,
+			FullyQualifiedName: "communication_links/" + link.Id,
+			Kind:               "resource",
+		})
+	}
+	if techAsset, ok := parsedModel.TechnicalAssets[risk.MostRelevantTechnicalAssetId]; ok {
+		locations = append(locations, sarifLogicalLocation{
+			Name:               techAsset.Title,
+			FullyQualifiedName: "technical_assets/" + techAsset.Id,
+			Kind:               "resource",
+		})
+	}
+	if trustBoundary, ok := parsedModel.TrustBoundaries[risk.MostRelevantTrustBoundaryId]; ok {
+		locations = append(locations, sarifLogicalLocation{
+			Name:               trustBoundary.Title,
+			FullyQualifiedName: "trust_boundaries/" + trustBoundary.Id,
+			Kind:               "resource",
+		})
+	}
+	if sharedRuntime, ok := parsedModel.SharedRuntimes[risk.MostRelevantSharedRuntimeId]; ok {
+		locations = append(locations, sarifLogicalLocation{
+			Name:               sharedRuntime.Title,
+			FullyQualifiedName: "shared_runtimes/" + sharedRuntime.Id,
+			Kind:               "resource",
+		})
+	}
+	if dataAsset, ok := parsedModel.DataAssets[risk.MostRelevantDataAssetId]; ok {
+		locations = append(locations, sarifLogicalLocation{
+			Name:               dataAsset.Title,
+			FullyQualifiedName: "data_assets/" + dataAsset.Id,
+			Kind:               "resource",
+		})
+	}
+	return locations
+}
+
+func sarifLevel(severity types.RiskSeverity) string {
+	switch severity {
+	case types.CriticalSeverity, types.HighSeverity:
+		return "error"
+	case types.ElevatedSeverity, types.MediumSeverity:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps the risk severity to the CVSS-like score used by code scanning tools
+func sarifSecuritySeverity(severity types.RiskSeverity) string {
+	switch severity {
+	case types.CriticalSeverity:
+		return "9.5"
+	case types.HighSeverity:
+		return "8.0"
+	case types.ElevatedSeverity:
+		return "6.5"
+	case types.MediumSeverity:
+		return "5.0"
+	default:
+		return "2.0"
+	}
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// newSARIFTestModel has risks located in the model file, in an include next to it, in an include outside its folder
+// and without any position
+func newSARIFTestModel(folder string) *types.Model {
+	parsedModel := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"web": {Id: "web", Title: "Web Server"},
+			"db":  {Id: "db", Title: "Database"},
+		},
+		BuiltInRiskCategories: types.RiskCategories{{
+			ID:          "sql-injection",
+			Title:       "SQL Injection",
+			Description: "SQL injection risks",
+			Mitigation:  "Use prepared statements.",
+			CheatSheet:  "https://cheatsheetseries.owasp.org/cheatsheets/SQL_Injection_Prevention_Cheat_Sheet.html",
+			Function:    types.Development,
+			STRIDE:      types.Tampering,
+			CWE:         89,
+		}},
+		CustomRiskCategories: types.RiskCategories{{
+			ID:         "leak",
+			Title:      "Leak",
+			Mitigation: "Do not leak.",
+			Function:   types.Architecture,
+			STRIDE:     types.InformationDisclosure,
+		}},
+		GeneratedRisksByCategory: map[string][]*types.Risk{
+			"sql-injection": {
+				{CategoryId: "sql-injection", SyntheticId: "sql-injection@db", Title: "<b>SQL Injection</b> at Database", Severity: types.HighSeverity,
+					ExploitationLikelihood: types.Likely, ExploitationImpact: types.HighImpact, MostRelevantTechnicalAssetId: "db"},
+			},
+			"leak": {
+				{CategoryId: "leak", SyntheticId: "leak@web", Title: "Leak at Web Server", Severity: types.MediumSeverity,
+					ExploitationLikelihood: types.Unlikely, ExploitationImpact: types.MediumImpact, MostRelevantTechnicalAssetId: "web"},
+				{CategoryId: "leak", SyntheticId: "leak@shared", Title: "Leak in Shared Include", Severity: types.LowSeverity,
+					ExploitationLikelihood: types.Unlikely, ExploitationImpact: types.LowImpact,
+					Position: &types.Position{File: filepath.Join(folder, "shared", "common.yaml"), Line: 3, Column: 5}},
+				{CategoryId: "leak", SyntheticId: "leak@unknown", Title: "Leak Somewhere", Severity: types.LowSeverity,
+					ExploitationLikelihood: types.Unlikely, ExploitationImpact: types.LowImpact},
+			},
+		},
+		RiskTracking: map[string]*types.RiskTracking{
+			"leak@web": {SyntheticRiskId: "leak@web", Status: types.Mitigated, Justification: "Public data only"},
+		},
+	}
+	parsedModel.AddPosition("technical_assets", "db", &types.Position{File: filepath.Join(folder, "model", "threagile.yaml"), Line: 12, Column: 3})
+	parsedModel.AddPosition("technical_assets", "web", &types.Position{File: filepath.Join(folder, "model", "includes", "assets.yaml"), Line: 4, Column: 3})
+
+	return parsedModel
+}
+
+func TestRisksSARIF(t *testing.T) {
+	folder := t.TempDir()
+	modelFilename := filepath.Join(folder, "model", "threagile.yaml")
+
+	sarif, err := json.MarshalIndent(risksSARIF(newSARIFTestModel(folder), modelFilename, "1.0.0"), "", "  ")
+	require.NoError(t, err)
+	// the temporary folder differs from run to run
+	sarif = []byte(strings.ReplaceAll(string(sarif), sarifFileUri(folder), "file:///tmp/threagile"))
+
+	goldenFilename := filepath.Join("testdata", "risks.sarif")
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(goldenFilename, sarif, 0600))
+	}
+	golden, err := os.ReadFile(goldenFilename)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(sarif))
+}
+
+func TestSarifArtifactLocationOf(t *testing.T) {
+	sourceRoot := filepath.Join(t.TempDir(), "model")
+
+	tests := []struct {
+		name     string
+		filename string
+		expected sarifArtifactLocation
+	}{
+		{"model file", filepath.Join(sourceRoot, "threagile.yaml"), sarifArtifactLocation{Uri: "threagile.yaml", UriBaseId: sarifSourceRoot}},
+		{"include below", filepath.Join(sourceRoot, "includes", "assets.yaml"), sarifArtifactLocation{Uri: "includes/assets.yaml", UriBaseId: sarifSourceRoot}},
+		{"include outside", filepath.Join(sourceRoot, "..", "common.yaml"), sarifArtifactLocation{Uri: sarifFileUri(filepath.Join(filepath.Dir(sourceRoot), "common.yaml"))}},
+		{"folder starting with dots", filepath.Join(sourceRoot, "..shared", "common.yaml"), sarifArtifactLocation{Uri: "..shared/common.yaml", UriBaseId: sarifSourceRoot}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, sarifArtifactLocationOf(test.filename, sourceRoot))
+		})
+	}
+}
@@ -56,6 +56,7 @@ func (r *pdfReporter) WriteReportPDF(reportFilename string,
 	templateFilename string,
 	dataFlowDiagramFilenamePNG string,
 	dataAssetDiagramFilenamePNG string,
+	riskCategoryDiagramFilenamesPNG map[string]string,
 	modelFilename string,
 	skipRiskRules []string,
 	buildTimestamp string,
@@ -107,9 +108,14 @@ func (r *pdfReporter) WriteReportPDF(reportFilename string,
 	r.createOutOfScopeAssets(model)
 	r.createModelFailures(model)
 	r.createQuestions(model)
-	r.createRiskCategories(model)
+	err = r.createRiskCategories(model, riskCategoryDiagramFilenamesPNG)
+	if err != nil {
+		return fmt.Errorf("error creating risk categories: %w", err)
+	}
 	r.createTechnicalAssets(model)
-	r.createDataAssets(model)
+	if len(model.DataAssets) > 0 { // like in the table of contents, otherwise the link targets get out of sync
+		r.createDataAssets(model)
+	}
 	r.createTrustBoundaries(model)
 	r.createSharedRuntimes(model)
 	if val := hideChapters[RiskRulesCheckedByThreagile]; !val {
@@ -2530,7 +2536,7 @@ func sortedTechnicalAssetsByTitle(parsedModel *types.Model) []*types.TechnicalAs
 	return assets
 }
 
-func (r *pdfReporter) createRiskCategories(parsedModel *types.Model) {
+func (r *pdfReporter) createRiskCategories(parsedModel *types.Model, riskCategoryDiagramFilenamesPNG map[string]string) error {
 	uni := r.pdf.UnicodeTranslatorFromDescriptor("")
 	// category title
 	title := "Identified Risks by Vulnerability category"
@@ -2639,6 +2645,14 @@ func (r *pdfReporter) createRiskCategories(parsedModel *types.Model) {
 		text.Reset()
 		r.pdf.SetTextColor(0, 0, 0)
 
+		// affected part of the data-flow diagram
+		if diagramFilenamePNG, ok := riskCategoryDiagramFilenamesPNG[category.ID]; ok {
+			err := r.embedRiskCategoryDiagram(diagramFilenamePNG)
+			if err != nil {
+				return fmt.Errorf("error embedding diagram of risk category %q: %w", category.ID, err)
+			}
+		}
+
 		// risk details
 		r.pageBreak()
 		r.pdf.SetY(36)
@@ -2748,6 +2762,7 @@ func (r *pdfReporter) createRiskCategories(parsedModel *types.Model) {
 		}
 		r.pdf.SetLeftMargin(oldLeft)
 	}
+	return nil
 }
 
 func (r *pdfReporter) writeRiskTrackingStatus(parsedModel *types.Model, risk *types.Risk) {
@@ -4585,6 +4600,29 @@ func (r *pdfReporter) embedDataFlowDiagram(diagramFilenamePNG string, tempFolder
 	}
 }
 
+func (r *pdfReporter) embedRiskCategoryDiagram(diagramFilenamePNG string) error {
+	r.pageBreak()
+	r.pdf.SetY(36)
+	html := r.pdf.HTMLBasicNew()
+	html.Write(5, "<b>Affected Data-Flow</b><br><br>The following excerpt of the data-flow diagram shows only the technical assets "+
+		"affected by this risk category, their direct neighbours and the trust boundaries around them. "+
+		"The most relevant communication links are highlighted as thick light blue arrows.<br><br>")
+
+	maxWidth, maxHeight := 190.0, 265.0-r.pdf.GetY()
+	height, err := getHeightWhenWidthIsFix(diagramFilenamePNG, maxWidth)
+	if err != nil {
+		return err
+	}
+	var options gofpdf.ImageOptions
+	r.pdf.RegisterImage(diagramFilenamePNG, "")
+	if height <= maxHeight {
+		r.pdf.ImageOptions(diagramFilenamePNG, 10, r.pdf.GetY(), maxWidth, 0, true, options, 0, "")
+	} else {
+		r.pdf.ImageOptions(diagramFilenamePNG, 10, r.pdf.GetY(), 0, maxHeight, true, options, 0, "")
+	}
+	return nil
+}
+
 func (r *pdfReporter) embedDataRiskMapping(diagramFilenamePNG string, tempFolder string) {
 	r.pdf.SetTextColor(0, 0, 0)
 	title := "Data Mapping"
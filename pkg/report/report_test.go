@@ -0,0 +1,76 @@
+package report
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+type reportTestConfig struct{}
+
+func (what *reportTestConfig) GetAppFolder() string          { return "" }
+func (what *reportTestConfig) GetTechnologyFilename() string { return "" }
+
+// noDataAssetsTestModel has a single technical asset with a risk and no data assets at all
+const noDataAssetsTestModel = `title: No Data Assets
+author:
+  name: Author
+business_criticality: important
+technical_assets:
+  Web Server:
+    id: web
+    type: process
+    usage: business
+    size: application
+    technology: web-server
+    machine: container
+    encryption: none
+    owner: Team
+    confidentiality: internal
+    integrity: operational
+    availability: operational
+custom_risk_categories:
+  - id: leak
+    title: Leak
+    function: architecture
+    stride: information-disclosure
+    risks_identified:
+      Leak at Web Server:
+        severity: high
+        exploitation_likelihood: likely
+        exploitation_impact: medium
+        data_breach_probability: possible
+        most_relevant_technical_asset: web
+`
+
+func TestWriteReportPDFWithoutDataAssets(t *testing.T) {
+	folder := t.TempDir()
+	diagramFilename := filepath.Join(folder, "diagram.png")
+	diagramFile, err := os.Create(diagramFilename)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(diagramFile, image.NewRGBA(image.Rect(0, 0, 400, 300))))
+	require.NoError(t, diagramFile.Close())
+
+	modelInput := new(input.Model)
+	require.NoError(t, yaml.Unmarshal([]byte(noDataAssetsTestModel), modelInput))
+	parsedModel, err := model.ParseModel(&reportTestConfig{}, modelInput, make(types.RiskRules), make(types.RiskRules))
+	require.NoError(t, err)
+
+	reportFilename := filepath.Join(folder, "report.pdf")
+	err = newPdfReporter(nil).WriteReportPDF(reportFilename, filepath.Join("..", "..", "report", "template", "background.pdf"),
+		diagramFilename, diagramFilename, map[string]string{}, filepath.Join(folder, "threagile.yaml"), nil, "", "1.0.0", "", "",
+		make(types.RiskRules), folder, parsedModel, map[ChaptersToShowHide]bool{})
+	require.NoError(t, err)
+
+	assert.FileExists(t, reportFilename, "the link targets of the chapters match the table of contents")
+}
@@ -0,0 +1,164 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+const highlightedLinkPenWidth = 6.0
+
+var riskCategoryDiagramFilenameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// GenerateRiskCategoryDiagrams renders for each risk category a focused data flow diagram containing only the affected
+// technical assets, their direct neighbours and the trust boundaries around them, with the most relevant links highlighted.
+// It returns the PNG filenames (inside targetDir) by risk category ID; categories without any affected technical asset have none.
+func GenerateRiskCategoryDiagrams(parsedModel *types.Model, targetDir string, tempFolder string, dpi int, nativeDiagrams bool,
+	progressReporter progressReporter) (map[string]string, error) {
+	progressReporter.Info("Rendering risk category diagrams")
+	result := make(map[string]string)
+	for _, category := range parsedModel.SortedRiskCategories() {
+		categoryModel, highlightedLinks := filterModelForRisks(parsedModel, parsedModel.SortedRisksOfCategory(category))
+		if categoryModel == nil {
+			continue
+		}
+
+		filenamePNG := "risk-category-" + riskCategoryDiagramFilenameCharacters.ReplaceAllString(category.ID, "_") + ".png"
+		if nativeDiagrams {
+			categoryDiagram, err := makeDataFlowDiagram(categoryModel, false, false)
+			if err != nil {
+				return nil, fmt.Errorf("error while generating diagram of risk category %q: %w", category.ID, err)
+			}
+			for _, edge := range categoryDiagram.edges {
+				if highlightedLinks[edge.id] {
+					edge.color, edge.penWidth = HighlightedLink, highlightedLinkPenWidth
+				}
+			}
+			err = writeNativeDiagram(categoryDiagram, filepath.Join(targetDir, filenamePNG), dpi)
+			if err != nil {
+				return nil, fmt.Errorf("error while rendering diagram of risk category %q: %w", category.ID, err)
+			}
+		} else {
+			tmpFileGV, err := os.CreateTemp(tempFolder, "risk-category-*.gv")
+			if err != nil {
+				return nil, err
+			}
+			_ = tmpFileGV.Close()
+			dotFile, err := writeDataFlowDiagramGraphvizDOT(categoryModel, tmpFileGV.Name(), dpi, false, false, highlightedLinks)
+			if err != nil {
+				_ = os.Remove(tmpFileGV.Name())
+				return nil, fmt.Errorf("error while generating diagram of risk category %q: %w", category.ID, err)
+			}
+			err = GenerateDataFlowDiagramGraphvizImage(dotFile, targetDir, tempFolder, filenamePNG, progressReporter, false)
+			_ = os.Remove(tmpFileGV.Name())
+			if err != nil {
+				progressReporter.Warn(fmt.Errorf("error while rendering diagram of risk category %q: %w", category.ID, err))
+				continue
+			}
+		}
+		result[category.ID] = filepath.Join(targetDir, filenamePNG)
+	}
+	return result, nil
+}
+
+// filterModelForRisks returns a shallow copy of the model reduced to the technical assets affected by the risks, their
+// direct neighbours and the trust boundaries around them, together with the IDs of the most relevant communication links.
+// The copy is only meant for rendering diagrams. It returns nil when none of the risks affects a technical asset.
+func filterModelForRisks(parsedModel *types.Model, risks []*types.Risk) (*types.Model, map[string]bool) {
+	affected := make(map[string]bool)
+	highlightedLinks := make(map[string]bool)
+	for _, risk := range risks {
+		if len(risk.MostRelevantTechnicalAssetId) > 0 {
+			affected[risk.MostRelevantTechnicalAssetId] = true
+		}
+		if link, ok := parsedModel.CommunicationLinks[risk.MostRelevantCommunicationLinkId]; ok {
+			affected[link.SourceId], affected[link.TargetId] = true, true
+			highlightedLinks[link.Id] = true
+		}
+		if trustBoundary, ok := parsedModel.TrustBoundaries[risk.MostRelevantTrustBoundaryId]; ok {
+			for _, id := range parsedModel.RecursivelyAllTechnicalAssetIDsInside(trustBoundary) {
+				affected[id] = true
+			}
+		}
+		if sharedRuntime, ok := parsedModel.SharedRuntimes[risk.MostRelevantSharedRuntimeId]; ok {
+			for _, id := range sharedRuntime.TechnicalAssetsRunning {
+				affected[id] = true
+			}
+		}
+	}
+
+	included := make(map[string]bool)
+	for id := range affected {
+		technicalAsset, ok := parsedModel.TechnicalAssets[id]
+		if !ok {
+			continue
+		}
+		included[id] = true
+		for _, link := range technicalAsset.CommunicationLinks {
+			included[link.TargetId] = true
+		}
+		for _, link := range parsedModel.IncomingTechnicalCommunicationLinksMappedByTargetId[id] {
+			included[link.SourceId] = true
+		}
+	}
+	if len(included) == 0 {
+		return nil, nil
+	}
+
+	filtered := *parsedModel
+	filtered.TechnicalAssets = make(map[string]*types.TechnicalAsset)
+	filtered.CommunicationLinks = make(map[string]*types.CommunicationLink)
+	for id := range included {
+		technicalAsset, ok := parsedModel.TechnicalAssets[id]
+		if !ok {
+			continue
+		}
+		copied := *technicalAsset
+		copied.CommunicationLinks = make([]*types.CommunicationLink, 0)
+		for _, link := range technicalAsset.CommunicationLinks {
+			// only the links of the affected assets, not the ones between their neighbours
+			if included[link.TargetId] && (affected[link.SourceId] || affected[link.TargetId]) {
+				copied.CommunicationLinks = append(copied.CommunicationLinks, link)
+				filtered.CommunicationLinks[link.Id] = link
+			}
+		}
+		filtered.TechnicalAssets[id] = &copied
+	}
+
+	containsIncluded := func(trustBoundary *types.TrustBoundary) bool {
+		for _, id := range parsedModel.RecursivelyAllTechnicalAssetIDsInside(trustBoundary) {
+			if included[id] {
+				return true
+			}
+		}
+		return false
+	}
+	filtered.TrustBoundaries = make(map[string]*types.TrustBoundary)
+	for id, trustBoundary := range parsedModel.TrustBoundaries {
+		if !containsIncluded(trustBoundary) {
+			continue
+		}
+		copied := *trustBoundary
+		copied.TechnicalAssetsInside = make([]string, 0)
+		for _, assetId := range trustBoundary.TechnicalAssetsInside {
+			if included[assetId] {
+				copied.TechnicalAssetsInside = append(copied.TechnicalAssetsInside, assetId)
+			}
+		}
+		copied.TrustBoundariesNested = make([]string, 0)
+		for _, nestedId := range trustBoundary.TrustBoundariesNested {
+			if nested, ok := parsedModel.TrustBoundaries[nestedId]; ok && containsIncluded(nested) {
+				copied.TrustBoundariesNested = append(copied.TrustBoundariesNested, nestedId)
+			}
+		}
+		filtered.TrustBoundaries[id] = &copied
+	}
+
+	// the layout tweaks refer to assets of the whole model
+	filtered.DiagramTweakSameRankAssets = nil
+	filtered.DiagramTweakInvisibleConnectionsBetweenAssets = nil
+	return &filtered, highlightedLinks
+}
@@ -0,0 +1,144 @@
+package report
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// newRiskCategoryDiagramTestModel has the chain client > web > app > db > backup and an unrelated printer; web is in
+// the "dmz", app in "internal" with the nested "data" holding db and backup, the printer in the "office" and run by the
+// "print-server" shared runtime
+func newRiskCategoryDiagramTestModel() *types.Model {
+	parsedModel := &types.Model{
+		TechnicalAssets:    make(map[string]*types.TechnicalAsset),
+		CommunicationLinks: make(map[string]*types.CommunicationLink),
+		TrustBoundaries: map[string]*types.TrustBoundary{
+			"dmz":      {Id: "dmz", TechnicalAssetsInside: []string{"web"}},
+			"internal": {Id: "internal", TechnicalAssetsInside: []string{"app"}, TrustBoundariesNested: []string{"data"}},
+			"data":     {Id: "data", TechnicalAssetsInside: []string{"db", "backup"}},
+			"office":   {Id: "office", TechnicalAssetsInside: []string{"printer"}},
+		},
+		SharedRuntimes: map[string]*types.SharedRuntime{
+			"print-server": {Id: "print-server", TechnicalAssetsRunning: []string{"printer"}},
+		},
+		IncomingTechnicalCommunicationLinksMappedByTargetId: make(map[string][]*types.CommunicationLink),
+		DiagramTweakSameRankAssets:                          []string{"web:printer"},
+	}
+	for _, id := range []string{"client", "web", "app", "db", "backup", "printer"} {
+		parsedModel.TechnicalAssets[id] = &types.TechnicalAsset{Id: id}
+	}
+	for _, link := range [][]string{{"client", "web"}, {"web", "app"}, {"app", "db"}, {"db", "backup"}} {
+		commLink := &types.CommunicationLink{Id: link[0] + ">" + link[1], SourceId: link[0], TargetId: link[1]}
+		source := parsedModel.TechnicalAssets[link[0]]
+		source.CommunicationLinks = append(source.CommunicationLinks, commLink)
+		parsedModel.CommunicationLinks[commLink.Id] = commLink
+		parsedModel.IncomingTechnicalCommunicationLinksMappedByTargetId[link[1]] = append(parsedModel.IncomingTechnicalCommunicationLinksMappedByTargetId[link[1]], commLink)
+	}
+	return parsedModel
+}
+
+func TestFilterModelForRisks(t *testing.T) {
+	tests := []struct {
+		name             string
+		risks            []*types.Risk
+		assets           []string
+		links            []string
+		trustBoundaries  map[string][]string
+		nested           map[string][]string
+		highlightedLinks map[string]bool
+	}{
+		{
+			name:            "technical asset",
+			risks:           []*types.Risk{{MostRelevantTechnicalAssetId: "app"}},
+			assets:          []string{"app", "db", "web"},
+			links:           []string{"app>db", "web>app"},
+			trustBoundaries: map[string][]string{"dmz": {"web"}, "internal": {"app"}, "data": {"db"}},
+			nested:          map[string][]string{"internal": {"data"}},
+		},
+		{
+			name:             "communication link",
+			risks:            []*types.Risk{{MostRelevantCommunicationLinkId: "web>app", MostRelevantTechnicalAssetId: "web"}},
+			assets:           []string{"app", "client", "db", "web"},
+			links:            []string{"app>db", "client>web", "web>app"},
+			trustBoundaries:  map[string][]string{"dmz": {"web"}, "internal": {"app"}, "data": {"db"}},
+			nested:           map[string][]string{"internal": {"data"}},
+			highlightedLinks: map[string]bool{"web>app": true},
+		},
+		{
+			name:            "trust boundary",
+			risks:           []*types.Risk{{MostRelevantTrustBoundaryId: "internal"}},
+			assets:          []string{"app", "backup", "db", "web"},
+			links:           []string{"app>db", "db>backup", "web>app"},
+			trustBoundaries: map[string][]string{"dmz": {"web"}, "internal": {"app"}, "data": {"db", "backup"}},
+			nested:          map[string][]string{"internal": {"data"}},
+		},
+		{
+			name:            "shared runtime",
+			risks:           []*types.Risk{{MostRelevantSharedRuntimeId: "print-server"}},
+			assets:          []string{"printer"},
+			links:           []string{},
+			trustBoundaries: map[string][]string{"office": {"printer"}},
+		},
+		{
+			name:            "several risks",
+			risks:           []*types.Risk{{MostRelevantTechnicalAssetId: "client"}, {MostRelevantTechnicalAssetId: "backup"}},
+			assets:          []string{"backup", "client", "db", "web"},
+			links:           []string{"client>web", "db>backup"},
+			trustBoundaries: map[string][]string{"dmz": {"web"}, "internal": {}, "data": {"db", "backup"}},
+			nested:          map[string][]string{"internal": {"data"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsedModel := newRiskCategoryDiagramTestModel()
+
+			filtered, highlightedLinks := filterModelForRisks(parsedModel, test.risks)
+
+			require.NotNil(t, filtered)
+			assert.ElementsMatch(t, test.assets, slices.Collect(maps.Keys(filtered.TechnicalAssets)))
+			assert.ElementsMatch(t, test.links, slices.Collect(maps.Keys(filtered.CommunicationLinks)))
+			linksOfAssets := make([]string, 0)
+			for _, technicalAsset := range filtered.TechnicalAssets {
+				for _, link := range technicalAsset.CommunicationLinks {
+					linksOfAssets = append(linksOfAssets, link.Id)
+				}
+			}
+			assert.ElementsMatch(t, test.links, linksOfAssets, "the assets hold the same links")
+
+			trustBoundaries := make(map[string][]string)
+			nested := make(map[string][]string)
+			for id, trustBoundary := range filtered.TrustBoundaries {
+				trustBoundaries[id] = trustBoundary.TechnicalAssetsInside
+				if len(trustBoundary.TrustBoundariesNested) > 0 {
+					nested[id] = trustBoundary.TrustBoundariesNested
+				}
+			}
+			assert.Equal(t, test.trustBoundaries, trustBoundaries)
+			if test.nested == nil {
+				test.nested = map[string][]string{}
+			}
+			assert.Equal(t, test.nested, nested)
+			if test.highlightedLinks == nil {
+				test.highlightedLinks = map[string]bool{}
+			}
+			assert.Equal(t, test.highlightedLinks, highlightedLinks)
+			assert.Nil(t, filtered.DiagramTweakSameRankAssets)
+
+			assert.Equal(t, newRiskCategoryDiagramTestModel(), parsedModel, "the model itself is left unchanged")
+		})
+	}
+}
+
+func TestFilterModelForRisksWithoutTechnicalAsset(t *testing.T) {
+	filtered, highlightedLinks := filterModelForRisks(newRiskCategoryDiagramTestModel(), []*types.Risk{{MostRelevantDataAssetId: "customer-data"}})
+
+	assert.Nil(t, filtered)
+	assert.Nil(t, highlightedLinks)
+}
@@ -2,9 +2,14 @@ package risks
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"github.com/threagile/threagile/pkg/risks/script"
 	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/threagile/threagile/pkg/risks/builtin"
 	"github.com/threagile/threagile/pkg/types"
@@ -77,6 +82,47 @@ func GetBuiltInRiskRules() types.RiskRules {
 	return rules
 }
 
+// GetRiskRules provides the built-in risk rules along with the script risk rules found in scriptRulesDir (if given).
+// Rules of the directory shadow built-in rules of the same id, which is listed as warning.
+func GetRiskRules(scriptRulesDir string, progressReporter types.ProgressReporter) types.RiskRules {
+	rules := GetBuiltInRiskRules()
+	shadowed := make([]string, 0)
+	for id, rule := range GetScriptRulesDirRiskRules(scriptRulesDir, progressReporter) {
+		if builtinRule, ok := rules[id]; ok && builtinRule != nil {
+			shadowed = append(shadowed, id)
+		}
+
+		rules[id] = rule
+	}
+
+	if len(shadowed) > 0 {
+		sort.Strings(shadowed)
+		progressReporter.Warnf("script risk rules from %q shadow built-in risk rules: %v", scriptRulesDir, strings.Join(shadowed, ", "))
+	}
+
+	return rules
+}
+
+// GetScriptRulesDirRiskRules provides only the script risk rules found in scriptRulesDir (if given), e.g. to list them
+// apart from the built-in rules. Rules failing to load are reported as warning.
+func GetScriptRulesDirRiskRules(scriptRulesDir string, progressReporter types.ProgressReporter) types.RiskRules {
+	rules := make(types.RiskRules)
+	if len(scriptRulesDir) == 0 {
+		return rules
+	}
+
+	scriptRules, scriptError := make(RiskRules).LoadRiskRulesFromDir(scriptRulesDir)
+	if scriptError != nil {
+		progressReporter.Warnf("error loading script risk rules from %q:\n%v", scriptRulesDir, scriptError)
+	}
+
+	for id, rule := range scriptRules {
+		rules[id] = rule
+	}
+
+	return rules
+}
+
 //go:embed scripts/*.yaml
 var ruleScripts embed.FS
 
@@ -87,16 +133,45 @@ func GetScriptRiskRules() (RiskRules, error) {
 }
 
 func (what RiskRules) LoadRiskRules() (RiskRules, error) {
-	fileSystem := ruleScripts
-	walkError := fs.WalkDir(fileSystem, "scripts", func(path string, entry fs.DirEntry, err error) error {
+	return what.loadRiskRules(ruleScripts, "scripts")
+}
+
+// LoadRiskRulesFromDir loads the script risk rules (*.yaml and *.yml files) of a directory and its subdirectories.
+// A file failing to load doesn't prevent loading the others, the returned error lists the failures per file.
+func (what RiskRules) LoadRiskRulesFromDir(dir string) (RiskRules, error) {
+	info, statError := os.Stat(dir)
+	if statError != nil {
+		return what, statError
+	}
+
+	if !info.IsDir() {
+		return what, fmt.Errorf("%q is not a directory", dir)
+	}
+
+	return what.loadRiskRules(os.DirFS(dir), ".")
+}
+
+func (what RiskRules) loadRiskRules(fileSystem fs.FS, root string) (RiskRules, error) {
+	loadErrors := make([]error, 0)
+	walkError := fs.WalkDir(fileSystem, root, func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
+		if extension := strings.ToLower(filepath.Ext(path)); extension != ".yaml" && extension != ".yml" {
+			return nil
+		}
+
+		// rule tests may live next to the rules they test
+		if script.IsRuleTestFile(path) {
+			return nil
+		}
+
 		newRule := new(script.RiskRule).Init()
 		loadError := newRule.Load(fileSystem, path, entry)
 		if loadError != nil {
-			return loadError
+			loadErrors = append(loadErrors, fmt.Errorf("%v: %w", path, loadError))
+			return nil
 		}
 
 		if newRule.Category().ID == "" {
@@ -111,5 +186,5 @@ func (what RiskRules) LoadRiskRules() (RiskRules, error) {
 		return nil, walkError
 	}
 
-	return what, nil
+	return what, errors.Join(loadErrors...)
 }
@@ -0,0 +1,120 @@
+package risks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRuleYAML = `
+id: %s
+title: Test Rule
+function: operations
+stride: information-disclosure
+cwe: 200
+description: Test description
+impact: Test impact
+asvs: V1
+cheat_sheet: https://example.com
+action: Test Action
+mitigation: Test mitigation
+check: Is it mitigated?
+detection_logic: Always
+risk_assessment: Low
+false_positives: None
+
+risk:
+  id:
+    parameter: tech_asset
+    id: "{$risk.id}@{tech_asset.id}"
+  match:
+    parameter: tech_asset
+    do:
+      - return: true
+  data:
+    parameter: tech_asset
+    title: "<b>Test Rule</b> risk at <b>{tech_asset.title}</b>"
+`
+
+type testProgressReporter struct {
+	warnings []string
+}
+
+func (what *testProgressReporter) Info(...any) {}
+func (what *testProgressReporter) Warn(a ...any) {
+	what.warnings = append(what.warnings, fmt.Sprint(a...))
+}
+func (what *testProgressReporter) Error(...any)          {}
+func (what *testProgressReporter) Infof(string, ...any)  {}
+func (what *testProgressReporter) Errorf(string, ...any) {}
+func (what *testProgressReporter) Warnf(format string, a ...any) {
+	what.warnings = append(what.warnings, fmt.Sprintf(format, a...))
+}
+
+func writeTestRule(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+}
+
+func TestLoadRiskRulesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRule(t, dir, "first.yaml", fmt.Sprintf(testRuleYAML, "first-rule"))
+	writeTestRule(t, dir, "nested/second.yml", fmt.Sprintf(testRuleYAML, "second-rule"))
+	writeTestRule(t, dir, "broken.yaml", "id: [broken")
+	writeTestRule(t, dir, "readme.txt", "not a rule")
+	writeTestRule(t, dir, "first.test.yaml", "rule: first-rule")
+
+	rules, err := make(RiskRules).LoadRiskRulesFromDir(dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken.yaml")
+	assert.NotContains(t, err.Error(), "readme.txt")
+	assert.NotContains(t, err.Error(), "first.test.yaml")
+	assert.Len(t, rules, 2)
+	assert.Contains(t, rules, "first-rule")
+	assert.Contains(t, rules, "second-rule")
+}
+
+func TestLoadRiskRulesFromDirMissing(t *testing.T) {
+	_, err := make(RiskRules).LoadRiskRulesFromDir(filepath.Join(t.TempDir(), "missing"))
+
+	assert.Error(t, err)
+}
+
+func TestGetRiskRulesShadowsBuiltIn(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRule(t, dir, "shadow.yaml", fmt.Sprintf(testRuleYAML, "accidental-secret-leak"))
+	writeTestRule(t, dir, "own.yaml", fmt.Sprintf(testRuleYAML, "own-rule"))
+
+	reporter := new(testProgressReporter)
+	rules := GetRiskRules(dir, reporter)
+
+	assert.Len(t, rules, len(GetBuiltInRiskRules())+1)
+	assert.Equal(t, "Test Rule", rules["accidental-secret-leak"].Category().Title)
+	require.Len(t, reporter.warnings, 1)
+	assert.Contains(t, reporter.warnings[0], "shadow built-in risk rules")
+}
+
+func TestGetScriptRulesDirRiskRules(t *testing.T) {
+	dir := t.TempDir()
+	writeTestRule(t, dir, "shadow.yaml", fmt.Sprintf(testRuleYAML, "accidental-secret-leak"))
+	writeTestRule(t, dir, "own.yaml", fmt.Sprintf(testRuleYAML, "own-rule"))
+	writeTestRule(t, dir, "broken.yaml", "id: [broken")
+
+	reporter := new(testProgressReporter)
+	rules := GetScriptRulesDirRiskRules(dir, reporter)
+
+	assert.Len(t, rules, 2, "only the rules of the directory")
+	assert.Contains(t, rules, "accidental-secret-leak")
+	assert.Contains(t, rules, "own-rule")
+	require.Len(t, reporter.warnings, 1)
+	assert.Contains(t, reporter.warnings[0], "broken.yaml")
+
+	assert.Empty(t, GetScriptRulesDirRiskRules("", reporter))
+}
@@ -0,0 +1,115 @@
+package script
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/threagile/threagile/pkg/risks/script/common"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+const (
+	TechnicalAssets    = common.TechnicalAssets
+	CommunicationLinks = common.CommunicationLinks
+	DataAssets         = common.DataAssets
+	TrustBoundaries    = common.TrustBoundaries
+	SharedRuntimes     = common.SharedRuntimes
+)
+
+// collection describes a model collection a script can iterate over, i.e. what the match, data and id sections get
+// passed as parameter, and which of the most relevant fields of a risk refers to its items
+type collection struct {
+	name     string
+	singular string
+	plural   string
+	required bool
+
+	// mostRelevant points to the risk field holding the id of the item the risk has been generated for
+	mostRelevant func(risk *types.Risk) *string
+
+	// setDefaults fills in further most relevant fields left empty by the data section
+	setDefaults func(risk *types.Risk, item map[string]any)
+}
+
+var collections = map[string]*collection{
+	TechnicalAssets: {
+		name:     TechnicalAssets,
+		singular: "technical asset",
+		plural:   "technical assets",
+		required: true,
+		mostRelevant: func(risk *types.Risk) *string {
+			return &risk.MostRelevantTechnicalAssetId
+		},
+	},
+	CommunicationLinks: {
+		name:     CommunicationLinks,
+		singular: "communication link",
+		plural:   "communication links",
+		mostRelevant: func(risk *types.Risk) *string {
+			return &risk.MostRelevantCommunicationLinkId
+		},
+		setDefaults: func(risk *types.Risk, item map[string]any) {
+			if len(risk.MostRelevantTechnicalAssetId) == 0 {
+				risk.MostRelevantTechnicalAssetId, _ = item["source_id"].(string)
+			}
+		},
+	},
+	DataAssets: {
+		name:     DataAssets,
+		singular: "data asset",
+		plural:   "data assets",
+		mostRelevant: func(risk *types.Risk) *string {
+			return &risk.MostRelevantDataAssetId
+		},
+	},
+	TrustBoundaries: {
+		name:     TrustBoundaries,
+		singular: "trust boundary",
+		plural:   "trust boundaries",
+		mostRelevant: func(risk *types.Risk) *string {
+			return &risk.MostRelevantTrustBoundaryId
+		},
+	},
+	SharedRuntimes: {
+		name:     SharedRuntimes,
+		singular: "shared runtime",
+		plural:   "shared runtimes",
+		mostRelevant: func(risk *types.Risk) *string {
+			return &risk.MostRelevantSharedRuntimeId
+		},
+	},
+}
+
+func getCollection(name string) (*collection, error) {
+	found, ok := collections[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(collections))
+		for collectionName := range collections {
+			names = append(names, collectionName)
+		}
+
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown collection %q, expected one of %v", name, strings.Join(names, ", "))
+	}
+
+	return found, nil
+}
+
+// complete sets the most relevant fields left empty by the data section to the item the risk has been generated for
+func (what *collection) complete(risk *types.Risk, itemID string, item any) {
+	mostRelevant := what.mostRelevant(risk)
+	if len(*mostRelevant) == 0 {
+		*mostRelevant = itemID
+	}
+
+	if what.setDefaults != nil {
+		itemMap, _ := item.(map[string]any)
+		what.setDefaults(risk, itemMap)
+	}
+}
+
+// defaultSyntheticID is used if the script comes without id section
+func (what *collection) defaultSyntheticID(risk *types.Risk) string {
+	return risk.CategoryId + "@" + *what.mostRelevant(risk)
+}
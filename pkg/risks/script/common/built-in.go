@@ -2,37 +2,70 @@ package common
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
 	"github.com/shopspring/decimal"
 	"github.com/threagile/threagile/pkg/types"
 )
 
 const (
-	calculateSeverity = "calculate_severity"
+	calculateSeverity          = "calculate_severity"
+	incomingLinks              = "incoming_links"
+	outgoingLinks              = "outgoing_links"
+	trustBoundaryOf            = "trust_boundary_of"
+	crossesTrustBoundary       = "crosses_trust_boundary"
+	mostConfidentialDataAssets = "most_confidential_data_assets"
+	sharedRuntimeSiblings      = "shared_runtime_siblings"
+	lower                      = "lower"
+	matches                    = "matches"
+	join                       = "join"
+)
+
+// keys of the model items (as seen by scripts) used by the built-ins
+const (
+	technicalAssetsInsideKey  = "technical_assets_inside"
+	technicalAssetsRunningKey = "technical_assets_running"
+	dataAssetsProcessedKey    = "data_assets_processed"
+	targetIDKey               = "target_id"
+	confidentialityKey        = "confidentiality"
 )
 
 var (
 	callers = map[string]builtInFunc{
-		calculateSeverity: calculateSeverityFunc,
+		calculateSeverity:          calculateSeverityFunc,
+		incomingLinks:              incomingLinksFunc,
+		outgoingLinks:              outgoingLinksFunc,
+		trustBoundaryOf:            trustBoundaryOfFunc,
+		crossesTrustBoundary:       crossesTrustBoundaryFunc,
+		mostConfidentialDataAssets: mostConfidentialDataAssetsFunc,
+		sharedRuntimeSiblings:      sharedRuntimeSiblingsFunc,
+		lower:                      lowerFunc,
+		matches:                    matchesFunc,
+		join:                       joinFunc,
 	}
 )
 
-type builtInFunc func(parameters []Value) (Value, error)
+// builtInFunc gets the scope to look up the model, which is not needed by all built-ins
+type builtInFunc func(scope *Scope, parameters []Value) (Value, error)
 
 func IsBuiltIn(builtInName string) bool {
 	_, ok := callers[builtInName]
 	return ok
 }
 
-func CallBuiltIn(builtInName string, parameters ...Value) (Value, error) {
+func CallBuiltIn(scope *Scope, builtInName string, parameters ...Value) (Value, error) {
 	caller, ok := callers[builtInName]
 	if !ok {
 		return nil, fmt.Errorf("unknown built-in %v", builtInName)
 	}
 
-	return caller(parameters)
+	return caller(scope, parameters)
 }
 
-func calculateSeverityFunc(parameters []Value) (Value, error) {
+func calculateSeverityFunc(_ *Scope, parameters []Value) (Value, error) {
 	if len(parameters) != 2 {
 		return nil, fmt.Errorf("failed to calculate severity: expected 2 parameters, got %d", len(parameters))
 	}
@@ -59,3 +92,315 @@ func calculateSeverityFunc(parameters []Value) (Value, error) {
 
 	return SomeStringValue(types.CalculateSeverity(types.RiskExploitationLikelihood(likelihoodDecimal), types.RiskExploitationImpact(impactDecimal)).String(), nil), nil
 }
+
+func incomingLinksFunc(scope *Scope, parameters []Value) (Value, error) {
+	assetID, parameterError := assetParameter(incomingLinks, parameters)
+	if parameterError != nil {
+		return nil, parameterError
+	}
+
+	links := make(map[string]any)
+	for _, asset := range modelItems(scope, TechnicalAssets) {
+		for _, link := range mapItems(asset, CommunicationLinks) {
+			if itemString(link, targetIDKey) == assetID {
+				links[itemString(link, ID)] = link
+			}
+		}
+	}
+
+	return itemsValue(links, "communication link", fmt.Sprintf("incoming links of technical asset '%v'", assetID)), nil
+}
+
+func outgoingLinksFunc(scope *Scope, parameters []Value) (Value, error) {
+	assetID, parameterError := assetParameter(outgoingLinks, parameters)
+	if parameterError != nil {
+		return nil, parameterError
+	}
+
+	links := make(map[string]any)
+	for _, link := range mapItems(modelItems(scope, TechnicalAssets)[assetID], CommunicationLinks) {
+		links[itemString(link, ID)] = link
+	}
+
+	return itemsValue(links, "communication link", fmt.Sprintf("outgoing links of technical asset '%v'", assetID)), nil
+}
+
+func trustBoundaryOfFunc(scope *Scope, parameters []Value) (Value, error) {
+	assetID, parameterError := assetParameter(trustBoundaryOf, parameters)
+	if parameterError != nil {
+		return nil, parameterError
+	}
+
+	path := NewPath(fmt.Sprintf("trust boundary of technical asset '%v'", assetID))
+	boundaryID, boundary := findTrustBoundary(scope, assetID)
+	if boundary == nil {
+		return SomeValue(nil, NewEvent(NewValueProperty(nil), path)), nil
+	}
+
+	return SomeValue(boundary, NewEvent(NewValueProperty(boundaryID), path)), nil
+}
+
+// crossesTrustBoundaryFunc tells whether two technical assets are not directly contained in the same trust boundary
+func crossesTrustBoundaryFunc(scope *Scope, parameters []Value) (Value, error) {
+	if len(parameters) != 2 {
+		return nil, fmt.Errorf("failed to call %q: expected 2 parameters, got %d", crossesTrustBoundary, len(parameters))
+	}
+
+	firstID, firstError := assetParameter(crossesTrustBoundary, parameters[:1])
+	if firstError != nil {
+		return nil, firstError
+	}
+
+	secondID, secondError := assetParameter(crossesTrustBoundary, parameters[1:])
+	if secondError != nil {
+		return nil, secondError
+	}
+
+	firstBoundaryID, _ := findTrustBoundary(scope, firstID)
+	secondBoundaryID, _ := findTrustBoundary(scope, secondID)
+
+	firstEvent := NewEvent(NewValueProperty(firstBoundaryID), NewPath(fmt.Sprintf("trust boundary of technical asset '%v'", firstID)))
+	secondEvent := NewEvent(NewValueProperty(secondBoundaryID), NewPath(fmt.Sprintf("trust boundary of technical asset '%v'", secondID)))
+	event := NewEvent(NewValueProperty(firstBoundaryID != secondBoundaryID), NewPath(fmt.Sprintf("trust boundary crossing between technical asset '%v' and '%v'", firstID, secondID))).AddHistory([]*Event{firstEvent, secondEvent})
+
+	return SomeBoolValue(firstBoundaryID != secondBoundaryID, event), nil
+}
+
+// mostConfidentialDataAssetsFunc returns the data assets processed by a technical asset which have the highest
+// confidentiality of them
+func mostConfidentialDataAssetsFunc(scope *Scope, parameters []Value) (Value, error) {
+	assetID, parameterError := assetParameter(mostConfidentialDataAssets, parameters)
+	if parameterError != nil {
+		return nil, parameterError
+	}
+
+	dataAssets := modelItems(scope, DataAssets)
+	highest := types.Confidentiality(-1)
+	mostConfidential := make(map[string]any)
+	for _, dataAssetID := range stringItems(modelItems(scope, TechnicalAssets)[assetID], dataAssetsProcessedKey) {
+		dataAsset, ok := dataAssets[dataAssetID]
+		if !ok {
+			continue
+		}
+
+		confidentiality := types.Public // omitted from the model as it's the zero value
+		if confidentialityText := itemString(dataAsset, confidentialityKey); len(confidentialityText) > 0 {
+			var confidentialityError error
+			confidentiality, confidentialityError = types.Confidentiality(0).Find(confidentialityText)
+			if confidentialityError != nil {
+				return nil, fmt.Errorf("failed to call %q: data asset %q: %w", mostConfidentialDataAssets, dataAssetID, confidentialityError)
+			}
+		}
+
+		if confidentiality > highest {
+			highest = confidentiality
+			mostConfidential = make(map[string]any)
+		}
+
+		if confidentiality == highest {
+			mostConfidential[dataAssetID] = dataAsset
+		}
+	}
+
+	path := fmt.Sprintf("most confidential data assets processed by technical asset '%v'", assetID)
+	if len(mostConfidential) > 0 {
+		path = fmt.Sprintf("%v (%v)", path, highest)
+	}
+
+	return itemsValue(mostConfidential, "data asset", path), nil
+}
+
+// sharedRuntimeSiblingsFunc returns the technical assets running on any of the shared runtimes a technical asset runs on
+func sharedRuntimeSiblingsFunc(scope *Scope, parameters []Value) (Value, error) {
+	assetID, parameterError := assetParameter(sharedRuntimeSiblings, parameters)
+	if parameterError != nil {
+		return nil, parameterError
+	}
+
+	technicalAssets := modelItems(scope, TechnicalAssets)
+	siblings := make(map[string]any)
+	for _, sharedRuntime := range modelItems(scope, SharedRuntimes) {
+		running := stringItems(sharedRuntime, technicalAssetsRunningKey)
+		if !slices.Contains(running, assetID) {
+			continue
+		}
+
+		for _, siblingID := range running {
+			if sibling, ok := technicalAssets[siblingID]; ok && siblingID != assetID {
+				siblings[siblingID] = sibling
+			}
+		}
+	}
+
+	return itemsValue(siblings, "technical asset", fmt.Sprintf("shared runtime siblings of technical asset '%v'", assetID)), nil
+}
+
+func lowerFunc(_ *Scope, parameters []Value) (Value, error) {
+	if len(parameters) != 1 {
+		return nil, fmt.Errorf("failed to call %q: expected 1 parameter, got %d", lower, len(parameters))
+	}
+
+	text, textError := ToString(parameters[0])
+	if textError != nil {
+		return nil, fmt.Errorf("failed to call %q: %w", lower, textError)
+	}
+
+	result := strings.ToLower(text.StringValue())
+	return SomeStringValue(result, NewEvent(NewValueProperty(result), NewPath(fmt.Sprintf("lower case of '%v'", text.StringValue()))).From(parameters...)), nil
+}
+
+func matchesFunc(_ *Scope, parameters []Value) (Value, error) {
+	if len(parameters) != 2 {
+		return nil, fmt.Errorf("failed to call %q: expected 2 parameters, got %d", matches, len(parameters))
+	}
+
+	text, textError := ToString(parameters[0])
+	if textError != nil {
+		return nil, fmt.Errorf("failed to call %q: %w", matches, textError)
+	}
+
+	pattern, patternError := ToString(parameters[1])
+	if patternError != nil {
+		return nil, fmt.Errorf("failed to call %q: %w", matches, patternError)
+	}
+
+	re, compileError := regexp.Compile(pattern.StringValue())
+	if compileError != nil {
+		return nil, fmt.Errorf("failed to call %q: %w", matches, compileError)
+	}
+
+	result := re.MatchString(text.StringValue())
+	return SomeBoolValue(result, NewEvent(NewValueProperty(result), NewPath(fmt.Sprintf("match of '%v' with '%v'", text.StringValue(), pattern.StringValue()))).From(parameters...)), nil
+}
+
+// joinFunc joins the items of an array with the given separator (", " by default); items that are model elements are
+// represented by their id
+func joinFunc(_ *Scope, parameters []Value) (Value, error) {
+	if len(parameters) < 1 || len(parameters) > 2 {
+		return nil, fmt.Errorf("failed to call %q: expected 1 or 2 parameters, got %d", join, len(parameters))
+	}
+
+	array, arrayError := ToArrayValue(parameters[0])
+	if arrayError != nil {
+		return nil, fmt.Errorf("failed to call %q: %w", join, arrayError)
+	}
+
+	separator := ", "
+	if len(parameters) > 1 {
+		separatorValue, separatorError := ToString(parameters[1])
+		if separatorError != nil {
+			return nil, fmt.Errorf("failed to call %q: %w", join, separatorError)
+		}
+
+		separator = separatorValue.StringValue()
+	}
+
+	texts := make([]string, 0)
+	for _, item := range array.ArrayValue() {
+		switch castItem := item.PlainValue().(type) {
+		case map[string]any:
+			texts = append(texts, itemString(castItem, ID))
+
+		default:
+			texts = append(texts, fmt.Sprintf("%v", castItem))
+		}
+	}
+
+	result := strings.Join(texts, separator)
+	return SomeStringValue(result, NewEvent(NewValueProperty(result), NewPath("joined items")).From(parameters...)), nil
+}
+
+// assetParameter returns the id of the technical asset passed either as item or as id
+func assetParameter(builtInName string, parameters []Value) (string, error) {
+	if len(parameters) != 1 {
+		return "", fmt.Errorf("failed to call %q: expected 1 parameter, got %d", builtInName, len(parameters))
+	}
+
+	switch castValue := parameters[0].PlainValue().(type) {
+	case map[string]any:
+		return itemString(castValue, ID), nil
+
+	case string:
+		return castValue, nil
+
+	default:
+		return "", fmt.Errorf("failed to call %q: expected technical asset or its id, got %T", builtInName, castValue)
+	}
+}
+
+func findTrustBoundary(scope *Scope, assetID string) (string, map[string]any) {
+	for boundaryID, boundary := range modelItems(scope, TrustBoundaries) {
+		if slices.Contains(stringItems(boundary, technicalAssetsInsideKey), assetID) {
+			return boundaryID, boundary
+		}
+	}
+
+	return "", nil
+}
+
+// itemsValue turns model elements keyed by id into an array sorted by id, each element carrying its own event
+func itemsValue(items map[string]any, itemDescription string, path string) Value {
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	values := make([]Value, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, SomeValue(items[id], NewEvent(NewValueProperty(items[id]), NewPath(fmt.Sprintf("%v '%v'", itemDescription, id)))))
+	}
+
+	text := "none"
+	if len(ids) > 0 {
+		text = strings.Join(ids, ", ")
+	}
+
+	return SomeArrayValue(values, NewEvent(NewValueProperty(text), NewPath(path)))
+}
+
+func modelItems(scope *Scope, name string) map[string]map[string]any {
+	items := make(map[string]map[string]any)
+	if scope == nil {
+		return items
+	}
+
+	collection, _ := scope.Model[name].(map[string]any)
+	for id, item := range collection {
+		if itemMap, ok := item.(map[string]any); ok {
+			items[id] = itemMap
+		}
+	}
+
+	return items
+}
+
+func mapItems(item map[string]any, name string) []map[string]any {
+	items := make([]map[string]any, 0)
+	list, _ := item[name].([]any)
+	for _, listItem := range list {
+		if itemMap, ok := listItem.(map[string]any); ok {
+			items = append(items, itemMap)
+		}
+	}
+
+	return items
+}
+
+func stringItems(item map[string]any, name string) []string {
+	items := make([]string, 0)
+	list, _ := item[name].([]any)
+	for _, listItem := range list {
+		if itemString, ok := listItem.(string); ok {
+			items = append(items, itemString)
+		}
+	}
+
+	return items
+}
+
+func itemString(item map[string]any, name string) string {
+	value, _ := item[name].(string)
+	return value
+}
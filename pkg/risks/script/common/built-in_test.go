@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/threagile/threagile/pkg/types"
 )
 
 func TestIsBuiltIn_Known(t *testing.T) {
@@ -19,19 +20,186 @@ func TestCallBuiltIn_CalculateSeverity(t *testing.T) {
 	unlikelyVal := SomeStringValue("unlikely", nil)
 	lowVal := SomeStringValue("low", nil)
 
-	result, err := CallBuiltIn("calculate_severity", unlikelyVal, lowVal)
+	result, err := CallBuiltIn(nil, "calculate_severity", unlikelyVal, lowVal)
 	assert.NoError(t, err)
 	assert.Equal(t, "low", result.Value())
 }
 
 func TestCallBuiltIn_WrongArgCount(t *testing.T) {
-	_, err := CallBuiltIn("calculate_severity", SomeStringValue("unlikely", nil))
+	_, err := CallBuiltIn(nil, "calculate_severity", SomeStringValue("unlikely", nil))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "expected 2 parameters")
 }
 
 func TestCallBuiltIn_UnknownFunction(t *testing.T) {
-	_, err := CallBuiltIn("nonexistent")
+	_, err := CallBuiltIn(nil, "nonexistent")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unknown built-in")
 }
+
+func newBuiltInTestScope(t *testing.T) *Scope {
+	t.Helper()
+
+	model := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"web": {
+				Id:                  "web",
+				DataAssetsProcessed: []string{"logs", "secrets", "keys"},
+				CommunicationLinks: []*types.CommunicationLink{
+					{Id: "web>db", SourceId: "web", TargetId: "db"},
+					{Id: "web>cache", SourceId: "web", TargetId: "cache"},
+				},
+			},
+			"db": {
+				Id: "db",
+				CommunicationLinks: []*types.CommunicationLink{
+					{Id: "db>backup", SourceId: "db", TargetId: "backup"},
+				},
+			},
+			"cache":  {Id: "cache"},
+			"backup": {Id: "backup"},
+		},
+		DataAssets: map[string]*types.DataAsset{
+			"logs":    {Id: "logs", Confidentiality: types.Public},
+			"secrets": {Id: "secrets", Confidentiality: types.StrictlyConfidential},
+			"keys":    {Id: "keys", Confidentiality: types.StrictlyConfidential},
+		},
+		TrustBoundaries: map[string]*types.TrustBoundary{
+			"dmz":      {Id: "dmz", TechnicalAssetsInside: []string{"web"}},
+			"internal": {Id: "internal", TechnicalAssetsInside: []string{"db", "cache"}},
+		},
+		SharedRuntimes: map[string]*types.SharedRuntime{
+			"cluster": {Id: "cluster", TechnicalAssetsRunning: []string{"db", "cache"}},
+		},
+	}
+
+	scope := new(Scope)
+	assert.NoError(t, scope.SetModel(model))
+
+	return scope
+}
+
+func builtInIDs(t *testing.T, value Value) []string {
+	t.Helper()
+
+	array, arrayError := ToArrayValue(value)
+	assert.NoError(t, arrayError)
+
+	ids := make([]string, 0)
+	for _, item := range array.ArrayValue() {
+		ids = append(ids, item.PlainValue().(map[string]any)["id"].(string))
+	}
+
+	return ids
+}
+
+func TestCallBuiltIn_IncomingLinks(t *testing.T) {
+	scope := newBuiltInTestScope(t)
+
+	result, err := CallBuiltIn(scope, "incoming_links", SomeStringValue("db", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web>db"}, builtInIDs(t, result))
+	assert.Contains(t, result.Event().String(), "incoming links of technical asset 'db'")
+}
+
+func TestCallBuiltIn_OutgoingLinks(t *testing.T) {
+	scope := newBuiltInTestScope(t)
+	asset := SomeValue(scope.Model["technical_assets"].(map[string]any)["web"], nil)
+
+	result, err := CallBuiltIn(scope, "outgoing_links", asset)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"web>cache", "web>db"}, builtInIDs(t, result))
+}
+
+func TestCallBuiltIn_OutgoingLinks_WrongArgCount(t *testing.T) {
+	_, err := CallBuiltIn(newBuiltInTestScope(t), "outgoing_links")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected 1 parameter")
+}
+
+func TestCallBuiltIn_TrustBoundaryOf(t *testing.T) {
+	scope := newBuiltInTestScope(t)
+
+	result, err := CallBuiltIn(scope, "trust_boundary_of", SomeStringValue("cache", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "internal", result.PlainValue().(map[string]any)["id"])
+	assert.Contains(t, result.Event().String(), "trust boundary of technical asset 'cache' is internal")
+
+	result, err = CallBuiltIn(scope, "trust_boundary_of", SomeStringValue("backup", nil))
+	assert.NoError(t, err)
+	assert.Nil(t, result.PlainValue())
+}
+
+func TestCallBuiltIn_CrossesTrustBoundary(t *testing.T) {
+	scope := newBuiltInTestScope(t)
+
+	result, err := CallBuiltIn(scope, "crosses_trust_boundary", SomeStringValue("web", nil), SomeStringValue("db", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, true, result.Value())
+	assert.Contains(t, result.Event().String(), "trust boundary of technical asset 'web' is dmz")
+	assert.Contains(t, result.Event().String(), "trust boundary of technical asset 'db' is internal")
+
+	result, err = CallBuiltIn(scope, "crosses_trust_boundary", SomeStringValue("db", nil), SomeStringValue("cache", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, false, result.Value())
+}
+
+func TestCallBuiltIn_MostConfidentialDataAssets(t *testing.T) {
+	scope := newBuiltInTestScope(t)
+
+	result, err := CallBuiltIn(scope, "most_confidential_data_assets", SomeStringValue("web", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"keys", "secrets"}, builtInIDs(t, result))
+	assert.Contains(t, result.Event().String(), "strictly-confidential")
+
+	result, err = CallBuiltIn(scope, "most_confidential_data_assets", SomeStringValue("db", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, builtInIDs(t, result))
+}
+
+func TestCallBuiltIn_SharedRuntimeSiblings(t *testing.T) {
+	scope := newBuiltInTestScope(t)
+
+	result, err := CallBuiltIn(scope, "shared_runtime_siblings", SomeStringValue("db", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache"}, builtInIDs(t, result))
+
+	result, err = CallBuiltIn(scope, "shared_runtime_siblings", SomeStringValue("web", nil))
+	assert.NoError(t, err)
+	assert.Empty(t, builtInIDs(t, result))
+}
+
+func TestCallBuiltIn_Lower(t *testing.T) {
+	result, err := CallBuiltIn(nil, "lower", SomeStringValue("MySQL", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "mysql", result.Value())
+	assert.Contains(t, result.Event().String(), "lower case of 'MySQL' is mysql")
+}
+
+func TestCallBuiltIn_Matches(t *testing.T) {
+	result, err := CallBuiltIn(nil, "matches", SomeStringValue("db-primary", nil), SomeStringValue("^db-", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, true, result.Value())
+
+	result, err = CallBuiltIn(nil, "matches", SomeStringValue("web", nil), SomeStringValue("^db-", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, false, result.Value())
+
+	_, err = CallBuiltIn(nil, "matches", SomeStringValue("web", nil), SomeStringValue("[", nil))
+	assert.Error(t, err)
+}
+
+func TestCallBuiltIn_Join(t *testing.T) {
+	scope := newBuiltInTestScope(t)
+
+	links, err := CallBuiltIn(scope, "outgoing_links", SomeStringValue("web", nil))
+	assert.NoError(t, err)
+
+	result, err := CallBuiltIn(scope, "join", links)
+	assert.NoError(t, err)
+	assert.Equal(t, "web>cache, web>db", result.Value())
+
+	result, err = CallBuiltIn(scope, "join", SomeArrayValue([]Value{SomeStringValue("a", nil), SomeStringValue("b", nil)}, nil), SomeStringValue("/", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "a/b", result.Value())
+}
@@ -7,6 +7,8 @@ const (
 	Match = "match"
 	Utils = "utils"
 
+	Iterate = "iterate"
+
 	Assign = "assign"
 	Loop   = "loop"
 	Do     = "do"
@@ -43,3 +45,12 @@ const (
 	Or             = "or"
 	True           = "true"
 )
+
+// collections of the model as seen by scripts
+const (
+	TechnicalAssets    = "technical_assets"
+	CommunicationLinks = "communication_links"
+	DataAssets         = "data_assets"
+	TrustBoundaries    = "trust_boundaries"
+	SharedRuntimes     = "shared_runtimes"
+)
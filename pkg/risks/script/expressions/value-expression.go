@@ -395,7 +395,7 @@ func (what *ValueExpression) resolveMethodCall(scope *common.Scope, reString str
 	}
 
 	if common.IsBuiltIn(name) {
-		callValue, callError := common.CallBuiltIn(name, args...)
+		callValue, callError := common.CallBuiltIn(scope, name, args...)
 		if callError != nil {
 			return common.NilValue(), what.Literal(), fmt.Errorf("failed to call %q: %w", name, callError)
 		}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/risks/script/common"
 	"github.com/threagile/threagile/pkg/types"
 	"gopkg.in/yaml.v3"
 )
@@ -59,6 +60,34 @@ func (what *RiskRule) SupportedTags() []string {
 }
 
 func (what *RiskRule) GenerateRisks(parsedModel *types.Model) ([]*types.Risk, error) {
+	newScope, scopeError := what.newScope(parsedModel)
+	if scopeError != nil {
+		return nil, scopeError
+	}
+
+	newRisks, errorLiteral, riskError := what.script.GenerateRisks(newScope)
+	if riskError != nil {
+		return nil, what.scriptError("error generating risks", riskError, errorLiteral)
+	}
+
+	return newRisks, nil
+}
+
+func (what *RiskRule) ExplainRisk(parsedModel *types.Model, riskID string) ([]string, error) {
+	newScope, scopeError := what.newScope(parsedModel)
+	if scopeError != nil {
+		return nil, scopeError
+	}
+
+	trace, errorLiteral, explainError := what.script.ExplainRisk(newScope, riskID)
+	if explainError != nil {
+		return nil, what.scriptError("error explaining risk", explainError, errorLiteral)
+	}
+
+	return trace, nil
+}
+
+func (what *RiskRule) newScope(parsedModel *types.Model) (*common.Scope, error) {
 	if what.script == nil {
 		return nil, fmt.Errorf("no script found in risk rule")
 	}
@@ -73,19 +102,18 @@ func (what *RiskRule) GenerateRisks(parsedModel *types.Model) ([]*types.Risk, er
 		return nil, modelError
 	}
 
-	newRisks, errorLiteral, riskError := what.script.GenerateRisks(newScope)
-	if riskError != nil {
-		msg := make([]string, 0)
-		msg = append(msg, fmt.Sprintf("error generating risks: %v\n", riskError))
+	return newScope, nil
+}
 
-		if len(errorLiteral) > 0 {
-			msg = append(msg, fmt.Sprintf("in:\n%v\n", new(input.Strings).IndentPrintf(1, errorLiteral)))
-		}
+func (what *RiskRule) scriptError(text string, scriptError error, errorLiteral string) error {
+	msg := make([]string, 0)
+	msg = append(msg, fmt.Sprintf("%v: %v\n", text, scriptError))
 
-		return nil, fmt.Errorf("%v", strings.Join(msg, "\n"))
+	if len(errorLiteral) > 0 {
+		msg = append(msg, fmt.Sprintf("in:\n%v\n", new(input.Strings).IndentPrintf(1, errorLiteral)))
 	}
 
-	return newRisks, nil
+	return fmt.Errorf("%v", strings.Join(msg, "\n"))
 }
 
 func (what *RiskRule) Load(fileSystem fs.FS, path string, entry fs.DirEntry) error {
@@ -116,3 +144,16 @@ func (what *RiskRule) loadRiskRule(fileSystem fs.FS, filename string) error {
 
 	return nil
 }
+
+// RuleTestFileSuffixes are the endings of rule test files, which may be placed next to script risk rules
+var RuleTestFileSuffixes = []string{".test.yaml", ".test.yml"}
+
+func IsRuleTestFile(filename string) bool {
+	for _, suffix := range RuleTestFileSuffixes {
+		if strings.HasSuffix(strings.ToLower(filename), suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,6 +1,7 @@
 package script
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -179,6 +180,50 @@ func TestRiskRule_GenerateRisks_NilScript(t *testing.T) {
 	assert.Contains(t, riskErr.Error(), "no script found")
 }
 
+func TestRiskRule_ExplainRisk_MatchingRisk(t *testing.T) {
+	rule := new(RiskRule).Init()
+	_, err := rule.ParseFromData([]byte(minimalTestYAML))
+	assert.NoError(t, err)
+
+	model := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"ta1": {
+				Id:    "ta1",
+				Title: "Test Asset",
+			},
+			"ta2": {
+				Id:    "ta2",
+				Title: "Other Asset",
+			},
+		},
+	}
+
+	trace, traceErr := rule.ExplainRisk(model, "test-rule@ta2")
+	assert.NoError(t, traceErr)
+	assert.NotEmpty(t, trace)
+	assert.Contains(t, trace[0], "ta2")
+}
+
+func TestRiskRule_ExplainRisk_UnknownRisk(t *testing.T) {
+	rule := new(RiskRule).Init()
+	_, err := rule.ParseFromData([]byte(minimalTestYAML))
+	assert.NoError(t, err)
+
+	model := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"ta1": {
+				Id:         "ta1",
+				Title:      "Out of Scope Asset",
+				OutOfScope: true,
+			},
+		},
+	}
+
+	_, traceErr := rule.ExplainRisk(model, "test-rule@ta1")
+	assert.Error(t, traceErr)
+	assert.Contains(t, traceErr.Error(), "not generated")
+}
+
 func TestRiskRule_ParseFromData_InvalidYAML(t *testing.T) {
 	invalidYAML := []byte(`{invalid yaml: [`)
 
@@ -261,3 +306,152 @@ func TestRiskRule_ImplementsRiskRuleInterface(t *testing.T) {
 	// Verify RiskRule satisfies the types.RiskRule interface at compile time
 	var _ types.RiskRule = rule
 }
+
+const communicationLinkTestYAML = `
+id: link-rule
+title: Link Rule
+function: operations
+stride: information-disclosure
+cwe: 319
+
+risk:
+  iterate: communication_links
+  match:
+    parameter: link
+    do:
+      - if:
+          false: "{link.vpn}"
+          then:
+            return: true
+  data:
+    parameter: link
+    title: "<b>Link Rule</b> risk at <b>{link.title}</b>"
+`
+
+func TestRiskRule_GenerateRisks_CommunicationLinks(t *testing.T) {
+	rule := new(RiskRule).Init()
+	_, err := rule.ParseFromData([]byte(communicationLinkTestYAML))
+	assert.NoError(t, err)
+
+	model := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"ta1": {Id: "ta1", Title: "Source"},
+			"ta2": {Id: "ta2", Title: "Target"},
+		},
+		CommunicationLinks: map[string]*types.CommunicationLink{
+			"ta1>plain": {Id: "ta1>plain", SourceId: "ta1", TargetId: "ta2", Title: "Plain"},
+			"ta1>vpn":   {Id: "ta1>vpn", SourceId: "ta1", TargetId: "ta2", Title: "VPN", VPN: true},
+		},
+	}
+
+	risks, riskErr := rule.GenerateRisks(model)
+	assert.NoError(t, riskErr)
+	assert.Len(t, risks, 1)
+	assert.Equal(t, "link-rule@ta1>plain", risks[0].SyntheticId)
+	assert.Equal(t, "ta1>plain", risks[0].MostRelevantCommunicationLinkId)
+	assert.Equal(t, "ta1", risks[0].MostRelevantTechnicalAssetId)
+	assert.Equal(t, "<b>Link Rule</b> risk at <b>Plain</b>", risks[0].Title)
+	assert.Contains(t, risks[0].RiskExplanation[0], "communication link 'ta1>plain'")
+
+	trace, traceErr := rule.ExplainRisk(model, "link-rule@ta1>plain")
+	assert.NoError(t, traceErr)
+	assert.Contains(t, trace[0], "match condition for communication link 'ta1>plain'")
+}
+
+func TestRiskRule_GenerateRisks_MissingOptionalCollection(t *testing.T) {
+	rule := new(RiskRule).Init()
+	_, err := rule.ParseFromData([]byte(strings.Replace(communicationLinkTestYAML, "communication_links", "shared_runtimes", 1)))
+	assert.NoError(t, err)
+
+	model := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"ta1": {Id: "ta1", Title: "Asset"},
+		},
+	}
+
+	risks, riskErr := rule.GenerateRisks(model)
+	assert.NoError(t, riskErr)
+	assert.Empty(t, risks)
+}
+
+func TestRiskRule_GenerateRisks_TrustBoundaries(t *testing.T) {
+	rule := new(RiskRule).Init()
+	_, err := rule.ParseFromData([]byte(strings.Replace(communicationLinkTestYAML, "communication_links", "trust_boundaries", 1)))
+	assert.NoError(t, err)
+
+	model := &types.Model{
+		TrustBoundaries: map[string]*types.TrustBoundary{
+			"tb1": {Id: "tb1", Title: "Boundary"},
+		},
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"ta1": {Id: "ta1", Title: "Asset"},
+		},
+	}
+
+	risks, riskErr := rule.GenerateRisks(model)
+	assert.NoError(t, riskErr)
+	assert.Len(t, risks, 1)
+	assert.Equal(t, "link-rule@tb1", risks[0].SyntheticId)
+	assert.Equal(t, "tb1", risks[0].MostRelevantTrustBoundaryId)
+	assert.Empty(t, risks[0].MostRelevantTechnicalAssetId)
+}
+
+func TestRiskRule_ParseFromData_UnknownCollection(t *testing.T) {
+	rule := new(RiskRule).Init()
+	_, err := rule.ParseFromData([]byte(strings.Replace(communicationLinkTestYAML, "communication_links", "risks", 1)))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown collection \"risks\"")
+}
+
+const builtInTestYAML = `
+id: crossing-rule
+title: Crossing Rule
+function: operations
+stride: information-disclosure
+cwe: 319
+
+risk:
+  iterate: communication_links
+  match:
+    parameter: link
+    do:
+      - if:
+          true: "crosses_trust_boundary({link.source_id}, {link.target_id})"
+          then:
+            return: true
+  data:
+    parameter: link
+    title: "<b>Crossing</b> at <b>lower({link.title})</b>"
+`
+
+func TestRiskRule_GenerateRisks_BuiltIns(t *testing.T) {
+	rule := new(RiskRule).Init()
+	_, err := rule.ParseFromData([]byte(builtInTestYAML))
+	assert.NoError(t, err)
+
+	model := &types.Model{
+		TechnicalAssets: map[string]*types.TechnicalAsset{
+			"web": {Id: "web"},
+			"db":  {Id: "db"},
+			"app": {Id: "app"},
+		},
+		TrustBoundaries: map[string]*types.TrustBoundary{
+			"dmz":      {Id: "dmz", TechnicalAssetsInside: []string{"web"}},
+			"internal": {Id: "internal", TechnicalAssetsInside: []string{"db", "app"}},
+		},
+		CommunicationLinks: map[string]*types.CommunicationLink{
+			"web>db": {Id: "web>db", SourceId: "web", TargetId: "db", Title: "SQL"},
+			"app>db": {Id: "app>db", SourceId: "app", TargetId: "db", Title: "SQL"},
+		},
+	}
+
+	risks, riskErr := rule.GenerateRisks(model)
+	assert.NoError(t, riskErr)
+	assert.Len(t, risks, 1)
+	assert.Equal(t, "crossing-rule@web>db", risks[0].SyntheticId)
+	assert.Equal(t, "<b>Crossing</b> at <b>sql</b>", risks[0].Title)
+
+	trace, traceErr := rule.ExplainRisk(model, "crossing-rule@web>db")
+	assert.NoError(t, traceErr)
+	assert.Contains(t, strings.Join(trace, "\n"), "trust boundary of technical asset 'web' is dmz")
+}
@@ -12,6 +12,7 @@ import (
 )
 
 type Script struct {
+	iterate   *collection
 	id        map[string]any
 	match     common.Statement
 	data      map[string]any
@@ -128,6 +129,19 @@ func (what *Script) ParseScriptFromData(text []byte) (*Script, error) {
 func (what *Script) ParseScript(script map[string]any) (*Script, error) {
 	for key, value := range script {
 		switch strings.ToLower(key) {
+		case common.Iterate:
+			name, ok := value.(string)
+			if !ok {
+				return what, fmt.Errorf("failed to parse %q: unexpected collection type %T", key, value)
+			}
+
+			iterate, collectionError := getCollection(name)
+			if collectionError != nil {
+				return what, fmt.Errorf("failed to parse %q: %w", key, collectionError)
+			}
+
+			what.iterate = iterate
+
 		case common.ID:
 			stringItem, ok := value.(map[string]any)
 			if !ok {
@@ -166,85 +180,131 @@ func (what *Script) ParseScript(script map[string]any) (*Script, error) {
 	return what, nil
 }
 
-func (what *Script) GetTechnicalAssetsByRiskID(scope *common.Scope, riskID string) ([]any, error) {
-	value, valueOk := what.getItem(scope.Model, "technical_assets")
-	if !valueOk {
-		return nil, fmt.Errorf("no technical assets in scope")
-	}
+func (what *Script) GenerateRisks(scope *common.Scope) ([]*types.Risk, string, error) {
+	risks := make([]*types.Risk, 0)
+	errorLiteral, riskError := what.forEachRisk(scope, func(risk *types.Risk, _ string, _ *common.Event) bool {
+		risks = append(risks, risk)
+		return true
+	})
 
-	techAssets, techAssetsOk := value.(map[string]common.Value)
-	if !techAssetsOk {
-		return nil, fmt.Errorf("unexpected format of technical assets %T", techAssets)
+	if riskError != nil {
+		return nil, errorLiteral, riskError
 	}
 
-	matchingTechAssets := make([]any, 0)
-	for techAssetName, techAsset := range techAssets {
-		_ = techAssetName
+	return risks, "", nil
+}
 
-		isMatch, _, matchError := what.matchRisk(scope, techAsset)
-		if matchError != nil {
-			return nil, matchError
+// ExplainRisk re-evaluates the script and returns the full event history of the match condition
+// for the risk with the given synthetic id
+func (what *Script) ExplainRisk(scope *common.Scope, riskID string) ([]string, string, error) {
+	var trace []string
+	errorLiteral, riskError := what.forEachRisk(scope, func(risk *types.Risk, itemID string, isMatchEvent *common.Event) bool {
+		if !strings.EqualFold(risk.SyntheticId, riskID) {
+			return true
 		}
 
-		if !isMatch.BoolValue() {
-			continue
+		trace = []string{fmt.Sprintf("match condition for %v '%v' is true because", what.getCollection().singular, itemID)}
+		if isMatchEvent != nil {
+			for _, event := range isMatchEvent.Events {
+				trace = append(trace, event.Indented(1)...)
+			}
 		}
 
-		matchingTechAssets = append(matchingTechAssets, techAsset)
-	}
+		return false
+	})
 
-	return matchingTechAssets, nil
-}
+	if riskError != nil {
+		return nil, errorLiteral, riskError
+	}
 
-func (what *Script) GenerateRisks(scope *common.Scope) ([]*types.Risk, string, error) {
-	value, valueOk := what.getItem(scope.Model, "technical_assets")
-	if !valueOk {
-		return nil, "", fmt.Errorf("no technical assets in scope")
+	if trace == nil {
+		return nil, "", fmt.Errorf("risk %q is not generated by this script", riskID)
 	}
 
-	techAssets, techAssetsOk := value.(map[string]any)
-	if !techAssetsOk {
-		return nil, "", fmt.Errorf("unexpected format of technical assets %T", techAssets)
+	return trace, "", nil
+}
+
+func (what *Script) forEachRisk(scope *common.Scope, handle func(risk *types.Risk, itemID string, isMatchEvent *common.Event) bool) (string, error) {
+	items, itemsError := what.getItems(scope.Model)
+	if itemsError != nil {
+		return "", itemsError
 	}
 
-	risks := make([]*types.Risk, 0)
-	for techAssetName, techAsset := range techAssets {
-		techAssetValue := common.SomeValue(techAsset, common.NewEvent(common.NewValueProperty(techAsset), common.NewPath(fmt.Sprintf("technical asset '%v'", techAssetName))))
-		isMatch, errorMatchLiteral, matchError := what.matchRisk(scope, techAssetValue)
+	for itemID, item := range items {
+		itemValue := what.newItemValue(itemID, item)
+		isMatch, errorMatchLiteral, matchError := what.matchRisk(scope, itemValue)
 		if matchError != nil {
-			return nil, errorMatchLiteral, matchError
+			return errorMatchLiteral, matchError
 		}
 
 		if !isMatch.BoolValue() {
 			continue
 		}
 
-		risk, errorRiskLiteral, riskError := what.generateRisk(scope, techAssetName, techAssetValue, isMatch.Event())
+		risk, errorRiskLiteral, riskError := what.generateRisk(scope, itemID, itemValue, isMatch.Event())
 		if riskError != nil {
-			return nil, errorRiskLiteral, riskError
+			return errorRiskLiteral, riskError
 		}
 
 		if risk == nil {
 			continue
 		}
 
-		riskId, errorGetIDLiteral, errorId := what.getRiskID(scope, techAssetValue, risk)
+		what.getCollection().complete(risk, itemID, item)
+
+		riskId, errorGetIDLiteral, errorId := what.getRiskID(scope, itemValue, risk)
 		if errorId != nil {
-			return nil, errorGetIDLiteral, errorId
+			return errorGetIDLiteral, errorId
 		}
 
 		risk.SyntheticId = riskId
 		if len(risk.SyntheticId) == 0 {
-			risk.SyntheticId = risk.CategoryId + "@" + risk.MostRelevantTechnicalAssetId
+			risk.SyntheticId = what.getCollection().defaultSyntheticID(risk)
 		}
 
-		risks = append(risks, risk)
+		if !handle(risk, itemID, isMatch.Event()) {
+			break
+		}
 	}
 
-	return risks, "", nil
+	return "", nil
+}
+
+// getCollection returns the collection the script iterates over, which is the technical assets if not stated otherwise
+func (what *Script) getCollection() *collection {
+	if what.iterate == nil {
+		return collections[TechnicalAssets]
+	}
+
+	return what.iterate
+}
+
+// getItems returns the items of the iterated collection in the model, keyed by their id; a missing collection is an
+// error only for the technical assets, as a model may well come without shared runtimes for instance
+func (what *Script) getItems(model any) (map[string]any, error) {
+	iterate := what.getCollection()
+	value, valueOk := what.getItem(model, iterate.name)
+	if !valueOk {
+		if iterate.required {
+			return nil, fmt.Errorf("no %v in scope", iterate.plural)
+		}
+
+		return make(map[string]any), nil
+	}
+
+	items, itemsOk := value.(map[string]any)
+	if !itemsOk {
+		return nil, fmt.Errorf("unexpected format of %v %T", iterate.plural, value)
+	}
+
+	return items, nil
+}
+
+func (what *Script) newItemValue(itemID string, item any) common.Value {
+	return common.SomeValue(item, common.NewEvent(common.NewValueProperty(item), common.NewPath(fmt.Sprintf("%v '%v'", what.getCollection().singular, itemID))))
 }
 
-func (what *Script) matchRisk(outerScope *common.Scope, techAsset common.Value) (*common.BoolValue, string, error) {
+func (what *Script) matchRisk(outerScope *common.Scope, item common.Value) (*common.BoolValue, string, error) {
 	if what.match == nil {
 		return common.EmptyBoolValue(), "", nil
 	}
@@ -254,7 +314,7 @@ func (what *Script) matchRisk(outerScope *common.Scope, techAsset common.Value)
 		return common.EmptyBoolValue(), "", fmt.Errorf("failed to clone scope: %w", cloneError)
 	}
 
-	scope.Args = append(scope.Args, techAsset)
+	scope.Args = append(scope.Args, item)
 
 	errorLiteral, runError := what.match.Run(scope)
 	if runError != nil {
@@ -273,7 +333,7 @@ func (what *Script) matchRisk(outerScope *common.Scope, techAsset common.Value)
 	return common.EmptyBoolValue(), "", nil
 }
 
-func (what *Script) generateRisk(outerScope *common.Scope, techAssetName string, techAsset common.Value, isMatchEvent *common.Event) (*types.Risk, string, error) {
+func (what *Script) generateRisk(outerScope *common.Scope, itemID string, item common.Value, isMatchEvent *common.Event) (*types.Risk, string, error) {
 	if what.data == nil {
 		return nil, "", fmt.Errorf("no data template")
 	}
@@ -283,7 +343,7 @@ func (what *Script) generateRisk(outerScope *common.Scope, techAssetName string,
 		return nil, "", fmt.Errorf("failed to clone scope: %w", cloneError)
 	}
 
-	scope.Args = append(scope.Args, techAsset)
+	scope.Args = append(scope.Args, item)
 
 	parameter, ok := what.data[common.Parameter]
 	if ok {
@@ -367,7 +427,7 @@ func (what *Script) generateRisk(outerScope *common.Scope, techAssetName string,
 	risk.CategoryId, _ = what.getItemString(scope.Risk, "id")
 
 	riskExplanation := make([]string, 0)
-	text := fmt.Sprintf("Risk '%v' has been flagged for technical asset '%v'", scope.Category.Title, techAssetName)
+	text := fmt.Sprintf("Risk '%v' has been flagged for %v '%v'", scope.Category.Title, what.getCollection().singular, itemID)
 
 	var explanation []string
 	if isMatchEvent != nil {
@@ -401,9 +461,9 @@ func (what *Script) Explain(history []*common.Event) []string {
 	return text
 }
 
-func (what *Script) getRiskID(outerScope *common.Scope, techAsset common.Value, risk *types.Risk) (string, string, error) {
+func (what *Script) getRiskID(outerScope *common.Scope, item common.Value, risk *types.Risk) (string, string, error) {
 	if len(what.id) == 0 {
-		return "", "", fmt.Errorf("no ID expression")
+		return "", "", nil // the caller falls back to the default synthetic id
 	}
 
 	scope, cloneError := outerScope.Clone()
@@ -411,7 +471,7 @@ func (what *Script) getRiskID(outerScope *common.Scope, techAsset common.Value,
 		return "", "", fmt.Errorf("failed to clone scope: %w", cloneError)
 	}
 
-	scope.Args = append(scope.Args, techAsset)
+	scope.Args = append(scope.Args, item)
 
 	parameter, parameterOk := what.id[common.Parameter]
 	if parameterOk {
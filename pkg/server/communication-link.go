@@ -0,0 +1,315 @@
+package server
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+type payloadCommunicationLink struct {
+	Title                  string   `yaml:"title" json:"title"`
+	Target                 string   `yaml:"target" json:"target"`
+	Description            string   `yaml:"description" json:"description"`
+	Protocol               string   `yaml:"protocol" json:"protocol"`
+	Authentication         string   `yaml:"authentication" json:"authentication"`
+	Authorization          string   `yaml:"authorization" json:"authorization"`
+	Tags                   []string `yaml:"tags" json:"tags"`
+	VPN                    bool     `yaml:"vpn" json:"vpn"`
+	IpFiltered             bool     `yaml:"ip_filtered" json:"ip_filtered"`
+	Readonly               bool     `yaml:"readonly" json:"readonly"`
+	Usage                  string   `yaml:"usage" json:"usage"`
+	DataAssetsSent         []string `yaml:"data_assets_sent" json:"data_assets_sent"`
+	DataAssetsReceived     []string `yaml:"data_assets_received" json:"data_assets_received"`
+	DiagramTweakWeight     int      `yaml:"diagram_tweak_weight" json:"diagram_tweak_weight"`
+	DiagramTweakConstraint bool     `yaml:"diagram_tweak_constraint" json:"diagram_tweak_constraint"`
+}
+
+func (s *server) getCommunicationLinks(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		_, techAsset, found := findTechnicalAsset(modelInput, ginContext.Param("technical-asset-id"))
+		if !found {
+			ginContext.JSON(http.StatusNotFound, gin.H{
+				"error": "technical asset not found",
+			})
+			return
+		}
+		ginContext.JSON(http.StatusOK, techAsset.CommunicationLinks)
+	}
+}
+
+func (s *server) getCommunicationLink(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		_, techAsset, found := findTechnicalAsset(modelInput, ginContext.Param("technical-asset-id"))
+		if !found {
+			ginContext.JSON(http.StatusNotFound, gin.H{
+				"error": "technical asset not found",
+			})
+			return
+		}
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title, commLink := range techAsset.CommunicationLinks {
+			if types.MakeID(title) == ginContext.Param("communication-link-id") {
+				ginContext.JSON(http.StatusOK, gin.H{
+					title: commLink,
+				})
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "communication link not found",
+		})
+	}
+}
+
+func (s *server) createNewCommunicationLink(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		techAssetTitle, techAsset, found := findTechnicalAsset(modelInput, ginContext.Param("technical-asset-id"))
+		if !found {
+			ginContext.JSON(http.StatusNotFound, gin.H{
+				"error": "technical asset not found",
+			})
+			return
+		}
+		payload := payloadCommunicationLink{}
+		err := ginContext.BindJSON(&payload)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": "unable to parse request payload",
+			})
+			return
+		}
+		// the id of a communication link is derived from its title, so this is also the uniqueness check of the id
+		for title := range techAsset.CommunicationLinks {
+			if types.MakeID(title) == types.MakeID(payload.Title) {
+				ginContext.JSON(http.StatusConflict, gin.H{
+					"error": "communication link with this title already exists",
+				})
+				return
+			}
+		}
+		commLinkInput, ok := populateCommunicationLink(ginContext, modelInput, payload)
+		if !ok {
+			return
+		}
+		if techAsset.CommunicationLinks == nil {
+			techAsset.CommunicationLinks = make(map[string]input.CommunicationLink)
+		}
+		techAsset.CommunicationLinks[payload.Title] = commLinkInput
+		modelInput.TechnicalAssets[techAssetTitle] = techAsset
+		ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Communication Link Creation")
+		if ok {
+			ginContext.JSON(http.StatusOK, gin.H{
+				"message": "communication link created",
+				"id":      types.MakeID(payload.Title),
+			})
+		}
+	}
+}
+
+func (s *server) setCommunicationLink(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		_, techAsset, found := findTechnicalAsset(modelInput, ginContext.Param("technical-asset-id"))
+		if !found {
+			ginContext.JSON(http.StatusNotFound, gin.H{
+				"error": "technical asset not found",
+			})
+			return
+		}
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title := range techAsset.CommunicationLinks {
+			if types.MakeID(title) == ginContext.Param("communication-link-id") {
+				payload := payloadCommunicationLink{}
+				err := ginContext.BindJSON(&payload)
+				if err != nil {
+					log.Println(err)
+					ginContext.JSON(http.StatusBadRequest, gin.H{
+						"error": "unable to parse request payload",
+					})
+					return
+				}
+				for otherTitle := range techAsset.CommunicationLinks {
+					if otherTitle != title && types.MakeID(otherTitle) == types.MakeID(payload.Title) {
+						ginContext.JSON(http.StatusConflict, gin.H{
+							"error": "communication link with this title already exists",
+						})
+						return
+					}
+				}
+				commLinkInput, ok := populateCommunicationLink(ginContext, modelInput, payload)
+				if !ok {
+					return
+				}
+				// in order to also update the title, remove the link from the map and re-insert it (with new key)
+				delete(techAsset.CommunicationLinks, title)
+				techAsset.CommunicationLinks[payload.Title] = commLinkInput
+				oldID, newID := techAsset.ID+">"+types.MakeID(title), techAsset.ID+">"+types.MakeID(payload.Title)
+				idChanged := oldID != newID
+				if idChanged { // ID-CHANGE-PROPAGATION
+					for _, individualRiskCat := range modelInput.CustomRiskCategories {
+						for individualRiskInstanceTitle, individualRiskInstance := range individualRiskCat.RisksIdentified {
+							if individualRiskInstance.MostRelevantCommunicationLink == oldID {
+								individualRiskInstance.MostRelevantCommunicationLink = newID
+								individualRiskCat.RisksIdentified[individualRiskInstanceTitle] = individualRiskInstance
+							}
+						}
+					}
+				}
+				ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Communication Link Update")
+				if ok {
+					ginContext.JSON(http.StatusOK, gin.H{
+						"message":    "communication link updated",
+						"id":         types.MakeID(payload.Title),
+						"id_changed": idChanged, // in order to signal to clients, that other model parts might've received updates as well and should be reloaded
+					})
+				}
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "communication link not found",
+		})
+	}
+}
+
+func (s *server) deleteCommunicationLink(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		_, techAsset, found := findTechnicalAsset(modelInput, ginContext.Param("technical-asset-id"))
+		if !found {
+			ginContext.JSON(http.StatusNotFound, gin.H{
+				"error": "technical asset not found",
+			})
+			return
+		}
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title := range techAsset.CommunicationLinks {
+			if types.MakeID(title) == ginContext.Param("communication-link-id") {
+				// remove it itself
+				delete(techAsset.CommunicationLinks, title)
+				// and all usages of it in individual risks
+				referencesDeleted := false
+				commLinkID := techAsset.ID + ">" + types.MakeID(title)
+				for _, individualRiskCat := range modelInput.CustomRiskCategories {
+					for individualRiskInstanceTitle, individualRiskInstance := range individualRiskCat.RisksIdentified {
+						if individualRiskInstance.MostRelevantCommunicationLink == commLinkID { // apply the removal
+							referencesDeleted = true
+							individualRiskInstance.MostRelevantCommunicationLink = ""
+							individualRiskCat.RisksIdentified[individualRiskInstanceTitle] = individualRiskInstance
+						}
+					}
+				}
+				ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Communication Link Deletion")
+				if ok {
+					ginContext.JSON(http.StatusOK, gin.H{
+						"message":            "communication link deleted",
+						"id":                 types.MakeID(title),
+						"references_deleted": referencesDeleted, // in order to signal to clients, that other model parts might've been deleted as well
+					})
+				}
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "communication link not found",
+		})
+	}
+}
+
+func populateCommunicationLink(ginContext *gin.Context, modelInput input.Model, payload payloadCommunicationLink) (commLinkInput input.CommunicationLink, ok bool) {
+	protocol, err := types.ParseProtocol(payload.Protocol)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return commLinkInput, false
+	}
+	authentication, err := types.ParseAuthentication(payload.Authentication)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return commLinkInput, false
+	}
+	authorization, err := types.ParseAuthorization(payload.Authorization)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return commLinkInput, false
+	}
+	usage, err := types.ParseUsage(payload.Usage)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return commLinkInput, false
+	}
+	if !checkTechnicalAssetsExisting(modelInput, []string{payload.Target}) {
+		ginContext.JSON(http.StatusBadRequest, gin.H{
+			"error": "referenced target technical asset does not exist",
+		})
+		return commLinkInput, false
+	}
+	if !checkDataAssetsExisting(modelInput, payload.DataAssetsSent) || !checkDataAssetsExisting(modelInput, payload.DataAssetsReceived) {
+		ginContext.JSON(http.StatusBadRequest, gin.H{
+			"error": "referenced data asset does not exist",
+		})
+		return commLinkInput, false
+	}
+	commLinkInput = input.CommunicationLink{
+		Target:                 payload.Target,
+		Description:            payload.Description,
+		Protocol:               protocol.String(),
+		Authentication:         authentication.String(),
+		Authorization:          authorization.String(),
+		Tags:                   lowerCaseAndTrim(payload.Tags),
+		VPN:                    payload.VPN,
+		IpFiltered:             payload.IpFiltered,
+		Readonly:               payload.Readonly,
+		Usage:                  usage.String(),
+		DataAssetsSent:         payload.DataAssetsSent,
+		DataAssetsReceived:     payload.DataAssetsReceived,
+		DiagramTweakWeight:     payload.DiagramTweakWeight,
+		DiagramTweakConstraint: payload.DiagramTweakConstraint,
+	}
+	return commLinkInput, true
+}
+
+func findTechnicalAsset(modelInput input.Model, techAssetID string) (title string, techAsset input.TechnicalAsset, found bool) {
+	for title, techAsset := range modelInput.TechnicalAssets {
+		if techAsset.ID == techAssetID {
+			return title, techAsset, true
+		}
+	}
+	return "", input.TechnicalAsset{}, false
+}
@@ -18,7 +18,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/threagile/threagile/pkg/input"
 	"github.com/threagile/threagile/pkg/model"
-	"github.com/threagile/threagile/pkg/risks"
 )
 
 func (s *server) analyze(ginContext *gin.Context) {
@@ -26,12 +25,46 @@ func (s *server) analyze(ginContext *gin.Context) {
 }
 
 func (s *server) check(ginContext *gin.Context) {
-	_, ok := s.execute(ginContext, true)
-	if ok {
-		ginContext.JSON(http.StatusOK, gin.H{
-			"message": "model is ok",
+	tmpInputDir, err := os.MkdirTemp(s.config.GetTempFolder(), "threagile-input-")
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return
+	}
+	defer func() { _ = os.RemoveAll(tmpInputDir) }()
+
+	yamlFile, filenameUploaded, ok := s.receiveModelFile(ginContext, tmpInputDir)
+	if !ok {
+		return
+	}
+
+	result := model.ValidateModelFile(yamlFile, s.config)
+	for _, problem := range result.Problems {
+		if problem.Position == nil {
+			continue
+		}
+
+		// report positions relative to the upload instead of the temp folder
+		if problem.Position.File == yamlFile && strings.ToLower(filepath.Ext(filenameUploaded)) != ".zip" {
+			problem.Position.File = filenameUploaded
+		} else if relativeFile, relativeError := filepath.Rel(tmpInputDir, problem.Position.File); relativeError == nil {
+			problem.Position.File = filepath.ToSlash(relativeFile)
+		}
+	}
+
+	if result.HasErrors() {
+		s.errorCount++
+		ginContext.JSON(http.StatusBadRequest, gin.H{
+			"error":    fmt.Sprintf("model has %d error(s)", result.Count(model.ValidationError)),
+			"problems": result.Problems,
 		})
+		return
 	}
+
+	s.successCount++
+	ginContext.JSON(http.StatusOK, gin.H{
+		"message":  "model is ok",
+		"problems": result.Problems,
+	})
 }
 
 func (s *server) execute(ginContext *gin.Context, dryRun bool) (yamlContent []byte, ok bool) {
@@ -54,23 +87,6 @@ func (s *server) execute(ginContext *gin.Context, dryRun bool) (yamlContent []by
 		return yamlContent, false
 	}
 
-	fileUploaded, header, err := ginContext.Request.FormFile("file")
-	if err != nil {
-		handleErrorInServiceCall(err, ginContext)
-		return yamlContent, false
-	}
-
-	if header.Size > 50000000 {
-		msg := "maximum model upload file size exceeded (denial-of-service protection)"
-		log.Println(msg)
-		ginContext.JSON(http.StatusRequestEntityTooLarge, gin.H{
-			"error": msg,
-		})
-		return yamlContent, false
-	}
-
-	filenameUploaded := strings.TrimSpace(header.Filename)
-
 	tmpInputDir, err := os.MkdirTemp(s.config.GetTempFolder(), "threagile-input-")
 	if err != nil {
 		handleErrorInServiceCall(err, ginContext)
@@ -78,42 +94,10 @@ func (s *server) execute(ginContext *gin.Context, dryRun bool) (yamlContent []by
 	}
 	defer func() { _ = os.RemoveAll(tmpInputDir) }()
 
-	tmpModelFile, err := os.CreateTemp(tmpInputDir, "threagile-model-*")
-	if err != nil {
-		handleErrorInServiceCall(err, ginContext)
+	yamlFile, _, ok := s.receiveModelFile(ginContext, tmpInputDir)
+	if !ok {
 		return yamlContent, false
 	}
-	defer func() { _ = os.Remove(tmpModelFile.Name()) }()
-	_, err = io.Copy(tmpModelFile, fileUploaded)
-	if err != nil {
-		handleErrorInServiceCall(err, ginContext)
-		return yamlContent, false
-	}
-
-	yamlFile := tmpModelFile.Name()
-
-	if strings.ToLower(filepath.Ext(filenameUploaded)) == ".zip" {
-		// unzip first (including the resources like images etc.)
-		if s.config.GetVerbose() {
-			fmt.Println("Decompressing uploaded archive")
-		}
-		filenamesUnzipped, err := unzip(tmpModelFile.Name(), tmpInputDir)
-		if err != nil {
-			handleErrorInServiceCall(err, ginContext)
-			return yamlContent, false
-		}
-		found := false
-		for _, name := range filenamesUnzipped {
-			if strings.ToLower(filepath.Ext(name)) == ".yaml" {
-				yamlFile = name
-				found = true
-				break
-			}
-		}
-		if !found {
-			panic(fmt.Errorf("no yaml file found in uploaded archive"))
-		}
-	}
 
 	tmpOutputDir, err := os.MkdirTemp(s.config.GetTempFolder(), "threagile-output-")
 	if err != nil {
@@ -176,6 +160,66 @@ func (s *server) execute(ginContext *gin.Context, dryRun bool) (yamlContent []by
 	return yamlContent, true
 }
 
+// receiveModelFile stores the uploaded model (or zip archive with the model and its resources) in tmpInputDir
+func (s *server) receiveModelFile(ginContext *gin.Context, tmpInputDir string) (yamlFile string, filenameUploaded string, ok bool) {
+	fileUploaded, header, err := ginContext.Request.FormFile("file")
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return yamlFile, filenameUploaded, false
+	}
+
+	if header.Size > 50000000 {
+		msg := "maximum model upload file size exceeded (denial-of-service protection)"
+		log.Println(msg)
+		ginContext.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": msg,
+		})
+		return yamlFile, filenameUploaded, false
+	}
+
+	filenameUploaded = strings.TrimSpace(header.Filename)
+
+	tmpModelFile, err := os.CreateTemp(tmpInputDir, "threagile-model-*")
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return yamlFile, filenameUploaded, false
+	}
+	_, err = io.Copy(tmpModelFile, fileUploaded)
+	_ = tmpModelFile.Close()
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return yamlFile, filenameUploaded, false
+	}
+
+	yamlFile = tmpModelFile.Name()
+
+	if strings.ToLower(filepath.Ext(filenameUploaded)) == ".zip" {
+		// unzip first (including the resources like images etc.)
+		if s.config.GetVerbose() {
+			fmt.Println("Decompressing uploaded archive")
+		}
+		filenamesUnzipped, err := unzip(tmpModelFile.Name(), tmpInputDir)
+		if err != nil {
+			handleErrorInServiceCall(err, ginContext)
+			return yamlFile, filenameUploaded, false
+		}
+		found := false
+		for _, name := range filenamesUnzipped {
+			if strings.ToLower(filepath.Ext(name)) == ".yaml" {
+				yamlFile = name
+				found = true
+				break
+			}
+		}
+		if !found {
+			handleErrorInServiceCall(fmt.Errorf("no yaml file found in uploaded archive"), ginContext)
+			return yamlFile, filenameUploaded, false
+		}
+	}
+
+	return yamlFile, filenameUploaded, true
+}
+
 // ultimately to avoid any in-process memory and/or data leaks by the used third party libs like PDF generation: exec and quit
 func (s *server) doItViaRuntimeCall(modelFile string, outputDir string,
 	generateDataFlowDiagram, generateDataAssetDiagram, generateReportPdf, generateRisksExcel, generateTagsExcel, generateRisksJSON, generateTechnicalAssetsJSON, generateStatsJSON bool,
@@ -186,10 +230,14 @@ func (s *server) doItViaRuntimeCall(modelFile string, outputDir string,
 		"--model", modelFile,
 		"--output", outputDir,
 		"--execute-model-macro", s.config.GetExecuteModelMacro(),
-		"--custom-risk-rules-plugin", strings.Join(s.config.GetRiskRulePlugins(), ","),
 		"--skip-risk-rules", strings.Join(s.config.GetSkipRiskRules(), ","),
 		"--diagram-dpi", strconv.Itoa(dpi),
 	}
+	if len(s.customRiskRules) > 0 { // the custom risk rules stay loaded here, persistent plugins are not started per call
+		customRisksFile := s.writeCustomRisks(modelFile)
+		defer func() { _ = os.Remove(customRisksFile) }()
+		args = append(args, "--custom-risks-file", customRisksFile)
+	}
 	if s.config.GetVerbose() {
 		args = append(args, "--verbose")
 	}
@@ -238,6 +286,26 @@ func (s *server) doItViaRuntimeCall(modelFile string, outputDir string,
 	}
 }
 
+// writeCustomRisks generates the risks of the custom risk rules for the model and writes them to a temp file
+func (s *server) writeCustomRisks(modelFile string) string {
+	progressReporter := DefaultProgressReporter{
+		Verbose:       s.config.GetVerbose(),
+		SuppressError: true,
+	}
+	customRisksFile, err := os.CreateTemp(s.config.GetTempFolder(), "threagile-custom-risks-*.yaml")
+	if err != nil {
+		panic(err)
+	}
+	err = model.GenerateCustomRisks(modelFile, customRisksFile, s.config, s.builtinRiskRules, s.customRiskRules, progressReporter)
+	_ = customRisksFile.Close()
+	if err != nil {
+		_ = os.Remove(customRisksFile.Name())
+		panic(err)
+	}
+
+	return customRisksFile.Name()
+}
+
 func (s *server) editModelAnalyze(ginContext *gin.Context) {
 	defer func() {
 		var err error
@@ -264,10 +332,7 @@ func (s *server) editModelAnalyze(ginContext *gin.Context) {
 		Verbose:       s.config.GetVerbose(),
 		SuppressError: true,
 	}
-	customRiskRules := model.LoadCustomRiskRules(s.config.GetPluginFolder(), s.config.GetRiskRulePlugins(), progressReporter)
-	builtinRiskRules := risks.GetBuiltInRiskRules()
-
-	result, err := model.AnalyzeModel(&modelInput, s.config, builtinRiskRules, customRiskRules, progressReporter)
+	result, err := model.AnalyzeModel(&modelInput, s.config, s.builtinRiskRules, s.customRiskRules, progressReporter)
 	if err != nil {
 		ginContext.JSON(http.StatusBadRequest, gin.H{
 			"error": "Unable to analyze model: " + err.Error(),
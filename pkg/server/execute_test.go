@@ -0,0 +1,73 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// testPluginRule stands in for a risk rule of a plugin, it generates a risk per technical asset
+type testPluginRule struct{}
+
+func (what *testPluginRule) Category() *types.RiskCategory {
+	return &types.RiskCategory{ID: "plugin-rule", Title: "Plugin Rule", STRIDE: types.Tampering}
+}
+
+func (what *testPluginRule) SupportedTags() []string {
+	return []string{"plugin-tag"}
+}
+
+func (what *testPluginRule) GenerateRisks(parsedModel *types.Model) ([]*types.Risk, error) {
+	risks := make([]*types.Risk, 0)
+	for id := range parsedModel.TechnicalAssets {
+		risks = append(risks, &types.Risk{
+			CategoryId:                   "plugin-rule",
+			Severity:                     types.HighSeverity,
+			SyntheticId:                  "plugin-rule@" + id,
+			MostRelevantTechnicalAssetId: id,
+		})
+	}
+
+	return risks, nil
+}
+
+// subProcessConfig is the config of the sub-process generating the reports, which only gets the custom risks file
+type subProcessConfig struct {
+	customRisksFile string
+}
+
+func (what *subProcessConfig) GetPluginFolder() string                { return "" }
+func (what *subProcessConfig) GetRiskRulePlugins() []string           { return nil }
+func (what *subProcessConfig) GetPersistentRiskRulePlugins() []string { return nil }
+func (what *subProcessConfig) GetPluginTimeout() int                  { return 0 }
+func (what *subProcessConfig) GetCustomRisksFile() string             { return what.customRisksFile }
+
+func TestWriteCustomRisks(t *testing.T) {
+	s := newTestServer(t)
+	s.customRiskRules = types.RiskRules{"plugin-rule": new(testPluginRule)}
+	modelFile := filepath.Join(t.TempDir(), "threagile.yaml")
+	require.NoError(t, os.WriteFile(modelFile, []byte(riskTrackingTestModel), 0600))
+
+	customRisksFile := s.writeCustomRisks(modelFile)
+	defer func() { _ = os.Remove(customRisksFile) }()
+
+	// the sub-process gets the file passed as --custom-risks-file and loads it like any custom risk rule
+	rules := model.LoadCustomRiskRules(&subProcessConfig{customRisksFile: customRisksFile}, DefaultProgressReporter{SuppressError: true})
+	require.Contains(t, rules, "plugin-rule")
+	rule := rules["plugin-rule"]
+	assert.Equal(t, "Plugin Rule", rule.Category().Title)
+	assert.Equal(t, types.Tampering, rule.Category().STRIDE)
+	assert.Equal(t, []string{"plugin-tag"}, rule.SupportedTags())
+
+	risks, err := rule.GenerateRisks(&types.Model{})
+	require.NoError(t, err)
+	require.Len(t, risks, 1)
+	assert.Equal(t, "plugin-rule@web", risks[0].SyntheticId)
+	assert.Equal(t, types.HighSeverity, risks[0].Severity)
+}
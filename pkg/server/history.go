@@ -0,0 +1,203 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/model"
+)
+
+const (
+	historyFolderName      = "history"
+	historyFileExtension   = ".backup"
+	historyTimestampFormat = "2006-01-02 15:04:05"
+)
+
+type historyEntry struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason"`
+}
+
+// getHistory lists the backups of a model, newest first. Each backup holds the model as it was before the change given
+// as reason.
+func (s *server) getHistory(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelID, ok := s.checkModelID(ginContext, ginContext.Param("model-id"), folderNameOfKey)
+	if !ok {
+		return
+	}
+	_, _, ok = s.readModel(ginContext, modelID, key, folderNameOfKey) // to ensure the key matches
+	if ok {
+		entries, err := s.listHistory(folderNameOfKey, modelID)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusInternalServerError, gin.H{
+				"error": "unable to list model history",
+			})
+			return
+		}
+		ginContext.JSON(http.StatusOK, entries)
+	}
+}
+
+func (s *server) getHistoryModel(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	_, yamlText, ok := s.readHistoryModel(ginContext, ginContext.Param("history-id"), key, folderNameOfKey)
+	if ok {
+		ginContext.Data(http.StatusOK, gin.MIMEYAML, []byte(yamlText))
+	}
+}
+
+// diffHistoryModel compares a backup of a model with another backup (given as "compare-to" query parameter) or the
+// current model
+func (s *server) diffHistoryModel(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	baselineInput, _, ok := s.readHistoryModel(ginContext, ginContext.Param("history-id"), key, folderNameOfKey)
+	if !ok {
+		return
+	}
+	var currentInput input.Model
+	if compareTo := ginContext.Query("compare-to"); len(compareTo) > 0 {
+		currentInput, _, ok = s.readHistoryModel(ginContext, compareTo, key, folderNameOfKey)
+	} else {
+		currentInput, _, ok = s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	}
+	if !ok {
+		return
+	}
+	progressReporter := DefaultProgressReporter{
+		Verbose:       s.config.GetVerbose(),
+		SuppressError: true,
+	}
+	baseline, err := model.AnalyzeModel(&baselineInput, s.config, s.builtinRiskRules, s.customRiskRules, progressReporter)
+	if err != nil {
+		handleErrorInServiceCall(fmt.Errorf("unable to analyze model of history entry: %w", err), ginContext)
+		return
+	}
+	current, err := model.AnalyzeModel(&currentInput, s.config, s.builtinRiskRules, s.customRiskRules, progressReporter)
+	if err != nil {
+		handleErrorInServiceCall(fmt.Errorf("unable to analyze model to compare to: %w", err), ginContext)
+		return
+	}
+	ginContext.JSON(http.StatusOK, model.DiffModels(baseline.ParsedModel, current.ParsedModel))
+}
+
+// restoreHistoryModel replaces the current model by one of its backups; the current model is backed up before, so
+// a restore can be undone as well
+func (s *server) restoreHistoryModel(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	_, yamlText, ok := s.readHistoryModel(ginContext, ginContext.Param("history-id"), key, folderNameOfKey)
+	if !ok {
+		return
+	}
+	modelID, ok := s.checkModelID(ginContext, ginContext.Param("model-id"), folderNameOfKey)
+	if !ok {
+		return
+	}
+	entry, _ := parseHistoryEntry(ginContext.Param("history-id"))
+	ok = s.writeModelYAML(ginContext, yamlText, key, folderNameOfKey, modelID, "Restore of "+entry.Timestamp.Format(historyTimestampFormat), false)
+	if ok {
+		ginContext.JSON(http.StatusOK, gin.H{
+			"message": "model restored",
+			"id":      ginContext.Param("history-id"),
+		})
+	}
+}
+
+func (s *server) readHistoryModel(ginContext *gin.Context, historyID string, key []byte, folderNameOfKey string) (modelInput input.Model, yamlText string, ok bool) {
+	modelID, ok := s.checkModelID(ginContext, ginContext.Param("model-id"), folderNameOfKey)
+	if !ok {
+		return modelInput, yamlText, false
+	}
+	_, ok = s.checkModelVersion(ginContext, folderNameOfKey, modelID)
+	if !ok {
+		return modelInput, yamlText, false
+	}
+	entries, err := s.listHistory(folderNameOfKey, modelID)
+	if err != nil {
+		log.Println(err)
+		ginContext.JSON(http.StatusInternalServerError, gin.H{
+			"error": "unable to list model history",
+		})
+		return modelInput, yamlText, false
+	}
+	// only accept ids of existing history entries, which also prevents any path traversal
+	for _, entry := range entries {
+		if entry.ID == historyID {
+			encryptedModel, err := s.storage.ReadHistory(folderNameOfKey, modelID, entry.ID)
+			if err != nil {
+				log.Println(err)
+				ginContext.JSON(http.StatusInternalServerError, gin.H{
+					"error": "unable to open model",
+				})
+				return modelInput, yamlText, false
+			}
+			return s.decryptModel(ginContext, encryptedModel, key)
+		}
+	}
+	ginContext.JSON(http.StatusNotFound, gin.H{
+		"error": "history entry not found",
+	})
+	return modelInput, yamlText, false
+}
+
+func (s *server) listHistory(folderNameOfKey string, modelID string) ([]historyEntry, error) {
+	entries := make([]historyEntry, 0)
+	historyIDs, err := s.storage.ListHistory(folderNameOfKey, modelID)
+	if err != nil {
+		return nil, err
+	}
+	for _, historyID := range historyIDs {
+		entry, ok := parseHistoryEntry(historyID)
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID > entries[j].ID
+	})
+	return entries, nil
+}
+
+// parseHistoryEntry splits the id of a backup (as given by newHistoryID) into timestamp and reason
+func parseHistoryEntry(historyID string) (entry historyEntry, ok bool) {
+	if len(historyID) < len(historyTimestampFormat) {
+		return entry, false
+	}
+	timestamp, err := time.ParseInLocation(historyTimestampFormat, historyID[:len(historyTimestampFormat)], time.Local)
+	if err != nil {
+		return entry, false
+	}
+	return historyEntry{
+		ID:        historyID,
+		Timestamp: timestamp,
+		Reason:    strings.TrimSpace(historyID[len(historyTimestampFormat):]),
+	}, true
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+func TestHistory(t *testing.T) {
+	s := newTestServer(t)
+	s.router.GET("/models/:model-id/risk-tracking", s.getRiskTrackings)
+	s.router.PUT("/models/:model-id/risk-tracking/:risk-id", s.setRiskTracking)
+	s.router.GET("/models/:model-id/history", s.getHistory)
+	s.router.GET("/models/:model-id/history/:history-id", s.getHistoryModel)
+	s.router.GET("/models/:model-id/history/:history-id/diff", s.diffHistoryModel)
+	s.router.POST("/models/:model-id/history/:history-id/restore", s.restoreHistoryModel)
+
+	modelID, _ := s.createModel(t)
+	s.writeTestModel(t, modelID, riskTrackingTestModel)
+	path := "/models/" + modelID
+
+	var initial []historyEntry
+	s.requestJSON(t, http.MethodGet, path+"/history", nil, nil, http.StatusOK, &initial)
+	assert.Empty(t, initial)
+
+	s.requestJSON(t, http.MethodPut, path+"/risk-tracking/leak@web", nil, payloadRiskTracking{Status: "mitigated"}, http.StatusOK, nil)
+	s.requestJSON(t, http.MethodPut, path+"/cover", nil, payloadCover{Title: "Changed"}, http.StatusOK, nil)
+
+	var history []historyEntry
+	s.requestJSON(t, http.MethodGet, path+"/history", nil, nil, http.StatusOK, &history)
+	require.Len(t, history, 2)
+	assert.False(t, history[0].Timestamp.Before(history[1].Timestamp), "history is listed newest first")
+	entries := make(map[string]historyEntry)
+	for _, entry := range history {
+		entries[entry.Reason] = entry
+	}
+	require.Contains(t, entries, "Risk Tracking Update")
+	require.Contains(t, entries, "Cover Update")
+	beforeTracking := path + "/history/" + url.PathEscape(entries["Risk Tracking Update"].ID)
+	beforeCover := path + "/history/" + url.PathEscape(entries["Cover Update"].ID)
+
+	response := s.request(t, http.MethodGet, beforeTracking, nil, nil)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Contains(t, response.Body.String(), "title: Risk Tracking Test", "a backup holds the model before the change")
+	assert.NotContains(t, response.Body.String(), "mitigated")
+
+	for _, historyID := range []string{"2024-01-02 03:04:05 Unknown", "..", "../../keys"} {
+		s.requestJSON(t, http.MethodGet, path+"/history/"+url.PathEscape(historyID), nil, nil, http.StatusNotFound, nil)
+	}
+
+	statusChange := []*model.RiskChange{{
+		SyntheticId: "leak@web",
+		CategoryId:  "leak",
+		Title:       "Leak at Web Server",
+		Changes:     []*model.FieldChange{{Field: "status", Baseline: types.Unchecked.String(), Current: types.Mitigated.String()}},
+	}}
+	var diff model.ModelDiff
+	s.requestJSON(t, http.MethodGet, beforeTracking+"/diff", nil, nil, http.StatusOK, &diff)
+	assert.Equal(t, statusChange, diff.ChangedRisks, "compared to the current model")
+	var diffToBackup model.ModelDiff
+	s.requestJSON(t, http.MethodGet, beforeTracking+"/diff?compare-to="+url.QueryEscape(entries["Cover Update"].ID), nil, nil, http.StatusOK, &diffToBackup)
+	assert.Equal(t, statusChange, diffToBackup.ChangedRisks, "compared to another backup")
+	var noDiff model.ModelDiff
+	s.requestJSON(t, http.MethodGet, beforeCover+"/diff", nil, nil, http.StatusOK, &noDiff)
+	assert.True(t, noDiff.IsEmpty(), "the cover is not part of the diff")
+
+	s.requestJSON(t, http.MethodPost, beforeTracking+"/restore", map[string]string{"If-Match": `"0123456789abcdef"`}, nil, http.StatusPreconditionFailed, nil)
+	s.requestJSON(t, http.MethodPost, beforeTracking+"/restore", nil, nil, http.StatusOK, nil)
+
+	var cover struct{ Title string }
+	s.requestJSON(t, http.MethodGet, path+"/cover", nil, nil, http.StatusOK, &cover)
+	assert.Equal(t, "Risk Tracking Test", cover.Title)
+	var trackings map[string]input.RiskTracking
+	s.requestJSON(t, http.MethodGet, path+"/risk-tracking", nil, nil, http.StatusOK, &trackings)
+	assert.Empty(t, trackings)
+
+	var restoredHistory []historyEntry
+	s.requestJSON(t, http.MethodGet, path+"/history", nil, nil, http.StatusOK, &restoredHistory)
+	require.Len(t, restoredHistory, 3, "the model is backed up before the restore, so it can be undone")
+	reasons := make([]string, 0)
+	for _, entry := range restoredHistory {
+		reasons = append(reasons, entry.Reason)
+	}
+	assert.Contains(t, reasons, "Restore of "+entries["Risk Tracking Update"].Timestamp.Format(historyTimestampFormat))
+}
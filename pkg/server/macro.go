@@ -0,0 +1,195 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/threagile/threagile/pkg/macros"
+	"github.com/threagile/threagile/pkg/model"
+)
+
+// macroSession keeps the state of a model macro between the requests walking through its questions
+type macroSession struct {
+	macro                macros.Macros
+	folderNameOfKey      string
+	modelID              string
+	question             macros.MacroQuestion
+	lastAccessedNanoTime int64
+}
+
+type payloadMacroStep struct {
+	SessionID string   `yaml:"session_id" json:"session_id"`
+	Answer    []string `yaml:"answer" json:"answer"`
+	GoBack    bool     `yaml:"go_back" json:"go_back"`
+	Execute   bool     `yaml:"execute" json:"execute"`
+}
+
+// runModelMacro walks through the questions of a model macro, one request per step: the first request (without session id)
+// starts a session and returns the first question, further requests answer the current question or go one step back.
+// Once all questions are answered the changes are returned, and a request with "execute" applies them to the stored model.
+func (s *server) runModelMacro(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if !ok {
+		return
+	}
+	payload := payloadMacroStep{}
+	err := ginContext.BindJSON(&payload)
+	if err != nil {
+		log.Println(err)
+		ginContext.JSON(http.StatusBadRequest, gin.H{
+			"error": "unable to parse request payload",
+		})
+		return
+	}
+
+	sessionID, session := payload.SessionID, s.getMacroSession(payload.SessionID, folderNameOfKey, ginContext.Param("model-id"))
+	if len(sessionID) == 0 {
+		macro, err := macros.GetMacroByID(ginContext.Param("macro-id"), s.customMacros)
+		if err != nil {
+			ginContext.JSON(http.StatusNotFound, gin.H{
+				"error": "model macro not found",
+			})
+			return
+		}
+		sessionID, session = uuid.New().String(), &macroSession{
+			macro:           macro,
+			folderNameOfKey: folderNameOfKey,
+			modelID:         ginContext.Param("model-id"),
+		}
+	} else if session == nil || session.macro.GetMacroDetails().ID != ginContext.Param("macro-id") {
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "model macro session not found",
+		})
+		return
+	}
+
+	parsedModel, err := model.ParseModel(s.config, &modelInput, s.builtinRiskRules, s.customRiskRules)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return
+	}
+
+	message, validResult := "", true
+	switch {
+	case payload.Execute:
+		nextQuestion, err := session.macro.GetNextQuestion(parsedModel)
+		if err != nil {
+			handleErrorInServiceCall(err, ginContext)
+			return
+		}
+		if !nextQuestion.NoMoreQuestions() {
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": "model macro has unanswered questions",
+			})
+			return
+		}
+		message, validResult, err = session.macro.Execute(&modelInput, parsedModel)
+		if err != nil {
+			handleErrorInServiceCall(err, ginContext)
+			return
+		}
+		if !validResult {
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+		// the session is kept when the model could not be written, so the client may retry executing it
+		ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Model Macro "+session.macro.GetMacroDetails().ID)
+		if ok {
+			s.deleteMacroSession(sessionID)
+			ginContext.JSON(http.StatusOK, gin.H{
+				"message": message,
+				"id":      session.macro.GetMacroDetails().ID,
+			})
+		}
+		return
+	case payload.GoBack:
+		message, validResult, err = session.macro.GoBack()
+	case len(session.question.ID) > 0:
+		answer := payload.Answer
+		if len(answer) == 0 && len(session.question.DefaultAnswer) > 0 { // accepting the default
+			answer = []string{session.question.DefaultAnswer}
+		}
+		answer, err = session.question.CheckAnswer(answer)
+		if err != nil {
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		message, validResult, err = session.macro.ApplyAnswer(session.question.ID, answer...)
+	}
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return
+	}
+
+	session.question, err = session.macro.GetNextQuestion(parsedModel)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return
+	}
+	s.putMacroSession(sessionID, session)
+	response := gin.H{
+		"session_id": sessionID,
+		"message":    message,
+		"valid":      validResult,
+	}
+	if session.question.NoMoreQuestions() {
+		changes, changeMessage, changeValidResult, err := session.macro.GetFinalChangeImpact(&modelInput, parsedModel)
+		if err != nil {
+			handleErrorInServiceCall(err, ginContext)
+			return
+		}
+		response["changes"] = changes
+		response["change_message"] = changeMessage
+		response["executable"] = changeValidResult
+	} else {
+		response["question"] = session.question
+	}
+	ginContext.JSON(http.StatusOK, response)
+}
+
+func (s *server) getMacroSession(sessionID string, folderNameOfKey string, modelID string) *macroSession {
+	s.globalLock.Lock()
+	defer s.globalLock.Unlock()
+	s.housekeepingMacroSessions()
+	session, exists := s.macroSessions[sessionID]
+	if !exists || session.folderNameOfKey != folderNameOfKey || session.modelID != modelID {
+		return nil
+	}
+	return session
+}
+
+func (s *server) putMacroSession(sessionID string, session *macroSession) {
+	s.globalLock.Lock()
+	defer s.globalLock.Unlock()
+	session.lastAccessedNanoTime = time.Now().UnixNano()
+	s.macroSessions[sessionID] = session
+}
+
+func (s *server) deleteMacroSession(sessionID string) {
+	s.globalLock.Lock()
+	defer s.globalLock.Unlock()
+	delete(s.macroSessions, sessionID)
+}
+
+func (s *server) housekeepingMacroSessions() {
+	now := time.Now().UnixNano()
+	for sessionID, session := range s.macroSessions {
+		// remove all sessions not accessed within 30 minutes (= 1800000000000 ns)
+		if now-session.lastAccessedNanoTime > 1800000000000 {
+			delete(s.macroSessions, sessionID)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/macros"
+)
+
+// testDataAssetMacro asks for the title and the confidentiality of a data asset to add
+const testDataAssetMacro = `id: add-data-asset
+title: Add Data Asset
+questions:
+  - id: title
+    title: What is the title of the data asset?
+  - id: confidentiality
+    title: How confidential is the data asset?
+    possible_answers: ["public", "internal", "confidential"]
+model: |
+  data_assets:
+    {{ answer "title" }}:
+      id: added-data
+      usage: business
+      quantity: few
+      confidentiality: {{ answer "confidentiality" }}
+      integrity: operational
+      availability: operational
+`
+
+// loadTestCustomMacros loads the custom model macros of a plugin folder holding testDataAssetMacro
+func loadTestCustomMacros(t *testing.T) []macros.Macros {
+	t.Helper()
+
+	pluginFolder := t.TempDir()
+	folder := filepath.Join(pluginFolder, macros.CustomMacrosFolder)
+	require.NoError(t, os.Mkdir(folder, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "add-data-asset.yaml"), []byte(testDataAssetMacro), 0600))
+
+	customMacros := macros.ListCustomMacros(pluginFolder, DefaultProgressReporter{})
+	require.Len(t, customMacros, 1)
+
+	return customMacros
+}
+
+type macroStepResponse struct {
+	SessionID     string                `json:"session_id"`
+	Valid         bool                  `json:"valid"`
+	Question      *macros.MacroQuestion `json:"question"`
+	Changes       []string              `json:"changes"`
+	ChangeMessage string                `json:"change_message"`
+	Executable    bool                  `json:"executable"`
+	Error         string                `json:"error"`
+}
+
+func newMacroTestServer(t *testing.T) (s *testServer, path string) {
+	t.Helper()
+
+	s = newTestServer(t)
+	s.customMacros = loadTestCustomMacros(t)
+	s.router.POST("/models/:model-id/macros/:macro-id", s.runModelMacro)
+	s.router.GET("/models/:model-id/data-assets/:data-asset-id", s.getDataAsset)
+
+	modelID, _ := s.createModel(t)
+	s.writeTestModel(t, modelID, riskTrackingTestModel)
+
+	return s, "/models/" + modelID
+}
+
+func TestRunModelMacro(t *testing.T) {
+	s, path := newMacroTestServer(t)
+	macroPath := path + "/macros/add-data-asset"
+
+	var started macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{}, http.StatusOK, &started)
+	require.NotEmpty(t, started.SessionID)
+	require.NotNil(t, started.Question)
+	assert.Equal(t, "title", started.Question.ID)
+
+	var titled macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Answer: []string{"Customer Data"}}, http.StatusOK, &titled)
+	assert.True(t, titled.Valid)
+	require.NotNil(t, titled.Question)
+	assert.Equal(t, "confidentiality", titled.Question.ID)
+	assert.Equal(t, []string{"public", "internal", "confidential"}, titled.Question.PossibleAnswers)
+
+	var answered macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Answer: []string{"internal"}}, http.StatusOK, &answered)
+	assert.Nil(t, answered.Question)
+	assert.True(t, answered.Executable)
+	assert.NotEmpty(t, answered.Changes)
+
+	var wentBack macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, GoBack: true}, http.StatusOK, &wentBack)
+	require.NotNil(t, wentBack.Question)
+	assert.Equal(t, "confidentiality", wentBack.Question.ID, "going back asks the last question again")
+
+	var reanswered macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Answer: []string{"Confidential"}}, http.StatusOK, &reanswered)
+	assert.True(t, reanswered.Executable)
+
+	var executed struct{ ID string }
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Execute: true}, http.StatusOK, &executed)
+	assert.Equal(t, "add-data-asset", executed.ID)
+
+	var dataAsset map[string]input.DataAsset
+	s.requestJSON(t, http.MethodGet, path+"/data-assets/added-data", nil, nil, http.StatusOK, &dataAsset)
+	require.Contains(t, dataAsset, "Customer Data")
+	assert.Equal(t, "confidential", dataAsset["Customer Data"].Confidentiality, "the answer is taken as the possible answer")
+
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Execute: true}, http.StatusNotFound, nil)
+}
+
+func TestRunModelMacroInvalidAnswer(t *testing.T) {
+	s, path := newMacroTestServer(t)
+	macroPath := path + "/macros/add-data-asset"
+
+	var started macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{}, http.StatusOK, &started)
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Answer: []string{"Customer Data"}}, http.StatusOK, nil)
+
+	var rejected macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Answer: []string{"secret"}}, http.StatusBadRequest, &rejected)
+	assert.Contains(t, rejected.Error, `answer "secret" to question "confidentiality" does not match any allowed value`)
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Answer: []string{"public", "internal"}}, http.StatusBadRequest, &rejected)
+	assert.Contains(t, rejected.Error, "expects exactly one answer, got 2")
+
+	var answered macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Answer: []string{"public"}}, http.StatusOK, &answered)
+	assert.True(t, answered.Executable, "the session still waits for an answer after a rejected one")
+}
+
+func TestRunModelMacroOpenQuestions(t *testing.T) {
+	s, path := newMacroTestServer(t)
+	macroPath := path + "/macros/add-data-asset"
+
+	var started macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{}, http.StatusOK, &started)
+
+	var rejected macroStepResponse
+	s.requestJSON(t, http.MethodPost, macroPath, nil, payloadMacroStep{SessionID: started.SessionID, Execute: true}, http.StatusBadRequest, &rejected)
+	assert.Equal(t, "model macro has unanswered questions", rejected.Error)
+
+	s.requestJSON(t, http.MethodGet, path+"/data-assets/added-data", nil, nil, http.StatusNotFound, nil)
+}
+
+func TestRunModelMacroNotFound(t *testing.T) {
+	s, path := newMacroTestServer(t)
+
+	var started macroStepResponse
+	s.requestJSON(t, http.MethodPost, path+"/macros/add-data-asset", nil, payloadMacroStep{}, http.StatusOK, &started)
+	otherModelID, _ := s.createModel(t)
+	s.writeTestModel(t, otherModelID, riskTrackingTestModel)
+
+	tests := []struct {
+		name    string
+		path    string
+		payload payloadMacroStep
+	}{
+		{"unknown macro", path + "/macros/add-nothing", payloadMacroStep{}},
+		{"unknown session", path + "/macros/add-data-asset", payloadMacroStep{SessionID: "0f4a1a2e-5c39-4bfb-b9a8-2d7f0a4e2c11"}},
+		{"session of another macro", path + "/macros/seed-tags", payloadMacroStep{SessionID: started.SessionID}},
+		{"session of another model", "/models/" + otherModelID + "/macros/add-data-asset", payloadMacroStep{SessionID: started.SessionID}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s.requestJSON(t, http.MethodPost, test.path, nil, test.payload, http.StatusNotFound, nil)
+		})
+	}
+}
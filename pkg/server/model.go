@@ -10,13 +10,13 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -31,7 +31,7 @@ import (
 	"golang.org/x/crypto/argon2"
 )
 
-// creates a sub-folder (named by a new UUID) inside the token folder
+// creates a new model (named by a new UUID) for the key of the token
 func (s *server) createNewModel(ginContext *gin.Context) {
 	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
 	if !ok {
@@ -45,13 +45,6 @@ func (s *server) createNewModel(ginContext *gin.Context) {
 	defer s.unlockFolder(folderNameOfKey)
 
 	aUuid := uuid.New().String()
-	err := os.Mkdir(folderNameForModel(folderNameOfKey, aUuid), 0700)
-	if err != nil {
-		ginContext.JSON(http.StatusInternalServerError, gin.H{
-			"error": "unable to create model",
-		})
-		return
-	}
 
 	aYaml := `title: New Threat Model
 threagile_version: ` + s.config.GetThreagileVersion() + `
@@ -77,14 +70,14 @@ trust_boundaries: {}
 shared_runtimes: {}
 individual_risk_categories: {}
 risk_tracking: {}
-diagram_tweak_nodesep: ""
-diagram_tweak_ranksep: ""
+diagram_tweak_nodesep: 2
+diagram_tweak_ranksep: 2
 diagram_tweak_edge_layout: ""
 diagram_tweak_suppress_edge_labels: false
 diagram_tweak_invisible_connections_between_assets: []
 diagram_tweak_same_rank_assets: []`
 
-	ok = s.writeModelYAML(ginContext, aYaml, key, folderNameForModel(folderNameOfKey, aUuid), "New Model Creation", true)
+	ok = s.writeModelYAML(ginContext, aYaml, key, folderNameOfKey, aUuid, "New Model Creation", true)
 	if ok {
 		ginContext.JSON(http.StatusCreated, gin.H{
 			"message": "model created",
@@ -109,43 +102,34 @@ func (s *server) listModels(ginContext *gin.Context) { // TODO currently returns
 	defer s.unlockFolder(folderNameOfKey)
 
 	result := make([]payloadModels, 0)
-	modelFolders, err := os.ReadDir(folderNameOfKey)
+	storedModels, err := s.storage.ListModels(folderNameOfKey)
 	if err != nil {
 		log.Println(err)
 		ginContext.JSON(http.StatusNotFound, gin.H{
-			"error": "token not found",
+			"error": "unable to list model",
 		})
 		return
 	}
-	for _, dirEntry := range modelFolders {
-		if dirEntry.IsDir() {
-			modelStat, err := os.Stat(filepath.Join(folderNameOfKey, dirEntry.Name(), s.config.GetInputFile()))
-			if err != nil {
-				log.Println(err)
-				ginContext.JSON(http.StatusNotFound, gin.H{
-					"error": "unable to list model",
-				})
-				return
-			}
-			aModel, _, ok := s.readModel(ginContext, dirEntry.Name(), key, folderNameOfKey)
-			if !ok {
-				return
-			}
-			fileInfo, err := dirEntry.Info()
-			if err != nil {
-				log.Println(err)
-				ginContext.JSON(http.StatusNotFound, gin.H{
-					"error": "unable to get file info",
-				})
-				return
-			}
-			result = append(result, payloadModels{
-				ID:                dirEntry.Name(),
-				Title:             aModel.Title,
-				TimestampCreated:  fileInfo.ModTime(),
-				TimestampModified: modelStat.ModTime(),
+	for _, storedModel := range storedModels {
+		// no readModel here: the version check (ETag and If-Match) is about a single model, not about the listing
+		encryptedModel, err := s.storage.ReadModel(folderNameOfKey, storedModel.ID)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusInternalServerError, gin.H{
+				"error": "unable to open model",
 			})
+			return
 		}
+		aModel, _, ok := s.decryptModel(ginContext, encryptedModel, key)
+		if !ok {
+			return
+		}
+		result = append(result, payloadModels{
+			ID:                storedModel.ID,
+			Title:             aModel.Title,
+			TimestampCreated:  storedModel.Created,
+			TimestampModified: storedModel.Modified,
+		})
 	}
 	ginContext.JSON(http.StatusOK, result)
 }
@@ -157,16 +141,15 @@ func (s *server) deleteModel(ginContext *gin.Context) {
 	}
 	s.lockFolder(folderNameOfKey)
 	defer s.unlockFolder(folderNameOfKey)
-	folder, ok := s.checkModelFolder(ginContext, ginContext.Param("model-id"), folderNameOfKey)
+	modelID, ok := s.checkModelID(ginContext, ginContext.Param("model-id"), folderNameOfKey)
 	if ok {
-		if folder != filepath.Clean(folder) {
-			ginContext.JSON(http.StatusInternalServerError, gin.H{
-				"error": "model-id is weird",
-			})
+		_, ok = s.checkModelVersion(ginContext, folderNameOfKey, modelID)
+		if !ok {
 			return
 		}
-		err := os.RemoveAll(folder)
+		err := s.storage.DeleteModel(folderNameOfKey, modelID)
 		if err != nil {
+			log.Println(err)
 			ginContext.JSON(http.StatusNotFound, gin.H{
 				"error": "model not found",
 			})
@@ -725,19 +708,6 @@ func (s *server) populateDataAsset(ginContext *gin.Context, payload payloadDataA
 	return dataAssetInput, true
 }
 
-func (s *server) getTrustBoundaries(ginContext *gin.Context) {
-	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
-	if !ok {
-		return
-	}
-	s.lockFolder(folderNameOfKey)
-	defer s.unlockFolder(folderNameOfKey)
-	aModel, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
-	if ok {
-		ginContext.JSON(http.StatusOK, aModel.TrustBoundaries)
-	}
-}
-
 type payloadSharedRuntime struct {
 	Title                  string   `yaml:"title" json:"title"`
 	Id                     string   `yaml:"id" json:"id"`
@@ -972,10 +942,61 @@ func (s *server) getSharedRuntimes(ginContext *gin.Context) {
 }
 
 func (s *server) readModel(ginContext *gin.Context, modelUUID string, key []byte, folderNameOfKey string) (modelInputResult input.Model, yamlText string, ok bool) {
-	modelFolder, ok := s.checkModelFolder(ginContext, modelUUID, folderNameOfKey)
+	modelID, ok := s.checkModelID(ginContext, modelUUID, folderNameOfKey)
+	if !ok {
+		return modelInputResult, yamlText, false
+	}
+	encryptedModel, ok := s.checkModelVersion(ginContext, folderNameOfKey, modelID)
 	if !ok {
 		return modelInputResult, yamlText, false
 	}
+	return s.decryptModel(ginContext, encryptedModel, key)
+}
+
+// modelVersionContextKey holds the version of the model read by a request, which the model is written based on
+const modelVersionContextKey = "modelVersion"
+
+// checkModelVersion reads the stored model, provides its version as ETag and rejects requests with an If-Match header
+// not matching it, so concurrent editors don't silently overwrite each other's changes. The version is remembered
+// for writeModelYAML, so the storage rejects the write when the model has been changed by another server process
+// after it was read.
+func (s *server) checkModelVersion(ginContext *gin.Context, folderNameOfKey string, modelID string) (encryptedModel []byte, ok bool) {
+	encryptedModel, err := s.storage.ReadModel(folderNameOfKey, modelID)
+	if err != nil {
+		log.Println(err)
+		ginContext.JSON(http.StatusInternalServerError, gin.H{
+			"error": "unable to open model",
+		})
+		return nil, false
+	}
+	ginContext.Set(modelVersionContextKey, modelVersion(encryptedModel))
+	eTag := modelETag(encryptedModel)
+	ginContext.Header("ETag", eTag)
+	ifMatch := ginContext.GetHeader("If-Match")
+	if len(ifMatch) == 0 {
+		return encryptedModel, true
+	}
+	// If-Match uses the strong comparison (RFC 7232, section 2.3.2), so weak ETags never match
+	for _, expected := range strings.Split(ifMatch, ",") {
+		expected = strings.TrimSpace(expected)
+		if expected == "*" || expected == eTag {
+			return encryptedModel, true
+		}
+	}
+	ginContext.JSON(http.StatusPreconditionFailed, gin.H{
+		"error": ErrModelChanged.Error(),
+	})
+	return nil, false
+}
+
+// modelETag derives the ETag from the version of the encrypted model content, so the ETag changes with each write of
+// the model
+func modelETag(encryptedModel []byte) string {
+	return `"` + modelVersion(encryptedModel) + `"`
+}
+
+// decryptModel decrypts a stored model, either the current model or one of its history backups
+func (s *server) decryptModel(ginContext *gin.Context, fileBytes []byte, key []byte) (modelInputResult input.Model, yamlText string, ok bool) {
 	cryptoKey := generateKeyFromAlreadyStrongRandomInput(key)
 	block, err := aes.NewCipher(cryptoKey)
 	if err != nil {
@@ -994,15 +1015,12 @@ func (s *server) readModel(ginContext *gin.Context, modelUUID string, key []byte
 		return modelInputResult, yamlText, false
 	}
 
-	fileBytes, err := os.ReadFile(filepath.Clean(filepath.Join(modelFolder, s.config.GetInputFile())))
-	if err != nil {
-		log.Println(err)
+	if len(fileBytes) < 12 {
 		ginContext.JSON(http.StatusInternalServerError, gin.H{
 			"error": "unable to open model",
 		})
 		return modelInputResult, yamlText, false
 	}
-
 	nonce := fileBytes[0:12]
 	ciphertext := fileBytes[12:]
 	plaintext, err := aesGcm.Open(nil, nonce, ciphertext, nil) // #nosec G407 // false positive The nounce is read from file for decryption not encryption
@@ -1038,7 +1056,7 @@ func (s *server) readModel(ginContext *gin.Context, modelUUID string, key []byte
 }
 
 func (s *server) writeModel(ginContext *gin.Context, key []byte, folderNameOfKey string, modelInput *input.Model, changeReasonForHistory string) (ok bool) {
-	modelFolder, ok := s.checkModelFolder(ginContext, ginContext.Param("model-id"), folderNameOfKey)
+	modelID, ok := s.checkModelID(ginContext, ginContext.Param("model-id"), folderNameOfKey)
 	if ok {
 		modelInput.ThreagileVersion = s.config.GetThreagileVersion()
 		yamlBytes, err := yaml.Marshal(modelInput)
@@ -1052,27 +1070,31 @@ func (s *server) writeModel(ginContext *gin.Context, key []byte, folderNameOfKey
 		/*
 			yamlBytes = model.ReformatYAML(yamlBytes)
 		*/
-		return s.writeModelYAML(ginContext, string(yamlBytes), key, modelFolder, changeReasonForHistory, false)
+		return s.writeModelYAML(ginContext, string(yamlBytes), key, folderNameOfKey, modelID, changeReasonForHistory, false)
 	}
 	return false
 }
 
-func (s *server) checkModelFolder(ginContext *gin.Context, modelUUID string, folderNameOfKey string) (modelFolder string, ok bool) {
+func (s *server) checkModelID(ginContext *gin.Context, modelUUID string, folderNameOfKey string) (modelID string, ok bool) {
 	uuidParsed, err := uuid.Parse(modelUUID)
 	if err != nil {
 		ginContext.JSON(http.StatusNotFound, gin.H{
 			"error": "model not found",
 		})
-		return modelFolder, false
+		return modelID, false
+	}
+	modelID = uuidParsed.String()
+	exists, err := s.storage.ModelExists(folderNameOfKey, modelID)
+	if err != nil {
+		log.Println(err)
 	}
-	modelFolder = folderNameForModel(folderNameOfKey, uuidParsed.String())
-	if _, err := os.Stat(modelFolder); os.IsNotExist(err) {
+	if !exists {
 		ginContext.JSON(http.StatusNotFound, gin.H{
 			"error": "model not found",
 		})
-		return modelFolder, false
+		return modelID, false
 	}
-	return modelFolder, true
+	return modelID, true
 }
 
 func (s *server) getModel(ginContext *gin.Context) {
@@ -1111,14 +1133,14 @@ func (s *server) importModel(ginContext *gin.Context) {
 	s.lockFolder(folderNameOfKey)
 	defer s.unlockFolder(folderNameOfKey)
 
-	aUuid := ginContext.Param("model-id") // UUID is syntactically validated in readModel+checkModelFolder (next line) via uuid.Parse(modelUUID)
+	aUuid := ginContext.Param("model-id") // UUID is syntactically validated in readModel+checkModelID (next line) via uuid.Parse(modelUUID)
 	_, _, ok = s.readModel(ginContext, aUuid, key, folderNameOfKey)
 	if ok {
 		// first analyze it simply by executing the full risk process (just discard the result) to ensure that everything would work
 		yamlContent, ok := s.execute(ginContext, true)
 		if ok {
 			// if we're here, then no problem was raised, so ok to proceed
-			ok = s.writeModelYAML(ginContext, string(yamlContent), key, folderNameForModel(folderNameOfKey, aUuid), "Model Import", false)
+			ok = s.writeModelYAML(ginContext, string(yamlContent), key, folderNameOfKey, aUuid, "Model Import", false)
 			if ok {
 				ginContext.JSON(http.StatusCreated, gin.H{
 					"message": "model imported",
@@ -1218,9 +1240,9 @@ func (s *server) analyzeModelOnServerDirectly(ginContext *gin.Context) {
 	ginContext.FileAttachment(tmpResultFile.Name(), "threagile-result.zip")
 }
 
-func (s *server) writeModelYAML(ginContext *gin.Context, yaml string, key []byte, modelFolder string, changeReasonForHistory string, skipBackup bool) (ok bool) {
+func (s *server) writeModelYAML(ginContext *gin.Context, yaml string, key []byte, folderNameOfKey string, modelID string, changeReasonForHistory string, skipBackup bool) (ok bool) {
 	if s.config.GetVerbose() {
-		fmt.Println("about to write " + strconv.Itoa(len(yaml)) + " bytes of yaml into model folder: " + modelFolder)
+		fmt.Println("about to write " + strconv.Itoa(len(yaml)) + " bytes of yaml into model: " + modelID)
 	}
 	var b bytes.Buffer
 	w := gzip.NewWriter(&b)
@@ -1254,30 +1276,43 @@ func (s *server) writeModelYAML(ginContext *gin.Context, yaml string, key []byte
 		return false
 	}
 	ciphertext := aesGcm.Seal(nil, nonce, plaintext, nil) // #nosec G407 // The nounce is read from random so it shoul be random each run
+	// a new model has no version yet, so it's written unconditionally
+	version := ginContext.GetString(modelVersionContextKey)
 	if !skipBackup {
-		err = s.backupModelToHistory(modelFolder, changeReasonForHistory)
+		err = s.storage.BackupModel(folderNameOfKey, modelID, version, changeReasonForHistory, s.config.GetBackupHistoryFilesToKeep())
 		if err != nil {
-			log.Println(err)
-			ginContext.JSON(http.StatusInternalServerError, gin.H{
-				"error": "unable to write model",
-			})
+			handleModelWriteError(err, ginContext)
 			return false
 		}
 	}
-	f, err := os.Create(filepath.Clean(filepath.Join(modelFolder, s.config.GetInputFile())))
+	encryptedModel := append(nonce, ciphertext...)
+	err = s.storage.WriteModel(folderNameOfKey, modelID, version, encryptedModel)
 	if err != nil {
-		log.Println(err)
-		ginContext.JSON(http.StatusInternalServerError, gin.H{
-			"error": "unable to write model",
-		})
+		handleModelWriteError(err, ginContext)
 		return false
 	}
-	_, _ = f.Write(nonce)
-	_, _ = f.Write(ciphertext)
-	_ = f.Close()
+	ginContext.Set(modelVersionContextKey, modelVersion(encryptedModel))
+	ginContext.Header("ETag", modelETag(encryptedModel))
 	return true
 }
 
+// handleModelWriteError rejects a write of a model changed by someone else since it was read like a mismatching
+// If-Match header, any other error is an internal one
+func handleModelWriteError(err error, ginContext *gin.Context) {
+	if errors.Is(err, ErrModelChanged) {
+		ginContext.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": ErrModelChanged.Error(),
+		})
+		return
+	}
+	log.Println(err)
+	ginContext.JSON(http.StatusInternalServerError, gin.H{
+		"error": "unable to write model",
+	})
+}
+
+// lockFolder serializes the changes to the models of a key within the server process; changes of other server
+// processes sharing the same storage are caught by the version check of the storage instead (see writeModelYAML)
 func (s *server) lockFolder(folderName string) {
 	s.globalLock.Lock()
 	defer s.globalLock.Unlock()
@@ -1295,68 +1330,6 @@ func (s *server) unlockFolder(folderName string) {
 	}
 }
 
-func (s *server) backupModelToHistory(modelFolder string, changeReasonForHistory string) (err error) {
-	// Sanitize inputs to prevent path traversal
-	safeReason := filepath.Base(changeReasonForHistory)
-	baseDir, resolveErr := filepath.Abs(s.config.GetDataFolder())
-	if resolveErr != nil {
-		return fmt.Errorf("failed to resolve data folder: %w", resolveErr)
-	}
-	cleanModelFolder, resolveErr := filepath.Abs(modelFolder)
-	if resolveErr != nil {
-		return fmt.Errorf("failed to resolve model folder: %w", resolveErr)
-	}
-	if !strings.HasPrefix(cleanModelFolder, baseDir) {
-		return fmt.Errorf("model folder %q is outside data directory", modelFolder)
-	}
-	historyFolder := filepath.Join(cleanModelFolder, "history")
-	if _, err := os.Stat(historyFolder); os.IsNotExist(err) {
-		err = os.Mkdir(historyFolder, 0700)
-		if err != nil {
-			return err
-		}
-	}
-	inputFile := filepath.Join(cleanModelFolder, filepath.Base(s.config.GetInputFile()))
-	inputModel, err := os.ReadFile(filepath.Clean(inputFile))
-	if err != nil {
-		return err
-	}
-	historyFile := filepath.Join(historyFolder, time.Now().Format("2006-01-02 15:04:05")+" "+safeReason+".backup")
-	err = os.WriteFile(historyFile, inputModel, 0400) // #nosec G703
-	if err != nil {
-		return err
-	}
-	// now delete any old files if over limit to keep
-	files, err := os.ReadDir(filepath.Clean(historyFolder))
-	if err != nil {
-		return err
-	}
-	if len(files) > s.config.GetBackupHistoryFilesToKeep() {
-		requiredToDelete := len(files) - s.config.GetBackupHistoryFilesToKeep()
-		sort.Slice(files, func(i, j int) bool {
-			return files[i].Name() < files[j].Name()
-		})
-		for _, file := range files {
-			requiredToDelete--
-			if file.Name() != filepath.Clean(file.Name()) {
-				return fmt.Errorf("weird file name %v", file.Name())
-			}
-			err = os.Remove(filepath.Clean(filepath.Join(historyFolder, file.Name())))
-			if err != nil {
-				return err
-			}
-			if requiredToDelete <= 0 {
-				break
-			}
-		}
-	}
-	return
-}
-
-func folderNameForModel(folderNameOfKey string, uuid string) string {
-	return filepath.Join(folderNameOfKey, uuid)
-}
-
 type argon2Params struct {
 	memory      uint32
 	iterations  uint32
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckModelVersion(t *testing.T) {
+	operations := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{"read", http.MethodGet, "/cover"},
+		{"update", http.MethodPut, "/cover"},
+		{"delete", http.MethodDelete, ""},
+	}
+	versions := []struct {
+		name    string
+		ifMatch func(eTag string) string
+		status  int
+	}{
+		{"missing", func(string) string { return "" }, http.StatusOK},
+		{"stale", func(string) string { return `"0123456789abcdef"` }, http.StatusPreconditionFailed},
+		{"weak", func(eTag string) string { return "W/" + eTag }, http.StatusPreconditionFailed},
+		{"matching", func(eTag string) string { return eTag }, http.StatusOK},
+		{"matching in list", func(eTag string) string { return `"0123456789abcdef", ` + eTag }, http.StatusOK},
+		{"any", func(string) string { return "*" }, http.StatusOK},
+	}
+
+	for _, operation := range operations {
+		s := newTestServer(t)
+		for _, version := range versions {
+			t.Run(operation.name+" "+version.name, func(t *testing.T) {
+				modelID, eTag := s.createModel(t)
+				headers := make(map[string]string)
+				if ifMatch := version.ifMatch(eTag); len(ifMatch) > 0 {
+					headers["If-Match"] = ifMatch
+				}
+
+				response := s.request(t, operation.method, "/models/"+modelID+operation.path, headers, payloadCover{Title: "Changed"})
+
+				assert.Equal(t, version.status, response.Code, response.Body.String())
+				if operation.method == http.MethodPut && response.Code == http.StatusOK {
+					assert.NotEqual(t, eTag, response.Header().Get("ETag"), "an update changes the version")
+				} else {
+					assert.Equal(t, eTag, response.Header().Get("ETag"))
+				}
+
+				if response.Code == http.StatusPreconditionFailed {
+					var cover struct{ Title string }
+					s.requestJSON(t, http.MethodGet, "/models/"+modelID+"/cover", nil, nil, http.StatusOK, &cover)
+					assert.Equal(t, "New Threat Model", cover.Title, "rejected requests leave the model alone")
+				}
+			})
+		}
+	}
+}
+
+func TestListModelsWithoutVersion(t *testing.T) {
+	s := newTestServer(t)
+	s.router.GET("/models", s.listModels)
+	firstID, _ := s.createModel(t)
+	secondID, _ := s.createModel(t)
+
+	response := s.request(t, http.MethodGet, "/models", map[string]string{"If-Match": `"0123456789abcdef"`}, nil)
+
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Empty(t, response.Header().Get("ETag"), "the listing has no version of its own")
+	var models []payloadModels
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &models))
+	ids := make([]string, 0)
+	for _, listed := range models {
+		ids = append(ids, listed.ID)
+		assert.Equal(t, "New Threat Model", listed.Title)
+	}
+	assert.ElementsMatch(t, []string{firstID, secondID}, ids)
+}
+
+// interferingStorage runs a change of another server process right before the next backup of a model
+type interferingStorage struct {
+	Storage
+	interfere func()
+}
+
+func (what *interferingStorage) BackupModel(keyID string, modelID string, version string, reason string, historyEntriesToKeep int) error {
+	if interfere := what.interfere; interfere != nil {
+		what.interfere = nil
+		interfere()
+	}
+	return what.Storage.BackupModel(keyID, modelID, version, reason, historyEntriesToKeep)
+}
+
+func TestWriteModelChangedByOtherProcess(t *testing.T) {
+	s := newTestServer(t)
+	storage := &interferingStorage{Storage: s.storage}
+	s.storage = storage
+	modelID, _ := s.createModel(t)
+	storage.interfere = func() { s.writeTestModel(t, modelID, riskTrackingTestModel) }
+
+	response := s.request(t, http.MethodPut, "/models/"+modelID+"/cover", nil, payloadCover{Title: "Changed"})
+
+	assert.Equal(t, http.StatusPreconditionFailed, response.Code, response.Body.String())
+	var cover struct{ Title string }
+	s.requestJSON(t, http.MethodGet, "/models/"+modelID+"/cover", nil, nil, http.StatusOK, &cover)
+	assert.Equal(t, "Risk Tracking Test", cover.Title, "the change of the other process is not overwritten")
+}
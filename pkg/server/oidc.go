@@ -0,0 +1,474 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	KeyAuth  = "key"
+	OIDCAuth = "oidc"
+)
+
+type role int
+
+const (
+	noRole role = iota
+	viewerRole
+	editorRole
+	adminRole
+)
+
+var roleNames = map[string]role{
+	"viewer": viewerRole,
+	"editor": editorRole,
+	"admin":  adminRole,
+}
+
+// oidcVerifier verifies bearer tokens (JWTs) issued by an OpenID Connect provider against the keys of its JWKS, which is
+// read from a local file or fetched from an url (and re-read when a token is signed by an unknown key)
+type oidcVerifier struct {
+	issuer      string
+	audience    string
+	jwks        string
+	groupsClaim string
+	rolesClaim  string
+	secret      []byte
+
+	lock      sync.Mutex
+	keys      map[string]crypto.PublicKey
+	lastFetch time.Time
+}
+
+type oidcConfigReader interface {
+	GetServerFolder() string
+	GetOIDCIssuer() string
+	GetOIDCAudience() string
+	GetOIDCJWKS() string
+	GetOIDCGroupsClaim() string
+	GetOIDCRolesClaim() string
+	GetOIDCSecretFile() string
+}
+
+func newOIDCVerifier(config oidcConfigReader) (*oidcVerifier, error) {
+	// without audience, tokens the issuer signed for any of its clients would be accepted
+	if len(config.GetOIDCIssuer()) == 0 || len(config.GetOIDCAudience()) == 0 || len(config.GetOIDCJWKS()) == 0 {
+		return nil, fmt.Errorf("oidc authentication requires an issuer, an audience and a jwks")
+	}
+	jwks := config.GetOIDCJWKS()
+	if !isURL(jwks) && !filepath.IsAbs(jwks) {
+		jwks = filepath.Join(config.GetServerFolder(), jwks)
+	}
+	secretFile := config.GetOIDCSecretFile()
+	if !filepath.IsAbs(secretFile) {
+		secretFile = filepath.Join(config.GetServerFolder(), secretFile)
+	}
+	secret, err := readOrCreateSecret(secretFile)
+	if err != nil {
+		return nil, err
+	}
+	verifier := &oidcVerifier{
+		issuer:      config.GetOIDCIssuer(),
+		audience:    config.GetOIDCAudience(),
+		jwks:        jwks,
+		groupsClaim: config.GetOIDCGroupsClaim(),
+		rolesClaim:  config.GetOIDCRolesClaim(),
+		secret:      secret,
+	}
+	verifier.keys, err = verifier.loadKeys()
+	if err != nil {
+		return nil, err
+	}
+	verifier.lastFetch = time.Now()
+	return verifier, nil
+}
+
+// readOrCreateSecret reads the server secret the keys of the owners are derived from; losing it means losing access to
+// all models stored with oidc authentication
+func readOrCreateSecret(filename string) ([]byte, error) {
+	secret, err := os.ReadFile(filepath.Clean(filename))
+	if err == nil {
+		if len(secret) < keySize {
+			return nil, fmt.Errorf("oidc secret file %q is too short", filename)
+		}
+		return secret, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read oidc secret file %q: %w", filename, err)
+	}
+	secret = make([]byte, keySize)
+	if _, err = rand.Read(secret); err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(filename, secret, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to write oidc secret file %q: %w", filename, err)
+	}
+	return secret, nil
+}
+
+// keyOfOwner derives the key of an owner (a user or group), which takes the place of the random keys of the key
+// authentication: it names the storage of the owner's models and encrypts them
+func (what *oidcVerifier) keyOfOwner(owner string) []byte {
+	mac := hmac.New(sha256.New, what.secret)
+	mac.Write([]byte(owner))
+	return mac.Sum(nil)
+}
+
+// loadKeys reads the jwks without touching the verifier's keys, so it may run without holding the lock
+func (what *oidcVerifier) loadKeys() (map[string]crypto.PublicKey, error) {
+	var content []byte
+	var err error
+	if isURL(what.jwks) {
+		content, err = fetchJWKS(what.jwks)
+	} else {
+		content, err = os.ReadFile(filepath.Clean(what.jwks))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to load jwks %q: %w", what.jwks, err)
+	}
+	keys, err := parseJWKS(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse jwks %q: %w", what.jwks, err)
+	}
+	return keys, nil
+}
+
+func fetchJWKS(url string) ([]byte, error) {
+	client := http.Client{Timeout: 10 * time.Second}
+	response, err := client.Get(url) // #nosec G107 // the url is given by the server config
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %v", response.Status)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, 1024*1024))
+}
+
+func (what *oidcVerifier) key(keyID string) (crypto.PublicKey, error) {
+	what.lock.Lock()
+	key, ok := what.lookupKey(keyID)
+	// the provider might have rotated its keys, but don't let unknown key ids trigger a reload with every request
+	reload := !ok && time.Since(what.lastFetch) > time.Minute
+	if reload {
+		what.lastFetch = time.Now() // claimed before fetching, so concurrent requests don't fetch as well
+	}
+	what.lock.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	if reload {
+		// fetched without holding the lock, a slow provider must not block requests signed by known keys
+		keys, err := what.loadKeys()
+		if err != nil {
+			log.Println(err)
+		} else {
+			what.lock.Lock()
+			what.keys = keys
+			key, ok = what.lookupKey(keyID)
+			what.lock.Unlock()
+			if ok {
+				return key, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown signing key %q", keyID)
+}
+
+func (what *oidcVerifier) lookupKey(keyID string) (crypto.PublicKey, bool) {
+	if len(keyID) == 0 && len(what.keys) == 1 {
+		for _, key := range what.keys {
+			return key, true
+		}
+	}
+	key, ok := what.keys[keyID]
+	return key, ok
+}
+
+// verify checks signature, issuer, audience and lifetime of a token and returns its claims
+func (what *oidcVerifier) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	var header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	key, err := what.key(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	err = verifySignature(header.Algorithm, key, []byte(parts[0]+"."+parts[1]), signature)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := make(map[string]any)
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if issuer, _ := claims["iss"].(string); issuer != what.issuer {
+		return nil, fmt.Errorf("token of unexpected issuer %q", issuer)
+	}
+	if !slices.Contains(stringValues(claims["aud"]), what.audience) {
+		return nil, fmt.Errorf("token not meant for audience %q", what.audience)
+	}
+	const leeway = 60 // seconds of clock skew to accept
+	now := float64(time.Now().Unix())
+	expiry, ok := claims["exp"].(float64)
+	if !ok || now > expiry+leeway {
+		return nil, fmt.Errorf("token expired")
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now < notBefore-leeway {
+		return nil, fmt.Errorf("token not valid yet")
+	}
+	if subject, _ := claims["sub"].(string); len(subject) == 0 {
+		return nil, fmt.Errorf("token without subject")
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, value any) error {
+	content, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, value)
+}
+
+func verifySignature(algorithm string, key crypto.PublicKey, signedContent []byte, signature []byte) error {
+	if len(algorithm) != 5 {
+		return fmt.Errorf("unsupported token algorithm %q", algorithm)
+	}
+	var hash crypto.Hash
+	switch algorithm[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", algorithm)
+	}
+	hasher := hash.New()
+	hasher.Write(signedContent)
+	digest := hasher.Sum(nil)
+
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		switch algorithm[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(publicKey, hash, digest, signature)
+		case "PS":
+			return rsa.VerifyPSS(publicKey, hash, digest, signature, nil)
+		}
+
+	case *ecdsa.PublicKey:
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if algorithm[:2] == "ES" && len(signature) == 2*size {
+			r, s := new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:])
+			if ecdsa.Verify(publicKey, digest, r, s) {
+				return nil
+			}
+			return fmt.Errorf("invalid token signature")
+		}
+	}
+	return fmt.Errorf("token algorithm %q does not match signing key", algorithm)
+}
+
+// parseJWKS reads the signature keys (RSA and EC) of a JSON Web Key Set
+func parseJWKS(content []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+			Curve   string `json:"crv"`
+			X       string `json:"x"`
+			Y       string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if len(jwk.Use) > 0 && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.KeyType {
+		case "RSA":
+			n, nError := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, eError := base64.RawURLEncoding.DecodeString(jwk.E)
+			if nError != nil || eError != nil || len(e) > 4 {
+				return nil, fmt.Errorf("invalid rsa key %q", jwk.KeyID)
+			}
+			keys[jwk.KeyID] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Curve {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("unsupported curve %q of key %q", jwk.Curve, jwk.KeyID)
+			}
+			x, xError := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, yError := base64.RawURLEncoding.DecodeString(jwk.Y)
+			size := (curve.Params().BitSize + 7) / 8
+			if xError != nil || yError != nil || len(x) != size || len(y) != size {
+				return nil, fmt.Errorf("invalid ec key %q", jwk.KeyID)
+			}
+			key, err := ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+			if err != nil {
+				return nil, fmt.Errorf("invalid ec key %q: %w", jwk.KeyID, err)
+			}
+			keys[jwk.KeyID] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signature keys found")
+	}
+	return keys, nil
+}
+
+type ownerHeader struct {
+	Owner string `header:"owner"`
+}
+
+// checkBearerToFolderName is the counterpart of checkTokenToFolderName for oidc authentication: the models are owned by
+// the subject of the token ("user:<sub>") or, given by the owner header, by one of its groups ("group:<name>"), and the
+// role of the token has to allow the request
+func (s *server) checkBearerToFolderName(ginContext *gin.Context) (folderNameOfKey string, key []byte, ok bool) {
+	bearer, found := strings.CutPrefix(ginContext.GetHeader("Authorization"), "Bearer ")
+	if !found {
+		ginContext.JSON(http.StatusUnauthorized, gin.H{
+			"error": "bearer token required",
+		})
+		return folderNameOfKey, key, false
+	}
+	claims, err := s.oidc.verify(strings.TrimSpace(bearer))
+	if err != nil {
+		log.Println(err)
+		ginContext.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid bearer token",
+		})
+		return folderNameOfKey, key, false
+	}
+
+	grantedRole := noRole
+	for _, roleName := range stringValues(claims[s.oidc.rolesClaim]) {
+		grantedRole = max(grantedRole, roleNames[strings.ToLower(roleName)])
+	}
+	if grantedRole < requiredRole(ginContext) {
+		ginContext.JSON(http.StatusForbidden, gin.H{
+			"error": "permission denied",
+		})
+		return folderNameOfKey, key, false
+	}
+
+	subject, _ := claims["sub"].(string)
+	owner := "user:" + subject
+	header := ownerHeader{}
+	_ = ginContext.ShouldBindHeader(&header)
+	if requestedOwner := strings.TrimSpace(header.Owner); len(requestedOwner) > 0 && requestedOwner != owner {
+		group, isGroup := strings.CutPrefix(requestedOwner, "group:")
+		if grantedRole < adminRole && (!isGroup || !slices.Contains(stringValues(claims[s.oidc.groupsClaim]), group)) {
+			ginContext.JSON(http.StatusForbidden, gin.H{
+				"error": "permission denied",
+			})
+			return folderNameOfKey, key, false
+		}
+		owner = requestedOwner
+	}
+
+	key = s.oidc.keyOfOwner(owner)
+	folderNameOfKey = s.folderNameFromKey(key)
+	s.globalLock.Lock()
+	defer s.globalLock.Unlock()
+	if exists, err := s.storage.KeyExists(folderNameOfKey); !exists { // the storage of an owner is created on first use
+		if err == nil {
+			err = s.storage.CreateKey(folderNameOfKey)
+		}
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusInternalServerError, gin.H{
+				"error": "unable to access models",
+			})
+			return folderNameOfKey, key, false
+		}
+	}
+	return folderNameOfKey, key, true
+}
+
+// requiredRole grants reading to viewers, deleting whole models to admins and any other change to editors
+func requiredRole(ginContext *gin.Context) role {
+	switch ginContext.Request.Method {
+	case http.MethodGet, http.MethodHead:
+		return viewerRole
+	case http.MethodDelete:
+		if ginContext.FullPath() == "/models/:model-id" {
+			return adminRole
+		}
+	}
+	return editorRole
+}
+
+// stringValues reads a claim which is either a single string or an array of strings
+func stringValues(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "https://") || strings.HasPrefix(location, "http://")
+}
@@ -0,0 +1,275 @@
+package server
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "threagile"
+)
+
+type testOIDCConfig struct {
+	serverFolder string
+	audience     string
+	jwks         string
+}
+
+func (what *testOIDCConfig) GetServerFolder() string    { return what.serverFolder }
+func (what *testOIDCConfig) GetOIDCIssuer() string      { return testIssuer }
+func (what *testOIDCConfig) GetOIDCAudience() string    { return what.audience }
+func (what *testOIDCConfig) GetOIDCJWKS() string        { return what.jwks }
+func (what *testOIDCConfig) GetOIDCGroupsClaim() string { return "groups" }
+func (what *testOIDCConfig) GetOIDCRolesClaim() string  { return "roles" }
+func (what *testOIDCConfig) GetOIDCSecretFile() string  { return "oidc-secret" }
+
+type testSigningKeys struct {
+	rsa      *rsa.PrivateKey
+	rsaKeyID string
+	ec       *ecdsa.PrivateKey
+	lock     sync.Mutex
+}
+
+func newTestSigningKeys(t *testing.T) *testSigningKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return &testSigningKeys{rsa: rsaKey, rsaKeyID: "rsa-key", ec: ecKey}
+}
+
+func (what *testSigningKeys) jwks() []byte {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+
+	encode := base64.RawURLEncoding.EncodeToString
+	ecKey, _ := what.ec.PublicKey.Bytes() // uncompressed point: 0x04 | x | y
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": what.rsaKeyID, "use": "sig", "n": encode(what.rsa.N.Bytes()), "e": encode(big.NewInt(int64(what.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-key", "use": "sig", "crv": "P-256", "x": encode(ecKey[1:33]), "y": encode(ecKey[33:])},
+	}})
+
+	return jwks
+}
+
+// sign creates a token; the algorithm decides which key signs it, "none" leaves the signature empty
+func (what *testSigningKeys) sign(t *testing.T, algorithm string, keyID string, claims map[string]any) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": algorithm, "kid": keyID, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signedContent := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signedContent))
+
+	what.lock.Lock()
+	defer what.lock.Unlock()
+
+	var signature []byte
+	switch algorithm {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, what.rsa, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+
+	case "ES256":
+		r, s, signError := ecdsa.Sign(rand.Reader, what.ec, digest[:])
+		require.NoError(t, signError)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signedContent + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validTestClaims() map[string]any {
+	return map[string]any{
+		"iss":   testIssuer,
+		"aud":   []string{"other-client", testAudience},
+		"sub":   "alice",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nbf":   time.Now().Add(-time.Minute).Unix(),
+		"roles": []string{"editor"},
+	}
+}
+
+// newTestVerifier serves the jwks of the keys via http; fetches counts the requests to the jwks endpoint
+func newTestVerifier(t *testing.T, keys *testSigningKeys) (verifier *oidcVerifier, fetches *atomic.Int32) {
+	t.Helper()
+
+	fetches = new(atomic.Int32)
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		_, _ = writer.Write(keys.jwks())
+	}))
+	t.Cleanup(jwksServer.Close)
+
+	verifier, err := newOIDCVerifier(&testOIDCConfig{serverFolder: t.TempDir(), audience: testAudience, jwks: jwksServer.URL})
+	require.NoError(t, err)
+
+	return verifier, fetches
+}
+
+func TestOIDCVerify(t *testing.T) {
+	keys := newTestSigningKeys(t)
+	verifier, _ := newTestVerifier(t, keys)
+
+	withClaim := func(name string, value any) map[string]any {
+		claims := validTestClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tamper := func(token string) string {
+		parts := strings.Split(token, ".")
+		claims := validTestClaims()
+		claims["roles"] = []string{"admin"}
+		payload, _ := json.Marshal(claims)
+		return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		error string
+	}{
+		{"valid rsa", keys.sign(t, "RS256", "rsa-key", validTestClaims()), ""},
+		{"valid ec", keys.sign(t, "ES256", "ec-key", validTestClaims()), ""},
+		{"single audience", keys.sign(t, "RS256", "rsa-key", withClaim("aud", testAudience)), ""},
+		{"expired", keys.sign(t, "RS256", "rsa-key", withClaim("exp", time.Now().Add(-time.Hour).Unix())), "token expired"},
+		{"without expiry", keys.sign(t, "RS256", "rsa-key", withClaim("exp", nil)), "token expired"},
+		{"not valid yet", keys.sign(t, "RS256", "rsa-key", withClaim("nbf", time.Now().Add(time.Hour).Unix())), "not valid yet"},
+		{"wrong issuer", keys.sign(t, "RS256", "rsa-key", withClaim("iss", "https://evil.example.com")), "unexpected issuer"},
+		{"wrong audience", keys.sign(t, "RS256", "rsa-key", withClaim("aud", "other-client")), "not meant for audience"},
+		{"without audience", keys.sign(t, "RS256", "rsa-key", withClaim("aud", nil)), "not meant for audience"},
+		{"without subject", keys.sign(t, "RS256", "rsa-key", withClaim("sub", nil)), "without subject"},
+		{"unknown kid", keys.sign(t, "RS256", "unknown-key", validTestClaims()), "unknown signing key"},
+		{"alg none", keys.sign(t, "none", "rsa-key", validTestClaims()), "unsupported token algorithm"},
+		{"alg of other key type", keys.sign(t, "RS256", "ec-key", validTestClaims()), "does not match signing key"},
+		{"tampered signature", tamper(keys.sign(t, "RS256", "rsa-key", validTestClaims())), "verification error"},
+		{"tampered ec signature", tamper(keys.sign(t, "ES256", "ec-key", validTestClaims())), "invalid token signature"},
+		{"malformed", "not-a-token", "malformed token"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims, err := verifier.verify(test.token)
+
+			if len(test.error) == 0 {
+				require.NoError(t, err)
+				assert.Equal(t, "alice", claims["sub"])
+				return
+			}
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.error)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
+func TestOIDCVerifierRequiresAudience(t *testing.T) {
+	_, err := newOIDCVerifier(&testOIDCConfig{serverFolder: t.TempDir(), jwks: "jwks.json"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audience")
+}
+
+func TestOIDCUnknownKeyReloadsJWKSOncePerMinute(t *testing.T) {
+	keys := newTestSigningKeys(t)
+	verifier, fetches := newTestVerifier(t, keys)
+	require.Equal(t, int32(1), fetches.Load())
+
+	// the provider rotates its rsa key
+	rotated := newTestSigningKeys(t)
+	keys.lock.Lock()
+	keys.rsa, keys.rsaKeyID = rotated.rsa, "rotated-key"
+	keys.lock.Unlock()
+	verifier.lastFetch = time.Now().Add(-2 * time.Minute)
+
+	_, err := verifier.verify(keys.sign(t, "RS256", "rotated-key", validTestClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	// unknown key ids don't trigger another reload within a minute
+	_, err = verifier.verify(keys.sign(t, "RS256", "unknown-key", validTestClaims()))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown signing key")
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestOIDCSlowJWKSDoesNotBlockKnownKeys(t *testing.T) {
+	keys := newTestSigningKeys(t)
+	release := make(chan struct{})
+	var slow atomic.Bool
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if slow.Load() {
+			<-release
+		}
+		_, _ = writer.Write(keys.jwks())
+	}))
+	defer jwksServer.Close()
+	defer close(release)
+
+	verifier, err := newOIDCVerifier(&testOIDCConfig{serverFolder: t.TempDir(), audience: testAudience, jwks: jwksServer.URL})
+	require.NoError(t, err)
+
+	slow.Store(true)
+	verifier.lastFetch = time.Time{}
+	reloading := make(chan struct{})
+	go func() {
+		defer close(reloading)
+		_, _ = verifier.verify(keys.sign(t, "RS256", "unknown-key", validTestClaims()))
+	}()
+
+	// wait until the reload is under way, then verify a token of a known key while the provider hangs
+	require.Eventually(t, func() bool {
+		verifier.lock.Lock()
+		defer verifier.lock.Unlock()
+		return !verifier.lastFetch.IsZero()
+	}, 5*time.Second, time.Millisecond)
+
+	verified := make(chan error, 1)
+	go func() {
+		_, verifyError := verifier.verify(keys.sign(t, "RS256", "rsa-key", validTestClaims()))
+		verified <- verifyError
+	}()
+
+	select {
+	case verifyError := <-verified:
+		assert.NoError(t, verifyError)
+	case <-time.After(5 * time.Second):
+		t.Fatal("verifying a token of a known key waited for the jwks reload")
+	}
+
+	select {
+	case <-reloading:
+		t.Fatal("the reload finished although the provider still hangs")
+	default:
+	}
+}
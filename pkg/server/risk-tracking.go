@@ -0,0 +1,213 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+type payloadRiskTracking struct {
+	Status        string `yaml:"status" json:"status"`
+	Justification string `yaml:"justification" json:"justification"`
+	Ticket        string `yaml:"ticket" json:"ticket"`
+	Date          string `yaml:"date" json:"date"`
+	CheckedBy     string `yaml:"checked_by" json:"checked_by"`
+}
+
+func (s *server) getRiskTrackings(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		ginContext.JSON(http.StatusOK, modelInput.RiskTracking)
+	}
+}
+
+func (s *server) getRiskTracking(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		syntheticRiskID := ginContext.Param("risk-id")
+		if riskTracking, exists := modelInput.RiskTracking[syntheticRiskID]; exists {
+			ginContext.JSON(http.StatusOK, gin.H{
+				syntheticRiskID: riskTracking,
+			})
+			return
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "risk tracking not found",
+		})
+	}
+}
+
+func (s *server) setRiskTracking(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		payload := payloadRiskTracking{}
+		err := ginContext.BindJSON(&payload)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": "unable to parse request payload",
+			})
+			return
+		}
+		syntheticRiskID := strings.TrimSpace(ginContext.Param("risk-id"))
+		ok = s.updateRiskTracking(ginContext, &modelInput, map[string]payloadRiskTracking{syntheticRiskID: payload})
+		if !ok {
+			return
+		}
+		ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Risk Tracking Update")
+		if ok {
+			ginContext.JSON(http.StatusOK, gin.H{
+				"message": "risk tracking updated",
+				"id":      syntheticRiskID,
+			})
+		}
+	}
+}
+
+func (s *server) setRiskTrackings(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		payload := make(map[string]payloadRiskTracking)
+		err := ginContext.BindJSON(&payload)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": "unable to parse request payload",
+			})
+			return
+		}
+		ok = s.updateRiskTracking(ginContext, &modelInput, payload)
+		if !ok {
+			return
+		}
+		ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Risk Tracking Bulk Update")
+		if ok {
+			ids := make([]string, 0, len(payload))
+			for syntheticRiskID := range payload {
+				ids = append(ids, strings.TrimSpace(syntheticRiskID))
+			}
+			sort.Strings(ids)
+			ginContext.JSON(http.StatusOK, gin.H{
+				"message": "risk tracking updated",
+				"ids":     ids,
+			})
+		}
+	}
+}
+
+func (s *server) deleteRiskTracking(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		syntheticRiskID := ginContext.Param("risk-id")
+		if _, exists := modelInput.RiskTracking[syntheticRiskID]; !exists {
+			ginContext.JSON(http.StatusNotFound, gin.H{
+				"error": "risk tracking not found",
+			})
+			return
+		}
+		delete(modelInput.RiskTracking, syntheticRiskID)
+		ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Risk Tracking Deletion")
+		if ok {
+			ginContext.JSON(http.StatusOK, gin.H{
+				"message": "risk tracking deleted",
+				"id":      syntheticRiskID,
+			})
+		}
+	}
+}
+
+// updateRiskTracking validates the given risk tracking entries against the current analysis of the model and applies
+// them; risk ids may contain wildcards, which have to match at least one identified risk
+func (s *server) updateRiskTracking(ginContext *gin.Context, modelInput *input.Model, payload map[string]payloadRiskTracking) (ok bool) {
+	progressReporter := DefaultProgressReporter{
+		Verbose:       s.config.GetVerbose(),
+		SuppressError: true,
+	}
+	result, err := model.AnalyzeModel(modelInput, s.config, s.builtinRiskRules, s.customRiskRules, progressReporter)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return false
+	}
+	riskTrackingInput := make(map[string]input.RiskTracking)
+	for syntheticRiskID, riskTracking := range payload {
+		syntheticRiskID = strings.TrimSpace(syntheticRiskID)
+		if !isIdentifiedRisk(result.ParsedModel, syntheticRiskID) {
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("risk id %q does not match any identified risk", syntheticRiskID),
+			})
+			return false
+		}
+		status, err := types.ParseRiskStatus(riskTracking.Status)
+		if err != nil {
+			handleErrorInServiceCall(err, ginContext)
+			return false
+		}
+		if len(riskTracking.Date) > 0 {
+			_, err = time.Parse("2006-01-02", riskTracking.Date)
+			if err != nil {
+				handleErrorInServiceCall(fmt.Errorf("unable to parse date %q of risk tracking %q (expected format is YYYY-MM-DD)", riskTracking.Date, syntheticRiskID), ginContext)
+				return false
+			}
+		}
+		riskTrackingInput[syntheticRiskID] = input.RiskTracking{
+			Status:        status.String(),
+			Justification: riskTracking.Justification,
+			Ticket:        riskTracking.Ticket,
+			Date:          riskTracking.Date,
+			CheckedBy:     riskTracking.CheckedBy,
+		}
+	}
+	if modelInput.RiskTracking == nil {
+		modelInput.RiskTracking = make(map[string]input.RiskTracking)
+	}
+	for syntheticRiskID, riskTracking := range riskTrackingInput {
+		modelInput.RiskTracking[syntheticRiskID] = riskTracking
+	}
+	return true
+}
+
+func isIdentifiedRisk(parsedModel *types.Model, syntheticRiskID string) bool {
+	if strings.Contains(syntheticRiskID, "*") {
+		return parsedModel.IsMatchingAnyGeneratedRisk(syntheticRiskID)
+	}
+	_, exists := parsedModel.GeneratedRisksBySyntheticId[strings.ToLower(syntheticRiskID)]
+	return exists
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/threagile/threagile/pkg/input"
+)
+
+// riskTrackingTestModel identifies the risks leak@web and crash@web
+const riskTrackingTestModel = `title: Risk Tracking Test
+business_criticality: important
+technical_assets:
+  Web Server:
+    id: web
+    type: process
+    usage: business
+    size: application
+    technology: web-server
+    machine: container
+    encryption: none
+    owner: Team
+    confidentiality: internal
+    integrity: operational
+    availability: operational
+custom_risk_categories:
+  - id: leak
+    title: Leak
+    function: architecture
+    stride: information-disclosure
+    risks_identified:
+      Leak at Web Server:
+        severity: high
+        exploitation_likelihood: likely
+        exploitation_impact: medium
+        data_breach_probability: possible
+        most_relevant_technical_asset: web
+  - id: crash
+    title: Crash
+    function: operations
+    stride: denial-of-service
+    risks_identified:
+      Crash at Web Server:
+        severity: medium
+        exploitation_likelihood: likely
+        exploitation_impact: low
+        data_breach_probability: improbable
+        most_relevant_technical_asset: web
+`
+
+func TestRiskTracking(t *testing.T) {
+	s := newTestServer(t)
+	s.router.GET("/models/:model-id/risk-tracking", s.getRiskTrackings)
+	s.router.PUT("/models/:model-id/risk-tracking", s.setRiskTrackings)
+	s.router.GET("/models/:model-id/risk-tracking/:risk-id", s.getRiskTracking)
+	s.router.PUT("/models/:model-id/risk-tracking/:risk-id", s.setRiskTracking)
+	s.router.DELETE("/models/:model-id/risk-tracking/:risk-id", s.deleteRiskTracking)
+
+	modelID, _ := s.createModel(t)
+	s.writeTestModel(t, modelID, riskTrackingTestModel)
+	path := "/models/" + modelID + "/risk-tracking"
+
+	var trackings map[string]input.RiskTracking
+	s.requestJSON(t, http.MethodGet, path, nil, nil, http.StatusOK, &trackings)
+	assert.Empty(t, trackings)
+
+	var updated struct{ ID string }
+	s.requestJSON(t, http.MethodPut, path+"/leak@web", nil, payloadRiskTracking{Status: "mitigated", Ticket: "SEC-1", Date: "2024-01-02"}, http.StatusOK, &updated)
+	assert.Equal(t, "leak@web", updated.ID)
+
+	var tracking map[string]input.RiskTracking
+	s.requestJSON(t, http.MethodGet, path+"/leak@web", nil, nil, http.StatusOK, &tracking)
+	assert.Equal(t, map[string]input.RiskTracking{"leak@web": {Status: "mitigated", Ticket: "SEC-1", Date: "2024-01-02"}}, tracking)
+
+	var bulkUpdated struct{ IDs []string }
+	s.requestJSON(t, http.MethodPut, path, nil, map[string]payloadRiskTracking{
+		"crash@*":  {Status: "accepted", Justification: "Restarts automatically"},
+		"leak@web": {Status: "in-progress"},
+	}, http.StatusOK, &bulkUpdated)
+	assert.Equal(t, []string{"crash@*", "leak@web"}, bulkUpdated.IDs)
+
+	var bulkTrackings map[string]input.RiskTracking
+	s.requestJSON(t, http.MethodGet, path, nil, nil, http.StatusOK, &bulkTrackings)
+	assert.Equal(t, map[string]input.RiskTracking{
+		"crash@*":  {Status: "accepted", Justification: "Restarts automatically"},
+		"leak@web": {Status: "in-progress"},
+	}, bulkTrackings)
+
+	s.requestJSON(t, http.MethodDelete, path+"/crash@*", nil, nil, http.StatusOK, nil)
+	s.requestJSON(t, http.MethodDelete, path+"/crash@*", nil, nil, http.StatusNotFound, nil)
+	s.requestJSON(t, http.MethodGet, path+"/crash@*", nil, nil, http.StatusNotFound, nil)
+
+	var remaining map[string]input.RiskTracking
+	s.requestJSON(t, http.MethodGet, path, nil, nil, http.StatusOK, &remaining)
+	assert.Equal(t, map[string]input.RiskTracking{"leak@web": {Status: "in-progress"}}, remaining)
+}
+
+func TestRiskTrackingValidation(t *testing.T) {
+	s := newTestServer(t)
+	s.router.GET("/models/:model-id/risk-tracking", s.getRiskTrackings)
+	s.router.PUT("/models/:model-id/risk-tracking", s.setRiskTrackings)
+	s.router.PUT("/models/:model-id/risk-tracking/:risk-id", s.setRiskTracking)
+
+	modelID, _ := s.createModel(t)
+	s.writeTestModel(t, modelID, riskTrackingTestModel)
+	path := "/models/" + modelID + "/risk-tracking"
+
+	tests := []struct {
+		name    string
+		path    string
+		payload any
+		err     string
+	}{
+		{"unknown risk", path + "/leak@database", payloadRiskTracking{Status: "mitigated"}, `risk id "leak@database" does not match any identified risk`},
+		{"unmatched wildcard", path + "/leak@*@database", payloadRiskTracking{Status: "mitigated"}, `risk id "leak@*@database" does not match any identified risk`},
+		{"unknown status", path + "/leak@web", payloadRiskTracking{Status: "ignored"}, "ignored"},
+		{"invalid date", path + "/leak@web", payloadRiskTracking{Status: "mitigated", Date: "02.01.2024"}, `unable to parse date "02.01.2024"`},
+		{"invalid entry in bulk update", path, map[string]payloadRiskTracking{
+			"leak@web":      {Status: "mitigated"},
+			"leak@database": {Status: "mitigated"},
+		}, `risk id "leak@database" does not match any identified risk`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var result struct{ Error string }
+			s.requestJSON(t, http.MethodPut, test.path, nil, test.payload, http.StatusBadRequest, &result)
+			assert.Contains(t, result.Error, test.err)
+		})
+	}
+
+	var tracking map[string]input.RiskTracking
+	s.requestJSON(t, http.MethodGet, path, nil, nil, http.StatusOK, &tracking)
+	assert.Empty(t, tracking, "rejected updates are not stored")
+}
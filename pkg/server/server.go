@@ -5,19 +5,25 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package server
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/threagile/threagile/pkg/macros"
 	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/risks/script"
 	"github.com/threagile/threagile/pkg/types"
 )
 
@@ -32,6 +38,15 @@ type serverConfigReader interface {
 	GetServerFolder() string
 	GetTempFolder() string
 	GetKeyFolder() string
+	GetServerStorage() string
+	GetServerDatabase() string
+	GetServerAuth() string
+	GetOIDCIssuer() string
+	GetOIDCAudience() string
+	GetOIDCJWKS() string
+	GetOIDCGroupsClaim() string
+	GetOIDCRolesClaim() string
+	GetOIDCSecretFile() string
 	GetInputFile() string
 	GetImportedInputFile() string
 	GetDataFlowDiagramFilenamePNG() string
@@ -50,6 +65,9 @@ type serverConfigReader interface {
 	GetTemplateFilename() string
 	GetTechnologyFilename() string
 	GetRiskRulePlugins() []string
+	GetPersistentRiskRulePlugins() []string
+	GetPluginTimeout() int
+	GetCustomRisksFile() string
 	GetSkipRiskRules() []string
 	GetExecuteModelMacro() string
 	GetServerMode() bool
@@ -66,30 +84,54 @@ type serverConfigReader interface {
 	GetProgressReporter() types.ProgressReporter
 }
 
+// shutdownTimeout is how long the server waits for running requests when it is asked to stop
+const shutdownTimeout = 30 * time.Second
+
 type server struct {
 	config                         serverConfigReader
+	storage                        Storage
+	oidc                           *oidcVerifier
 	successCount                   int
 	errorCount                     int
 	globalLock                     sync.Mutex
 	throttlerLock                  sync.Mutex
 	createdObjectsThrottler        map[string][]int64
-	mapTokenHashToTimeoutStruct    map[string]timeoutStruct
-	mapFolderNameToTokenHash       map[string]string
 	extremeShortTimeoutsForTesting bool
 	locksByFolderName              map[string]*sync.Mutex
 	builtinRiskRules               types.RiskRules
 	customRiskRules                types.RiskRules
+	customMacros                   []macros.Macros
+	macroSessions                  map[string]*macroSession
 }
 
-func RunServer(config serverConfigReader, builtinRiskRules types.RiskRules) {
+func RunServer(config serverConfigReader, builtinRiskRules types.RiskRules) error {
+	storage, err := OpenStorage(config.GetServerStorage(), config)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = storage.Close() }()
+
+	var oidc *oidcVerifier
+	switch config.GetServerAuth() {
+	case KeyAuth, "":
+	case OIDCAuth:
+		oidc, err = newOIDCVerifier(config)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown server authentication %q (supported are %q and %q)", config.GetServerAuth(), KeyAuth, OIDCAuth)
+	}
+
 	s := &server{
 		config:                         config,
+		storage:                        storage,
+		oidc:                           oidc,
 		createdObjectsThrottler:        make(map[string][]int64),
-		mapTokenHashToTimeoutStruct:    make(map[string]timeoutStruct),
-		mapFolderNameToTokenHash:       make(map[string]string),
 		extremeShortTimeoutsForTesting: false,
 		locksByFolderName:              make(map[string]*sync.Mutex),
 		builtinRiskRules:               builtinRiskRules,
+		macroSessions:                  make(map[string]*macroSession),
 	}
 	router := gin.Default()
 	router.LoadHTMLGlob(filepath.Join(s.config.GetServerFolder(), "static", "*.html")) // <==
@@ -163,8 +205,8 @@ func RunServer(config serverConfigReader, builtinRiskRules types.RiskRules) {
 		})
 	})
 
-	// TODO router.GET("/meta/risk-rules", listRiskRules)
-	// TODO router.GET("/meta/model-macros", listModelMacros)
+	router.GET("/meta/risk-rules", s.listRiskRules)
+	router.GET("/meta/model-macros", s.listModelMacros)
 
 	router.GET("/meta/stats", s.stats)
 
@@ -214,10 +256,20 @@ func RunServer(config serverConfigReader, builtinRiskRules types.RiskRules) {
 	router.DELETE("/models/:model-id/data-assets/:data-asset-id", s.deleteDataAsset)
 
 	router.GET("/models/:model-id/trust-boundaries", s.getTrustBoundaries)
-	//	router.POST("/models/:model-id/trust-boundaries", createNewTrustBoundary)
-	//	router.GET("/models/:model-id/trust-boundaries/:trust-boundary-id", getTrustBoundary)
-	//	router.PUT("/models/:model-id/trust-boundaries/:trust-boundary-id", setTrustBoundary)
-	//	router.DELETE("/models/:model-id/trust-boundaries/:trust-boundary-id", deleteTrustBoundary)
+	router.POST("/models/:model-id/trust-boundaries", s.createNewTrustBoundary)
+	router.GET("/models/:model-id/trust-boundaries/:trust-boundary-id", s.getTrustBoundary)
+	router.PUT("/models/:model-id/trust-boundaries/:trust-boundary-id", s.setTrustBoundary)
+	router.DELETE("/models/:model-id/trust-boundaries/:trust-boundary-id", s.deleteTrustBoundary)
+
+	router.POST("/models/:model-id/technical-assets", s.createNewTechnicalAsset)
+	router.GET("/models/:model-id/technical-assets/:technical-asset-id", s.getTechnicalAsset)
+	router.PUT("/models/:model-id/technical-assets/:technical-asset-id", s.setTechnicalAsset)
+	router.DELETE("/models/:model-id/technical-assets/:technical-asset-id", s.deleteTechnicalAsset)
+	router.GET("/models/:model-id/technical-assets/:technical-asset-id/communication-links", s.getCommunicationLinks)
+	router.POST("/models/:model-id/technical-assets/:technical-asset-id/communication-links", s.createNewCommunicationLink)
+	router.GET("/models/:model-id/technical-assets/:technical-asset-id/communication-links/:communication-link-id", s.getCommunicationLink)
+	router.PUT("/models/:model-id/technical-assets/:technical-asset-id/communication-links/:communication-link-id", s.setCommunicationLink)
+	router.DELETE("/models/:model-id/technical-assets/:technical-asset-id/communication-links/:communication-link-id", s.deleteCommunicationLink)
 
 	router.GET("/models/:model-id/shared-runtimes", s.getSharedRuntimes)
 	router.POST("/models/:model-id/shared-runtimes", s.createNewSharedRuntime)
@@ -225,10 +277,48 @@ func RunServer(config serverConfigReader, builtinRiskRules types.RiskRules) {
 	router.PUT("/models/:model-id/shared-runtimes/:shared-runtime-id", s.setSharedRuntime)
 	router.DELETE("/models/:model-id/shared-runtimes/:shared-runtime-id", s.deleteSharedRuntime)
 
-	s.customRiskRules = model.LoadCustomRiskRules(s.config.GetPluginFolder(), s.config.GetRiskRulePlugins(), config.GetProgressReporter())
+	router.GET("/models/:model-id/risk-tracking", s.getRiskTrackings)
+	router.PUT("/models/:model-id/risk-tracking", s.setRiskTrackings)
+	router.GET("/models/:model-id/risk-tracking/:risk-id", s.getRiskTracking)
+	router.PUT("/models/:model-id/risk-tracking/:risk-id", s.setRiskTracking)
+	router.DELETE("/models/:model-id/risk-tracking/:risk-id", s.deleteRiskTracking)
+
+	router.POST("/models/:model-id/macros/:macro-id", s.runModelMacro)
+
+	router.GET("/models/:model-id/history", s.getHistory)
+	router.GET("/models/:model-id/history/:history-id", s.getHistoryModel)
+	router.GET("/models/:model-id/history/:history-id/diff", s.diffHistoryModel)
+	router.POST("/models/:model-id/history/:history-id/restore", s.restoreHistoryModel)
+
+	s.customRiskRules = model.LoadCustomRiskRules(s.config, config.GetProgressReporter())
+	defer model.CloseCustomRiskRules(s.customRiskRules)
+	s.customMacros = macros.ListCustomMacros(s.config.GetPluginFolder(), config.GetProgressReporter())
+
+	// stop gracefully on SIGINT or SIGTERM, so the persistent risk rule plugins and the storage get closed
+	stopContext, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:    ":" + strconv.Itoa(s.config.GetServerPort()), // listen and serve on 0.0.0.0:8080 or whatever port was specified
+		Handler: router.Handler(),
+	}
+
+	serveError := make(chan error, 1)
+	go func() { serveError <- httpServer.ListenAndServe() }()
 
 	fmt.Println("Threagile is running...")
-	_ = router.Run(":" + strconv.Itoa(s.config.GetServerPort())) // listen and serve on 0.0.0.0:8080 or whatever port was specified
+	select {
+	case err = <-serveError:
+		return err
+
+	case <-stopContext.Done():
+	}
+
+	fmt.Println("Threagile is shutting down...")
+	shutdownContext, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return httpServer.Shutdown(shutdownContext)
 }
 
 func (s *server) exampleFile(ginContext *gin.Context) {
@@ -297,9 +387,69 @@ func arrayOfStringValues(values []types.TypeEnum) []string {
 	return result
 }
 
+type riskRuleDetails struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	STRIDE        string   `json:"stride"`
+	Function      string   `json:"function"`
+	CWE           int      `json:"cwe,omitempty"`
+	SupportedTags []string `json:"supported_tags"`
+	Source        string   `json:"source"`
+}
+
+func (s *server) listRiskRules(ginContext *gin.Context) {
+	riskRules := make([]riskRuleDetails, 0)
+	for _, rule := range s.builtinRiskRules {
+		source := "builtin"
+		if _, isScript := rule.(*script.RiskRule); isScript {
+			source = "script"
+		}
+		riskRules = append(riskRules, newRiskRuleDetails(rule, source))
+	}
+	for _, rule := range s.customRiskRules {
+		riskRules = append(riskRules, newRiskRuleDetails(rule, "custom"))
+	}
+	sort.Slice(riskRules, func(i, j int) bool {
+		return riskRules[i].ID < riskRules[j].ID
+	})
+	ginContext.JSON(http.StatusOK, riskRules)
+}
+
+func newRiskRuleDetails(rule types.RiskRule, source string) riskRuleDetails {
+	category := rule.Category()
+	supportedTags := rule.SupportedTags()
+	if supportedTags == nil {
+		supportedTags = make([]string, 0)
+	}
+	return riskRuleDetails{
+		ID:            category.ID,
+		Title:         category.Title,
+		STRIDE:        category.STRIDE.String(),
+		Function:      category.Function.String(),
+		CWE:           category.CWE,
+		SupportedTags: supportedTags,
+		Source:        source,
+	}
+}
+
+type modelMacroDetails struct {
+	macros.MacroDetails
+	Source string `json:"source"`
+}
+
+func (s *server) listModelMacros(ginContext *gin.Context) {
+	modelMacros := make([]modelMacroDetails, 0)
+	for _, macro := range macros.ListBuiltInMacros() {
+		modelMacros = append(modelMacros, modelMacroDetails{MacroDetails: macro.GetMacroDetails(), Source: "builtin"})
+	}
+	for _, macro := range s.customMacros {
+		modelMacros = append(modelMacros, modelMacroDetails{MacroDetails: macro.GetMacroDetails(), Source: "custom"})
+	}
+	ginContext.JSON(http.StatusOK, modelMacros)
+}
+
 func (s *server) stats(ginContext *gin.Context) {
-	keyCount, modelCount := 0, 0
-	keyFolders, err := os.ReadDir(filepath.Join(s.config.GetServerFolder(), s.config.GetKeyFolder()))
+	keyIDs, err := s.storage.ListKeys()
 	if err != nil {
 		log.Println(err)
 		ginContext.JSON(http.StatusInternalServerError, gin.H{
@@ -307,33 +457,21 @@ func (s *server) stats(ginContext *gin.Context) {
 		})
 		return
 	}
-	for _, keyFolder := range keyFolders {
-		if len(keyFolder.Name()) == 128 { // it's a sha512 token hash probably, so count it as token folder for the stats
-			keyCount++
-			if keyFolder.Name() != filepath.Clean(keyFolder.Name()) {
-				ginContext.JSON(http.StatusInternalServerError, gin.H{
-					"error": "weird file path",
-				})
-				return
-			}
-			modelFolders, err := os.ReadDir(filepath.Join(s.config.GetServerFolder(), s.config.GetKeyFolder(), keyFolder.Name()))
-			if err != nil {
-				log.Println(err)
-				ginContext.JSON(http.StatusInternalServerError, gin.H{
-					"error": "unable to collect stats",
-				})
-				return
-			}
-			for _, modelFolder := range modelFolders {
-				if len(modelFolder.Name()) == 36 { // it's a uuid model folder probably, so count it as model folder for the stats
-					modelCount++
-				}
-			}
+	modelCount := 0
+	for _, keyID := range keyIDs {
+		storedModels, err := s.storage.ListModels(keyID)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusInternalServerError, gin.H{
+				"error": "unable to collect stats",
+			})
+			return
 		}
+		modelCount += len(storedModels)
 	}
 	// TODO collect and deliver more stats (old model count?) and health info
 	ginContext.JSON(http.StatusOK, gin.H{
-		"key_count":     keyCount,
+		"key_count":     len(keyIDs),
 		"model_count":   modelCount,
 		"success_count": s.successCount,
 		"error_count":   s.errorCount,
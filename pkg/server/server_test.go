@@ -0,0 +1,263 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/macros"
+	"github.com/threagile/threagile/pkg/model"
+	"github.com/threagile/threagile/pkg/risks"
+	"github.com/threagile/threagile/pkg/risks/builtin"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+// testServerConfig provides the settings the model endpoints need, calling any other getter panics
+type testServerConfig struct {
+	serverConfigReader
+	serverFolder string
+}
+
+func (what *testServerConfig) GetVerbose() bool                            { return false }
+func (what *testServerConfig) GetAppFolder() string                        { return what.serverFolder }
+func (what *testServerConfig) GetServerFolder() string                     { return what.serverFolder }
+func (what *testServerConfig) GetTempFolder() string                       { return what.serverFolder }
+func (what *testServerConfig) GetKeyFolder() string                        { return "keys" }
+func (what *testServerConfig) GetInputFile() string                        { return "threagile.yaml" }
+func (what *testServerConfig) GetServerDatabase() string                   { return "threagile.db" }
+func (what *testServerConfig) GetBackupHistoryFilesToKeep() int            { return 5 }
+func (what *testServerConfig) GetThreagileVersion() string                 { return "1.0.0" }
+func (what *testServerConfig) GetTechnologyFilename() string               { return "" }
+func (what *testServerConfig) GetSkipRiskRules() []string                  { return nil }
+func (what *testServerConfig) GetIgnoreOrphanedRiskTracking() bool         { return false }
+func (what *testServerConfig) GetProgressReporter() types.ProgressReporter { return nil }
+
+type testServer struct {
+	*server
+	router *gin.Engine
+	key    []byte
+	token  string
+}
+
+// newTestServer starts a server on a filesystem storage with the model endpoints and creates a key and a token for it
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	config := &testServerConfig{serverFolder: t.TempDir()}
+	storage, err := OpenStorage(FilesystemStorage, config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	s := &testServer{
+		server: &server{
+			config:                  config,
+			storage:                 storage,
+			createdObjectsThrottler: make(map[string][]int64),
+			locksByFolderName:       make(map[string]*sync.Mutex),
+			builtinRiskRules:        make(types.RiskRules),
+			customRiskRules:         make(types.RiskRules),
+			macroSessions:           make(map[string]*macroSession),
+		},
+		router: gin.New(),
+	}
+
+	s.router.POST("/auth/keys", s.createKey)
+	s.router.POST("/auth/tokens", s.createToken)
+	s.router.POST("/models", s.createNewModel)
+	s.router.DELETE("/models/:model-id", s.deleteModel)
+	s.router.GET("/models/:model-id/cover", s.getCover)
+	s.router.PUT("/models/:model-id/cover", s.setCover)
+
+	var key struct{ Key string }
+	s.requestJSON(t, http.MethodPost, "/auth/keys", nil, nil, http.StatusCreated, &key)
+	var token struct{ Token string }
+	s.requestJSON(t, http.MethodPost, "/auth/tokens", map[string]string{"key": key.Key}, nil, http.StatusCreated, &token)
+	s.token = token.Token
+	s.key, err = base64.RawURLEncoding.DecodeString(key.Key)
+	require.NoError(t, err)
+
+	return s
+}
+
+// request sends a request with the token of the server and the given headers and payload (marshalled as JSON)
+func (what *testServer) request(t *testing.T, method string, path string, headers map[string]string, payload any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	if payload != nil {
+		require.NoError(t, json.NewEncoder(&body).Encode(payload))
+	}
+
+	request := httptest.NewRequest(method, path, &body)
+	request.Header.Set("token", what.token)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response := httptest.NewRecorder()
+	what.router.ServeHTTP(response, request)
+
+	return response
+}
+
+// requestJSON sends a request, checks the status code and reads the JSON response into the result unless it is nil
+func (what *testServer) requestJSON(t *testing.T, method string, path string, headers map[string]string, payload any, status int, result any) http.Header {
+	t.Helper()
+
+	response := what.request(t, method, path, headers, payload)
+	require.Equal(t, status, response.Code, "%v %v: %v", method, path, response.Body.String())
+	if result != nil {
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), result), response.Body.String())
+	}
+
+	return response.Header()
+}
+
+// createModel creates an empty model and provides its id and ETag
+func (what *testServer) createModel(t *testing.T) (id string, eTag string) {
+	t.Helper()
+
+	var created struct{ ID string }
+	header := what.requestJSON(t, http.MethodPost, "/models", nil, nil, http.StatusCreated, &created)
+	require.NotEmpty(t, header.Get("ETag"))
+
+	return created.ID, header.Get("ETag")
+}
+
+// writeTestModel replaces the content of a model, bypassing the validation of the endpoints
+func (what *testServer) writeTestModel(t *testing.T, modelID string, yaml string) {
+	t.Helper()
+
+	response := httptest.NewRecorder()
+	ginContext, _ := gin.CreateTestContext(response)
+	require.True(t, what.writeModelYAML(ginContext, yaml, what.key, what.folderNameFromKey(what.key), modelID, "Test", true), response.Body.String())
+}
+
+func TestListRiskRules(t *testing.T) {
+	scriptRules, err := risks.GetScriptRiskRules()
+	require.NoError(t, err)
+	require.Contains(t, scriptRules, "accidental-secret-leak")
+
+	s := newTestServer(t)
+	s.builtinRiskRules = types.RiskRules{
+		"code-backdooring":       builtin.NewCodeBackdooringRule(),
+		"accidental-secret-leak": scriptRules["accidental-secret-leak"],
+	}
+	s.customRiskRules = types.RiskRules{
+		"custom-leak": new(model.CustomRiskCategory).Init(&types.RiskCategory{ID: "custom-leak", Title: "Custom Leak", STRIDE: types.InformationDisclosure, Function: types.Architecture}, []string{"pci"}),
+	}
+	s.router.GET("/meta/risk-rules", s.listRiskRules)
+
+	var riskRules []riskRuleDetails
+	s.requestJSON(t, http.MethodGet, "/meta/risk-rules", nil, nil, http.StatusOK, &riskRules)
+
+	require.Len(t, riskRules, 3)
+	sources := make(map[string]string)
+	for _, rule := range riskRules {
+		sources[rule.ID] = rule.Source
+	}
+	assert.Equal(t, map[string]string{"accidental-secret-leak": "script", "code-backdooring": "builtin", "custom-leak": "custom"}, sources)
+	assert.Equal(t, riskRuleDetails{
+		ID:            "custom-leak",
+		Title:         "Custom Leak",
+		STRIDE:        types.InformationDisclosure.String(),
+		Function:      types.Architecture.String(),
+		SupportedTags: []string{"pci"},
+		Source:        "custom",
+	}, riskRules[2], "the rules are sorted by id")
+}
+
+func TestListModelMacros(t *testing.T) {
+	s := newTestServer(t)
+	s.customMacros = loadTestCustomMacros(t)
+	s.router.GET("/meta/model-macros", s.listModelMacros)
+
+	var modelMacros []modelMacroDetails
+	s.requestJSON(t, http.MethodGet, "/meta/model-macros", nil, nil, http.StatusOK, &modelMacros)
+
+	require.Len(t, modelMacros, len(macros.ListBuiltInMacros())+1)
+	sources := make(map[string]string)
+	for _, macro := range modelMacros {
+		sources[macro.ID] = macro.Source
+	}
+	assert.Equal(t, "builtin", sources["add-identity-provider"])
+	assert.Equal(t, "custom", sources["add-data-asset"])
+	assert.Equal(t, modelMacroDetails{MacroDetails: macros.MacroDetails{ID: "add-data-asset", Title: "Add Data Asset"}, Source: "custom"}, modelMacros[len(modelMacros)-1])
+}
+
+// runServerTestConfig adds the settings RunServer needs to start
+type runServerTestConfig struct {
+	testServerConfig
+	port int
+}
+
+func (what *runServerTestConfig) GetServerStorage() string               { return SQLiteStorage }
+func (what *runServerTestConfig) GetServerAuth() string                  { return KeyAuth }
+func (what *runServerTestConfig) GetServerPort() int                     { return what.port }
+func (what *runServerTestConfig) GetPluginFolder() string                { return what.serverFolder }
+func (what *runServerTestConfig) GetRiskRulePlugins() []string           { return nil }
+func (what *runServerTestConfig) GetPersistentRiskRulePlugins() []string { return nil }
+func (what *runServerTestConfig) GetPluginTimeout() int                  { return 0 }
+func (what *runServerTestConfig) GetCustomRisksFile() string             { return "" }
+func (what *runServerTestConfig) GetProgressReporter() types.ProgressReporter {
+	return DefaultProgressReporter{}
+}
+
+func TestRunServerStopsOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals can't be sent to a process on windows")
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, signal := range []os.Signal{os.Interrupt, syscall.SIGTERM} {
+		t.Run(signal.String(), func(t *testing.T) {
+			config := &runServerTestConfig{testServerConfig: testServerConfig{serverFolder: t.TempDir()}}
+			require.NoError(t, os.MkdirAll(filepath.Join(config.serverFolder, "static"), 0700))
+			require.NoError(t, os.WriteFile(filepath.Join(config.serverFolder, "static", "index.html"), []byte("threagile"), 0600))
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			config.port = listener.Addr().(*net.TCPAddr).Port
+			require.NoError(t, listener.Close())
+
+			stopped := make(chan error, 1)
+			go func() { stopped <- RunServer(config, make(types.RiskRules)) }()
+
+			url := "http://127.0.0.1:" + strconv.Itoa(config.port) + "/meta/ping"
+			require.Eventually(t, func() bool {
+				response, pingError := http.Get(url) // #nosec G107
+				if pingError != nil {
+					return false
+				}
+				_ = response.Body.Close()
+				return response.StatusCode == http.StatusOK
+			}, 10*time.Second, 50*time.Millisecond, "server did not start")
+
+			process, err := os.FindProcess(os.Getpid())
+			require.NoError(t, err)
+			require.NoError(t, process.Signal(signal))
+
+			select {
+			case err = <-stopped:
+				assert.NoError(t, err, "stopping is no error")
+			case <-time.After(shutdownTimeout):
+				t.Fatal("server did not stop")
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// tokenFolderName is the folder of the filesystem storage holding a file per token (named by the token hash)
+const tokenFolderName = "tokens"
+
+// filesystemStorage keeps a folder per key (named by the key hash) with a sub-folder per model (named by its UUID),
+// which holds the model file and a history folder with the backups. The version checks of WriteModel and BackupModel
+// and the changes to tokens are serialized by a lock held in memory, so they don't hold against other server
+// processes sharing the same folder.
+type filesystemStorage struct {
+	folder    string
+	modelFile string
+	lock      sync.Mutex
+}
+
+type filesystemToken struct {
+	KeyID        string    `json:"key_id"`
+	XorRand      []byte    `json:"xor_rand"`
+	Created      time.Time `json:"created"`
+	LastAccessed time.Time `json:"last_accessed"`
+}
+
+func newFilesystemStorage(folder string, modelFile string) (*filesystemStorage, error) {
+	err := os.MkdirAll(folder, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key dir %q: %w", folder, err)
+	}
+	return &filesystemStorage{
+		folder:    folder,
+		modelFile: filepath.Base(modelFile),
+	}, nil
+}
+
+// path joins the given names to a path inside the storage folder, rejecting anything but plain file names to prevent
+// path traversal
+func (what *filesystemStorage) path(names ...string) (string, error) {
+	for _, name := range names {
+		if len(name) == 0 || name != filepath.Base(name) || name == "." || name == ".." {
+			return "", fmt.Errorf("weird file name %q", name)
+		}
+	}
+	return filepath.Join(append([]string{what.folder}, names...)...), nil
+}
+
+func (what *filesystemStorage) CreateKey(keyID string) error {
+	keyFolder, err := what.path(keyID)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(keyFolder, 0700)
+}
+
+func (what *filesystemStorage) KeyExists(keyID string) (bool, error) {
+	keyFolder, err := what.path(keyID)
+	if err != nil {
+		return false, err
+	}
+	return fileExists(keyFolder)
+}
+
+func (what *filesystemStorage) DeleteKey(keyID string) error {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+	keyFolder, err := what.path(keyID)
+	if err != nil {
+		return err
+	}
+	err = what.deleteTokens(func(token filesystemToken) bool { return token.KeyID == keyID })
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(keyFolder)
+}
+
+func (what *filesystemStorage) ListKeys() ([]string, error) {
+	keyIDs := make([]string, 0)
+	keyFolders, err := os.ReadDir(what.folder)
+	if err != nil {
+		return nil, err
+	}
+	for _, keyFolder := range keyFolders {
+		if keyFolder.IsDir() && len(keyFolder.Name()) == 128 { // it's a sha512 key hash probably, so take it as key folder
+			keyIDs = append(keyIDs, keyFolder.Name())
+		}
+	}
+	return keyIDs, nil
+}
+
+func (what *filesystemStorage) ListModels(keyID string) ([]StoredModel, error) {
+	keyFolder, err := what.path(keyID)
+	if err != nil {
+		return nil, err
+	}
+	modelFolders, err := os.ReadDir(keyFolder)
+	if err != nil {
+		return nil, err
+	}
+	storedModels := make([]StoredModel, 0)
+	for _, modelFolder := range modelFolders {
+		if _, uuidError := uuid.Parse(modelFolder.Name()); !modelFolder.IsDir() || uuidError != nil {
+			continue
+		}
+		folderInfo, err := modelFolder.Info()
+		if err != nil {
+			return nil, err
+		}
+		fileInfo, err := os.Stat(filepath.Join(keyFolder, modelFolder.Name(), what.modelFile))
+		if err != nil {
+			return nil, err
+		}
+		storedModels = append(storedModels, StoredModel{
+			ID:       modelFolder.Name(),
+			Created:  folderInfo.ModTime(),
+			Modified: fileInfo.ModTime(),
+		})
+	}
+	return storedModels, nil
+}
+
+func (what *filesystemStorage) ModelExists(keyID string, modelID string) (bool, error) {
+	modelFolder, err := what.path(keyID, modelID)
+	if err != nil {
+		return false, err
+	}
+	return fileExists(modelFolder)
+}
+
+func (what *filesystemStorage) ReadModel(keyID string, modelID string) ([]byte, error) {
+	filename, err := what.path(keyID, modelID, what.modelFile)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Clean(filename))
+}
+
+func (what *filesystemStorage) WriteModel(keyID string, modelID string, version string, content []byte) error {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+	if len(version) > 0 {
+		if _, err := what.readModelOfVersion(keyID, modelID, version); err != nil {
+			return err
+		}
+	}
+	return what.writeModel(keyID, modelID, content)
+}
+
+func (what *filesystemStorage) writeModel(keyID string, modelID string, content []byte) error {
+	modelFolder, err := what.path(keyID, modelID)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(modelFolder, 0700)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(modelFolder, what.modelFile), content, 0600)
+}
+
+func (what *filesystemStorage) DeleteModel(keyID string, modelID string) error {
+	modelFolder, err := what.path(keyID, modelID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(modelFolder)
+}
+
+func (what *filesystemStorage) BackupModel(keyID string, modelID string, version string, reason string, historyEntriesToKeep int) error {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+	historyFolder, err := what.path(keyID, modelID, historyFolderName)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(historyFolder, 0700)
+	if err != nil {
+		return err
+	}
+	content, err := what.readModelOfVersion(keyID, modelID, version)
+	if err != nil {
+		return err
+	}
+	historyID, err := newHistoryID(reason, func(historyID string) (bool, error) {
+		return fileExists(filepath.Join(historyFolder, historyID+historyFileExtension))
+	})
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(historyFolder, historyID+historyFileExtension), content, 0400) // #nosec G703
+	if err != nil {
+		return err
+	}
+	// now delete any old files if over limit to keep (the ids start with the timestamp, so they sort by age)
+	historyIDs, err := what.ListHistory(keyID, modelID)
+	if err != nil {
+		return err
+	}
+	if len(historyIDs) > historyEntriesToKeep {
+		for _, oldHistoryID := range historyIDs[:len(historyIDs)-historyEntriesToKeep] {
+			filename, err := what.path(keyID, modelID, historyFolderName, oldHistoryID+historyFileExtension)
+			if err != nil {
+				return err
+			}
+			err = os.Remove(filename)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (what *filesystemStorage) ListHistory(keyID string, modelID string) ([]string, error) {
+	historyIDs := make([]string, 0)
+	historyFolder, err := what.path(keyID, modelID, historyFolderName)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(historyFolder)
+	if os.IsNotExist(err) {
+		return historyIDs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), historyFileExtension) {
+			historyIDs = append(historyIDs, strings.TrimSuffix(file.Name(), historyFileExtension))
+		}
+	}
+	sort.Strings(historyIDs) // by id, as the file extension would sort "x.backup" after "x (2).backup"
+	return historyIDs, nil
+}
+
+func (what *filesystemStorage) ReadHistory(keyID string, modelID string, historyID string) ([]byte, error) {
+	filename, err := what.path(keyID, modelID, historyFolderName, historyID+historyFileExtension)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Clean(filename))
+}
+
+func (what *filesystemStorage) ImportModel(keyID string, storedModel StoredModel, content []byte) error {
+	err := what.writeModel(keyID, storedModel.ID, content)
+	if err != nil {
+		return err
+	}
+	modelFolder, err := what.path(keyID, storedModel.ID)
+	if err != nil {
+		return err
+	}
+	err = os.Chtimes(filepath.Join(modelFolder, what.modelFile), time.Time{}, storedModel.Modified)
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(modelFolder, time.Time{}, storedModel.Created)
+}
+
+func (what *filesystemStorage) ImportHistory(keyID string, modelID string, historyID string, content []byte) error {
+	filename, err := what.path(keyID, modelID, historyFolderName, historyID+historyFileExtension)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+	_ = os.Remove(filename)                      // backups are read-only, so replace instead of overwriting them
+	return os.WriteFile(filename, content, 0400) // #nosec G703
+}
+
+func (what *filesystemStorage) CreateToken(token StoredToken) error {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+	err := what.deleteTokens(func(other filesystemToken) bool { return other.KeyID == token.KeyID })
+	if err != nil {
+		return err
+	}
+	return what.writeToken(token.Hash, filesystemToken{
+		KeyID:        token.KeyID,
+		XorRand:      token.XorRand,
+		Created:      token.Created,
+		LastAccessed: token.LastAccessed,
+	})
+}
+
+func (what *filesystemStorage) ReadToken(tokenHash string) (StoredToken, bool, error) {
+	token, exists, err := what.readToken(tokenHash)
+	if !exists || err != nil {
+		return StoredToken{Hash: tokenHash}, false, err
+	}
+	return StoredToken{
+		Hash:         tokenHash,
+		KeyID:        token.KeyID,
+		XorRand:      token.XorRand,
+		Created:      token.Created,
+		LastAccessed: token.LastAccessed,
+	}, true, nil
+}
+
+func (what *filesystemStorage) TouchToken(tokenHash string, lastAccessed time.Time) error {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+	token, exists, err := what.readToken(tokenHash)
+	if !exists || err != nil {
+		return err
+	}
+	token.LastAccessed = lastAccessed
+	return what.writeToken(tokenHash, token)
+}
+
+func (what *filesystemStorage) DeleteToken(tokenHash string) error {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+	filename, err := what.path(tokenFolderName, tokenHash)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (what *filesystemStorage) DeleteExpiredTokens(lastAccessedBefore time.Time, createdBefore time.Time) error {
+	what.lock.Lock()
+	defer what.lock.Unlock()
+	return what.deleteTokens(func(token filesystemToken) bool {
+		return token.LastAccessed.Before(lastAccessedBefore) || token.Created.Before(createdBefore)
+	})
+}
+
+func (what *filesystemStorage) Close() error {
+	return nil
+}
+
+// readModelOfVersion reads the content of a model, failing with ErrModelChanged when it is not of the given version
+// (any version is fine when empty)
+func (what *filesystemStorage) readModelOfVersion(keyID string, modelID string, version string) ([]byte, error) {
+	content, err := what.ReadModel(keyID, modelID)
+	if errors.Is(err, os.ErrNotExist) && len(version) > 0 {
+		return nil, ErrModelChanged // deleted in the meantime
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(version) > 0 && modelVersion(content) != version {
+		return nil, ErrModelChanged
+	}
+	return content, nil
+}
+
+func (what *filesystemStorage) readToken(tokenHash string) (filesystemToken, bool, error) {
+	var token filesystemToken
+	filename, err := what.path(tokenFolderName, tokenHash)
+	if err != nil {
+		return token, false, err
+	}
+	content, err := os.ReadFile(filepath.Clean(filename))
+	if os.IsNotExist(err) {
+		return token, false, nil
+	}
+	if err != nil {
+		return token, false, err
+	}
+	return token, true, json.Unmarshal(content, &token)
+}
+
+func (what *filesystemStorage) writeToken(tokenHash string, token filesystemToken) error {
+	filename, err := what.path(tokenFolderName, tokenHash)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, content, 0600)
+}
+
+// deleteTokens removes all tokens matching the given condition
+func (what *filesystemStorage) deleteTokens(matches func(token filesystemToken) bool) error {
+	tokenFolder, err := what.path(tokenFolderName)
+	if err != nil {
+		return err
+	}
+	files, err := os.ReadDir(tokenFolder)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		token, exists, err := what.readToken(file.Name())
+		if err != nil {
+			return err
+		}
+		if exists && matches(token) {
+			err = os.Remove(filepath.Join(tokenFolder, file.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fileExists(filename string) (bool, error) {
+	_, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // pure go driver, so no cgo is required
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS keys (
+	id      TEXT PRIMARY KEY,
+	created INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS models (
+	key_id   TEXT NOT NULL,
+	id       TEXT NOT NULL,
+	content  BLOB NOT NULL,
+	created  INTEGER NOT NULL,
+	modified INTEGER NOT NULL,
+	PRIMARY KEY (key_id, id)
+);
+CREATE TABLE IF NOT EXISTS history (
+	key_id   TEXT NOT NULL,
+	model_id TEXT NOT NULL,
+	id       TEXT NOT NULL,
+	content  BLOB NOT NULL,
+	PRIMARY KEY (key_id, model_id, id)
+);
+CREATE TABLE IF NOT EXISTS tokens (
+	hash          TEXT PRIMARY KEY,
+	key_id        TEXT NOT NULL UNIQUE,
+	xor_rand      BLOB NOT NULL,
+	created       INTEGER NOT NULL,
+	last_accessed INTEGER NOT NULL
+);`
+
+// sqliteStorage keeps keys, models, history and tokens in a single embedded database file; timestamps are stored as
+// unix nanos. Transactions take the write lock of the database upfront, so the version checks of WriteModel and
+// BackupModel also hold against other server processes using the same database file.
+type sqliteStorage struct {
+	db *sql.DB
+}
+
+func newSQLiteStorage(filename string) (*sqliteStorage, error) {
+	err := os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database dir %q: %w", filepath.Dir(filename), err)
+	}
+	// create the database file upfront to not leave it readable for others
+	file, err := os.OpenFile(filepath.Clean(filename), os.O_RDONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create database %q: %w", filename, err)
+	}
+	_ = file.Close()
+
+	db, err := sql.Open("sqlite", "file:"+filename+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %q: %w", filename, err)
+	}
+	db.SetMaxOpenConns(1) // sqlite allows a single writer only anyway
+	_, err = db.Exec(sqliteSchema)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create tables in database %q: %w", filename, err)
+	}
+	return &sqliteStorage{db: db}, nil
+}
+
+func (what *sqliteStorage) CreateKey(keyID string) error {
+	_, err := what.db.Exec(`INSERT OR IGNORE INTO keys (id, created) VALUES (?, ?)`, keyID, time.Now().UnixNano())
+	return err
+}
+
+func (what *sqliteStorage) KeyExists(keyID string) (bool, error) {
+	return what.exists(`SELECT 1 FROM keys WHERE id = ?`, keyID)
+}
+
+func (what *sqliteStorage) DeleteKey(keyID string) error {
+	return what.inTransaction(func(tx *sql.Tx) error {
+		for _, statement := range []string{
+			`DELETE FROM tokens WHERE key_id = ?`,
+			`DELETE FROM history WHERE key_id = ?`,
+			`DELETE FROM models WHERE key_id = ?`,
+			`DELETE FROM keys WHERE id = ?`,
+		} {
+			if _, err := tx.Exec(statement, keyID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (what *sqliteStorage) ListKeys() ([]string, error) {
+	rows, err := what.db.Query(`SELECT id FROM keys ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	keyIDs := make([]string, 0)
+	for rows.Next() {
+		var keyID string
+		if err = rows.Scan(&keyID); err != nil {
+			return nil, err
+		}
+		keyIDs = append(keyIDs, keyID)
+	}
+	return keyIDs, rows.Err()
+}
+
+func (what *sqliteStorage) ListModels(keyID string) ([]StoredModel, error) {
+	rows, err := what.db.Query(`SELECT id, created, modified FROM models WHERE key_id = ? ORDER BY id`, keyID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	storedModels := make([]StoredModel, 0)
+	for rows.Next() {
+		var modelID string
+		var created, modified int64
+		if err = rows.Scan(&modelID, &created, &modified); err != nil {
+			return nil, err
+		}
+		storedModels = append(storedModels, StoredModel{
+			ID:       modelID,
+			Created:  time.Unix(0, created),
+			Modified: time.Unix(0, modified),
+		})
+	}
+	return storedModels, rows.Err()
+}
+
+func (what *sqliteStorage) ModelExists(keyID string, modelID string) (bool, error) {
+	return what.exists(`SELECT 1 FROM models WHERE key_id = ? AND id = ?`, keyID, modelID)
+}
+
+func (what *sqliteStorage) ReadModel(keyID string, modelID string) ([]byte, error) {
+	var content []byte
+	err := what.db.QueryRow(`SELECT content FROM models WHERE key_id = ? AND id = ?`, keyID, modelID).Scan(&content)
+	return content, err
+}
+
+func (what *sqliteStorage) WriteModel(keyID string, modelID string, version string, content []byte) error {
+	return what.inTransaction(func(tx *sql.Tx) error {
+		if len(version) > 0 {
+			if _, err := readModelOfVersion(tx, keyID, modelID, version); err != nil {
+				return err
+			}
+		}
+		now := time.Now().UnixNano()
+		_, err := tx.Exec(`INSERT INTO models (key_id, id, content, created, modified) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (key_id, id) DO UPDATE SET content = excluded.content, modified = excluded.modified`,
+			keyID, modelID, content, now, now)
+		return err
+	})
+}
+
+func (what *sqliteStorage) DeleteModel(keyID string, modelID string) error {
+	return what.inTransaction(func(tx *sql.Tx) error {
+		for _, statement := range []string{
+			`DELETE FROM history WHERE key_id = ? AND model_id = ?`,
+			`DELETE FROM models WHERE key_id = ? AND id = ?`,
+		} {
+			if _, err := tx.Exec(statement, keyID, modelID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (what *sqliteStorage) BackupModel(keyID string, modelID string, version string, reason string, historyEntriesToKeep int) error {
+	return what.inTransaction(func(tx *sql.Tx) error {
+		content, err := readModelOfVersion(tx, keyID, modelID, version)
+		if err != nil {
+			return err
+		}
+		historyID, err := newHistoryID(reason, func(historyID string) (bool, error) {
+			err := tx.QueryRow(`SELECT 1 FROM history WHERE key_id = ? AND model_id = ? AND id = ?`, keyID, modelID, historyID).Scan(new(int))
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return err == nil, err
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT INTO history (key_id, model_id, id, content) VALUES (?, ?, ?, ?)`, keyID, modelID, historyID, content)
+		if err != nil {
+			return err
+		}
+		// now delete any old entries if over limit to keep (the ids start with the timestamp, so they sort by age)
+		_, err = tx.Exec(`DELETE FROM history WHERE key_id = ? AND model_id = ? AND id NOT IN (
+			SELECT id FROM history WHERE key_id = ? AND model_id = ? ORDER BY id DESC LIMIT ?)`,
+			keyID, modelID, keyID, modelID, historyEntriesToKeep)
+		return err
+	})
+}
+
+func (what *sqliteStorage) ListHistory(keyID string, modelID string) ([]string, error) {
+	rows, err := what.db.Query(`SELECT id FROM history WHERE key_id = ? AND model_id = ? ORDER BY id`, keyID, modelID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	historyIDs := make([]string, 0)
+	for rows.Next() {
+		var historyID string
+		if err = rows.Scan(&historyID); err != nil {
+			return nil, err
+		}
+		historyIDs = append(historyIDs, historyID)
+	}
+	return historyIDs, rows.Err()
+}
+
+func (what *sqliteStorage) ReadHistory(keyID string, modelID string, historyID string) ([]byte, error) {
+	var content []byte
+	err := what.db.QueryRow(`SELECT content FROM history WHERE key_id = ? AND model_id = ? AND id = ?`, keyID, modelID, historyID).Scan(&content)
+	return content, err
+}
+
+func (what *sqliteStorage) ImportModel(keyID string, storedModel StoredModel, content []byte) error {
+	_, err := what.db.Exec(`INSERT OR REPLACE INTO models (key_id, id, content, created, modified) VALUES (?, ?, ?, ?, ?)`,
+		keyID, storedModel.ID, content, storedModel.Created.UnixNano(), storedModel.Modified.UnixNano())
+	return err
+}
+
+func (what *sqliteStorage) ImportHistory(keyID string, modelID string, historyID string, content []byte) error {
+	_, err := what.db.Exec(`INSERT OR REPLACE INTO history (key_id, model_id, id, content) VALUES (?, ?, ?, ?)`, keyID, modelID, historyID, content)
+	return err
+}
+
+func (what *sqliteStorage) CreateToken(token StoredToken) error {
+	_, err := what.db.Exec(`INSERT OR REPLACE INTO tokens (hash, key_id, xor_rand, created, last_accessed) VALUES (?, ?, ?, ?, ?)`,
+		token.Hash, token.KeyID, token.XorRand, token.Created.UnixNano(), token.LastAccessed.UnixNano())
+	return err
+}
+
+func (what *sqliteStorage) ReadToken(tokenHash string) (StoredToken, bool, error) {
+	token := StoredToken{Hash: tokenHash}
+	var created, lastAccessed int64
+	err := what.db.QueryRow(`SELECT key_id, xor_rand, created, last_accessed FROM tokens WHERE hash = ?`, tokenHash).
+		Scan(&token.KeyID, &token.XorRand, &created, &lastAccessed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return token, false, nil
+	}
+	if err != nil {
+		return token, false, err
+	}
+	token.Created = time.Unix(0, created)
+	token.LastAccessed = time.Unix(0, lastAccessed)
+	return token, true, nil
+}
+
+func (what *sqliteStorage) TouchToken(tokenHash string, lastAccessed time.Time) error {
+	_, err := what.db.Exec(`UPDATE tokens SET last_accessed = ? WHERE hash = ?`, lastAccessed.UnixNano(), tokenHash)
+	return err
+}
+
+func (what *sqliteStorage) DeleteToken(tokenHash string) error {
+	_, err := what.db.Exec(`DELETE FROM tokens WHERE hash = ?`, tokenHash)
+	return err
+}
+
+func (what *sqliteStorage) DeleteExpiredTokens(lastAccessedBefore time.Time, createdBefore time.Time) error {
+	_, err := what.db.Exec(`DELETE FROM tokens WHERE last_accessed < ? OR created < ?`, lastAccessedBefore.UnixNano(), createdBefore.UnixNano())
+	return err
+}
+
+func (what *sqliteStorage) Close() error {
+	return what.db.Close()
+}
+
+func (what *sqliteStorage) exists(query string, args ...any) (bool, error) {
+	err := what.db.QueryRow(query, args...).Scan(new(int))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// readModelOfVersion reads the content of a model within a transaction, failing with ErrModelChanged when it is not of
+// the given version (any version is fine when empty)
+func readModelOfVersion(tx *sql.Tx, keyID string, modelID string, version string) ([]byte, error) {
+	var content []byte
+	err := tx.QueryRow(`SELECT content FROM models WHERE key_id = ? AND id = ?`, keyID, modelID).Scan(&content)
+	if errors.Is(err, sql.ErrNoRows) && len(version) > 0 {
+		return nil, ErrModelChanged // deleted in the meantime
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(version) > 0 && modelVersion(content) != version {
+		return nil, ErrModelChanged
+	}
+	return content, nil
+}
+
+func (what *sqliteStorage) inTransaction(statements func(tx *sql.Tx) error) error {
+	tx, err := what.db.Begin()
+	if err != nil {
+		return err
+	}
+	err = statements(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
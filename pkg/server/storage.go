@@ -0,0 +1,166 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	FilesystemStorage = "filesystem"
+	SQLiteStorage     = "sqlite"
+)
+
+// Storage persists the models of the server mode per key, along with their history. Keys are only known by their hash
+// (see folderNameFromKey) and models are stored already encrypted with the key, so a storage never sees anything
+// readable.
+type Storage interface {
+	CreateKey(keyID string) error
+	KeyExists(keyID string) (bool, error)
+	DeleteKey(keyID string) error
+	ListKeys() ([]string, error)
+
+	ListModels(keyID string) ([]StoredModel, error)
+	ModelExists(keyID string, modelID string) (bool, error)
+	ReadModel(keyID string, modelID string) ([]byte, error)
+	// WriteModel replaces the content of a model, but only when the stored content still has the given version (see
+	// modelVersion) and fails with ErrModelChanged otherwise; an empty version writes the model unconditionally
+	WriteModel(keyID string, modelID string, version string, content []byte) error
+	DeleteModel(keyID string, modelID string) error
+
+	// BackupModel copies the current content of a model into its history (named by timestamp and reason) and removes
+	// the oldest history entries exceeding historyEntriesToKeep; the version is checked like with WriteModel
+	BackupModel(keyID string, modelID string, version string, reason string, historyEntriesToKeep int) error
+	ListHistory(keyID string, modelID string) ([]string, error)
+	ReadHistory(keyID string, modelID string, historyID string) ([]byte, error)
+
+	// ImportModel and ImportHistory store content as-is (keeping the timestamps), they are used to migrate between storages
+	ImportModel(keyID string, storedModel StoredModel, content []byte) error
+	ImportHistory(keyID string, modelID string, historyID string, content []byte) error
+
+	// CreateToken stores a token, replacing any previous token of the same key
+	CreateToken(token StoredToken) error
+	ReadToken(tokenHash string) (StoredToken, bool, error)
+	TouchToken(tokenHash string, lastAccessed time.Time) error
+	DeleteToken(tokenHash string) error
+	// DeleteExpiredTokens removes all tokens last accessed before lastAccessedBefore or created before createdBefore
+	DeleteExpiredTokens(lastAccessedBefore time.Time, createdBefore time.Time) error
+
+	Close() error
+}
+
+// ErrModelChanged is returned when a model is written based on a version which is not the stored one anymore
+var ErrModelChanged = errors.New("model has been changed in the meantime")
+
+type StoredModel struct {
+	ID       string
+	Created  time.Time
+	Modified time.Time
+}
+
+// StoredToken is a token handed out for a key; only the hash of the token and the random value it was xor-ed with are
+// stored, so the key can be re-created from a token sent along with a request, but not from the stored data alone
+type StoredToken struct {
+	Hash         string
+	KeyID        string
+	XorRand      []byte
+	Created      time.Time
+	LastAccessed time.Time
+}
+
+type storageConfigReader interface {
+	GetServerFolder() string
+	GetKeyFolder() string
+	GetInputFile() string
+	GetServerDatabase() string
+}
+
+// OpenStorage opens the storage of the given kind (filesystem or sqlite) at the location given by the config
+func OpenStorage(kind string, config storageConfigReader) (Storage, error) {
+	switch kind {
+	case FilesystemStorage, "":
+		return newFilesystemStorage(filepath.Join(config.GetServerFolder(), config.GetKeyFolder()), config.GetInputFile())
+
+	case SQLiteStorage:
+		database := config.GetServerDatabase()
+		if !filepath.IsAbs(database) {
+			database = filepath.Join(config.GetServerFolder(), database)
+		}
+		return newSQLiteStorage(database)
+
+	default:
+		return nil, fmt.Errorf("unknown server storage %q (supported are %q and %q)", kind, FilesystemStorage, SQLiteStorage)
+	}
+}
+
+// MigrateStorage copies all keys, models and history entries from one storage to another; the content stays encrypted
+// with the respective keys, so the models are readable with the very same keys afterward
+func MigrateStorage(from Storage, to Storage) (keyCount int, modelCount int, err error) {
+	keyIDs, err := from.ListKeys()
+	if err != nil {
+		return keyCount, modelCount, fmt.Errorf("unable to list keys: %w", err)
+	}
+	for _, keyID := range keyIDs {
+		err = to.CreateKey(keyID)
+		if err != nil {
+			return keyCount, modelCount, fmt.Errorf("unable to create key: %w", err)
+		}
+		storedModels, err := from.ListModels(keyID)
+		if err != nil {
+			return keyCount, modelCount, fmt.Errorf("unable to list models: %w", err)
+		}
+		for _, storedModel := range storedModels {
+			err = migrateModel(from, to, keyID, storedModel)
+			if err != nil {
+				return keyCount, modelCount, fmt.Errorf("unable to migrate model %q: %w", storedModel.ID, err)
+			}
+			modelCount++
+		}
+		keyCount++
+	}
+	return keyCount, modelCount, nil
+}
+
+func migrateModel(from Storage, to Storage, keyID string, storedModel StoredModel) error {
+	historyIDs, err := from.ListHistory(keyID, storedModel.ID)
+	if err != nil {
+		return err
+	}
+	for _, historyID := range historyIDs {
+		content, err := from.ReadHistory(keyID, storedModel.ID, historyID)
+		if err != nil {
+			return err
+		}
+		err = to.ImportHistory(keyID, storedModel.ID, historyID, content)
+		if err != nil {
+			return err
+		}
+	}
+	// the model itself goes last, so its timestamps are not touched by importing the history
+	content, err := from.ReadModel(keyID, storedModel.ID)
+	if err != nil {
+		return err
+	}
+	return to.ImportModel(keyID, storedModel, content)
+}
+
+// modelVersion identifies the content of a model, so it changes with each write of the model
+func modelVersion(content []byte) string {
+	return hashSHA256(content)
+}
+
+// newHistoryID names a backup by timestamp and reason, appending a counter to never overwrite a backup of another
+// change within the same second
+func newHistoryID(reason string, exists func(historyID string) (bool, error)) (string, error) {
+	historyName := time.Now().Format(historyTimestampFormat) + " " + filepath.Base(reason)
+	historyID := historyName
+	for i := 2; ; i++ {
+		found, err := exists(historyID)
+		if err != nil || !found {
+			return historyID, err
+		}
+		historyID = historyName + " (" + strconv.Itoa(i) + ")"
+	}
+}
@@ -0,0 +1,332 @@
+package server
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeyID looks like a key hash, the filesystem storage only lists folders of that length as keys
+var testKeyID = strings.Repeat("a", 128)
+
+type testStorageConfig struct {
+	serverFolder string
+}
+
+func (what *testStorageConfig) GetServerFolder() string   { return what.serverFolder }
+func (what *testStorageConfig) GetKeyFolder() string      { return "keys" }
+func (what *testStorageConfig) GetInputFile() string      { return "threagile.yaml" }
+func (what *testStorageConfig) GetServerDatabase() string { return "threagile.db" }
+
+func openTestStorage(t *testing.T, kind string) Storage {
+	t.Helper()
+
+	storage, err := OpenStorage(kind, &testStorageConfig{serverFolder: t.TempDir()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	return storage
+}
+
+// TestStorage runs the same checks against every storage, they have to behave alike
+func TestStorage(t *testing.T) {
+	for _, kind := range []string{FilesystemStorage, SQLiteStorage} {
+		t.Run(kind, func(t *testing.T) {
+			t.Run("keys", func(t *testing.T) { testStorageKeys(t, openTestStorage(t, kind)) })
+			t.Run("models", func(t *testing.T) { testStorageModels(t, openTestStorage(t, kind)) })
+			t.Run("history", func(t *testing.T) { testStorageHistory(t, openTestStorage(t, kind)) })
+			t.Run("versions", func(t *testing.T) { testStorageVersions(t, openTestStorage(t, kind)) })
+			t.Run("tokens", func(t *testing.T) { testStorageTokens(t, openTestStorage(t, kind)) })
+		})
+	}
+}
+
+func testStorageKeys(t *testing.T, storage Storage) {
+	exists, err := storage.KeyExists(testKeyID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, storage.CreateKey(testKeyID))
+	require.NoError(t, storage.CreateKey(testKeyID), "creating an existing key is fine")
+
+	exists, err = storage.KeyExists(testKeyID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	keyIDs, err := storage.ListKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{testKeyID}, keyIDs)
+
+	modelID := uuid.New().String()
+	require.NoError(t, storage.WriteModel(testKeyID, modelID, "", []byte("model")))
+	require.NoError(t, storage.DeleteKey(testKeyID))
+
+	exists, err = storage.KeyExists(testKeyID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = storage.ModelExists(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.False(t, exists, "deleting a key deletes its models")
+
+	keyIDs, err = storage.ListKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keyIDs)
+}
+
+func testStorageModels(t *testing.T, storage Storage) {
+	require.NoError(t, storage.CreateKey(testKeyID))
+
+	storedModels, err := storage.ListModels(testKeyID)
+	require.NoError(t, err)
+	assert.Empty(t, storedModels)
+
+	modelID := uuid.New().String()
+	exists, err := storage.ModelExists(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	before := time.Now().Add(-time.Second)
+	require.NoError(t, storage.WriteModel(testKeyID, modelID, "", []byte("first")))
+	require.NoError(t, storage.WriteModel(testKeyID, modelID, "", []byte("second")))
+
+	content, err := storage.ReadModel(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	exists, err = storage.ModelExists(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	otherModelID := uuid.New().String()
+	require.NoError(t, storage.WriteModel(testKeyID, otherModelID, "", []byte("other")))
+
+	storedModels, err = storage.ListModels(testKeyID)
+	require.NoError(t, err)
+	require.Len(t, storedModels, 2)
+	ids := []string{storedModels[0].ID, storedModels[1].ID}
+	assert.ElementsMatch(t, []string{modelID, otherModelID}, ids)
+	for _, storedModel := range storedModels {
+		assert.True(t, storedModel.Created.After(before), "created %v", storedModel.Created)
+		assert.False(t, storedModel.Modified.Before(storedModel.Created.Truncate(time.Second)), "modified %v", storedModel.Modified)
+	}
+
+	require.NoError(t, storage.DeleteModel(testKeyID, modelID))
+
+	exists, err = storage.ModelExists(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = storage.ReadModel(testKeyID, modelID)
+	assert.Error(t, err)
+
+	storedModels, err = storage.ListModels(testKeyID)
+	require.NoError(t, err)
+	require.Len(t, storedModels, 1)
+	assert.Equal(t, otherModelID, storedModels[0].ID)
+}
+
+func testStorageHistory(t *testing.T, storage Storage) {
+	require.NoError(t, storage.CreateKey(testKeyID))
+	modelID := uuid.New().String()
+
+	historyIDs, err := storage.ListHistory(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.Empty(t, historyIDs)
+
+	// several backups within the same second get a counter appended to their ids
+	for _, content := range []string{"first", "second", "third"} {
+		require.NoError(t, storage.WriteModel(testKeyID, modelID, "", []byte(content)))
+		require.NoError(t, storage.BackupModel(testKeyID, modelID, "", "update", 2))
+	}
+
+	historyIDs, err = storage.ListHistory(testKeyID, modelID)
+	require.NoError(t, err)
+	require.Len(t, historyIDs, 2, "only the latest backups are kept")
+
+	contents := make([]string, 0)
+	for _, historyID := range historyIDs {
+		assert.True(t, strings.Contains(historyID, "update"), historyID)
+		content, readError := storage.ReadHistory(testKeyID, modelID, historyID)
+		require.NoError(t, readError)
+		contents = append(contents, string(content))
+	}
+	assert.Equal(t, []string{"second", "third"}, contents, "history is listed oldest first")
+
+	require.NoError(t, storage.DeleteModel(testKeyID, modelID))
+
+	historyIDs, err = storage.ListHistory(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.Empty(t, historyIDs, "deleting a model deletes its history")
+}
+
+func testStorageVersions(t *testing.T, storage Storage) {
+	require.NoError(t, storage.CreateKey(testKeyID))
+	modelID := uuid.New().String()
+
+	assert.ErrorIs(t, storage.WriteModel(testKeyID, modelID, modelVersion([]byte("first")), []byte("second")), ErrModelChanged,
+		"a model which does not exist has no version")
+
+	require.NoError(t, storage.WriteModel(testKeyID, modelID, "", []byte("first")))
+	first := modelVersion([]byte("first"))
+	require.NoError(t, storage.BackupModel(testKeyID, modelID, first, "update", 10))
+	require.NoError(t, storage.WriteModel(testKeyID, modelID, first, []byte("second")))
+
+	// a write based on the first version comes too late now
+	assert.ErrorIs(t, storage.BackupModel(testKeyID, modelID, first, "update", 10), ErrModelChanged)
+	assert.ErrorIs(t, storage.WriteModel(testKeyID, modelID, first, []byte("lost update")), ErrModelChanged)
+
+	content, err := storage.ReadModel(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(content))
+
+	historyIDs, err := storage.ListHistory(testKeyID, modelID)
+	require.NoError(t, err)
+	assert.Len(t, historyIDs, 1, "a rejected backup leaves no history entry")
+}
+
+func testStorageTokens(t *testing.T, storage Storage) {
+	require.NoError(t, storage.CreateKey(testKeyID))
+	now := time.Now().Truncate(time.Millisecond)
+
+	_, exists, err := storage.ReadToken("unknown")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	first := StoredToken{Hash: strings.Repeat("1", 128), KeyID: testKeyID, XorRand: []byte("first"), Created: now, LastAccessed: now}
+	require.NoError(t, storage.CreateToken(first))
+
+	token, exists, err := storage.ReadToken(first.Hash)
+	require.NoError(t, err)
+	require.True(t, exists)
+	assert.Equal(t, first.KeyID, token.KeyID)
+	assert.Equal(t, first.XorRand, token.XorRand)
+	assert.True(t, first.Created.Equal(token.Created), "created %v", token.Created)
+
+	later := now.Add(time.Minute)
+	require.NoError(t, storage.TouchToken(first.Hash, later))
+	token, _, err = storage.ReadToken(first.Hash)
+	require.NoError(t, err)
+	assert.True(t, later.Equal(token.LastAccessed), "last accessed %v", token.LastAccessed)
+
+	second := StoredToken{Hash: strings.Repeat("2", 128), KeyID: testKeyID, XorRand: []byte("second"), Created: now, LastAccessed: now}
+	require.NoError(t, storage.CreateToken(second))
+	_, exists, err = storage.ReadToken(first.Hash)
+	require.NoError(t, err)
+	assert.False(t, exists, "a new token of a key replaces the previous one")
+
+	require.NoError(t, storage.DeleteExpiredTokens(now, now))
+	_, exists, err = storage.ReadToken(second.Hash)
+	require.NoError(t, err)
+	assert.True(t, exists, "not expired yet")
+	require.NoError(t, storage.DeleteExpiredTokens(now.Add(time.Second), now))
+	_, exists, err = storage.ReadToken(second.Hash)
+	require.NoError(t, err)
+	assert.False(t, exists, "not accessed for too long")
+
+	require.NoError(t, storage.CreateToken(first))
+	require.NoError(t, storage.DeleteToken(first.Hash))
+	require.NoError(t, storage.DeleteToken(first.Hash), "deleting a deleted token is fine")
+	_, exists, err = storage.ReadToken(first.Hash)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, storage.CreateToken(first))
+	require.NoError(t, storage.DeleteKey(testKeyID))
+	_, exists, err = storage.ReadToken(first.Hash)
+	require.NoError(t, err)
+	assert.False(t, exists, "deleting a key deletes its token")
+}
+
+// TestSQLiteStorageSharedDatabase checks the version check between two server processes sharing a database file
+func TestSQLiteStorageSharedDatabase(t *testing.T) {
+	database := filepath.Join(t.TempDir(), "threagile.db")
+	replicas := make([]Storage, 0)
+	for i := 0; i < 2; i++ {
+		storage, err := newSQLiteStorage(database)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = storage.Close() })
+		replicas = append(replicas, storage)
+	}
+	modelID := uuid.New().String()
+	require.NoError(t, replicas[0].CreateKey(testKeyID))
+	require.NoError(t, replicas[0].WriteModel(testKeyID, modelID, "", []byte("first")))
+
+	// both replicas read the first version, so only the first write based on it succeeds
+	content, err := replicas[1].ReadModel(testKeyID, modelID)
+	require.NoError(t, err)
+	version := modelVersion(content)
+	require.NoError(t, replicas[0].WriteModel(testKeyID, modelID, version, []byte("from replica 0")))
+	assert.ErrorIs(t, replicas[1].WriteModel(testKeyID, modelID, version, []byte("from replica 1")), ErrModelChanged)
+
+	token := StoredToken{Hash: strings.Repeat("1", 128), KeyID: testKeyID, XorRand: []byte("random"), Created: time.Now(), LastAccessed: time.Now()}
+	require.NoError(t, replicas[0].CreateToken(token))
+	_, exists, err := replicas[1].ReadToken(token.Hash)
+	require.NoError(t, err)
+	assert.True(t, exists, "tokens are shared as well")
+}
+
+func TestFilesystemStoragePath(t *testing.T) {
+	folder := t.TempDir()
+	storage, err := newFilesystemStorage(folder, "threagile.yaml")
+	require.NoError(t, err)
+
+	path, err := storage.path(testKeyID, "model", "history")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(folder, testKeyID, "model", "history"), path)
+
+	for _, name := range []string{"", ".", "..", "../other", "a/b", "/etc", "model/../../other"} {
+		_, err = storage.path(testKeyID, name)
+		assert.Error(t, err, "name %q", name)
+	}
+
+	_, err = storage.ReadModel(testKeyID, "../../outside")
+	assert.Error(t, err)
+	assert.Error(t, storage.WriteModel("..", "model", "", []byte("model")))
+	assert.Error(t, storage.DeleteModel(testKeyID, ".."))
+	_, err = storage.ReadHistory(testKeyID, "model", "../../../outside")
+	assert.Error(t, err)
+}
+
+func TestMigrateStorageFromFilesystemToSQLite(t *testing.T) {
+	from := openTestStorage(t, FilesystemStorage)
+	to := openTestStorage(t, SQLiteStorage)
+
+	storedModel := StoredModel{
+		ID:       uuid.New().String(),
+		Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local),
+		Modified: time.Date(2024, 6, 7, 8, 9, 10, 0, time.Local),
+	}
+	require.NoError(t, from.CreateKey(testKeyID))
+	require.NoError(t, from.ImportHistory(testKeyID, storedModel.ID, "2024-06-07 08:09:10 update", []byte("old")))
+	require.NoError(t, from.ImportModel(testKeyID, storedModel, []byte("current")))
+
+	keyCount, modelCount, err := MigrateStorage(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 1, keyCount)
+	assert.Equal(t, 1, modelCount)
+
+	storedModels, err := to.ListModels(testKeyID)
+	require.NoError(t, err)
+	require.Len(t, storedModels, 1)
+	assert.Equal(t, storedModel.ID, storedModels[0].ID)
+	assert.True(t, storedModel.Created.Equal(storedModels[0].Created), "created %v", storedModels[0].Created)
+	assert.True(t, storedModel.Modified.Equal(storedModels[0].Modified), "modified %v", storedModels[0].Modified)
+
+	content, err := to.ReadModel(testKeyID, storedModel.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(content))
+
+	historyIDs, err := to.ListHistory(testKeyID, storedModel.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-06-07 08:09:10 update"}, historyIDs)
+
+	content, err = to.ReadHistory(testKeyID, storedModel.ID, historyIDs[0])
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(content))
+}
@@ -0,0 +1,458 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+type payloadTechnicalAsset struct {
+	Title                   string   `yaml:"title" json:"title"`
+	Id                      string   `yaml:"id" json:"id"`
+	Description             string   `yaml:"description" json:"description"`
+	Type                    string   `yaml:"type" json:"type"`
+	Usage                   string   `yaml:"usage" json:"usage"`
+	UsedAsClientByHuman     bool     `yaml:"used_as_client_by_human" json:"used_as_client_by_human"`
+	OutOfScope              bool     `yaml:"out_of_scope" json:"out_of_scope"`
+	JustificationOutOfScope string   `yaml:"justification_out_of_scope" json:"justification_out_of_scope"`
+	Size                    string   `yaml:"size" json:"size"`
+	Technology              string   `yaml:"technology" json:"technology"`
+	Technologies            []string `yaml:"technologies" json:"technologies"`
+	Tags                    []string `yaml:"tags" json:"tags"`
+	Internet                bool     `yaml:"internet" json:"internet"`
+	Machine                 string   `yaml:"machine" json:"machine"`
+	Encryption              string   `yaml:"encryption" json:"encryption"`
+	Owner                   string   `yaml:"owner" json:"owner"`
+	Confidentiality         string   `yaml:"confidentiality" json:"confidentiality"`
+	Integrity               string   `yaml:"integrity" json:"integrity"`
+	Availability            string   `yaml:"availability" json:"availability"`
+	JustificationCiaRating  string   `yaml:"justification_cia_rating" json:"justification_cia_rating"`
+	MultiTenant             bool     `yaml:"multi_tenant" json:"multi_tenant"`
+	Redundant               bool     `yaml:"redundant" json:"redundant"`
+	CustomDevelopedParts    bool     `yaml:"custom_developed_parts" json:"custom_developed_parts"`
+	DataAssetsProcessed     []string `yaml:"data_assets_processed" json:"data_assets_processed"`
+	DataAssetsStored        []string `yaml:"data_assets_stored" json:"data_assets_stored"`
+	DataFormatsAccepted     []string `yaml:"data_formats_accepted" json:"data_formats_accepted"`
+	DiagramTweakOrder       int      `yaml:"diagram_tweak_order" json:"diagram_tweak_order"`
+}
+
+func (s *server) getTechnicalAsset(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title, techAsset := range modelInput.TechnicalAssets {
+			if techAsset.ID == ginContext.Param("technical-asset-id") {
+				ginContext.JSON(http.StatusOK, gin.H{
+					title: techAsset,
+				})
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "technical asset not found",
+		})
+	}
+}
+
+func (s *server) createNewTechnicalAsset(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		payload := payloadTechnicalAsset{}
+		err := ginContext.BindJSON(&payload)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": "unable to parse request payload",
+			})
+			return
+		}
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		if _, exists := modelInput.TechnicalAssets[payload.Title]; exists {
+			ginContext.JSON(http.StatusConflict, gin.H{
+				"error": "technical asset with this title already exists",
+			})
+			return
+		}
+		// but later it will in memory keyed by its "id", so do this uniqueness check also
+		for _, techAsset := range modelInput.TechnicalAssets {
+			if techAsset.ID == payload.Id {
+				ginContext.JSON(http.StatusConflict, gin.H{
+					"error": "technical asset with this id already exists",
+				})
+				return
+			}
+		}
+		techAssetInput, ok := s.populateTechnicalAsset(ginContext, modelInput, payload)
+		if !ok {
+			return
+		}
+		if modelInput.TechnicalAssets == nil {
+			modelInput.TechnicalAssets = make(map[string]input.TechnicalAsset)
+		}
+		modelInput.TechnicalAssets[payload.Title] = techAssetInput
+		ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Technical Asset Creation")
+		if ok {
+			ginContext.JSON(http.StatusOK, gin.H{
+				"message": "technical asset created",
+				"id":      techAssetInput.ID,
+			})
+		}
+	}
+}
+
+func (s *server) setTechnicalAsset(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title, techAsset := range modelInput.TechnicalAssets {
+			if techAsset.ID == ginContext.Param("technical-asset-id") {
+				payload := payloadTechnicalAsset{}
+				err := ginContext.BindJSON(&payload)
+				if err != nil {
+					log.Println(err)
+					ginContext.JSON(http.StatusBadRequest, gin.H{
+						"error": "unable to parse request payload",
+					})
+					return
+				}
+				for otherTitle, other := range modelInput.TechnicalAssets {
+					if otherTitle != title && (otherTitle == payload.Title || other.ID == payload.Id) {
+						ginContext.JSON(http.StatusConflict, gin.H{
+							"error": "technical asset with this title or id already exists",
+						})
+						return
+					}
+				}
+				techAssetInput, ok := s.populateTechnicalAsset(ginContext, modelInput, payload)
+				if !ok {
+					return
+				}
+				// the communication links are maintained via their own endpoints
+				techAssetInput.CommunicationLinks = techAsset.CommunicationLinks
+				// in order to also update the title, remove the asset from the map and re-insert it (with new key)
+				delete(modelInput.TechnicalAssets, title)
+				modelInput.TechnicalAssets[payload.Title] = techAssetInput
+				idChanged := techAssetInput.ID != techAsset.ID
+				if idChanged { // ID-CHANGE-PROPAGATION
+					replaceTechnicalAssetReferences(&modelInput, techAsset.ID, techAssetInput.ID)
+				}
+				ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Technical Asset Update")
+				if ok {
+					ginContext.JSON(http.StatusOK, gin.H{
+						"message":    "technical asset updated",
+						"id":         techAssetInput.ID,
+						"id_changed": idChanged, // in order to signal to clients, that other model parts might've received updates as well and should be reloaded
+					})
+				}
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "technical asset not found",
+		})
+	}
+}
+
+func (s *server) deleteTechnicalAsset(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title, techAsset := range modelInput.TechnicalAssets {
+			if techAsset.ID == ginContext.Param("technical-asset-id") {
+				// remove it itself (including its outgoing communication links) and all usages of it !!
+				delete(modelInput.TechnicalAssets, title)
+				referencesDeleted := removeTechnicalAssetReferences(&modelInput, techAsset)
+				ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Technical Asset Deletion")
+				if ok {
+					ginContext.JSON(http.StatusOK, gin.H{
+						"message":            "technical asset deleted",
+						"id":                 techAsset.ID,
+						"references_deleted": referencesDeleted, // in order to signal to clients, that other model parts might've been deleted as well
+					})
+				}
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "technical asset not found",
+		})
+	}
+}
+
+func (s *server) populateTechnicalAsset(ginContext *gin.Context, modelInput input.Model, payload payloadTechnicalAsset) (techAssetInput input.TechnicalAsset, ok bool) {
+	techAssetType, err := types.ParseTechnicalAssetType(payload.Type)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	usage, err := types.ParseUsage(payload.Usage)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	size, err := types.ParseTechnicalAssetSize(payload.Size)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	machine, err := types.ParseTechnicalAssetMachine(payload.Machine)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	encryption, err := types.ParseEncryptionStyle(payload.Encryption)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	confidentiality, err := types.ParseConfidentiality(payload.Confidentiality)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	integrity, err := types.ParseCriticality(payload.Integrity)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	availability, err := types.ParseCriticality(payload.Availability)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	dataFormatsAccepted := make([]string, 0)
+	for _, dataFormatName := range payload.DataFormatsAccepted {
+		dataFormat, err := types.ParseDataFormat(dataFormatName)
+		if err != nil {
+			handleErrorInServiceCall(err, ginContext)
+			return techAssetInput, false
+		}
+		dataFormatsAccepted = append(dataFormatsAccepted, dataFormat.String())
+	}
+	technologies := make(types.TechnologyMap)
+	err = technologies.LoadWithConfig(s.config, "technologies.yaml")
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return techAssetInput, false
+	}
+	for _, technology := range append([]string{payload.Technology}, payload.Technologies...) {
+		if len(technology) > 0 && technologies.Get(technology) == nil {
+			handleErrorInServiceCall(fmt.Errorf("unknown technology: %v", technology), ginContext)
+			return techAssetInput, false
+		}
+	}
+	if !checkDataAssetsExisting(modelInput, payload.DataAssetsProcessed) || !checkDataAssetsExisting(modelInput, payload.DataAssetsStored) {
+		ginContext.JSON(http.StatusBadRequest, gin.H{
+			"error": "referenced data asset does not exist",
+		})
+		return techAssetInput, false
+	}
+	techAssetInput = input.TechnicalAsset{
+		ID:                      payload.Id,
+		Description:             payload.Description,
+		Type:                    techAssetType.String(),
+		Usage:                   usage.String(),
+		UsedAsClientByHuman:     payload.UsedAsClientByHuman,
+		OutOfScope:              payload.OutOfScope,
+		JustificationOutOfScope: payload.JustificationOutOfScope,
+		Size:                    size.String(),
+		Technology:              payload.Technology,
+		Technologies:            payload.Technologies,
+		Tags:                    lowerCaseAndTrim(payload.Tags),
+		Internet:                payload.Internet,
+		Machine:                 machine.String(),
+		Encryption:              encryption.String(),
+		Owner:                   payload.Owner,
+		Confidentiality:         confidentiality.String(),
+		Integrity:               integrity.String(),
+		Availability:            availability.String(),
+		JustificationCiaRating:  payload.JustificationCiaRating,
+		MultiTenant:             payload.MultiTenant,
+		Redundant:               payload.Redundant,
+		CustomDevelopedParts:    payload.CustomDevelopedParts,
+		DataAssetsProcessed:     payload.DataAssetsProcessed,
+		DataAssetsStored:        payload.DataAssetsStored,
+		DataFormatsAccepted:     dataFormatsAccepted,
+		DiagramTweakOrder:       payload.DiagramTweakOrder,
+	}
+	return techAssetInput, true
+}
+
+func checkDataAssetsExisting(modelInput input.Model, dataAssetIDs []string) (ok bool) {
+	for _, dataAssetID := range dataAssetIDs {
+		exists := false
+		for _, val := range modelInput.DataAssets {
+			if val.ID == dataAssetID {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceTechnicalAssetReferences points all usages of a technical asset to its changed ID
+func replaceTechnicalAssetReferences(modelInput *input.Model, oldID string, newID string) {
+	for _, techAsset := range modelInput.TechnicalAssets {
+		for linkTitle, commLink := range techAsset.CommunicationLinks {
+			if commLink.Target == oldID { // apply the ID change
+				commLink.Target = newID
+				techAsset.CommunicationLinks[linkTitle] = commLink
+			}
+		}
+	}
+	for _, trustBoundary := range modelInput.TrustBoundaries {
+		for i, assetID := range trustBoundary.TechnicalAssetsInside {
+			if assetID == oldID { // apply the ID change
+				trustBoundary.TechnicalAssetsInside[i] = newID
+			}
+		}
+	}
+	for _, sharedRuntime := range modelInput.SharedRuntimes {
+		for i, assetID := range sharedRuntime.TechnicalAssetsRunning {
+			if assetID == oldID { // apply the ID change
+				sharedRuntime.TechnicalAssetsRunning[i] = newID
+			}
+		}
+	}
+	for _, individualRiskCat := range modelInput.CustomRiskCategories {
+		for individualRiskInstanceTitle, individualRiskInstance := range individualRiskCat.RisksIdentified {
+			if individualRiskInstance.MostRelevantTechnicalAsset == oldID { // apply the ID change
+				individualRiskInstance.MostRelevantTechnicalAsset = newID
+			}
+			if strings.HasPrefix(individualRiskInstance.MostRelevantCommunicationLink, oldID+">") { // link IDs start with their source asset ID
+				individualRiskInstance.MostRelevantCommunicationLink = newID + strings.TrimPrefix(individualRiskInstance.MostRelevantCommunicationLink, oldID)
+			}
+			for i, assetID := range individualRiskInstance.DataBreachTechnicalAssets {
+				if assetID == oldID { // apply the ID change
+					individualRiskInstance.DataBreachTechnicalAssets[i] = newID
+				}
+			}
+			individualRiskCat.RisksIdentified[individualRiskInstanceTitle] = individualRiskInstance
+		}
+	}
+	modelInput.DiagramTweakInvisibleConnectionsBetweenAssets = replaceInAssetLists(modelInput.DiagramTweakInvisibleConnectionsBetweenAssets, oldID, newID)
+	modelInput.DiagramTweakSameRankAssets = replaceInAssetLists(modelInput.DiagramTweakSameRankAssets, oldID, newID)
+}
+
+// removeTechnicalAssetReferences removes all usages of a deleted technical asset: communication links targeting it,
+// its membership in trust boundaries and shared runtimes, diagram tweaks and references of individual risks
+func removeTechnicalAssetReferences(modelInput *input.Model, deleted input.TechnicalAsset) (referencesDeleted bool) {
+	deletedLinkIDs := make([]string, 0)
+	for linkTitle := range deleted.CommunicationLinks {
+		deletedLinkIDs = append(deletedLinkIDs, deleted.ID+">"+types.MakeID(linkTitle))
+	}
+	for _, techAsset := range modelInput.TechnicalAssets {
+		for linkTitle, commLink := range techAsset.CommunicationLinks {
+			if commLink.Target == deleted.ID { // apply the removal
+				referencesDeleted = true
+				deletedLinkIDs = append(deletedLinkIDs, techAsset.ID+">"+types.MakeID(linkTitle))
+				delete(techAsset.CommunicationLinks, linkTitle)
+			}
+		}
+	}
+	for title, trustBoundary := range modelInput.TrustBoundaries {
+		if slices.Contains(trustBoundary.TechnicalAssetsInside, deleted.ID) { // apply the removal
+			referencesDeleted = true
+			trustBoundary.TechnicalAssetsInside = removeValue(trustBoundary.TechnicalAssetsInside, deleted.ID)
+			modelInput.TrustBoundaries[title] = trustBoundary
+		}
+	}
+	for title, sharedRuntime := range modelInput.SharedRuntimes {
+		if slices.Contains(sharedRuntime.TechnicalAssetsRunning, deleted.ID) { // apply the removal
+			referencesDeleted = true
+			sharedRuntime.TechnicalAssetsRunning = removeValue(sharedRuntime.TechnicalAssetsRunning, deleted.ID)
+			modelInput.SharedRuntimes[title] = sharedRuntime
+		}
+	}
+	for _, individualRiskCat := range modelInput.CustomRiskCategories {
+		for individualRiskInstanceTitle, individualRiskInstance := range individualRiskCat.RisksIdentified {
+			changed := false
+			if individualRiskInstance.MostRelevantTechnicalAsset == deleted.ID {
+				individualRiskInstance.MostRelevantTechnicalAsset = ""
+				changed = true
+			}
+			if slices.Contains(deletedLinkIDs, individualRiskInstance.MostRelevantCommunicationLink) {
+				individualRiskInstance.MostRelevantCommunicationLink = ""
+				changed = true
+			}
+			if slices.Contains(individualRiskInstance.DataBreachTechnicalAssets, deleted.ID) {
+				individualRiskInstance.DataBreachTechnicalAssets = removeValue(individualRiskInstance.DataBreachTechnicalAssets, deleted.ID)
+				changed = true
+			}
+			if changed { // apply the removal
+				referencesDeleted = true
+				individualRiskCat.RisksIdentified[individualRiskInstanceTitle] = individualRiskInstance
+			}
+		}
+	}
+	invisibleConnections := removeFromAssetLists(modelInput.DiagramTweakInvisibleConnectionsBetweenAssets, deleted.ID)
+	sameRankAssets := removeFromAssetLists(modelInput.DiagramTweakSameRankAssets, deleted.ID)
+	if !slices.Equal(invisibleConnections, modelInput.DiagramTweakInvisibleConnectionsBetweenAssets) || !slices.Equal(sameRankAssets, modelInput.DiagramTweakSameRankAssets) {
+		referencesDeleted = true
+	}
+	modelInput.DiagramTweakInvisibleConnectionsBetweenAssets = invisibleConnections
+	modelInput.DiagramTweakSameRankAssets = sameRankAssets
+	return referencesDeleted
+}
+
+// replaceInAssetLists changes an asset ID in diagram tweaks given as colon-separated asset IDs
+func replaceInAssetLists(assetLists []string, oldID string, newID string) []string {
+	result := make([]string, 0, len(assetLists))
+	for _, assetList := range assetLists {
+		assetIDs := strings.Split(assetList, ":")
+		for i, assetID := range assetIDs {
+			if assetID == oldID {
+				assetIDs[i] = newID
+			}
+		}
+		result = append(result, strings.Join(assetIDs, ":"))
+	}
+	return result
+}
+
+// removeFromAssetLists removes an asset ID from diagram tweaks given as colon-separated asset IDs, dropping tweaks
+// that no longer connect at least two assets
+func removeFromAssetLists(assetLists []string, removedID string) []string {
+	result := make([]string, 0, len(assetLists))
+	for _, assetList := range assetLists {
+		assetIDs := removeValue(strings.Split(assetList, ":"), removedID)
+		if len(assetIDs) > 1 {
+			result = append(result, strings.Join(assetIDs, ":"))
+		}
+	}
+	return result
+}
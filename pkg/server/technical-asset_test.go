@@ -0,0 +1,91 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/threagile/threagile/pkg/input"
+)
+
+// newTechnicalAssetTestModel has a browser calling a web server, which calls a database; every kind of reference to the
+// web server is used somewhere
+func newTechnicalAssetTestModel() input.Model {
+	return input.Model{
+		TechnicalAssets: map[string]input.TechnicalAsset{
+			"Browser": {ID: "browser", CommunicationLinks: map[string]input.CommunicationLink{
+				"Web Access":    {Target: "web"},
+				"Direct Access": {Target: "db"},
+			}},
+			"Web Server": {ID: "web", CommunicationLinks: map[string]input.CommunicationLink{
+				"Database Access": {Target: "db"},
+			}},
+			"Database": {ID: "db"},
+		},
+		TrustBoundaries: map[string]input.TrustBoundary{
+			"Network": {ID: "network", TechnicalAssetsInside: []string{"web", "db"}},
+		},
+		SharedRuntimes: map[string]input.SharedRuntime{
+			"Cluster": {ID: "cluster", TechnicalAssetsRunning: []string{"web"}},
+		},
+		CustomRiskCategories: input.RiskCategories{
+			{ID: "custom", RisksIdentified: map[string]input.RiskIdentified{
+				"Asset":         {MostRelevantTechnicalAsset: "web"},
+				"Outgoing Link": {MostRelevantCommunicationLink: "web>database-access"},
+				"Incoming Link": {MostRelevantCommunicationLink: "browser>web-access"},
+				"Data Breach":   {DataBreachTechnicalAssets: []string{"web", "db"}},
+				"Unrelated":     {MostRelevantTechnicalAsset: "db", MostRelevantCommunicationLink: "browser>direct-access"},
+			}},
+		},
+		DiagramTweakInvisibleConnectionsBetweenAssets: []string{"web:db", "browser:db"},
+		DiagramTweakSameRankAssets:                    []string{"web:db:browser"},
+	}
+}
+
+func TestRemoveTechnicalAssetReferences(t *testing.T) {
+	modelInput := newTechnicalAssetTestModel()
+	deleted := modelInput.TechnicalAssets["Web Server"]
+	delete(modelInput.TechnicalAssets, "Web Server")
+
+	assert.True(t, removeTechnicalAssetReferences(&modelInput, deleted))
+
+	assert.Equal(t, map[string]input.CommunicationLink{"Direct Access": {Target: "db"}}, modelInput.TechnicalAssets["Browser"].CommunicationLinks)
+	assert.Equal(t, []string{"db"}, modelInput.TrustBoundaries["Network"].TechnicalAssetsInside)
+	assert.Empty(t, modelInput.SharedRuntimes["Cluster"].TechnicalAssetsRunning)
+	assert.Equal(t, map[string]input.RiskIdentified{
+		"Asset":         {},
+		"Outgoing Link": {},
+		"Incoming Link": {},
+		"Data Breach":   {DataBreachTechnicalAssets: []string{"db"}},
+		"Unrelated":     {MostRelevantTechnicalAsset: "db", MostRelevantCommunicationLink: "browser>direct-access"},
+	}, modelInput.CustomRiskCategories[0].RisksIdentified)
+	assert.Equal(t, []string{"browser:db"}, modelInput.DiagramTweakInvisibleConnectionsBetweenAssets, "connections need two assets")
+	assert.Equal(t, []string{"db:browser"}, modelInput.DiagramTweakSameRankAssets)
+}
+
+func TestRemoveTechnicalAssetReferencesUnused(t *testing.T) {
+	modelInput := newTechnicalAssetTestModel()
+	modelInput.TechnicalAssets["Printer"] = input.TechnicalAsset{ID: "printer"}
+	deleted := modelInput.TechnicalAssets["Printer"]
+	delete(modelInput.TechnicalAssets, "Printer")
+
+	assert.False(t, removeTechnicalAssetReferences(&modelInput, deleted))
+	assert.Equal(t, newTechnicalAssetTestModel(), modelInput)
+}
+
+func TestReplaceTechnicalAssetReferences(t *testing.T) {
+	modelInput := newTechnicalAssetTestModel()
+
+	replaceTechnicalAssetReferences(&modelInput, "web", "frontend")
+
+	assert.Equal(t, "frontend", modelInput.TechnicalAssets["Browser"].CommunicationLinks["Web Access"].Target)
+	assert.Equal(t, []string{"frontend", "db"}, modelInput.TrustBoundaries["Network"].TechnicalAssetsInside)
+	assert.Equal(t, []string{"frontend"}, modelInput.SharedRuntimes["Cluster"].TechnicalAssetsRunning)
+	risks := modelInput.CustomRiskCategories[0].RisksIdentified
+	assert.Equal(t, "frontend", risks["Asset"].MostRelevantTechnicalAsset)
+	assert.Equal(t, "frontend>database-access", risks["Outgoing Link"].MostRelevantCommunicationLink)
+	assert.Equal(t, "browser>web-access", risks["Incoming Link"].MostRelevantCommunicationLink)
+	assert.Equal(t, []string{"frontend", "db"}, risks["Data Breach"].DataBreachTechnicalAssets)
+	assert.Equal(t, []string{"frontend:db", "browser:db"}, modelInput.DiagramTweakInvisibleConnectionsBetweenAssets)
+	assert.Equal(t, []string{"frontend:db:browser"}, modelInput.DiagramTweakSameRankAssets)
+}
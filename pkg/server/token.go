@@ -7,11 +7,8 @@ package server
 import (
 	"crypto/rand"
 	"encoding/base64"
-	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -24,13 +21,12 @@ type keyHeader struct {
 	Key string `header:"key"`
 }
 
-type timeoutStruct struct {
-	xorRand                               []byte
-	createdNanoTime, lastAccessedNanoTime int64
-}
-
 func (s *server) createKey(ginContext *gin.Context) {
-	ok := s.checkObjectCreationThrottler(ginContext, "KEY")
+	ok := s.checkKeyAuthentication(ginContext)
+	if !ok {
+		return
+	}
+	ok = s.checkObjectCreationThrottler(ginContext, "KEY")
 	if !ok {
 		return
 	}
@@ -46,7 +42,7 @@ func (s *server) createKey(ginContext *gin.Context) {
 		})
 		return
 	}
-	err = os.MkdirAll(s.folderNameFromKey(keyBytesArr), 0700)
+	err = s.storage.CreateKey(s.folderNameFromKey(keyBytesArr))
 	if err != nil {
 		log.Println(err)
 		ginContext.JSON(http.StatusInternalServerError, gin.H{
@@ -109,7 +105,7 @@ func (s *server) deleteKey(ginContext *gin.Context) {
 	}
 	s.globalLock.Lock()
 	defer s.globalLock.Unlock()
-	err := os.RemoveAll(folderName)
+	err := s.storage.DeleteKey(folderName)
 	if err != nil {
 		log.Println("error during key delete: " + err.Error())
 		ginContext.JSON(http.StatusNotFound, gin.H{
@@ -127,12 +123,6 @@ func (s *server) createToken(ginContext *gin.Context) {
 	if !ok {
 		return
 	}
-	s.globalLock.Lock()
-	defer s.globalLock.Unlock()
-	if tokenHash, exists := s.mapFolderNameToTokenHash[folderName]; exists {
-		// invalidate previous token
-		delete(s.mapTokenHashToTimeoutStruct, tokenHash)
-	}
 	// create a strong random 256 bit value (used to xor)
 	xorBytesArr := make([]byte, keySize)
 	n, err := rand.Read(xorBytesArr[:])
@@ -143,16 +133,27 @@ func (s *server) createToken(ginContext *gin.Context) {
 		})
 		return
 	}
-	now := time.Now().UnixNano()
+	now := time.Now()
 	token := xor(key, xorBytesArr)
-	tokenHash := hashSHA256(token)
-	s.housekeepingTokenMaps()
-	s.mapTokenHashToTimeoutStruct[tokenHash] = timeoutStruct{
-		xorRand:              xorBytesArr,
-		createdNanoTime:      now,
-		lastAccessedNanoTime: now,
+	err = s.housekeepingTokens(now)
+	if err != nil {
+		log.Println(err)
+	}
+	// this invalidates the previous token of the key, as the storage keeps a single token per key
+	err = s.storage.CreateToken(StoredToken{
+		Hash:         hashSHA256(token),
+		KeyID:        folderName,
+		XorRand:      xorBytesArr,
+		Created:      now,
+		LastAccessed: now,
+	})
+	if err != nil {
+		log.Println(err)
+		ginContext.JSON(http.StatusInternalServerError, gin.H{
+			"error": "unable to create token",
+		})
+		return
 	}
-	s.mapFolderNameToTokenHash[folderName] = tokenHash
 	ginContext.JSON(http.StatusCreated, gin.H{
 		"token": base64.RawURLEncoding.EncodeToString(token[:]),
 	})
@@ -163,6 +164,9 @@ type tokenHeader struct {
 }
 
 func (s *server) deleteToken(ginContext *gin.Context) {
+	if !s.checkKeyAuthentication(ginContext) {
+		return
+	}
 	header := tokenHeader{}
 	if err := ginContext.ShouldBindHeader(&header); err != nil {
 		ginContext.JSON(http.StatusNotFound, gin.H{
@@ -180,15 +184,35 @@ func (s *server) deleteToken(ginContext *gin.Context) {
 		})
 		return
 	}
-	s.globalLock.Lock()
-	defer s.globalLock.Unlock()
-	s.deleteTokenHashFromMaps(hashSHA256(token))
+	err = s.storage.DeleteToken(hashSHA256(token))
+	if err != nil {
+		log.Println(err)
+		ginContext.JSON(http.StatusInternalServerError, gin.H{
+			"error": "unable to delete token",
+		})
+		return
+	}
 	ginContext.JSON(http.StatusOK, gin.H{
 		"message": "token deleted",
 	})
 }
 
+// checkKeyAuthentication rejects the key and token endpoints when the server uses oidc authentication, where no keys
+// are handed out
+func (s *server) checkKeyAuthentication(ginContext *gin.Context) bool {
+	if s.oidc != nil {
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "key authentication is disabled, use a bearer token instead",
+		})
+		return false
+	}
+	return true
+}
+
 func (s *server) checkKeyToFolderName(ginContext *gin.Context) (folderNameOfKey string, key []byte, ok bool) {
+	if !s.checkKeyAuthentication(ginContext) {
+		return folderNameOfKey, key, false
+	}
 	header := keyHeader{}
 	if err := ginContext.ShouldBindHeader(&header); err != nil {
 		log.Println(err)
@@ -208,8 +232,10 @@ func (s *server) checkKeyToFolderName(ginContext *gin.Context) (folderNameOfKey
 		return folderNameOfKey, key, false
 	}
 	folderNameOfKey = s.folderNameFromKey(key)
-	if _, err := os.Stat(folderNameOfKey); os.IsNotExist(err) {
-		log.Println(err)
+	if exists, err := s.storage.KeyExists(folderNameOfKey); !exists {
+		if err != nil {
+			log.Println(err)
+		}
 		ginContext.JSON(http.StatusNotFound, gin.H{
 			"error": "key not found",
 		})
@@ -219,6 +245,9 @@ func (s *server) checkKeyToFolderName(ginContext *gin.Context) (folderNameOfKey
 }
 
 func (s *server) checkTokenToFolderName(ginContext *gin.Context) (folderNameOfKey string, key []byte, ok bool) {
+	if s.oidc != nil {
+		return s.checkBearerToFolderName(ginContext)
+	}
 	header := tokenHeader{}
 	if err := ginContext.ShouldBindHeader(&header); err != nil {
 		log.Println(err)
@@ -237,62 +266,58 @@ func (s *server) checkTokenToFolderName(ginContext *gin.Context) (folderNameOfKe
 		})
 		return folderNameOfKey, key, false
 	}
-	s.globalLock.Lock()
-	defer s.globalLock.Unlock()
-	s.housekeepingTokenMaps() // to remove timed-out ones
 	tokenHash := hashSHA256(token)
-	if timeoutStruct, exists := s.mapTokenHashToTimeoutStruct[tokenHash]; exists {
-		// re-create the key from token
-		key := xor(token, timeoutStruct.xorRand)
-		folderNameOfKey := s.folderNameFromKey(key)
-		if _, err := os.Stat(folderNameOfKey); os.IsNotExist(err) {
+	storedToken, exists, err := s.storage.ReadToken(tokenHash)
+	if err != nil {
+		log.Println(err)
+	}
+	now := time.Now()
+	if !exists || s.tokenExpired(storedToken, now) {
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "token not found",
+		})
+		return folderNameOfKey, key, false
+	}
+	// re-create the key from token
+	key = xor(token, storedToken.XorRand)
+	folderNameOfKey = s.folderNameFromKey(key)
+	if exists, err := s.storage.KeyExists(folderNameOfKey); !exists {
+		if err != nil {
 			log.Println(err)
-			ginContext.JSON(http.StatusNotFound, gin.H{
-				"error": "token not found",
-			})
-			return folderNameOfKey, key, false
 		}
-		timeoutStruct.lastAccessedNanoTime = time.Now().UnixNano()
-		return folderNameOfKey, key, true
-	} else {
 		ginContext.JSON(http.StatusNotFound, gin.H{
 			"error": "token not found",
 		})
 		return folderNameOfKey, key, false
 	}
+	err = s.storage.TouchToken(tokenHash, now)
+	if err != nil {
+		log.Println(err)
+	}
+	return folderNameOfKey, key, true
 }
 
+// folderNameFromKey provides the id of a key within the storage (named folder name, as it's the key folder of the
+// filesystem storage), which is its hash, so the key itself is never stored
 func (s *server) folderNameFromKey(key []byte) string {
-	sha512Hash := hashSHA256(key)
-	return filepath.Join(s.config.GetServerFolder(), s.config.GetKeyFolder(), sha512Hash)
+	return hashSHA256(key)
 }
 
-func (s *server) housekeepingTokenMaps() {
-	now := time.Now().UnixNano()
-	for tokenHash, val := range s.mapTokenHashToTimeoutStruct {
-		if s.extremeShortTimeoutsForTesting {
-			// remove all elements older than 1 minute (= 60000000000 ns) soft
-			// and all elements older than 3 minutes (= 180000000000 ns) hard
-			if now-val.lastAccessedNanoTime > 60000000000 || now-val.createdNanoTime > 180000000000 {
-				fmt.Println("About to remove a token hash from maps")
-				s.deleteTokenHashFromMaps(tokenHash)
-			}
-		} else {
-			// remove all elements older than 30 minutes (= 1800000000000 ns) soft
-			// and all elements older than 10 hours (= 36000000000000 ns) hard
-			if now-val.lastAccessedNanoTime > 1800000000000 || now-val.createdNanoTime > 36000000000000 {
-				s.deleteTokenHashFromMaps(tokenHash)
-			}
-		}
+// tokenTimeouts provides how long a token stays valid after its last access (soft) and after its creation (hard)
+func (s *server) tokenTimeouts() (soft time.Duration, hard time.Duration) {
+	if s.extremeShortTimeoutsForTesting {
+		return time.Minute, 3 * time.Minute
 	}
+	return 30 * time.Minute, 10 * time.Hour
 }
 
-func (s *server) deleteTokenHashFromMaps(tokenHash string) {
-	delete(s.mapTokenHashToTimeoutStruct, tokenHash)
-	for folderName, check := range s.mapFolderNameToTokenHash {
-		if check == tokenHash {
-			delete(s.mapFolderNameToTokenHash, folderName)
-			break
-		}
-	}
+func (s *server) tokenExpired(token StoredToken, now time.Time) bool {
+	soft, hard := s.tokenTimeouts()
+	return now.Sub(token.LastAccessed) > soft || now.Sub(token.Created) > hard
+}
+
+// housekeepingTokens removes the timed-out tokens from the storage
+func (s *server) housekeepingTokens(now time.Time) error {
+	soft, hard := s.tokenTimeouts()
+	return s.storage.DeleteExpiredTokens(now.Add(-soft), now.Add(-hard))
 }
@@ -0,0 +1,349 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/threagile/threagile/pkg/input"
+	"github.com/threagile/threagile/pkg/types"
+)
+
+type payloadTrustBoundary struct {
+	Title                 string   `yaml:"title" json:"title"`
+	Id                    string   `yaml:"id" json:"id"`
+	Description           string   `yaml:"description" json:"description"`
+	Type                  string   `yaml:"type" json:"type"`
+	Tags                  []string `yaml:"tags" json:"tags"`
+	TechnicalAssetsInside []string `yaml:"technical_assets_inside" json:"technical_assets_inside"`
+	TrustBoundariesNested []string `yaml:"trust_boundaries_nested" json:"trust_boundaries_nested"`
+}
+
+func (s *server) getTrustBoundaries(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	aModel, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		ginContext.JSON(http.StatusOK, aModel.TrustBoundaries)
+	}
+}
+
+func (s *server) getTrustBoundary(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title, trustBoundary := range modelInput.TrustBoundaries {
+			if trustBoundary.ID == ginContext.Param("trust-boundary-id") {
+				ginContext.JSON(http.StatusOK, gin.H{
+					title: trustBoundary,
+				})
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "trust boundary not found",
+		})
+	}
+}
+
+func (s *server) createNewTrustBoundary(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		payload := payloadTrustBoundary{}
+		err := ginContext.BindJSON(&payload)
+		if err != nil {
+			log.Println(err)
+			ginContext.JSON(http.StatusBadRequest, gin.H{
+				"error": "unable to parse request payload",
+			})
+			return
+		}
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		if _, exists := modelInput.TrustBoundaries[payload.Title]; exists {
+			ginContext.JSON(http.StatusConflict, gin.H{
+				"error": "trust boundary with this title already exists",
+			})
+			return
+		}
+		// but later it will in memory keyed by its "id", so do this uniqueness check also
+		for _, trustBoundary := range modelInput.TrustBoundaries {
+			if trustBoundary.ID == payload.Id {
+				ginContext.JSON(http.StatusConflict, gin.H{
+					"error": "trust boundary with this id already exists",
+				})
+				return
+			}
+		}
+		trustBoundaryInput, ok := populateTrustBoundary(ginContext, payload)
+		if !ok {
+			return
+		}
+		if !checkTrustBoundaryReferences(ginContext, modelInput, "", trustBoundaryInput) {
+			return
+		}
+		if modelInput.TrustBoundaries == nil {
+			modelInput.TrustBoundaries = make(map[string]input.TrustBoundary)
+		}
+		modelInput.TrustBoundaries[payload.Title] = trustBoundaryInput
+		ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Trust Boundary Creation")
+		if ok {
+			ginContext.JSON(http.StatusOK, gin.H{
+				"message": "trust boundary created",
+				"id":      trustBoundaryInput.ID,
+			})
+		}
+	}
+}
+
+func (s *server) setTrustBoundary(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title, trustBoundary := range modelInput.TrustBoundaries {
+			if trustBoundary.ID == ginContext.Param("trust-boundary-id") {
+				payload := payloadTrustBoundary{}
+				err := ginContext.BindJSON(&payload)
+				if err != nil {
+					log.Println(err)
+					ginContext.JSON(http.StatusBadRequest, gin.H{
+						"error": "unable to parse request payload",
+					})
+					return
+				}
+				trustBoundaryInput, ok := populateTrustBoundary(ginContext, payload)
+				if !ok {
+					return
+				}
+				for otherTitle, other := range modelInput.TrustBoundaries {
+					if otherTitle != title && (otherTitle == payload.Title || other.ID == payload.Id) {
+						ginContext.JSON(http.StatusConflict, gin.H{
+							"error": "trust boundary with this title or id already exists",
+						})
+						return
+					}
+				}
+				if !checkTrustBoundaryReferences(ginContext, modelInput, trustBoundary.ID, trustBoundaryInput) {
+					return
+				}
+				// in order to also update the title, remove the trust boundary from the map and re-insert it (with new key)
+				delete(modelInput.TrustBoundaries, title)
+				modelInput.TrustBoundaries[payload.Title] = trustBoundaryInput
+				idChanged := trustBoundaryInput.ID != trustBoundary.ID
+				if idChanged { // ID-CHANGE-PROPAGATION
+					for otherTitle, other := range modelInput.TrustBoundaries {
+						for i, nested := range other.TrustBoundariesNested {
+							if nested == trustBoundary.ID { // apply the ID change
+								modelInput.TrustBoundaries[otherTitle].TrustBoundariesNested[i] = trustBoundaryInput.ID
+							}
+						}
+					}
+					for _, individualRiskCat := range modelInput.CustomRiskCategories {
+						if individualRiskCat.RisksIdentified != nil {
+							for individualRiskInstanceTitle, individualRiskInstance := range individualRiskCat.RisksIdentified {
+								if individualRiskInstance.MostRelevantTrustBoundary == trustBoundary.ID { // apply the ID change
+									x := individualRiskCat.RisksIdentified[individualRiskInstanceTitle]
+									x.MostRelevantTrustBoundary = trustBoundaryInput.ID
+									individualRiskCat.RisksIdentified[individualRiskInstanceTitle] = x
+								}
+							}
+						}
+					}
+				}
+				ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Trust Boundary Update")
+				if ok {
+					ginContext.JSON(http.StatusOK, gin.H{
+						"message":    "trust boundary updated",
+						"id":         trustBoundaryInput.ID,
+						"id_changed": idChanged, // in order to signal to clients, that other model parts might've received updates as well and should be reloaded
+					})
+				}
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "trust boundary not found",
+		})
+	}
+}
+
+func (s *server) deleteTrustBoundary(ginContext *gin.Context) {
+	folderNameOfKey, key, ok := s.checkTokenToFolderName(ginContext)
+	if !ok {
+		return
+	}
+	s.lockFolder(folderNameOfKey)
+	defer s.unlockFolder(folderNameOfKey)
+	modelInput, _, ok := s.readModel(ginContext, ginContext.Param("model-id"), key, folderNameOfKey)
+	if ok {
+		referencesDeleted := false
+		// yes, here keyed by title in YAML for better readability in the YAML file itself
+		for title, trustBoundary := range modelInput.TrustBoundaries {
+			if trustBoundary.ID == ginContext.Param("trust-boundary-id") {
+				// also remove all usages of this trust boundary !! (nested boundaries and assets inside are kept, they are just no longer surrounded by it)
+				for otherTitle, other := range modelInput.TrustBoundaries {
+					if slices.Contains(other.TrustBoundariesNested, trustBoundary.ID) { // apply the removal
+						referencesDeleted = true
+						other.TrustBoundariesNested = removeValue(other.TrustBoundariesNested, trustBoundary.ID)
+						modelInput.TrustBoundaries[otherTitle] = other
+					}
+				}
+				for _, individualRiskCat := range modelInput.CustomRiskCategories {
+					if individualRiskCat.RisksIdentified != nil {
+						for individualRiskInstanceTitle, individualRiskInstance := range individualRiskCat.RisksIdentified {
+							if individualRiskInstance.MostRelevantTrustBoundary == trustBoundary.ID { // apply the removal
+								referencesDeleted = true
+								x := individualRiskCat.RisksIdentified[individualRiskInstanceTitle]
+								x.MostRelevantTrustBoundary = ""
+								individualRiskCat.RisksIdentified[individualRiskInstanceTitle] = x
+							}
+						}
+					}
+				}
+				// remove it itself
+				delete(modelInput.TrustBoundaries, title)
+				ok = s.writeModel(ginContext, key, folderNameOfKey, &modelInput, "Trust Boundary Deletion")
+				if ok {
+					ginContext.JSON(http.StatusOK, gin.H{
+						"message":            "trust boundary deleted",
+						"id":                 trustBoundary.ID,
+						"references_deleted": referencesDeleted, // in order to signal to clients, that other model parts might've been deleted as well
+					})
+				}
+				return
+			}
+		}
+		ginContext.JSON(http.StatusNotFound, gin.H{
+			"error": "trust boundary not found",
+		})
+	}
+}
+
+func populateTrustBoundary(ginContext *gin.Context, payload payloadTrustBoundary) (trustBoundaryInput input.TrustBoundary, ok bool) {
+	trustBoundaryType, err := types.ParseTrustBoundary(payload.Type)
+	if err != nil {
+		handleErrorInServiceCall(err, ginContext)
+		return trustBoundaryInput, false
+	}
+	trustBoundaryInput = input.TrustBoundary{
+		ID:                    payload.Id,
+		Description:           payload.Description,
+		Type:                  trustBoundaryType.String(),
+		Tags:                  lowerCaseAndTrim(payload.Tags),
+		TechnicalAssetsInside: payload.TechnicalAssetsInside,
+		TrustBoundariesNested: payload.TrustBoundariesNested,
+	}
+	return trustBoundaryInput, true
+}
+
+// checkTrustBoundaryReferences validates the assets inside and the nested boundaries of a new or updated (previousID)
+// trust boundary: they have to exist and must not be part of another trust boundary, and nesting must not form a cycle
+func checkTrustBoundaryReferences(ginContext *gin.Context, modelInput input.Model, previousID string, trustBoundary input.TrustBoundary) (ok bool) {
+	err := trustBoundaryReferencesError(modelInput, previousID, trustBoundary)
+	if err != nil {
+		ginContext.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func trustBoundaryReferencesError(modelInput input.Model, previousID string, trustBoundary input.TrustBoundary) error {
+	if !checkTechnicalAssetsExisting(modelInput, trustBoundary.TechnicalAssetsInside) {
+		return fmt.Errorf("referenced technical asset does not exist")
+	}
+
+	boundaryIDs := make(map[string]bool)
+	nestedBy := make(map[string]string)
+	for _, other := range modelInput.TrustBoundaries {
+		if other.ID == previousID {
+			continue
+		}
+		boundaryIDs[other.ID] = true
+		for _, assetID := range trustBoundary.TechnicalAssetsInside {
+			if slices.Contains(other.TechnicalAssetsInside, assetID) {
+				return fmt.Errorf("technical asset %q is already inside trust boundary %q", assetID, other.ID)
+			}
+		}
+		for _, nestedID := range other.TrustBoundariesNested {
+			if nestedID != previousID {
+				nestedBy[nestedID] = other.ID
+			}
+		}
+	}
+
+	for _, nestedID := range trustBoundary.TrustBoundariesNested {
+		if nestedID == trustBoundary.ID || nestedID == previousID {
+			return fmt.Errorf("trust boundary %q cannot be nested into itself", nestedID)
+		}
+		if !boundaryIDs[nestedID] {
+			return fmt.Errorf("referenced trust boundary %q does not exist", nestedID)
+		}
+		if parentID, nested := nestedBy[nestedID]; nested {
+			return fmt.Errorf("trust boundary %q is already nested into trust boundary %q", nestedID, parentID)
+		}
+	}
+
+	// the nesting after the change must stay a tree: the boundary must not be reachable from its own nested boundaries
+	children := map[string][]string{trustBoundary.ID: trustBoundary.TrustBoundariesNested}
+	for _, other := range modelInput.TrustBoundaries {
+		if other.ID == previousID {
+			continue
+		}
+		for _, nestedID := range other.TrustBoundariesNested {
+			if nestedID == previousID {
+				nestedID = trustBoundary.ID
+			}
+			children[other.ID] = append(children[other.ID], nestedID)
+		}
+	}
+	visited := make(map[string]bool)
+	pending := slices.Clone(trustBoundary.TrustBoundariesNested)
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		if current == trustBoundary.ID {
+			return fmt.Errorf("nesting of trust boundary %q would create a cycle", trustBoundary.ID)
+		}
+		if !visited[current] {
+			visited[current] = true
+			pending = append(pending, children[current]...)
+		}
+	}
+
+	return nil
+}
+
+func removeValue(values []string, value string) []string {
+	result := make([]string, 0, len(values))
+	for _, candidate := range values {
+		if candidate != value {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
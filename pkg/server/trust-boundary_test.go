@@ -0,0 +1,108 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/threagile/threagile/pkg/input"
+)
+
+// newTrustBoundaryTestModel nests "core" into "inner" and "inner" into "outer", "other" stands alone
+func newTrustBoundaryTestModel() input.Model {
+	return input.Model{
+		TechnicalAssets: map[string]input.TechnicalAsset{
+			"Browser":    {ID: "browser"},
+			"Web Server": {ID: "web"},
+			"Database":   {ID: "db"},
+		},
+		TrustBoundaries: map[string]input.TrustBoundary{
+			"Outer": {ID: "outer", TechnicalAssetsInside: []string{"web"}, TrustBoundariesNested: []string{"inner"}},
+			"Inner": {ID: "inner", TechnicalAssetsInside: []string{"db"}, TrustBoundariesNested: []string{"core"}},
+			"Core":  {ID: "core"},
+			"Other": {ID: "other"},
+		},
+	}
+}
+
+func TestTrustBoundaryReferencesError(t *testing.T) {
+	tests := []struct {
+		name          string
+		previousID    string
+		trustBoundary input.TrustBoundary
+		err           string
+	}{
+		{
+			name:          "new boundary",
+			trustBoundary: input.TrustBoundary{ID: "new", TechnicalAssetsInside: []string{"browser"}, TrustBoundariesNested: []string{"other"}},
+		},
+		{
+			name:          "unchanged boundary",
+			previousID:    "outer",
+			trustBoundary: input.TrustBoundary{ID: "outer", TechnicalAssetsInside: []string{"web"}, TrustBoundariesNested: []string{"inner"}},
+		},
+		{
+			name:          "renamed boundary",
+			previousID:    "inner",
+			trustBoundary: input.TrustBoundary{ID: "middle", TechnicalAssetsInside: []string{"db"}, TrustBoundariesNested: []string{"core"}},
+		},
+		{
+			name:          "unknown technical asset",
+			trustBoundary: input.TrustBoundary{ID: "new", TechnicalAssetsInside: []string{"missing"}},
+			err:           "referenced technical asset does not exist",
+		},
+		{
+			name:          "technical asset inside another boundary",
+			trustBoundary: input.TrustBoundary{ID: "new", TechnicalAssetsInside: []string{"db"}},
+			err:           `technical asset "db" is already inside trust boundary "inner"`,
+		},
+		{
+			name:          "unknown nested boundary",
+			trustBoundary: input.TrustBoundary{ID: "new", TrustBoundariesNested: []string{"missing"}},
+			err:           `referenced trust boundary "missing" does not exist`,
+		},
+		{
+			name:          "nested into itself",
+			previousID:    "other",
+			trustBoundary: input.TrustBoundary{ID: "other", TrustBoundariesNested: []string{"other"}},
+			err:           `trust boundary "other" cannot be nested into itself`,
+		},
+		{
+			name:          "boundary nested into another boundary",
+			trustBoundary: input.TrustBoundary{ID: "new", TrustBoundariesNested: []string{"inner"}},
+			err:           `trust boundary "inner" is already nested into trust boundary "outer"`,
+		},
+		{
+			name:          "cycle of two",
+			previousID:    "inner",
+			trustBoundary: input.TrustBoundary{ID: "inner", TechnicalAssetsInside: []string{"db"}, TrustBoundariesNested: []string{"core", "outer"}},
+			err:           `nesting of trust boundary "inner" would create a cycle`,
+		},
+		{
+			name:          "cycle of three",
+			previousID:    "core",
+			trustBoundary: input.TrustBoundary{ID: "core", TrustBoundariesNested: []string{"outer"}},
+			err:           `nesting of trust boundary "core" would create a cycle`,
+		},
+		{
+			name:          "cycle through renamed boundary",
+			previousID:    "inner",
+			trustBoundary: input.TrustBoundary{ID: "middle", TrustBoundariesNested: []string{"outer"}},
+			err:           `nesting of trust boundary "middle" would create a cycle`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := trustBoundaryReferencesError(newTrustBoundaryTestModel(), test.previousID, test.trustBoundary)
+
+			if len(test.err) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, test.err, err.Error())
+		})
+	}
+}
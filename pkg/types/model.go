@@ -54,6 +54,7 @@ type Model struct {
 	DirectContainingTrustBoundaryMappedByTechnicalAssetId map[string]*TrustBoundary       `json:"direct_containing_trust_boundary_mapped_by_technical_asset_id,omitempty" yaml:"direct_containing_trust_boundary_mapped_by_technical_asset_id,omitempty"`
 	GeneratedRisksByCategory                              map[string][]*Risk              `json:"generated_risks_by_category,omitempty" yaml:"generated_risks_by_category,omitempty"`
 	GeneratedRisksBySyntheticId                           map[string]*Risk                `json:"generated_risks_by_synthetic_id,omitempty" yaml:"generated_risks_by_synthetic_id,omitempty"`
+	SourcePositions                                       map[string]*Position            `json:"-" yaml:"-"`
 }
 
 type ProgressReporter interface {
@@ -139,18 +140,21 @@ func (model *Model) ApplyWildcardRiskTrackingEvaluation(ignoreOrphanedRiskTracki
 	return nil
 }
 
+// IsMatchingAnyGeneratedRisk checks whether a synthetic risk id, which may contain wildcards, matches any generated risk
+func (model *Model) IsMatchingAnyGeneratedRisk(syntheticRiskIdPattern string) bool {
+	var matchingRiskIdExpression = regexp.MustCompile(strings.ReplaceAll(regexp.QuoteMeta(syntheticRiskIdPattern), `\*`, `[^@]+`))
+	for syntheticRiskId := range model.GeneratedRisksBySyntheticId {
+		if matchingRiskIdExpression.Match([]byte(syntheticRiskId)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (model *Model) CheckRiskTracking(ignoreOrphanedRiskTracking bool, progressReporter ProgressReporter) error {
 	progressReporter.Info("Checking risk tracking")
 	for _, tracking := range model.RiskTracking {
-		foundSome := false
-		var matchingRiskIdExpression = regexp.MustCompile(strings.ReplaceAll(regexp.QuoteMeta(tracking.SyntheticRiskId), `\*`, `[^@]+`))
-		for syntheticRiskId := range model.GeneratedRisksBySyntheticId {
-			if matchingRiskIdExpression.Match([]byte(syntheticRiskId)) {
-				foundSome = true
-			}
-		}
-
-		if !foundSome {
+		if !model.IsMatchingAnyGeneratedRisk(tracking.SyntheticRiskId) {
 			if ignoreOrphanedRiskTracking {
 				progressReporter.Infof("Risk tracking references unknown risk (risk id not found): %v", tracking.SyntheticRiskId)
 			} else {
@@ -511,6 +515,48 @@ func (model *Model) GetRiskCategory(categoryID string) *RiskCategory {
 	return nil
 }
 
+// AddPosition records the source position of a model element, kind is the model file section like "technical_assets"
+func (model *Model) AddPosition(kind string, id string, position *Position) {
+	if position == nil {
+		return
+	}
+
+	if model.SourcePositions == nil {
+		model.SourcePositions = make(map[string]*Position)
+	}
+
+	model.SourcePositions[kind+"/"+id] = position
+}
+
+func (model *Model) GetPosition(kind string, id string) *Position {
+	if len(id) == 0 {
+		return nil
+	}
+
+	return model.SourcePositions[kind+"/"+id]
+}
+
+// GetRiskPosition returns the source position of the most relevant model element of a risk
+func (model *Model) GetRiskPosition(risk *Risk) *Position {
+	if risk.Position != nil {
+		return risk.Position
+	}
+
+	for _, position := range []*Position{
+		model.GetPosition("communication_links", risk.MostRelevantCommunicationLinkId),
+		model.GetPosition("technical_assets", risk.MostRelevantTechnicalAssetId),
+		model.GetPosition("trust_boundaries", risk.MostRelevantTrustBoundaryId),
+		model.GetPosition("shared_runtimes", risk.MostRelevantSharedRuntimeId),
+		model.GetPosition("data_assets", risk.MostRelevantDataAssetId),
+	} {
+		if position != nil {
+			return position
+		}
+	}
+
+	return nil
+}
+
 func (model *Model) AllRisks() []*Risk {
 	result := make([]*Risk, 0)
 	for _, risks := range model.GeneratedRisksByCategory {
@@ -0,0 +1,56 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Position is the location of a model element in one of the model source files
+type Position struct {
+	File   string `json:"file,omitempty" yaml:"file,omitempty"`
+	Line   int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column int    `json:"column,omitempty" yaml:"column,omitempty"`
+}
+
+func (what *Position) String() string {
+	switch {
+	case what == nil:
+		return ""
+
+	case what.Line == 0:
+		return what.File
+
+	case what.Column == 0:
+		return fmt.Sprintf("%v:%d", what.File, what.Line)
+	}
+
+	return fmt.Sprintf("%v:%d:%d", what.File, what.Line, what.Column)
+}
+
+// PositionError is an error caused by the model element at the given position
+type PositionError struct {
+	Position *Position
+	Err      error
+}
+
+// NewPositionError adds the position to an error, unless it already carries a (more precise) one
+func NewPositionError(position *Position, err error) error {
+	if err == nil || position == nil {
+		return err
+	}
+
+	var positionError *PositionError
+	if errors.As(err, &positionError) {
+		return err
+	}
+
+	return &PositionError{Position: position, Err: err}
+}
+
+func (what *PositionError) Error() string {
+	return fmt.Sprintf("%v: %v", what.Position, what.Err)
+}
+
+func (what *PositionError) Unwrap() error {
+	return what.Err
+}
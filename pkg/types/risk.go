@@ -17,5 +17,6 @@ type Risk struct {
 	DataBreachTechnicalAssetIDs     []string                   `yaml:"data_breach_technical_assets,omitempty" json:"data_breach_technical_assets,omitempty"`
 	RiskExplanation                 []string                   `yaml:"risk_explanation,omitempty" json:"risk_explanation,omitempty"`
 	RatingExplanation               []string                   `yaml:"rating_explanation,omitempty" json:"rating_explanation,omitempty"`
+	Position                        *Position                  `yaml:"position,omitempty" json:"position,omitempty"` // source position of the most relevant model element, is assigned in risk evaluation phase automatically
 	// TODO: refactor all "ID" here to "ID"?
 }